@@ -0,0 +1,17 @@
+#!/usr/bin/env bash
+
+set -euo pipefail
+
+HELM_VERSION="v3.14.4"
+HELM_TARBALL="helm-${HELM_VERSION}-linux-amd64.tar.gz"
+echo "Downloading helm ${HELM_VERSION}"
+curl -fsSLO "https://get.helm.sh/${HELM_TARBALL}"
+curl -fsSLO "https://get.helm.sh/${HELM_TARBALL}.sha256sum"
+sha256sum -c "${HELM_TARBALL}.sha256sum"
+
+echo "unpacking helm"
+tar -xzf "${HELM_TARBALL}" linux-amd64/helm
+sudo install -m 0755 linux-amd64/helm /usr/local/bin/helm
+rm -rf linux-amd64 "${HELM_TARBALL}" "${HELM_TARBALL}.sha256sum"
+
+helm version
@@ -0,0 +1,274 @@
+package e2e_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+)
+
+const (
+	// private endpoints require the premium SKU
+	privateACRSKU = "Premium"
+
+	registryIDTemplate            = "/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ContainerRegistry/registries/%s"
+	privateDNSZoneIDTemplate      = "/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/privateDnsZones/%s"
+	privateEndpointIDTemplate     = "/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/privateEndpoints/%s"
+	acrPullRoleDefinitionTemplate = "/subscriptions/%s/providers/Microsoft.Authorization/roleDefinitions/7f951dda-4ed3-4680-a7ca-43fe172d538d"
+	importImageURLTemplate        = "%s%s/importImage?api-version=%s"
+
+	registryAPIVersion        = "2023-07-01"
+	privateDNSZoneAPIVersion  = "2020-06-01"
+	privateEndpointAPIVersion = "2022-07-01"
+	roleAssignmentAPIVersion  = "2022-04-01"
+
+	acrPrivateLinkGroupID = "registry"
+	// the VNet link, private endpoint connection and DNS zone group are the only ones of their kind within their parents
+	privateACRChildResourceName = "agentbaker-e2e"
+
+	// imported into the private ACR from MCR, such that scenarios can pull it from the private ACR
+	privateACRSourceRegistry = "mcr.microsoft.com"
+	privateACRSourceImage    = "oss/nginx/nginx:1.21.6"
+	privateACRImage          = "nginx:1.21.6"
+)
+
+type privateACR struct {
+	resourceID  string
+	loginServer string
+	// the private IPs of the registry's private endpoint, to which its login server is expected to resolve from the VNet
+	endpointIPs []string
+	// the image imported into the registry, qualified with its login server
+	image string
+}
+
+var (
+	// serializes the ensuring of the private ACR by concurrently-running scenarios
+	privateACRMu sync.Mutex
+	// maps the ID of each cluster VNet to the private ACR reachable from it, once ensured
+	privateACRs = map[string]*privateACR{}
+)
+
+// Ensures the suite's private ACR exists within the suite's resource group, containing an image imported from MCR, that the
+// specified identity can pull from it, and that it's reachable from the cluster's VNet through a private endpoint, whose
+// private DNS zone is linked to the VNet such that the registry's login server resolves to the endpoint's private IP
+func ensurePrivateACR(ctx context.Context, cloud *azureClient, suiteConfig *suiteConfig, clusterConfig clusterConfig, identity *userAssignedIdentity) (*privateACR, error) {
+	privateACRMu.Lock()
+	defer privateACRMu.Unlock()
+
+	vnetID, _, found := strings.Cut(clusterConfig.subnetId, "/subnets/")
+	if !found {
+		return nil, fmt.Errorf("unable to determine the VNet of subnet %q", clusterConfig.subnetId)
+	}
+	if acr, ok := privateACRs[vnetID]; ok {
+		return acr, nil
+	}
+
+	// each of the resources below is put on every run, restamping the tags, and so the expiry, of those reused from previous runs
+	tags := getResourceTags(suiteConfig, "", clusterResourceTTL)
+	nodeResourceGroup := *clusterConfig.cluster.Properties.NodeResourceGroup
+
+	// registry names are globally unique, so the name is derived from the suite's subscription and resource group
+	name := fmt.Sprintf("abe2e%x", sha256.Sum256([]byte(suiteConfig.subscription+suiteConfig.resourceGroupName)))[:20]
+	registryID := fmt.Sprintf(registryIDTemplate, suiteConfig.subscription, suiteConfig.resourceGroupName, name)
+	log.Printf("ensuring private ACR %q...", registryID)
+	registry, err := createOrUpdateResource(ctx, cloud, registryID, registryAPIVersion, armresources.GenericResource{
+		Location: to.Ptr(suiteConfig.location),
+		Tags:     tags,
+		SKU:      &armresources.SKU{Name: to.Ptr(privateACRSKU)},
+		Properties: map[string]any{
+			"adminUserEnabled": false,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	properties, _ := registry.Properties.(map[string]any)
+	loginServer, _ := properties["loginServer"].(string)
+	if loginServer == "" {
+		return nil, fmt.Errorf("private ACR %q is missing its login server: %v", registryID, registry.Properties)
+	}
+
+	if err := importImage(ctx, cloud, registryID); err != nil {
+		return nil, err
+	}
+
+	// role assignments are named by GUIDs, which are derived from the assignment's scope and principal such that reassigning
+	// the role to the same principal is idempotent
+	roleAssignmentName := formatGUID(sha256.Sum256([]byte(registryID + identity.principalID)))
+	log.Printf("assigning AcrPull on private ACR %q to principal %q...", registryID, identity.principalID)
+	if _, err := createOrUpdateResource(ctx, cloud, fmt.Sprintf("%s/providers/Microsoft.Authorization/roleAssignments/%s", registryID, roleAssignmentName), roleAssignmentAPIVersion, armresources.GenericResource{
+		Properties: map[string]any{
+			"roleDefinitionId": fmt.Sprintf(acrPullRoleDefinitionTemplate, suiteConfig.subscription),
+			"principalId":      identity.principalID,
+			// avoids failures caused by the replication delay of newly-created identities
+			"principalType": "ServicePrincipal",
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	zoneID := fmt.Sprintf(privateDNSZoneIDTemplate, suiteConfig.subscription, nodeResourceGroup, cloud.environment.acrPrivateDNSZoneName())
+	log.Printf("ensuring private DNS zone %q is linked to VNet %q...", zoneID, vnetID)
+	if _, err := createOrUpdateResource(ctx, cloud, zoneID, privateDNSZoneAPIVersion, armresources.GenericResource{
+		Location: to.Ptr("global"),
+		Tags:     tags,
+	}); err != nil {
+		return nil, err
+	}
+	if _, err := createOrUpdateResource(ctx, cloud, fmt.Sprintf("%s/virtualNetworkLinks/%s", zoneID, privateACRChildResourceName), privateDNSZoneAPIVersion, armresources.GenericResource{
+		Location: to.Ptr("global"),
+		Tags:     tags,
+		Properties: map[string]any{
+			"virtualNetwork":      map[string]any{"id": vnetID},
+			"registrationEnabled": false,
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	endpointID := fmt.Sprintf(privateEndpointIDTemplate, suiteConfig.subscription, nodeResourceGroup, name)
+	log.Printf("ensuring private endpoint %q of private ACR %q...", endpointID, registryID)
+	if _, err := createOrUpdateResource(ctx, cloud, endpointID, privateEndpointAPIVersion, armresources.GenericResource{
+		Location: to.Ptr(suiteConfig.location),
+		Tags:     tags,
+		Properties: map[string]any{
+			"subnet": map[string]any{"id": clusterConfig.subnetId},
+			"privateLinkServiceConnections": []any{
+				map[string]any{
+					"name": privateACRChildResourceName,
+					"properties": map[string]any{
+						"privateLinkServiceId": registryID,
+						"groupIds":             []string{acrPrivateLinkGroupID},
+					},
+				},
+			},
+		},
+	}); err != nil {
+		return nil, err
+	}
+	// registers the A records of the endpoint's private IPs within the private DNS zone
+	zoneGroup, err := createOrUpdateResource(ctx, cloud, fmt.Sprintf("%s/privateDnsZoneGroups/%s", endpointID, privateACRChildResourceName), privateEndpointAPIVersion, armresources.GenericResource{
+		Properties: map[string]any{
+			"privateDnsZoneConfigs": []any{
+				map[string]any{
+					"name":       strings.ReplaceAll(cloud.environment.acrPrivateDNSZoneName(), ".", "-"),
+					"properties": map[string]any{"privateDnsZoneId": zoneID},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	endpointIPs := privateDNSRecordIPs(zoneGroup.Properties, name)
+	if len(endpointIPs) == 0 {
+		return nil, fmt.Errorf("private endpoint %q has no A record for private ACR %q: %v", endpointID, registryID, zoneGroup.Properties)
+	}
+
+	acr := &privateACR{
+		resourceID:  registryID,
+		loginServer: loginServer,
+		endpointIPs: endpointIPs,
+		image:       fmt.Sprintf("%s/%s", loginServer, privateACRImage),
+	}
+	privateACRs[vnetID] = acr
+	return acr, nil
+}
+
+// Returns the IPs of the named A record set registered by a private DNS zone group, given the zone group's properties, e.g.
+// those of the record set named after a registry, rather than that of its regional data endpoint
+func privateDNSRecordIPs(zoneGroupProperties any, recordSetName string) []string {
+	properties, _ := zoneGroupProperties.(map[string]any)
+	zoneConfigs, _ := properties["privateDnsZoneConfigs"].([]any)
+	var ips []string
+	for _, zoneConfig := range zoneConfigs {
+		zoneConfigFields, _ := zoneConfig.(map[string]any)
+		zoneConfigProperties, _ := zoneConfigFields["properties"].(map[string]any)
+		recordSets, _ := zoneConfigProperties["recordSets"].([]any)
+		for _, recordSet := range recordSets {
+			record, _ := recordSet.(map[string]any)
+			if name, _ := record["recordSetName"].(string); !strings.EqualFold(name, recordSetName) {
+				continue
+			}
+			addresses, _ := record["ipAddresses"].([]any)
+			for _, address := range addresses {
+				if ip, ok := address.(string); ok {
+					ips = append(ips, ip)
+				}
+			}
+		}
+	}
+	return ips
+}
+
+// Imports the source image from MCR into the registry, overwriting any image previously imported with the same tag
+func importImage(ctx context.Context, cloud *azureClient, registryID string) error {
+	log.Printf("importing image %s/%s into private ACR %q...", privateACRSourceRegistry, privateACRSourceImage, registryID)
+	req, err := runtime.NewRequest(ctx, http.MethodPost, fmt.Sprintf(importImageURLTemplate, cloud.environment.resourceManagerEndpoint(), registryID, registryAPIVersion))
+	if err != nil {
+		return err
+	}
+	if err := runtime.MarshalAsJSON(req, map[string]any{
+		"source": map[string]any{
+			"registryUri": privateACRSourceRegistry,
+			"sourceImage": privateACRSourceImage,
+		},
+		"targetTags": []string{privateACRImage},
+		"mode":       "Force",
+	}); err != nil {
+		return err
+	}
+
+	resp, err := cloud.coreClient.Pipeline().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to begin importing image into private ACR %q: %w", registryID, err)
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK, http.StatusAccepted) {
+		return fmt.Errorf("failed to begin importing image into private ACR %q: %w", registryID, runtime.NewResponseError(resp))
+	}
+	poller, err := runtime.NewPoller[any](resp, cloud.coreClient.Pipeline(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to poll the import of image into private ACR %q: %w", registryID, err)
+	}
+	if _, err := poller.PollUntilDone(ctx, nil); err != nil {
+		return fmt.Errorf("failed to import image into private ACR %q: %w", registryID, err)
+	}
+	return nil
+}
+
+func createOrUpdateResource(ctx context.Context, cloud *azureClient, resourceID, apiVersion string, resource armresources.GenericResource) (*armresources.GenericResource, error) {
+	poller, err := cloud.resourceClient.BeginCreateOrUpdateByID(ctx, resourceID, apiVersion, resource, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin creating %q: %w", resourceID, err)
+	}
+	resp, err := poller.PollUntilDone(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %q: %w", resourceID, err)
+	}
+	return &resp.GenericResource, nil
+}
+
+// Formats the first 16 bytes of a digest as a GUID, e.g. "0a1b2c3d-4e5f-6a7b-8c9d-0e1f2a3b4c5d"
+func formatGUID(digest [sha256.Size]byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", digest[0:4], digest[4:6], digest[6:8], digest[8:10], digest[10:16])
+}
+
+// Validates that a pod pinned to the node can pull the image imported into the private ACR, which kubelet authenticates
+// with using the node's kubelet identity
+func validatePrivateACRPull(ctx context.Context, kube *kubeclient, namespace, nodeName string, acr *privateACR) error {
+	podName := fmt.Sprintf("%s-private-acr", nodeName)
+	if err := ensurePod(ctx, kube, namespace, podName, getPrivateACRPodTemplate(namespace, nodeName, acr.image)); err != nil {
+		return fmt.Errorf("failed to ensure pod %q pulling %s from private ACR: %w", podName, acr.image, err)
+	}
+	if err := waitUntilPodDeleted(ctx, kube, namespace, podName); err != nil {
+		return fmt.Errorf("error waiting for pod %q to be deleted: %w", podName, err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package e2e_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPrivateDNSRecordIPs(t *testing.T) {
+	cases := []struct {
+		name       string
+		properties string
+		expected   []string
+	}{
+		{
+			name: "registry and data endpoint records",
+			properties: `{
+				"privateDnsZoneConfigs": [{
+					"name": "privatelink-azurecr-io",
+					"properties": {
+						"privateDnsZoneId": "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/privateDnsZones/privatelink.azurecr.io",
+						"recordSets": [
+							{"recordType": "A", "recordSetName": "abe2eregistry", "fqdn": "abe2eregistry.privatelink.azurecr.io", "ipAddresses": ["10.224.0.7"]},
+							{"recordType": "A", "recordSetName": "abe2eregistry.eastus.data", "fqdn": "abe2eregistry.eastus.data.privatelink.azurecr.io", "ipAddresses": ["10.224.0.6"]}
+						]
+					}
+				}]
+			}`,
+			expected: []string{"10.224.0.7"},
+		},
+		{
+			name: "record set name differs in case",
+			properties: `{
+				"privateDnsZoneConfigs": [{
+					"properties": {"recordSets": [{"recordSetName": "ABE2ERegistry", "ipAddresses": ["10.224.0.7", "10.224.0.8"]}]}
+				}]
+			}`,
+			expected: []string{"10.224.0.7", "10.224.0.8"},
+		},
+		{
+			name:       "no record sets",
+			properties: `{"privateDnsZoneConfigs": [{"properties": {}}]}`,
+		},
+		{
+			name:       "no properties",
+			properties: `null`,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			var properties any
+			if err := json.Unmarshal([]byte(c.properties), &properties); err != nil {
+				t.Fatalf("unable to unmarshal properties: %s", err)
+			}
+			if ips := privateDNSRecordIPs(properties, "abe2eregistry"); !reflect.DeepEqual(ips, c.expected) {
+				t.Errorf("expected IPs %v, but got %v", c.expected, ips)
+			}
+		})
+	}
+}
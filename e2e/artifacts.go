@@ -0,0 +1,113 @@
+package e2e_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"strings"
+)
+
+const (
+	failureArtifactsArchiveName = "failure-artifacts.tar.gz"
+	networkArtifactsArchiveName = "network-artifacts.tar.gz"
+)
+
+// Paths on the node which are archived whenever a scenario fails
+var failureArtifactPaths = []string{
+	"/var/log/azure/aks",
+	"/var/log/azure/cluster-provision.log",
+	"/var/log/azure/cluster-provision-cse-output.log",
+	"/var/log/azure/custom-script",
+	"/var/lib/waagent/custom-script/download",
+}
+
+// Maps the names of files written into the scenario's artifacts directory whenever a scenario fails
+// to the commands run on the VM to generate their contents
+var failureArtifactCommands = map[string]string{
+	"containerd.log":         "journalctl -u containerd --no-pager",
+	"containerd-config.toml": "cat /etc/containerd/config.toml",
+	"ctr-version.log":        "ctr version",
+	dmesgArtifactName:        "dmesg -T",
+}
+
+// Paths on the node which are archived, along with the output of each network artifact command, whenever a scenario
+// fails after its node has joined the cluster, such that pod scheduling and networking failures can be debugged
+var networkArtifactPaths = []string{
+	"/etc/cni/net.d",
+	"/var/log/azure-vnet.log",
+	"/var/log/azure-vnet-ipam.log",
+	"/var/run/azure-vnet.json",
+	"/var/run/azure-vnet-ipam.json",
+}
+
+var networkArtifactCommands = map[string]string{
+	"iptables-save.log":  "iptables-save",
+	"ip6tables-save.log": "ip6tables-save",
+	"ip-addr.log":        "ip addr show",
+	"ip-link.log":        "ip -d link show",
+	"ip-route.log":       "ip route show table all",
+	"ip6-route.log":      "ip -6 route show table all",
+}
+
+// Collects the failure artifacts of the VM into the scenario's artifacts directory. Returns the contents of each
+// failure artifact command's output, keyed by file name.
+func collectFailureArtifacts(ctx context.Context, vmssName, privateIP, sshPrivateKey string, opts *scenarioRunOpts) (map[string]string, error) {
+	log.Printf("collecting failure artifacts from VM at %s of VMSS %s", privateIP, vmssName)
+	return collectArtifacts(ctx, vmssName, privateIP, sshPrivateKey, failureArtifactPaths, failureArtifactsArchiveName, failureArtifactCommands, opts)
+}
+
+// Collects the network state of the VM, i.e. its iptables rules, routes, interfaces and CNI config, into the
+// scenario's artifacts directory
+func collectNetworkArtifacts(ctx context.Context, vmssName, privateIP, sshPrivateKey string, opts *scenarioRunOpts) error {
+	log.Printf("collecting network artifacts from VM at %s of VMSS %s", privateIP, vmssName)
+	_, err := collectArtifacts(ctx, vmssName, privateIP, sshPrivateKey, networkArtifactPaths, networkArtifactsArchiveName, networkArtifactCommands, opts)
+	return err
+}
+
+// Archives the specified paths on the VM and writes the resulting tarball, along with the output of each of the
+// specified commands, into the scenario's artifacts directory. The archive is base64-encoded on the VM such that
+// it can be safely transferred through the output stream of the remote command. Returns the contents of each
+// command's output, keyed by file name.
+func collectArtifacts(ctx context.Context, vmssName, privateIP, sshPrivateKey string, paths []string, archiveName string, commands map[string]string, opts *scenarioRunOpts) (map[string]string, error) {
+	podName, err := getDebugPodName(opts.clusterConfig.kube)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get debug pod name: %w", err)
+	}
+
+	command := fmt.Sprintf("tar -czf - --ignore-failed-read %s 2>/dev/null | base64 -w 0", strings.Join(paths, " "))
+
+	execResult, err := pollExecOnInstance(ctx, vmssName, privateIP, podName, sshPrivateKey, command, false, opts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to archive artifacts: %w", err)
+	}
+
+	archive, err := base64.StdEncoding.DecodeString(strings.TrimSpace(execResult.stdout.String()))
+	if err != nil {
+		execResult.dumpStderr()
+		return nil, fmt.Errorf("unable to decode artifacts archive: %w", err)
+	}
+
+	archivePath, err := opts.artifacts.writeFile(archiveName, string(archive))
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("wrote artifacts archive to %s", archivePath)
+
+	artifacts := map[string]string{}
+	for file, command := range commands {
+		execResult, err := pollExecOnInstance(ctx, vmssName, privateIP, podName, sshPrivateKey, command, false, opts)
+		if err != nil {
+			return nil, fmt.Errorf("unable to execute artifact command %q: %w", command, err)
+		}
+		// include stderr since the output of failing commands is as useful as the output of succeeding ones
+		artifacts[file] = execResult.stdout.String() + execResult.stderr.String()
+	}
+
+	if err := opts.artifacts.writeFiles(artifacts); err != nil {
+		return nil, err
+	}
+
+	return artifacts, nil
+}
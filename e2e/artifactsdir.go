@@ -0,0 +1,143 @@
+package e2e_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+)
+
+const (
+	instanceLogsDirTemplate = "instance-%s"
+
+	// the private SSH key of a retained VMSS, which is only ever written locally
+	sshKeyArtifactName = "sshkey"
+
+	blobAPIVersion = "2021-08-06"
+)
+
+// The directory into which everything a scenario produces is written - by its runner, validators and log collectors - such
+// that the outputs of concurrently running scenarios can be found in one place per scenario rather than interleaved within
+// the test logs. When the suite is configured with an artifacts storage container, the directory is also uploaded to it once
+// the scenario has finished.
+type artifactsDir struct {
+	// the local path of the directory
+	path string
+
+	// the URL of the directory within the artifacts storage container, empty when artifacts aren't uploaded
+	url string
+}
+
+// Creates the artifacts directory of the named scenario within the suite's local artifacts directory
+func newScenarioArtifactsDir(suiteConfig *suiteConfig, scenarioName string) (artifactsDir, error) {
+	dir := artifactsDir{
+		path: filepath.Join(suiteConfig.artifactsDir, scenarioName),
+	}
+	if suiteConfig.artifactsContainerURL != "" {
+		dir.url = strings.Join([]string{strings.TrimSuffix(suiteConfig.artifactsContainerURL, "/"), suiteConfig.buildID, scenarioName}, "/")
+	}
+	return dir, createDirIfNeeded(dir.path)
+}
+
+// Creates the artifacts directory of the specified VMSS instance, nested within the scenario's artifacts directory
+func (d artifactsDir) instance(instanceID string) (artifactsDir, error) {
+	name := fmt.Sprintf(instanceLogsDirTemplate, instanceID)
+	instanceDir := artifactsDir{
+		path: filepath.Join(d.path, name),
+	}
+	if d.url != "" {
+		instanceDir.url = d.url + "/" + name
+	}
+	return instanceDir, createDirIfNeeded(instanceDir.path)
+}
+
+// Returns where the directory's artifacts can be found, i.e. its URL when uploaded or its local path otherwise, for
+// inclusion within failure messages
+func (d artifactsDir) location() string {
+	if d.url != "" {
+		return d.url
+	}
+	if abs, err := filepath.Abs(d.path); err == nil {
+		return abs
+	}
+	return d.path
+}
+
+// Returns the local path of the named artifact within the directory
+func (d artifactsDir) filePath(name string) string {
+	return filepath.Join(d.path, name)
+}
+
+// Writes the named artifact into the directory, returning its local path
+func (d artifactsDir) writeFile(name, contents string) (string, error) {
+	filePath := d.filePath(name)
+	if err := writeToFile(filePath, contents); err != nil {
+		return "", fmt.Errorf("unable to write artifact %s: %w", filePath, err)
+	}
+	return filePath, nil
+}
+
+// Writes each of the supplied files into the directory, keyed by path, flattening each path to its base name
+func (d artifactsDir) writeFiles(files map[string]string) error {
+	for filePath, contents := range files {
+		if _, err := d.writeFile(filepath.Base(filePath), contents); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Uploads every file within the directory, including those of nested instance directories, to the artifacts storage container.
+// The private SSH key of a retained VMSS is never uploaded
+func (d artifactsDir) upload(ctx context.Context, cloud *azureClient) error {
+	if d.url == "" {
+		return nil
+	}
+
+	log.Printf("uploading artifacts within %s to %s", d.path, d.url)
+	return filepath.WalkDir(d.path, func(filePath string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || entry.Name() == sshKeyArtifactName {
+			return err
+		}
+		relPath, err := filepath.Rel(d.path, filePath)
+		if err != nil {
+			return err
+		}
+		contents, err := os.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("unable to read artifact %s: %w", filePath, err)
+		}
+		return uploadBlob(ctx, cloud, d.url+"/"+path.Clean(filepath.ToSlash(relPath)), contents)
+	})
+}
+
+func uploadBlob(ctx context.Context, cloud *azureClient, blobURL string, contents []byte) error {
+	req, err := runtime.NewRequest(ctx, http.MethodPut, blobURL)
+	if err != nil {
+		return err
+	}
+	req.Raw().Header.Set("x-ms-blob-type", "BlockBlob")
+	req.Raw().Header.Set("x-ms-version", blobAPIVersion)
+	if err := req.SetBody(streaming.NopCloser(bytes.NewReader(contents)), "application/octet-stream"); err != nil {
+		return err
+	}
+
+	resp, err := cloud.storagePipeline.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to upload blob %s: %w", blobURL, err)
+	}
+	defer resp.Body.Close()
+
+	if !runtime.HasStatusCode(resp, http.StatusCreated) {
+		return fmt.Errorf("unable to upload blob %s: %w", blobURL, runtime.NewResponseError(resp))
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	mrand "math/rand"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	bootstrapTokenSecretNamespace  = "kube-system"
+	bootstrapTokenSecretNamePrefix = "bootstrap-token-"
+	bootstrapTokenSecretType       = corev1.SecretType("bootstrap.kubernetes.io/token")
+	bootstrapTokenIDLength         = 6
+	bootstrapTokenSecretLength     = 16
+
+	// long enough to cover VMSS creation, CSE retries and node registration, after which the token is no longer needed
+	bootstrapTokenTTL = 4 * time.Hour
+
+	// used when the cluster's own bootstrap token secret can't be found to copy its groups from
+	defaultBootstrapTokenExtraGroups = "system:bootstrappers:kubeadm:default-node-token"
+)
+
+// Mints a new bootstrap token within the cluster by creating a bootstrap token secret, returning the token in its
+// "<token-id>.<token-secret>" form along with a function which deletes the secret. The new token is granted the same
+// extra groups as sharedToken, the cluster's existing bootstrap token, such that it's bound to the same RBAC roles.
+// Only the token's ID is drawn from r, such that it's reproduced by the seed, whereas its secret is drawn from crypto/rand.
+func mintBootstrapToken(ctx context.Context, r *mrand.Rand, kube *kubeclient, sharedToken, description string) (string, func(), error) {
+	extraGroups, err := getBootstrapTokenExtraGroups(ctx, kube, sharedToken)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tokenID := randomLowercaseString(r, bootstrapTokenIDLength)
+	tokenSecret, err := randomLowercaseSecret(bootstrapTokenSecretLength)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate bootstrap token secret: %w", err)
+	}
+	secretName := bootstrapTokenSecretNamePrefix + tokenID
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: bootstrapTokenSecretNamespace,
+		},
+		Type: bootstrapTokenSecretType,
+		StringData: map[string]string{
+			"description":                    description,
+			"token-id":                       tokenID,
+			"token-secret":                   tokenSecret,
+			"expiration":                     time.Now().Add(bootstrapTokenTTL).UTC().Format(time.RFC3339),
+			"usage-bootstrap-authentication": "true",
+			"usage-bootstrap-signing":        "true",
+			"auth-extra-groups":              extraGroups,
+		},
+	}
+
+	if _, err := kube.typed.CoreV1().Secrets(bootstrapTokenSecretNamespace).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		return "", nil, fmt.Errorf("failed to create bootstrap token secret %q: %w", secretName, err)
+	}
+	log.Printf("minted bootstrap token with ID %q", tokenID)
+
+	cleanup := func() {
+		err := kube.typed.CoreV1().Secrets(bootstrapTokenSecretNamespace).Delete(context.Background(), secretName, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			log.Printf("failed to delete bootstrap token secret %q: %s", secretName, err)
+		}
+	}
+
+	return fmt.Sprintf("%s.%s", tokenID, tokenSecret), cleanup, nil
+}
+
+// Returns the extra groups of the bootstrap token secret backing the specified token, falling back to the default
+// kubeadm node token group if the secret doesn't exist
+func getBootstrapTokenExtraGroups(ctx context.Context, kube *kubeclient, token string) (string, error) {
+	tokenID, _, found := strings.Cut(token, ".")
+	if !found {
+		return defaultBootstrapTokenExtraGroups, nil
+	}
+
+	secretName := bootstrapTokenSecretNamePrefix + tokenID
+	secret, err := kube.typed.CoreV1().Secrets(bootstrapTokenSecretNamespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return defaultBootstrapTokenExtraGroups, nil
+		}
+		return "", fmt.Errorf("failed to get bootstrap token secret %q: %w", secretName, err)
+	}
+
+	if groups := string(secret.Data["auth-extra-groups"]); groups != "" {
+		return groups, nil
+	}
+	return defaultBootstrapTokenExtraGroups, nil
+}
@@ -0,0 +1,224 @@
+package e2e_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Azure/agentbakere2e/scenario"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+)
+
+const (
+	getFeatureURLTemplate  = "%s/subscriptions/%s/providers/Microsoft.Features/providers/%s/features/%s?api-version=2021-07-01"
+	registeredFeatureState = "Registered"
+
+	virtualMachinesResourceType = "virtualMachines"
+)
+
+type getFeatureResult struct {
+	Name       string `json:"name,omitempty"`
+	Properties struct {
+		State string `json:"state,omitempty"`
+	} `json:"properties,omitempty"`
+}
+
+// Probes the capabilities of the suite's subscription and region which scenarios may require, i.e. the VM sizes available to
+// the subscription, its registered features, and its remaining vCPU quota. The result of each probe is cached, such that
+// it's shared by every scenario
+type capabilityProbe struct {
+	cloud        *azureClient
+	subscription string
+	location     string
+
+	mu sync.Mutex
+	// maps the lowercase name of each VM size offered within the region to the reason it's restricted, empty if it isn't
+	vmSizes map[string]string
+	// maps the name of each VM family to the number of vCPUs of its quota remaining within the region
+	vcpuQuotas map[string]int64
+	// maps each probed feature to whether it's registered
+	features map[string]bool
+}
+
+func newCapabilityProbe(cloud *azureClient, suiteConfig *suiteConfig) *capabilityProbe {
+	return &capabilityProbe{
+		cloud:        cloud,
+		subscription: suiteConfig.subscription,
+		location:     suiteConfig.location,
+		features:     map[string]bool{},
+	}
+}
+
+// Returns the reason each of the specified requirements isn't met, which is empty when all of them are met
+func (p *capabilityProbe) unmetRequirements(ctx context.Context, requirements scenario.Requirements) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var unmet []string
+
+	if len(requirements.VMSizes) > 0 {
+		if err := p.probeVMSizes(ctx); err != nil {
+			return nil, err
+		}
+		for _, vmSize := range requirements.VMSizes {
+			restriction, offered := p.vmSizes[strings.ToLower(vmSize)]
+			if !offered {
+				unmet = append(unmet, fmt.Sprintf("VM size %s isn't offered in region %s", vmSize, p.location))
+			} else if restriction != "" {
+				unmet = append(unmet, fmt.Sprintf("VM size %s isn't available to subscription %s in region %s: %s", vmSize, p.subscription, p.location, restriction))
+			}
+		}
+	}
+
+	for _, feature := range requirements.Features {
+		registered, err := p.probeFeature(ctx, feature)
+		if err != nil {
+			return nil, err
+		}
+		if !registered {
+			unmet = append(unmet, fmt.Sprintf("feature %s isn't registered on subscription %s", feature, p.subscription))
+		}
+	}
+
+	if len(requirements.VCPUQuotas) > 0 {
+		if err := p.probeVCPUQuotas(ctx); err != nil {
+			return nil, err
+		}
+		families := make([]string, 0, len(requirements.VCPUQuotas))
+		for family := range requirements.VCPUQuotas {
+			families = append(families, family)
+		}
+		sort.Strings(families)
+		for _, family := range families {
+			required := int64(requirements.VCPUQuotas[family])
+			if available := p.vcpuQuotas[family]; available < required {
+				unmet = append(unmet, fmt.Sprintf("only %d of the %d vCPUs required of the %s quota are available in region %s", available, required, family, p.location))
+			}
+		}
+	}
+
+	return unmet, nil
+}
+
+func (p *capabilityProbe) probeVMSizes(ctx context.Context) error {
+	if p.vmSizes != nil {
+		return nil
+	}
+
+	log.Printf("probing the VM sizes available within region %q", p.location)
+	vmSizes := map[string]string{}
+	pager := p.cloud.resourceSKUClient.NewListPager(&armcompute.ResourceSKUsClientListOptions{
+		Filter: to.Ptr(fmt.Sprintf("location eq '%s'", p.location)),
+	})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list resource SKUs of region %q: %w", p.location, err)
+		}
+		for _, sku := range page.Value {
+			if sku == nil || sku.Name == nil || sku.ResourceType == nil || *sku.ResourceType != virtualMachinesResourceType {
+				continue
+			}
+			vmSizes[strings.ToLower(*sku.Name)] = getLocationRestriction(sku)
+		}
+	}
+
+	p.vmSizes = vmSizes
+	return nil
+}
+
+// Returns the reason the SKU is restricted throughout the region, or an empty string if it isn't. Restrictions of
+// individual zones are ignored, since the SKU can still be used within the region's other zones
+func getLocationRestriction(sku *armcompute.ResourceSKU) string {
+	for _, restriction := range sku.Restrictions {
+		if restriction == nil || restriction.Type == nil || *restriction.Type != armcompute.ResourceSKURestrictionsTypeLocation {
+			continue
+		}
+		if restriction.ReasonCode != nil {
+			return string(*restriction.ReasonCode)
+		}
+		return "restricted"
+	}
+	return ""
+}
+
+func (p *capabilityProbe) probeVCPUQuotas(ctx context.Context) error {
+	if p.vcpuQuotas != nil {
+		return nil
+	}
+
+	log.Printf("probing the vCPU quotas of subscription %q within region %q", p.subscription, p.location)
+	vcpuQuotas := map[string]int64{}
+	pager := p.cloud.usageClient.NewListPager(p.location, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list compute usages of region %q: %w", p.location, err)
+		}
+		for _, usage := range page.Value {
+			if usage == nil || usage.Name == nil || usage.Name.Value == nil || usage.Limit == nil || usage.CurrentValue == nil {
+				continue
+			}
+			vcpuQuotas[*usage.Name.Value] = *usage.Limit - int64(*usage.CurrentValue)
+		}
+	}
+
+	p.vcpuQuotas = vcpuQuotas
+	return nil
+}
+
+func (p *capabilityProbe) probeFeature(ctx context.Context, feature string) (bool, error) {
+	if registered, ok := p.features[feature]; ok {
+		return registered, nil
+	}
+	registered, err := isFeatureRegistered(ctx, p.cloud, p.subscription, feature)
+	if err != nil {
+		return false, err
+	}
+	p.features[feature] = registered
+	return registered, nil
+}
+
+// Returns true if the specified feature, of the form "<namespace>/<name>", is registered on the specified subscription
+func isFeatureRegistered(ctx context.Context, cloud *azureClient, subscription, feature string) (bool, error) {
+	namespace, name, ok := strings.Cut(feature, "/")
+	if !ok {
+		return false, fmt.Errorf("feature %q isn't of the form <namespace>/<name>", feature)
+	}
+
+	req, err := runtime.NewRequest(ctx, http.MethodGet, fmt.Sprintf(getFeatureURLTemplate, cloud.environment.resourceManagerEndpoint(), subscription, namespace, name))
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := cloud.coreClient.Pipeline().Do(req)
+	if err != nil {
+		return false, err
+	}
+
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("failed to get feature %q of subscription %q, received status %d: %s", feature, subscription, resp.StatusCode, string(respBytes))
+	}
+
+	var result getFeatureResult
+	if err := json.Unmarshal(respBytes, &result); err != nil {
+		return false, err
+	}
+
+	return strings.EqualFold(result.Properties.State, registeredFeatureState), nil
+}
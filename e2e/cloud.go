@@ -7,11 +7,11 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/Azure/agentbakere2e/scenario"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
@@ -20,16 +20,30 @@ import (
 )
 
 type azureClient struct {
+	// the cloud each of the clients is configured for
+	environment cloudEnvironment
+
+	// authenticates each of the clients with the suite's subscription
+	credential azcore.TokenCredential
+
 	coreClient          *azcore.Client
+	storagePipeline     runtime.Pipeline
 	vmssClient          *armcompute.VirtualMachineScaleSetsClient
 	vmssVMClient        *armcompute.VirtualMachineScaleSetVMsClient
+	vmssExtensionClient *armcompute.VirtualMachineScaleSetExtensionsClient
+	galleryClient       *armcompute.GalleryImageVersionsClient
+	resourceSKUClient   *armcompute.ResourceSKUsClient
+	usageClient         *armcompute.UsageClient
 	vnetClient          *armnetwork.VirtualNetworksClient
 	resourceClient      *armresources.Client
 	resourceGroupClient *armresources.ResourceGroupsClient
 	aksClient           *armcontainerservice.ManagedClustersClient
+
+	// caches the admin kubeconfigs of the clusters listed through aksClient
+	kubeconfigs *kubeconfigCache
 }
 
-func newAzureClient(subscription string) (*azureClient, error) {
+func newAzureClient(subscription, kubeconfigCacheDir string, environment cloudEnvironment, credential azcore.TokenCredential) (*azureClient, error) {
 	httpClient := &http.Client{
 		// use a bunch of connections for load balancing
 		// ensure all timeouts are defined and reasonable
@@ -60,23 +74,25 @@ func newAzureClient(subscription string) (*azureClient, error) {
 
 	opts := &arm.ClientOptions{
 		ClientOptions: azcore.ClientOptions{
+			Cloud:     environment.configuration,
 			Transport: httpClient,
 			PerCallPolicies: []policy.Policy{
 				logger,
 			},
 		},
 	}
-
-	credential, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create credential: %w", err)
+	// the options of the clients which use the SDK's default transport
+	cloudOpts := &arm.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Cloud: environment.configuration,
+		},
 	}
 
 	plOpts := runtime.PipelineOptions{}
 	clOpts := &azcore.ClientOptions{
 		Transport: httpClient,
 		PerCallPolicies: []policy.Policy{
-			runtime.NewBearerTokenPolicy(credential, []string{defaultAzureTokenScope}, nil),
+			runtime.NewBearerTokenPolicy(credential, []string{environment.resourceManagerScope()}, nil),
 			logger,
 		},
 	}
@@ -87,21 +103,51 @@ func newAzureClient(subscription string) (*azureClient, error) {
 		return nil, fmt.Errorf("failed to create core client: %w", err)
 	}
 
-	aksClient, err := armcontainerservice.NewManagedClustersClient(subscription, credential, nil)
+	// authenticates requests to the data plane of storage accounts, e.g. to upload scenario artifacts. Bodies aren't logged
+	// since they're the artifacts themselves
+	storagePipeline := runtime.NewPipeline("agentbakere2e.e2e_test", "v0.0.0", runtime.PipelineOptions{}, &azcore.ClientOptions{
+		Transport: httpClient,
+		PerCallPolicies: []policy.Policy{
+			runtime.NewBearerTokenPolicy(credential, []string{environment.storageScope()}, nil),
+		},
+	})
+
+	aksClient, err := armcontainerservice.NewManagedClustersClient(subscription, credential, cloudOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create aks client: %w", err)
 	}
 
-	vmssClient, err := armcompute.NewVirtualMachineScaleSetsClient(subscription, credential, nil)
+	vmssClient, err := armcompute.NewVirtualMachineScaleSetsClient(subscription, credential, cloudOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create vmss client: %w", err)
 	}
 
-	vmssVMClient, err := armcompute.NewVirtualMachineScaleSetVMsClient(subscription, credential, nil)
+	vmssVMClient, err := armcompute.NewVirtualMachineScaleSetVMsClient(subscription, credential, cloudOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create vmss vm client: %w", err)
 	}
 
+	vmssExtensionClient, err := armcompute.NewVirtualMachineScaleSetExtensionsClient(subscription, credential, cloudOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vmss extension client: %w", err)
+	}
+
+	// the test VHDs live within a gallery in the ACS test subscription, which isn't necessarily the subscription being tested in
+	galleryClient, err := armcompute.NewGalleryImageVersionsClient(scenario.GallerySubscriptionID, credential, cloudOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gallery image versions client: %w", err)
+	}
+
+	resourceSKUClient, err := armcompute.NewResourceSKUsClient(subscription, credential, cloudOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource sku client: %w", err)
+	}
+
+	usageClient, err := armcompute.NewUsageClient(subscription, credential, cloudOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create usage client: %w", err)
+	}
+
 	resourceClient, err := armresources.NewClient(subscription, credential, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create resource client: %w", err)
@@ -112,19 +158,27 @@ func newAzureClient(subscription string) (*azureClient, error) {
 		return nil, fmt.Errorf("failed to create resource group client: %w", err)
 	}
 
-	vnetClient, err := armnetwork.NewVirtualNetworksClient(subscription, credential, nil)
+	vnetClient, err := armnetwork.NewVirtualNetworksClient(subscription, credential, cloudOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create vnet client: %w", err)
 	}
 
 	var cloud = &azureClient{
+		environment:         environment,
+		credential:          credential,
 		coreClient:          coreClient,
+		storagePipeline:     storagePipeline,
 		aksClient:           aksClient,
 		resourceClient:      resourceClient,
 		resourceGroupClient: resourceGroupClient,
 		vmssClient:          vmssClient,
 		vmssVMClient:        vmssVMClient,
+		vmssExtensionClient: vmssExtensionClient,
+		galleryClient:       galleryClient,
+		resourceSKUClient:   resourceSKUClient,
+		usageClient:         usageClient,
 		vnetClient:          vnetClient,
+		kubeconfigs:         newKubeconfigCache(subscription, kubeconfigCacheDir),
 	}
 
 	return cloud, nil
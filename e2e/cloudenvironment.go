@@ -0,0 +1,104 @@
+package e2e_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+)
+
+const (
+	// the audience of the data plane of storage accounts, which is the same within every cloud
+	defaultStorageAudience = "https://storage.azure.com/"
+
+	// the cloud the suite runs against when AZURE_CLOUD isn't set
+	defaultAzureCloudName = datamodel.AzurePublicCloud
+)
+
+// cloudEnvironment is the Azure cloud the suite runs against, e.g. one of the sovereign clouds, determining the endpoints and
+// token audiences of the suite's Azure clients, the authority which authenticates them, and the cloud nodes are bootstrapped for
+type cloudEnvironment struct {
+	// the name AgentBaker identifies the cloud by within bootstrap configs, e.g. "AzureUSGovernmentCloud"
+	name string
+
+	configuration   cloud.Configuration
+	storageAudience string
+
+	// the DNS suffix of the cloud's VMs, e.g. "cloudapp.usgovcloudapi.net"
+	vmDNSSuffix string
+
+	// the DNS suffix of the cloud's container registries, e.g. "azurecr.us"
+	containerRegistryDNSSuffix string
+}
+
+// the clouds AgentBaker has OS image settings for, and can hence bootstrap nodes within
+var (
+	azurePublicCloudEnvironment = cloudEnvironment{
+		name:                       datamodel.AzurePublicCloud,
+		configuration:              cloud.AzurePublic,
+		storageAudience:            defaultStorageAudience,
+		vmDNSSuffix:                "cloudapp.azure.com",
+		containerRegistryDNSSuffix: "azurecr.io",
+	}
+	azureUSGovernmentCloudEnvironment = cloudEnvironment{
+		name:                       datamodel.AzureUSGovernmentCloud,
+		configuration:              cloud.AzureGovernment,
+		storageAudience:            defaultStorageAudience,
+		vmDNSSuffix:                "cloudapp.usgovcloudapi.net",
+		containerRegistryDNSSuffix: "azurecr.us",
+	}
+	azureChinaCloudEnvironment = cloudEnvironment{
+		name:                       datamodel.AzureChinaCloud,
+		configuration:              cloud.AzureChina,
+		storageAudience:            defaultStorageAudience,
+		vmDNSSuffix:                "cloudapp.chinacloudapi.cn",
+		containerRegistryDNSSuffix: "azurecr.cn",
+	}
+)
+
+// Returns the cloud environment named by AZURE_CLOUD, i.e. "AzurePublicCloud", "AzureUSGovernmentCloud" or "AzureChinaCloud".
+// The names the Azure CLI uses, e.g. "AzureCloud" and "AzureUSGovernment", are accepted too
+func newCloudEnvironment() (cloudEnvironment, error) {
+	name := getEnvWithDefault(defaultAzureCloudName, "AZURE_CLOUD")
+	switch strings.ToLower(name) {
+	case strings.ToLower(datamodel.AzurePublicCloud), "azurecloud":
+		return azurePublicCloudEnvironment, nil
+	case strings.ToLower(datamodel.AzureUSGovernmentCloud), "azureusgovernment":
+		return azureUSGovernmentCloudEnvironment, nil
+	case strings.ToLower(datamodel.AzureChinaCloud):
+		return azureChinaCloudEnvironment, nil
+	default:
+		return cloudEnvironment{}, fmt.Errorf("unknown cloud %q, expected one of %s, %s or %s",
+			name, datamodel.AzurePublicCloud, datamodel.AzureUSGovernmentCloud, datamodel.AzureChinaCloud)
+	}
+}
+
+// Returns the base URL of the cloud's resource manager, without a trailing slash, e.g. "https://management.usgovcloudapi.net"
+func (e cloudEnvironment) resourceManagerEndpoint() string {
+	return strings.TrimSuffix(e.configuration.Services[cloud.ResourceManager].Endpoint, "/")
+}
+
+// Returns the audience of tokens authenticating requests to the cloud's resource manager, e.g. as requested from IMDS by nodes
+func (e cloudEnvironment) resourceManagerAudience() string {
+	return e.configuration.Services[cloud.ResourceManager].Audience
+}
+
+// Returns the scope of tokens authenticating requests to the cloud's resource manager
+func (e cloudEnvironment) resourceManagerScope() string {
+	return audienceScope(e.resourceManagerAudience())
+}
+
+// Returns the private DNS zone resolving the private endpoints of the cloud's container registries
+func (e cloudEnvironment) acrPrivateDNSZoneName() string {
+	return "privatelink." + e.containerRegistryDNSSuffix
+}
+
+// Returns the scope of tokens authenticating requests to the data plane of the cloud's storage accounts
+func (e cloudEnvironment) storageScope() string {
+	return audienceScope(e.storageAudience)
+}
+
+func audienceScope(audience string) string {
+	return strings.TrimSuffix(audience, "/") + "/.default"
+}
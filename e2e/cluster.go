@@ -6,6 +6,7 @@ import (
 	"log"
 	mrand "math/rand"
 	"strings"
+	"sync"
 
 	"github.com/Azure/agentbakere2e/scenario"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
@@ -28,6 +29,22 @@ type clusterConfig struct {
 	parameters   clusterParameters
 	subnetId     string
 	isNewCluster bool
+
+	// held by each scenario running on the cluster, exclusively by those needing exclusive use of the cluster
+	access *sync.RWMutex
+}
+
+// Blocks until the scenario may run on the cluster, i.e. until no other scenario holds the cluster exclusively, or until no
+// other scenario runs on it at all when the scenario needs exclusive use of it. Returns a function releasing the cluster
+func (c clusterConfig) acquire(s *scenario.Scenario) func() {
+	if !s.ExclusiveCluster {
+		c.access.RLock()
+		return c.access.RUnlock
+	}
+	log.Printf("scenario %q is waiting for exclusive use of cluster %q", s.Name, *c.cluster.Name)
+	c.access.Lock()
+	log.Printf("scenario %q acquired exclusive use of cluster %q", s.Name, *c.cluster.Name)
+	return c.access.Unlock
 }
 
 // Returns true if the cluster is configured with Azure CNI
@@ -144,11 +161,14 @@ func validateExistingClusterState(ctx context.Context, cloud *azureClient, resou
 func createNewCluster(
 	ctx context.Context,
 	cloud *azureClient,
-	resourceGroupName string,
+	suiteConfig *suiteConfig,
 	clusterModel *armcontainerservice.ManagedCluster) (*armcontainerservice.ManagedCluster, error) {
+	// AKS also propagates cluster tags to the resources it creates within the cluster's node resource group
+	clusterModel.Tags = mergeResourceTags(clusterModel.Tags, getResourceTags(suiteConfig, "", clusterResourceTTL))
+
 	pollerResp, err := cloud.aksClient.BeginCreateOrUpdate(
 		ctx,
-		resourceGroupName,
+		suiteConfig.resourceGroupName,
 		*clusterModel.Name,
 		*clusterModel,
 		nil,
@@ -165,6 +185,33 @@ func createNewCluster(
 	return &clusterResp.ManagedCluster, nil
 }
 
+// Restamps the tags of a reused cluster, such that its expiry is pushed back for as long as runs keep reusing it
+// rather than lapsing a week after its creation
+func refreshClusterTags(ctx context.Context, cloud *azureClient, suiteConfig *suiteConfig, config *clusterConfig) error {
+	clusterName := *config.cluster.Name
+	log.Printf("refreshing tags of reused cluster %q...", clusterName)
+
+	tags := mergeResourceTags(config.cluster.Tags, getResourceTags(suiteConfig, "", clusterResourceTTL))
+	pollerResp, err := cloud.aksClient.BeginUpdateTags(
+		ctx,
+		suiteConfig.resourceGroupName,
+		clusterName,
+		armcontainerservice.TagsObject{Tags: tags},
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to begin updating tags of aks cluster %q: %w", clusterName, err)
+	}
+
+	clusterResp, err := pollerResp.PollUntilDone(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to wait for tags of aks cluster %q to be updated: %w", clusterName, err)
+	}
+
+	config.cluster = &clusterResp.ManagedCluster
+	return nil
+}
+
 func deleteExistingCluster(ctx context.Context, cloud *azureClient, resourceGroupName, clusterName string) error {
 	poller, err := cloud.aksClient.BeginDelete(ctx, resourceGroupName, clusterName, nil)
 	if err != nil {
@@ -227,7 +274,7 @@ func getInitialClusterConfigs(ctx context.Context, cloud *azureClient, resourceG
 				}
 
 				log.Printf("found agentbaker e2e cluster %q in provisioning state %q", *resource.Name, *cluster.Properties.ProvisioningState)
-				configs = append(configs, clusterConfig{cluster: &cluster.ManagedCluster})
+				configs = append(configs, clusterConfig{cluster: &cluster.ManagedCluster, access: &sync.RWMutex{}})
 			}
 		}
 	}
@@ -252,10 +299,11 @@ func createMissingClusters(
 	scenarios scenario.Table,
 	clusterConfigs *[]clusterConfig) error {
 	var newConfigs []clusterConfig
-	for _, scenario := range scenarios {
+	// iterated in order, such that the names of the clusters created are reproduced by a run with the same seed
+	for _, scenario := range scenarios.Sorted() {
 		if !hasViableConfig(scenario, *clusterConfigs) && !hasViableConfig(scenario, newConfigs) {
 			newClusterModel := getNewClusterModelForScenario(generateClusterName(r), suiteConfig.location, scenario)
-			newConfigs = append(newConfigs, clusterConfig{cluster: &newClusterModel, isNewCluster: true})
+			newConfigs = append(newConfigs, clusterConfig{cluster: &newClusterModel, isNewCluster: true, access: &sync.RWMutex{}})
 		}
 	}
 
@@ -267,7 +315,7 @@ func createMissingClusters(
 			clusterName := *config.cluster.Name
 
 			log.Printf("creating cluster %q...", clusterName)
-			liveCluster, err := createNewCluster(ctx, cloud, suiteConfig.resourceGroupName, config.cluster)
+			liveCluster, err := createNewCluster(ctx, cloud, suiteConfig, config.cluster)
 			if err != nil {
 				return fmt.Errorf("unable to create new cluster: %w", err)
 			}
@@ -346,7 +394,7 @@ func validateAndPrepareCluster(ctx context.Context, r *mrand.Rand, cloud *azureC
 		if err != nil {
 			return err
 		}
-		newCluster, err := createNewCluster(ctx, cloud, suiteConfig.resourceGroupName, newModel)
+		newCluster, err := createNewCluster(ctx, cloud, suiteConfig, newModel)
 		if err != nil {
 			return err
 		}
@@ -354,10 +402,20 @@ func validateAndPrepareCluster(ctx context.Context, r *mrand.Rand, cloud *azureC
 		config.cluster = newCluster
 	}
 
+	if !needRecreate {
+		if err := refreshClusterTags(ctx, cloud, suiteConfig, config); err != nil {
+			return err
+		}
+	}
+
 	kube, subnetId, clusterParams, err := prepareClusterForTests(ctx, cloud, suiteConfig, config.cluster)
 	if err != nil {
 		return err
 	}
+	// only reused clusters accumulate the objects of previous runs
+	if !needRecreate && suiteConfig.staleObjectMaxAge > 0 {
+		collectStaleObjects(ctx, kube, *config.cluster.Name, suiteConfig.staleObjectMaxAge)
+	}
 
 	config.kube = kube
 	config.parameters = clusterParams
@@ -382,7 +440,12 @@ func prepareClusterForTests(
 		return nil, "", nil, fmt.Errorf("unable get kube client using cluster %q: %w", clusterName, err)
 	}
 
-	if err := ensureDebugDaemonset(ctx, kube); err != nil {
+	// the debug daemonset, along with the suite's other shared pods, are created within the default namespace
+	if err := ensurePrivilegedPodSecurity(ctx, kube, defaultNamespace, suiteConfig.podSecurityLabels); err != nil {
+		return nil, "", nil, fmt.Errorf("unable to ensure pod security admission of viable cluster %q admits debug pods: %w", clusterName, err)
+	}
+
+	if err := ensureDebugDaemonset(ctx, kube, suiteConfig.debugDaemonset); err != nil {
 		return nil, "", nil, fmt.Errorf("unable to ensure debug damonset of viable cluster %q: %w", clusterName, err)
 	}
 
@@ -2,7 +2,7 @@ package e2e_test
 
 const (
 	testClusterNameTemplate        = "agentbaker-e2e-test-cluster-%s"
-	defaultAzureTokenScope         = "https://management.azure.com/.default"
 	defaultNamespace               = "default"
 	abe2eResourceGroupNameTemplate = "abe2e-%s"
+	imageVersionsSegment           = "/versions/"
 )
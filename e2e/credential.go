@@ -0,0 +1,192 @@
+package e2e_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+const (
+	// the sources of the OIDC tokens exchanged for Azure AD tokens through federated credentials, selected by
+	// AZURE_FEDERATED_TOKEN_SOURCE
+	federatedTokenSourceFile           = "file"
+	federatedTokenSourceGitHub         = "github"
+	federatedTokenSourceAzurePipelines = "azure-pipelines"
+
+	// the audience Azure AD expects of the tokens presented as client assertions by federated credentials
+	federatedTokenAudience = "api://AzureADTokenExchange"
+
+	azurePipelinesOIDCAPIVersion = "7.1"
+
+	federatedTokenRequestTimeout = 30 * time.Second
+)
+
+// federatedCredentialConfig configures the suite to authenticate as an Azure AD application, or user-assigned managed identity,
+// with a federated credential trusting the OIDC tokens the CI system running the suite issues, rather than a client secret
+type federatedCredentialConfig struct {
+	source   string
+	tenantID string
+	clientID string
+
+	// the file holding the token, which is read whenever a token is needed, such that it can be rotated, e.g. by kubelet
+	tokenFile string
+
+	// the ID of the Azure Pipelines service connection whose federated credential the token is issued for
+	serviceConnectionID string
+}
+
+// Returns the federated credential configured through AZURE_FEDERATED_TOKEN_SOURCE, along with AZURE_TENANT_ID and
+// AZURE_CLIENT_ID, or nil when none is. The source defaults to the file named by AZURE_FEDERATED_TOKEN_FILE, as set by
+// workload identity, when it's set
+func newFederatedCredentialConfig() (*federatedCredentialConfig, error) {
+	config := &federatedCredentialConfig{
+		source:              os.Getenv("AZURE_FEDERATED_TOKEN_SOURCE"),
+		tenantID:            os.Getenv("AZURE_TENANT_ID"),
+		clientID:            os.Getenv("AZURE_CLIENT_ID"),
+		tokenFile:           os.Getenv("AZURE_FEDERATED_TOKEN_FILE"),
+		serviceConnectionID: os.Getenv("AZURE_SERVICE_CONNECTION_ID"),
+	}
+	if config.source == "" && config.tokenFile != "" {
+		config.source = federatedTokenSourceFile
+	}
+	if config.source == "" {
+		return nil, nil
+	}
+
+	var required []string
+	switch config.source {
+	case federatedTokenSourceFile:
+		required = []string{"AZURE_FEDERATED_TOKEN_FILE"}
+	case federatedTokenSourceGitHub:
+		// only set for jobs granted the id-token: write permission
+		required = []string{"ACTIONS_ID_TOKEN_REQUEST_URL", "ACTIONS_ID_TOKEN_REQUEST_TOKEN"}
+	case federatedTokenSourceAzurePipelines:
+		// SYSTEM_ACCESSTOKEN must be mapped into the environment of the step running the suite
+		required = []string{"AZURE_SERVICE_CONNECTION_ID", "SYSTEM_OIDCREQUESTURI", "SYSTEM_ACCESSTOKEN"}
+	default:
+		return nil, fmt.Errorf("unknown federated token source %q, expected one of %s, %s or %s",
+			config.source, federatedTokenSourceFile, federatedTokenSourceGitHub, federatedTokenSourceAzurePipelines)
+	}
+	for _, key := range append([]string{"AZURE_TENANT_ID", "AZURE_CLIENT_ID"}, required...) {
+		if os.Getenv(key) == "" {
+			return nil, fmt.Errorf("missing environment variable %q required by federated token source %q", key, config.source)
+		}
+	}
+	return config, nil
+}
+
+// Returns a credential presenting the OIDC tokens of the configured source as client assertions, which Azure AD exchanges
+// for tokens of the configured application. A fresh OIDC token is requested whenever an Azure AD token is, since OIDC tokens
+// issued by CI systems are short-lived
+func (c *federatedCredentialConfig) newCredential(environment cloudEnvironment) (azcore.TokenCredential, error) {
+	var getAssertion func(context.Context) (string, error)
+	switch c.source {
+	case federatedTokenSourceFile:
+		getAssertion = c.readTokenFile
+	case federatedTokenSourceGitHub:
+		getAssertion = requestGitHubOIDCToken
+	case federatedTokenSourceAzurePipelines:
+		getAssertion = c.requestAzurePipelinesOIDCToken
+	}
+
+	credential, err := azidentity.NewClientAssertionCredential(c.tenantID, c.clientID, getAssertion, &azidentity.ClientAssertionCredentialOptions{
+		ClientOptions: azcore.ClientOptions{
+			Cloud: environment.configuration,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create federated credential of client %q: %w", c.clientID, err)
+	}
+	return credential, nil
+}
+
+func (c *federatedCredentialConfig) readTokenFile(_ context.Context) (string, error) {
+	token, err := os.ReadFile(c.tokenFile)
+	if err != nil {
+		return "", fmt.Errorf("unable to read federated token file %q: %w", c.tokenFile, err)
+	}
+	return strings.TrimSpace(string(token)), nil
+}
+
+// Requests an OIDC token of the running GitHub Actions job, the subject of which identifies the job's repository and ref
+func requestGitHubOIDCToken(ctx context.Context) (string, error) {
+	requestURL, err := url.Parse(os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL"))
+	if err != nil {
+		return "", fmt.Errorf("invalid ACTIONS_ID_TOKEN_REQUEST_URL: %w", err)
+	}
+	query := requestURL.Query()
+	query.Set("audience", federatedTokenAudience)
+	requestURL.RawQuery = query.Encode()
+
+	var response struct {
+		Value string `json:"value"`
+	}
+	if err := requestOIDCToken(ctx, http.MethodGet, requestURL.String(), os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN"), &response); err != nil {
+		return "", fmt.Errorf("failed to request GitHub Actions OIDC token: %w", err)
+	}
+	if response.Value == "" {
+		return "", fmt.Errorf("GitHub Actions responded without an OIDC token")
+	}
+	return response.Value, nil
+}
+
+// Requests an OIDC token of the running Azure Pipelines job, issued for the service connection's federated credential
+func (c *federatedCredentialConfig) requestAzurePipelinesOIDCToken(ctx context.Context) (string, error) {
+	requestURL, err := url.Parse(os.Getenv("SYSTEM_OIDCREQUESTURI"))
+	if err != nil {
+		return "", fmt.Errorf("invalid SYSTEM_OIDCREQUESTURI: %w", err)
+	}
+	query := requestURL.Query()
+	query.Set("api-version", azurePipelinesOIDCAPIVersion)
+	query.Set("serviceConnectionId", c.serviceConnectionID)
+	requestURL.RawQuery = query.Encode()
+
+	var response struct {
+		OIDCToken string `json:"oidcToken"`
+	}
+	if err := requestOIDCToken(ctx, http.MethodPost, requestURL.String(), os.Getenv("SYSTEM_ACCESSTOKEN"), &response); err != nil {
+		return "", fmt.Errorf("failed to request Azure Pipelines OIDC token of service connection %q: %w", c.serviceConnectionID, err)
+	}
+	if response.OIDCToken == "" {
+		return "", fmt.Errorf("Azure Pipelines responded without an OIDC token for service connection %q", c.serviceConnectionID)
+	}
+	return response.OIDCToken, nil
+}
+
+// Requests an OIDC token from the CI system's token endpoint, authenticated by the job's bearer token, decoding the JSON response
+func requestOIDCToken(ctx context.Context, method, requestURL, bearerToken string, response any) error {
+	ctx, cancel := context.WithTimeout(ctx, federatedTokenRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+bearerToken)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("received status %d: %s", resp.StatusCode, string(body))
+	}
+	return json.Unmarshal(body, response)
+}
@@ -0,0 +1,147 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// the credentials the suite can authenticate its Azure clients with, tried in the order AZURE_CREDENTIAL_CHAIN lists them
+const (
+	credentialAzureCLI        = "azurecli"
+	credentialEnvironment     = "environment"
+	credentialManagedIdentity = "managedidentity"
+	credentialDeviceCode      = "devicecode"
+	credentialFederated       = "federated"
+)
+
+var credentialKinds = []string{credentialAzureCLI, credentialEnvironment, credentialManagedIdentity, credentialDeviceCode, credentialFederated}
+
+// the chain used when AZURE_CREDENTIAL_CHAIN isn't set, which excludes the device code credential, since it waits on a user
+// to sign in, and is preceded by the federated credential when one is configured
+var defaultCredentialChain = []string{credentialAzureCLI, credentialEnvironment, credentialManagedIdentity}
+
+// Parses the comma-separated list of credentials AZURE_CREDENTIAL_CHAIN names, e.g. "azurecli,devicecode", returning the default
+// chain when the list is empty. The federated credential can only be listed once it's configured
+func parseCredentialChain(value string, federated *federatedCredentialConfig) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		if federated != nil {
+			return append([]string{credentialFederated}, defaultCredentialChain...), nil
+		}
+		return defaultCredentialChain, nil
+	}
+
+	var chain []string
+	seen := map[string]bool{}
+	for _, kind := range strings.Split(value, ",") {
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		if !isCredentialKind(kind) {
+			return nil, fmt.Errorf("unknown credential %q, expected one of %s", kind, strings.Join(credentialKinds, ", "))
+		}
+		if kind == credentialFederated && federated == nil {
+			return nil, fmt.Errorf("credential %q isn't configured, see AZURE_FEDERATED_TOKEN_SOURCE", kind)
+		}
+		if seen[kind] {
+			return nil, fmt.Errorf("credential %q is listed more than once", kind)
+		}
+		seen[kind] = true
+		chain = append(chain, kind)
+	}
+	return chain, nil
+}
+
+func isCredentialKind(kind string) bool {
+	for _, known := range credentialKinds {
+		if kind == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the credential authenticating each of the suite's Azure clients, which tries each credential of the suite's chain
+// in turn until one of them is available, logging which one was used. Credentials which can't be constructed, e.g. the
+// environment credential when no client secret or certificate is set, are skipped
+func newSuiteCredential(suiteConfig *suiteConfig) (azcore.TokenCredential, error) {
+	clientOpts := azcore.ClientOptions{
+		Cloud: suiteConfig.cloudEnvironment.configuration,
+	}
+	tenantID := os.Getenv("AZURE_TENANT_ID")
+
+	var sources []azcore.TokenCredential
+	var names []string
+	for _, kind := range suiteConfig.credentialChain {
+		var credential azcore.TokenCredential
+		var err error
+		switch kind {
+		case credentialAzureCLI:
+			credential, err = azidentity.NewAzureCLICredential(&azidentity.AzureCLICredentialOptions{TenantID: tenantID})
+		case credentialEnvironment:
+			credential, err = azidentity.NewEnvironmentCredential(&azidentity.EnvironmentCredentialOptions{ClientOptions: clientOpts})
+		case credentialManagedIdentity:
+			opts := &azidentity.ManagedIdentityCredentialOptions{ClientOptions: clientOpts}
+			// as the default credential chain does, a user-assigned identity is selected by its client ID
+			if clientID := os.Getenv("AZURE_CLIENT_ID"); clientID != "" {
+				opts.ID = azidentity.ClientID(clientID)
+			}
+			credential, err = azidentity.NewManagedIdentityCredential(opts)
+		case credentialDeviceCode:
+			credential, err = azidentity.NewDeviceCodeCredential(&azidentity.DeviceCodeCredentialOptions{
+				ClientOptions: clientOpts,
+				TenantID:      tenantID,
+				UserPrompt: func(_ context.Context, message azidentity.DeviceCodeMessage) error {
+					log.Println(message.Message)
+					return nil
+				},
+			})
+		case credentialFederated:
+			credential, err = suiteConfig.federatedCredential.newCredential(suiteConfig.cloudEnvironment)
+		}
+		if err != nil {
+			log.Printf("skipping %s credential, which can't be used: %s", kind, err)
+			continue
+		}
+		sources = append(sources, &loggedCredential{name: kind, credential: credential})
+		names = append(names, kind)
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("none of the credentials of chain %s can be used", strings.Join(suiteConfig.credentialChain, ","))
+	}
+	log.Printf("authenticating with the first available credential of chain %s", strings.Join(names, ","))
+
+	if len(sources) == 1 {
+		return sources[0], nil
+	}
+	credential, err := azidentity.NewChainedTokenCredential(sources, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create credential chain: %w", err)
+	}
+	return credential, nil
+}
+
+// loggedCredential logs the first token the credential it wraps acquires, such that the log of each run records which
+// credential of the chain authenticated the suite
+type loggedCredential struct {
+	name       string
+	credential azcore.TokenCredential
+	logged     sync.Once
+}
+
+// Returns the wrapped credential's error as is, since the chain only tries its next credential when the error identifies the
+// credential as unavailable
+func (c *loggedCredential) GetToken(ctx context.Context, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	token, err := c.credential.GetToken(ctx, opts)
+	if err == nil {
+		c.logged.Do(func() {
+			log.Printf("authenticated with %s credential", c.name)
+		})
+	}
+	return token, err
+}
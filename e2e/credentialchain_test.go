@@ -0,0 +1,72 @@
+package e2e_test
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCredentialChain(t *testing.T) {
+	federated := &federatedCredentialConfig{source: federatedTokenSourceGitHub}
+
+	cases := []struct {
+		name      string
+		value     string
+		federated *federatedCredentialConfig
+		chain     []string
+		err       bool
+	}{
+		{
+			name:  "unset",
+			chain: []string{credentialAzureCLI, credentialEnvironment, credentialManagedIdentity},
+		},
+		{
+			name:      "unset with a federated credential",
+			federated: federated,
+			chain:     []string{credentialFederated, credentialAzureCLI, credentialEnvironment, credentialManagedIdentity},
+		},
+		{
+			name:  "listed credentials in order",
+			value: " DeviceCode, azurecli ",
+			chain: []string{credentialDeviceCode, credentialAzureCLI},
+		},
+		{
+			name:      "listed federated credential",
+			value:     "federated",
+			federated: federated,
+			chain:     []string{credentialFederated},
+		},
+		{
+			name:  "federated credential which isn't configured",
+			value: "federated,azurecli",
+			err:   true,
+		},
+		{
+			name:  "unknown credential",
+			value: "azurecli,password",
+			err:   true,
+		},
+		{
+			name:  "duplicate credential",
+			value: "azurecli,environment,azurecli",
+			err:   true,
+		},
+		{
+			name:  "empty entry",
+			value: "azurecli,,environment",
+			err:   true,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			chain, err := parseCredentialChain(c.value, c.federated)
+			if c.err != (err != nil) {
+				t.Fatalf("expected error %t, but got: %v", c.err, err)
+			}
+			if !reflect.DeepEqual(chain, c.chain) {
+				t.Errorf("expected chain %v, but got %v", c.chain, chain)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// the storage classes AKS creates within every cluster, provisioning volumes through the Azure Disk and Azure File CSI drivers
+	azureDiskCSIStorageClass = "managed-csi"
+	azureFileCSIStorageClass = "azurefile-csi"
+)
+
+// Validates the Azure Disk and Azure File CSI node drivers on the node, along with the dependencies bootstrap lays down for them,
+// by running a pod on the node which writes to, and reads back from, a volume provisioned through each driver's storage class
+func validateCSIDrivers(ctx context.Context, kube *kubeclient, namespace, nodeName string) error {
+	diskClaimName := fmt.Sprintf("%s-csi-disk", nodeName)
+	fileClaimName := fmt.Sprintf("%s-csi-file", nodeName)
+	claims := []string{
+		getCSISmokeTestPVCTemplate(namespace, diskClaimName, azureDiskCSIStorageClass, string(corev1.ReadWriteOnce)),
+		getCSISmokeTestPVCTemplate(namespace, fileClaimName, azureFileCSIStorageClass, string(corev1.ReadWriteMany)),
+	}
+	for _, manifest := range claims {
+		claimName, err := ensurePVC(ctx, kube, manifest)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := deletePVC(ctx, kube, namespace, claimName); err != nil {
+				log.Printf("unable to delete CSI smoke test PVC: %s", err)
+			}
+		}()
+	}
+
+	podName := fmt.Sprintf("%s-csi-smoke-test", nodeName)
+	if err := applyPodManifest(ctx, kube, getCSISmokeTestPodTemplate(namespace, nodeName, diskClaimName, fileClaimName)); err != nil {
+		return fmt.Errorf("failed to ensure CSI smoke test pod %q: %w", podName, err)
+	}
+	// the volumes can only be deleted once the pod no longer mounts them
+	defer func() {
+		if err := waitUntilPodDeleted(ctx, kube, namespace, podName); err != nil {
+			log.Printf("unable to delete CSI smoke test pod %q: %s", podName, err)
+		}
+	}()
+
+	log.Printf("waiting for CSI smoke test pod %q to write to its Azure Disk and Azure File volumes...", podName)
+	if err := waitUntilPodSucceeded(ctx, kube, namespace, podName); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Creates the PVC unless it already exists, returning its name. A PVC's spec can't be updated once it has been bound, so an
+// existing PVC is used as is
+func ensurePVC(ctx context.Context, kube *kubeclient, manifest string) (string, error) {
+	var pvc corev1.PersistentVolumeClaim
+	if err := yaml.Unmarshal([]byte(manifest), &pvc); err != nil {
+		return "", fmt.Errorf("failed to unmarshal PVC manifest: %w", err)
+	}
+	if err := kube.dynamic.Create(ctx, &pvc); err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", fmt.Errorf("failed to create PVC %s/%s: %w", pvc.Namespace, pvc.Name, err)
+	}
+	return pvc.Name, nil
+}
+
+// Deletes the PVC, the volume of which is then deleted by its CSI driver as per its storage class' reclaim policy
+func deletePVC(ctx context.Context, kube *kubeclient, namespace, name string) error {
+	err := kube.typed.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete PVC %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
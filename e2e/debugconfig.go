@@ -0,0 +1,172 @@
+package e2e_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// the registry the debug daemonset's image is pulled from by default
+const defaultDebugDaemonsetRegistry = "mcr.microsoft.com"
+
+// debugDaemonsetConfig customizes the debug daemonset, through which the suite reaches the cluster's nodes, for environments
+// which can't pull its default image from MCR, or whose debug access is restricted to specific nodes or taints
+type debugDaemonsetConfig struct {
+	// image, when set, replaces the image of the debug daemonset, which must provide sleep and nsenter
+	image string
+
+	// registry, when set, replaces the registry of the debug daemonset's default image, e.g. a mirror of MCR
+	registry string
+
+	// tolerations, when set, replace the debug daemonset's default toleration of every taint
+	tolerations []corev1.Toleration
+
+	// nodeSelector, when set, replaces the node selector of the debug daemonset, which otherwise targets the nodes of
+	// the cluster's nodepool1
+	nodeSelector map[string]string
+
+	// resourceRequests are the resources requested by the debug daemonset's containers
+	resourceRequests corev1.ResourceList
+}
+
+// Reads the debug daemonset's config from the DEBUG_DAEMONSET_* environment variables, each of which is optional
+func newDebugDaemonsetConfig() (debugDaemonsetConfig, error) {
+	config := debugDaemonsetConfig{
+		image:    os.Getenv("DEBUG_DAEMONSET_IMAGE"),
+		registry: strings.TrimSuffix(os.Getenv("DEBUG_DAEMONSET_REGISTRY"), "/"),
+	}
+
+	tolerations, err := parseTolerations(os.Getenv("DEBUG_DAEMONSET_TOLERATIONS"))
+	if err != nil {
+		return debugDaemonsetConfig{}, fmt.Errorf("invalid DEBUG_DAEMONSET_TOLERATIONS: %w", err)
+	}
+	config.tolerations = tolerations
+
+	nodeSelector, err := parseNodeSelector(os.Getenv("DEBUG_DAEMONSET_NODE_SELECTOR"))
+	if err != nil {
+		return debugDaemonsetConfig{}, fmt.Errorf("invalid DEBUG_DAEMONSET_NODE_SELECTOR: %w", err)
+	}
+	config.nodeSelector = nodeSelector
+
+	requests, err := parseResourceList(os.Getenv("DEBUG_DAEMONSET_RESOURCE_REQUESTS"))
+	if err != nil {
+		return debugDaemonsetConfig{}, fmt.Errorf("invalid DEBUG_DAEMONSET_RESOURCE_REQUESTS: %w", err)
+	}
+	config.resourceRequests = requests
+
+	return config, nil
+}
+
+// Applies the config to the pod template of the debug daemonset
+func (c debugDaemonsetConfig) apply(spec *corev1.PodSpec) {
+	for i := range spec.Containers {
+		container := &spec.Containers[i]
+		if c.image != "" {
+			container.Image = c.image
+		} else if c.registry != "" {
+			container.Image = c.registry + strings.TrimPrefix(container.Image, defaultDebugDaemonsetRegistry)
+		}
+		if len(c.resourceRequests) > 0 {
+			container.Resources.Requests = c.resourceRequests.DeepCopy()
+		}
+	}
+	if len(c.tolerations) > 0 {
+		spec.Tolerations = append([]corev1.Toleration{}, c.tolerations...)
+	}
+	if len(c.nodeSelector) > 0 {
+		spec.NodeSelector = map[string]string{}
+		for key, value := range c.nodeSelector {
+			spec.NodeSelector[key] = value
+		}
+	}
+}
+
+// Parses a comma-separated list of tolerations, each of which is in the form "key=value:effect", "key:effect" or "key",
+// tolerating taints with the key and value, any value, or any value and effect respectively. The effect may be omitted from
+// "key=value" to tolerate every effect, and "*" tolerates every taint
+func parseTolerations(tolerationList string) ([]corev1.Toleration, error) {
+	var tolerations []corev1.Toleration
+	for _, spec := range strings.Split(tolerationList, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		if spec == "*" {
+			tolerations = append(tolerations, corev1.Toleration{Operator: corev1.TolerationOpExists})
+			continue
+		}
+
+		keyValue, effect, _ := strings.Cut(spec, ":")
+		key, value, hasValue := strings.Cut(keyValue, "=")
+		if key == "" {
+			return nil, fmt.Errorf("toleration %q has no key", spec)
+		}
+		toleration := corev1.Toleration{Key: key, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffect(effect)}
+		if hasValue {
+			toleration.Operator = corev1.TolerationOpEqual
+			toleration.Value = value
+		}
+		switch toleration.Effect {
+		case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		default:
+			return nil, fmt.Errorf("toleration %q has unknown effect %q", spec, effect)
+		}
+		tolerations = append(tolerations, toleration)
+	}
+	return tolerations, nil
+}
+
+// Parses a comma-separated list of node labels in the form "key=value", e.g. "kubernetes.azure.com/agentpool=system", each of
+// which the selected nodes must have
+func parseNodeSelector(selector string) (map[string]string, error) {
+	var nodeSelector map[string]string
+	for _, spec := range strings.Split(selector, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		key, value, found := strings.Cut(spec, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("label %q isn't in the form key=value", spec)
+		}
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("label %q has an invalid key: %s", spec, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return nil, fmt.Errorf("label %q has an invalid value: %s", spec, strings.Join(errs, "; "))
+		}
+		if nodeSelector == nil {
+			nodeSelector = map[string]string{}
+		}
+		nodeSelector[key] = value
+	}
+	return nodeSelector, nil
+}
+
+// Parses a comma-separated list of resource quantities in the form "name=quantity", e.g. "cpu=100m,memory=128Mi"
+func parseResourceList(resourceList string) (corev1.ResourceList, error) {
+	var resources corev1.ResourceList
+	for _, spec := range strings.Split(resourceList, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		name, value, found := strings.Cut(spec, "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf("resource %q isn't in the form name=quantity", spec)
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("resource %q has an invalid quantity: %w", spec, err)
+		}
+		if resources == nil {
+			resources = corev1.ResourceList{}
+		}
+		resources[corev1.ResourceName(name)] = quantity
+	}
+	return resources, nil
+}
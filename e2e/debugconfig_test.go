@@ -0,0 +1,115 @@
+package e2e_test
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParseTolerations(t *testing.T) {
+	cases := []struct {
+		name        string
+		list        string
+		tolerations []corev1.Toleration
+		err         bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:        "every taint",
+			list:        "*",
+			tolerations: []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+		},
+		{
+			name: "each form",
+			list: "sku=gpu:NoSchedule, dedicated:NoExecute, CriticalAddonsOnly, zone=a",
+			tolerations: []corev1.Toleration{
+				{Key: "sku", Operator: corev1.TolerationOpEqual, Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "dedicated", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+				{Key: "CriticalAddonsOnly", Operator: corev1.TolerationOpExists},
+				{Key: "zone", Operator: corev1.TolerationOpEqual, Value: "a"},
+			},
+		},
+		{
+			name: "missing key",
+			list: "=gpu:NoSchedule",
+			err:  true,
+		},
+		{
+			name: "unknown effect",
+			list: "sku=gpu:NoRun",
+			err:  true,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			tolerations, err := parseTolerations(c.list)
+			if c.err != (err != nil) {
+				t.Fatalf("expected error %t, but got: %v", c.err, err)
+			}
+			if !reflect.DeepEqual(tolerations, c.tolerations) {
+				t.Errorf("expected tolerations %+v, but got %+v", c.tolerations, tolerations)
+			}
+		})
+	}
+}
+
+func TestParseNodeSelector(t *testing.T) {
+	cases := []struct {
+		name         string
+		selector     string
+		nodeSelector map[string]string
+		err          bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:         "labels",
+			selector:     "kubernetes.azure.com/agentpool=system, kubernetes.io/os=linux,",
+			nodeSelector: map[string]string{"kubernetes.azure.com/agentpool": "system", "kubernetes.io/os": "linux"},
+		},
+		{
+			name:         "empty value",
+			selector:     "node-role.kubernetes.io/agent=",
+			nodeSelector: map[string]string{"node-role.kubernetes.io/agent": ""},
+		},
+		{
+			name:     "missing value",
+			selector: "kubernetes.io/os",
+			err:      true,
+		},
+		{
+			name:     "missing key",
+			selector: "=linux",
+			err:      true,
+		},
+		{
+			name:     "invalid key",
+			selector: "kubernetes.io/os/name=linux",
+			err:      true,
+		},
+		{
+			name:     "invalid value",
+			selector: "kubernetes.io/os=linux!",
+			err:      true,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			nodeSelector, err := parseNodeSelector(c.selector)
+			if c.err != (err != nil) {
+				t.Fatalf("expected error %t, but got: %v", c.err, err)
+			}
+			if !reflect.DeepEqual(nodeSelector, c.nodeSelector) {
+				t.Errorf("expected node selector %v, but got %v", c.nodeSelector, nodeSelector)
+			}
+		})
+	}
+}
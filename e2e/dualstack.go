@@ -0,0 +1,180 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/Azure/agentbakere2e/scenario"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ipv4AddressVersion = "IPv4"
+	ipv6AddressVersion = "IPv6"
+	kubeletNodeIPFlag  = "--node-ip"
+)
+
+// vmIPAddresses holds the private IP addresses assigned to the primary NIC of a VMSS instance, keyed by address version
+type vmIPAddresses map[string][]string
+
+func (a vmIPAddresses) isDualStack() bool {
+	return len(a[ipv4AddressVersion]) > 0 && len(a[ipv6AddressVersion]) > 0
+}
+
+func (a vmIPAddresses) contains(ip string) bool {
+	for _, addresses := range a {
+		for _, address := range addresses {
+			if address == ip {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Returns the private IPv4 and IPv6 addresses assigned to the primary NIC of the scenario's VMSS instance
+func getVMIPAddresses(ctx context.Context, vmssName string, opts *scenarioRunOpts) (vmIPAddresses, error) {
+	instanceNICResult, err := getVMNetworkInterfaces(ctx, opts.cloud, opts.suiteConfig.subscription, *opts.clusterConfig.cluster.Properties.NodeResourceGroup, vmssName, opts.instance.instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network interfaces of vmss %q: %w", vmssName, err)
+	}
+	if len(instanceNICResult.Value) == 0 {
+		return nil, fmt.Errorf("instance %q of vmss %q has no network interfaces", opts.instance.instanceID, vmssName)
+	}
+
+	nic := instanceNICResult.Value[0]
+	for _, candidate := range instanceNICResult.Value {
+		if candidate.Properties.Primary {
+			nic = candidate
+			break
+		}
+	}
+
+	addresses := vmIPAddresses{}
+	for _, ipConfig := range nic.Properties.IPConfigurations {
+		address := ipConfig.Properties.PrivateIPAddress
+		if address == "" {
+			continue
+		}
+		// the primary IP config of each address version is listed first, since it's the one kubelet should report
+		version := ipConfig.Properties.PrivateIPAddressVersion
+		if ipConfig.Properties.Primary {
+			addresses[version] = append([]string{address}, addresses[version]...)
+		} else {
+			addresses[version] = append(addresses[version], address)
+		}
+	}
+
+	return addresses, nil
+}
+
+// Asserts that the node reports the primary IPv4 and IPv6 addresses of its NIC as internal addresses, and that any
+// --node-ip supplied to kubelet through the bootstrap config refers to the NIC's addresses, determines which of them the node
+// reports first, and was passed to the running kubelet. On kubenet clusters, the node's CNI config is also expected to
+// allocate pod IPs from each of the node's pod CIDRs
+func validateDualStackNodeIPs(ctx context.Context, nodeName, vmssName, vmPrivateIP, sshPrivateKey string, addresses vmIPAddresses, opts *scenarioRunOpts) error {
+	if !addresses.isDualStack() {
+		return fmt.Errorf("expected instance %q of vmss %q to have both IPv4 and IPv6 addresses, but had %v", opts.instance.instanceID, vmssName, addresses)
+	}
+
+	node, err := opts.clusterConfig.kube.typed.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to get node %q: %w", nodeName, err)
+	}
+
+	// the node's first internal IP is its primary IP, e.g. the one reported as the host IP of its pods
+	var internalIPs []string
+	isInternalIP := map[string]bool{}
+	for _, address := range node.Status.Addresses {
+		if address.Type == corev1.NodeInternalIP {
+			internalIPs = append(internalIPs, address.Address)
+			isInternalIP[address.Address] = true
+		}
+	}
+	for _, version := range []string{ipv4AddressVersion, ipv6AddressVersion} {
+		if expected := addresses[version][0]; !isInternalIP[expected] {
+			return fmt.Errorf("expected node %q to report %s address %s as an internal IP, but its addresses were %v", nodeName, version, expected, node.Status.Addresses)
+		}
+	}
+
+	log.Printf("node %q reports dual-stack addresses %v", nodeName, addresses)
+
+	var validators []*scenario.LiveVMValidator
+	if nodeIP := opts.nbc.KubeletConfig[kubeletNodeIPFlag]; nodeIP != "" {
+		expectedPrimary, err := getExpectedPrimaryNodeIP(nodeIP, addresses)
+		if err != nil {
+			return fmt.Errorf("unable to determine the primary IP of node %q: %w", nodeName, err)
+		}
+		if internalIPs[0] != expectedPrimary {
+			return fmt.Errorf("expected node %q to report %s as its primary internal IP given kubelet %s %q, but its internal IPs were %v", nodeName, expectedPrimary, kubeletNodeIPFlag, nodeIP, internalIPs)
+		}
+		validators = append(validators, scenario.KubeletCommandLineValidator(map[string]string{kubeletNodeIPFlag: nodeIP}))
+	}
+
+	isAzureCNI, err := opts.clusterConfig.isAzureCNI()
+	if err != nil {
+		return err
+	}
+	if !isAzureCNI {
+		if len(node.Spec.PodCIDRs) < 2 {
+			return fmt.Errorf("expected node %q to be assigned both IPv4 and IPv6 pod CIDRs, but was assigned %v", nodeName, node.Spec.PodCIDRs)
+		}
+		validators = append(validators, scenario.KubenetCNIConfigValidator(node.Spec.PodCIDRs))
+	}
+
+	if len(validators) == 0 {
+		return nil
+	}
+	podName, err := getDebugPodName(opts.clusterConfig.kube)
+	if err != nil {
+		return fmt.Errorf("unable to get debug pod name: %w", err)
+	}
+	run := &liveVMValidatorRun{
+		exec: func(ctx context.Context, command string, isShellBuiltIn bool) (*podExecResult, error) {
+			return execOnVMWithRunCommandFallback(ctx, vmssName, vmPrivateIP, podName, sshPrivateKey, command, isShellBuiltIn, opts)
+		},
+	}
+	for _, validator := range validators {
+		failures, err := run.validate(ctx, validator, validator.Description)
+		if err != nil {
+			return err
+		}
+		if len(failures) > 0 {
+			return fmt.Errorf("failed dual-stack validator:\n%s", strings.Join(failures, "\n"))
+		}
+	}
+
+	return nil
+}
+
+// Returns the address the node is expected to report first given kubelet's --node-ip, each of whose addresses must be
+// assigned to the NIC. An unspecified address, e.g. "::", instead selects the NIC's primary address of its address version
+func getExpectedPrimaryNodeIP(nodeIP string, addresses vmIPAddresses) (string, error) {
+	for _, ip := range strings.Split(nodeIP, ",") {
+		ip = strings.TrimSpace(ip)
+		if parsed := net.ParseIP(ip); parsed != nil && parsed.IsUnspecified() {
+			continue
+		}
+		if !addresses.contains(ip) {
+			return "", fmt.Errorf("kubelet %s %q refers to address %s, which isn't assigned to the NIC: %v", kubeletNodeIPFlag, nodeIP, ip, addresses)
+		}
+	}
+
+	primary := strings.TrimSpace(strings.Split(nodeIP, ",")[0])
+	parsed := net.ParseIP(primary)
+	if parsed == nil {
+		return "", fmt.Errorf("unable to parse kubelet %s %q", kubeletNodeIPFlag, nodeIP)
+	}
+	if !parsed.IsUnspecified() {
+		return primary, nil
+	}
+	version := ipv4AddressVersion
+	if parsed.To4() == nil {
+		version = ipv6AddressVersion
+	}
+	return addresses[version][0], nil
+}
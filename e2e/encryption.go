@@ -0,0 +1,32 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+)
+
+func enableEncryptionAtHost(vmss *armcompute.VirtualMachineScaleSet) {
+	if vmss.Properties.VirtualMachineProfile.SecurityProfile == nil {
+		vmss.Properties.VirtualMachineProfile.SecurityProfile = &armcompute.SecurityProfile{}
+	}
+	vmss.Properties.VirtualMachineProfile.SecurityProfile.EncryptionAtHost = to.Ptr(true)
+}
+
+// Asserts that the scenario's VMSS instance was provisioned with encryption at host enabled
+func validateEncryptionAtHost(ctx context.Context, vmssName string, opts *scenarioRunOpts) error {
+	vmResp, err := opts.cloud.vmssVMClient.Get(ctx, *opts.clusterConfig.cluster.Properties.NodeResourceGroup, vmssName, opts.instance.instanceID, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get instance %q of vmss %q: %w", opts.instance.instanceID, vmssName, err)
+	}
+
+	properties := vmResp.VirtualMachineScaleSetVM.Properties
+	if properties == nil || properties.SecurityProfile == nil || properties.SecurityProfile.EncryptionAtHost == nil ||
+		!*properties.SecurityProfile.EncryptionAtHost {
+		return fmt.Errorf("expected instance %q of vmss %q to have encryption at host enabled, but it did not", opts.instance.instanceID, vmssName)
+	}
+
+	return nil
+}
@@ -8,6 +8,34 @@ const (
 	notFoundErrorCode                = "404 Not Found"
 )
 
+// Error codes returned by Azure for failures which are expected to succeed if retried, e.g. due to capacity or throttling
+var transientErrorCodes = []string{
+	"AllocationFailed",
+	"ZonalAllocationFailed",
+	"OverconstrainedAllocationRequest",
+	"OverconstrainedZonalAllocationRequest",
+	"SkuNotAvailable",
+	"TooManyRequests",
+	"OperationPreempted",
+	"RetryableError",
+	"InternalServerError",
+	"InternalExecutionError",
+}
+
+// Returns true if the error is classified as transient, i.e. is unrelated to node bootstrapping and likely to succeed if retried
+func isTransientError(err error) bool {
+	// CSE failures are bootstrapping failures, regardless of how they're reported
+	if isVMExtensionProvisioningError(err) {
+		return false
+	}
+	for _, code := range transientErrorCodes {
+		if errorHasSubstring(err, code) {
+			return true
+		}
+	}
+	return false
+}
+
 func isVMExtensionProvisioningError(err error) bool {
 	return errorHasSubstring(err, vmExtensionProvisioningErrorCode)
 }
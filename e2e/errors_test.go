@@ -0,0 +1,50 @@
+package e2e_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestIsTransientError(t *testing.T) {
+	cases := []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name:      "allocation failure",
+			err:       errors.New(`PUT https://management.azure.com/...: RESPONSE 409: ERROR CODE: AllocationFailed`),
+			transient: true,
+		},
+		{
+			name:      "wrapped throttling",
+			err:       fmt.Errorf("failed to create VMSS: %w", errors.New("ERROR CODE: TooManyRequests")),
+			transient: true,
+		},
+		{
+			name: "CSE failure",
+			err:  errors.New("ERROR CODE: VMExtensionProvisioningError, exit status=51"),
+		},
+		{
+			name: "CSE failure reporting an internal error",
+			err:  errors.New("ERROR CODE: VMExtensionProvisioningError: InternalExecutionError"),
+		},
+		{
+			name: "unclassified failure",
+			err:  errors.New("ERROR CODE: InvalidParameter"),
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			if transient := isTransientError(c.err); transient != c.transient {
+				t.Errorf("expected isTransientError to be %t, but got %t", c.transient, transient)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const eventsArtifactName = "events.log"
+
+// Collects the events involving the node, the pods scheduled onto it and the objects within the scenario's namespace into the
+// scenario's artifacts directory, such as those of pods whose volumes failed to mount or images failed to pull, or of a node
+// whose network isn't ready, which explain why a node that joined the cluster still failed to run its workloads
+func collectEventsArtifact(ctx context.Context, nodeName string, opts *scenarioRunOpts) error {
+	events, err := listNodeEvents(ctx, opts.clusterConfig.kube, opts.namespace, nodeName)
+	if err != nil {
+		return err
+	}
+
+	path, err := opts.artifacts.writeFile(eventsArtifactName, formatEvents(events))
+	if err != nil {
+		return err
+	}
+	log.Printf("wrote %d events involving node %q to %s", len(events), nodeName, path)
+
+	reasons := map[string]int{}
+	for _, event := range events {
+		if event.Type == corev1.EventTypeWarning {
+			reasons[event.Reason]++
+		}
+	}
+	if len(reasons) > 0 {
+		summary := make([]string, 0, len(reasons))
+		for reason, count := range reasons {
+			summary = append(summary, fmt.Sprintf("%s (%d)", reason, count))
+		}
+		sort.Strings(summary)
+		log.Printf("warning events involving node %q: %s", nodeName, strings.Join(summary, ", "))
+	}
+	return nil
+}
+
+// Returns the events involving the node, the pods currently scheduled onto it, and any object within the namespace, whose
+// pods may since have been deleted, ordered by when they last occurred
+func listNodeEvents(ctx context.Context, kube *kubeclient, namespace, nodeName string) ([]corev1.Event, error) {
+	var events []corev1.Event
+	seen := map[types.UID]bool{}
+	add := func(eventNamespace string, selector fields.Set) error {
+		opts := metav1.ListOptions{}
+		if selector != nil {
+			opts.FieldSelector = fields.SelectorFromSet(selector).String()
+		}
+		list, err := kube.typed.CoreV1().Events(eventNamespace).List(ctx, opts)
+		if err != nil {
+			return fmt.Errorf("failed to list events within namespace %q matching %v: %w", eventNamespace, selector, err)
+		}
+		for _, event := range list.Items {
+			if !seen[event.UID] {
+				seen[event.UID] = true
+				events = append(events, event)
+			}
+		}
+		return nil
+	}
+
+	if err := add(metav1.NamespaceAll, fields.Set{"involvedObject.kind": "Node", "involvedObject.name": nodeName}); err != nil {
+		return nil, err
+	}
+	pods, err := kube.typed.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods scheduled onto node %q: %w", nodeName, err)
+	}
+	for _, pod := range pods.Items {
+		if pod.Namespace == namespace {
+			continue
+		}
+		if err := add(pod.Namespace, fields.Set{"involvedObject.kind": "Pod", "involvedObject.name": pod.Name}); err != nil {
+			return nil, err
+		}
+	}
+	if err := add(namespace, nil); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).Before(eventTime(events[j]))
+	})
+	return events, nil
+}
+
+// Formats the events as a line each, in the form "<time> <type> <reason> <kind> <namespace>/<name> (x<count>): <message>"
+func formatEvents(events []corev1.Event) string {
+	var formatted strings.Builder
+	for _, event := range events {
+		object := event.InvolvedObject.Name
+		if event.InvolvedObject.Namespace != "" {
+			object = event.InvolvedObject.Namespace + "/" + object
+		}
+		count := event.Count
+		if count == 0 {
+			count = 1
+		}
+		fmt.Fprintf(&formatted, "%s %s %s %s %s (x%d): %s\n", eventTime(event).UTC().Format(time.RFC3339), event.Type, event.Reason, event.InvolvedObject.Kind, object, count, strings.TrimSpace(event.Message))
+	}
+	return formatted.String()
+}
+
+// Returns when the event last occurred, falling back on its event time for events recorded through the events API, which don't
+// set its timestamps
+func eventTime(event corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
@@ -3,16 +3,35 @@ package e2e_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
 )
 
 const (
+	// the time allowed for each attempt at executing a command within a pod, and the number of attempts made when
+	// attempts fail with transient errors
+	podExecTimeout       = 5 * time.Minute
+	podExecMaxAttempts   = 3
+	podExecRetryInterval = 5 * time.Second
+
+	// reported by the API server when it's unable to reach the kubelet of the pod's node, e.g. while its tunnel reconnects
+	podExecDialBackendError = "error dialing backend"
+	// reported by the API server or kubelet when the pod hasn't yet been scheduled, or its container hasn't yet started
+	podExecNoHostError            = "does not have a host assigned"
+	podExecContainerNotFoundError = "container not found"
+
 	sshCommandTemplate = `echo '%s' > sshkey%[2]s && chmod 0600 sshkey%[2]s && ssh -i sshkey%[2]s -o PasswordAuthentication=no -o UserKnownHostsFile=/dev/null -o StrictHostKeyChecking=no -o ConnectTimeout=5 azureuser@%s`
 )
 
@@ -70,7 +89,7 @@ func extractLogsFromVM(ctx context.Context, vmssName, privateIP, sshPrivateKey s
 	for file, sourceCmd := range commandList {
 		log.Printf("executing command on remote VM at %s of VMSS %s: %q", privateIP, vmssName, sourceCmd)
 
-		execResult, err := execOnVM(ctx, opts.clusterConfig.kube, privateIP, podName, sshPrivateKey, sourceCmd, false)
+		execResult, err := execOnInstance(ctx, vmssName, privateIP, podName, sshPrivateKey, sourceCmd, false, opts)
 		if execResult != nil {
 			execResult.dumpStderr()
 		}
@@ -106,6 +125,10 @@ func extractClusterParameters(ctx context.Context, kube *kubeclient) (map[string
 		if err != nil {
 			return nil, err
 		}
+		// e.g. the file not having been written yet, in which case its contents would otherwise be extracted as empty
+		if execResult.exitCode != "0" {
+			return nil, fmt.Errorf("command %q terminated with exit code %s, stderr: %q", sourceCmd, execResult.exitCode, execResult.stderr.String())
+		}
 
 		result[file] = execResult.stdout.String()
 	}
@@ -128,56 +151,106 @@ func execOnVM(ctx context.Context, kube *kubeclient, vmPrivateIP, jumpboxPodName
 	return execResult, nil
 }
 
+// Returns whether the instance's VM can't be reached over SSH, since CSE disables its SSH daemon when bootstrapped with SSH turned off
+func isSSHDisabled(opts *scenarioRunOpts) bool {
+	return opts.nbc.SSHStatus == datamodel.SSHOff
+}
+
+// Executes the command on the instance's VM over SSH through the jumpbox debug pod, unless the VM can't be reached over SSH, in which
+// case the command is executed through the debug pod scheduled onto the instance's node, or through the run command API when the
+// node never became ready to schedule it
+func execOnInstance(ctx context.Context, vmssName, vmPrivateIP, jumpboxPodName, sshPrivateKey, command string, isShellBuiltIn bool, opts *scenarioRunOpts) (*podExecResult, error) {
+	if !isSSHDisabled(opts) {
+		return execOnVM(ctx, opts.clusterConfig.kube, vmPrivateIP, jumpboxPodName, sshPrivateKey, command, isShellBuiltIn)
+	}
+	if opts.nodeDebugPodName != "" {
+		return execOnPrivilegedPod(ctx, opts.clusterConfig.kube, opts.namespace, opts.nodeDebugPodName, command)
+	}
+	return runCommandOnVM(ctx, vmssName, command, opts)
+}
+
 func execOnPrivilegedPod(ctx context.Context, kube *kubeclient, namespace, podName string, command string) (*podExecResult, error) {
 	privilegedCommand := append(nsenterCommandArray(), command)
-	return execOnPod(ctx, kube, namespace, podName, privilegedCommand)
+	return kube.execOnPod(ctx, namespace, podName, privilegedCommand)
 }
 
-func execOnPod(ctx context.Context, kube *kubeclient, namespace, podName string, command []string) (*podExecResult, error) {
-	req := kube.typed.CoreV1().RESTClient().Post().Resource("pods").Name(podName).Namespace(namespace).SubResource("exec")
+// Executes the command within the pod, bounding each attempt by podExecTimeout and retrying up to podExecMaxAttempts times
+// when the exec fails with a transient API error. A command which runs to completion isn't an error regardless of its exit
+// code, which is returned along with its captured stdout and stderr
+func (k *kubeclient) execOnPod(ctx context.Context, namespace, podName string, command []string) (*podExecResult, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := k.execOnPodOnce(ctx, namespace, podName, command)
+		if err == nil || attempt >= podExecMaxAttempts || ctx.Err() != nil || !isTransientPodExecError(err) {
+			return result, err
+		}
 
-	option := &corev1.PodExecOptions{
-		Command: command,
-		Stdout:  true,
-		Stderr:  true,
+		log.Printf("attempt %d of %d to execute command on pod %s/%s failed with a transient error, will retry: %s", attempt, podExecMaxAttempts, namespace, podName, err)
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(podExecRetryInterval):
+		}
 	}
+}
+
+func (k *kubeclient) execOnPodOnce(ctx context.Context, namespace, podName string, command []string) (*podExecResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, podExecTimeout)
+	defer cancel()
 
+	req := k.typed.CoreV1().RESTClient().Post().Resource("pods").Name(podName).Namespace(namespace).SubResource("exec")
 	req.VersionedParams(
-		option,
+		&corev1.PodExecOptions{
+			Command: command,
+			Stdout:  true,
+			Stderr:  true,
+		},
 		scheme.ParameterCodec,
 	)
 
-	exec, err := remotecommand.NewSPDYExecutor(kube.rest, "POST", req.URL())
+	exec, err := remotecommand.NewSPDYExecutor(k.rest, "POST", req.URL())
 	if err != nil {
 		return nil, fmt.Errorf("unable to create new SPDY executor for pod exec: %w", err)
 	}
 
-	var (
-		stdout, stderr bytes.Buffer
-		exitCode       string = "0"
-	)
+	var stdout, stderr bytes.Buffer
+	result := &podExecResult{
+		exitCode: "0",
+		stdout:   &stdout,
+		stderr:   &stderr,
+	}
 
 	err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{
 		Stdout: &stdout,
 		Stderr: &stderr,
 	})
-	if err != nil {
-		if strings.Contains(err.Error(), "command terminated with exit code") {
-			code, err := extractExitCode(err.Error())
-			if err != nil {
-				return nil, fmt.Errorf("error extracing exit code from remote command execution error msg: %w", err)
-			}
-			exitCode = code
-		} else {
-			return nil, fmt.Errorf("encountered unexpected error when executing command on pod: %w", err)
-		}
+	var exitErr utilexec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr) && exitErr.Exited():
+		result.exitCode = strconv.Itoa(exitErr.ExitStatus())
+	default:
+		return nil, fmt.Errorf("encountered unexpected error when executing command on pod %s/%s: %w, stderr: %q", namespace, podName, err, stderr.String())
 	}
+	return result, nil
+}
 
-	return &podExecResult{
-		exitCode: exitCode,
-		stdout:   &stdout,
-		stderr:   &stderr,
-	}, nil
+// Returns true if the pod exec failed before the command was started, due to an error of the API server or of its connection
+// to the pod's kubelet, such that it's likely to succeed if retried without the command running twice. Errors of an established
+// stream, e.g. EOF or a connection reset, aren't transient, since the command may already have run, nor is exceeding
+// podExecTimeout, since the command itself most likely hung
+func isTransientPodExecError(err error) bool {
+	return apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsInternalError(err) ||
+		utilnet.IsConnectionRefused(err) ||
+		errorHasSubstring(err, podExecDialBackendError)
+}
+
+// Returns true if the pod exec failed because the pod isn't yet running its container, which it's expected to once it has
+// been scheduled and its image pulled
+func isPodNotStartedExecError(err error) bool {
+	return errorHasSubstring(err, podExecNoHostError) || errorHasSubstring(err, podExecContainerNotFoundError)
 }
 
 func getWasmCurlCommand(url string) string {
@@ -0,0 +1,65 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Azure/agentbakere2e/scenario"
+)
+
+// Validates that the error returned when creating a negative scenario's VMSS is a CSE failure matching the scenario's expected failure
+func validateExpectedFailure(vmssErr error, expected *scenario.ExpectedFailure) error {
+	if vmssErr == nil {
+		return fmt.Errorf("expected bootstrapping to fail, but vmss was created successfully")
+	}
+	if !isVMExtensionProvisioningError(vmssErr) {
+		return fmt.Errorf("expected bootstrapping to fail due to a CSE error, but vmss creation failed with: %w", vmssErr)
+	}
+
+	errMsg := vmssErr.Error()
+	exitCode, err := extractCSEExitCode(errMsg)
+	if err != nil {
+		return fmt.Errorf("unable to extract CSE exit code from error %q: %w", errMsg, err)
+	}
+	if expected.CSEExitCode != 0 && exitCode != expected.CSEExitCode {
+		return fmt.Errorf("expected CSE to fail with exit code %d, but it failed with exit code %d: %s", expected.CSEExitCode, exitCode, errMsg)
+	}
+	if expected.ErrorMessage != "" && !strings.Contains(errMsg, expected.ErrorMessage) {
+		return fmt.Errorf("expected CSE error message to contain %q, but it was: %s", expected.ErrorMessage, errMsg)
+	}
+
+	log.Printf("CSE failed as expected with exit code %d", exitCode)
+	return nil
+}
+
+// Extracts the provisioning logs of each of the negative scenario's instances, such that the failure can still be inspected.
+// Failures are only logged, since the scenario's outcome has already been determined by its expected failure
+func extractExpectedFailureLogs(ctx context.Context, vmssName string, privateKeyBytes []byte, opts *scenarioRunOpts) {
+	instances, err := listVMSSInstances(ctx, vmssName, opts)
+	if err != nil {
+		log.Printf("unable to list instances of vmss %q to extract logs from: %s", vmssName, err)
+		return
+	}
+
+	for _, instance := range instances {
+		artifacts := opts.artifacts
+		if len(instances) > 1 {
+			if artifacts, err = opts.artifacts.instance(instance.instanceID); err != nil {
+				log.Printf("unable to create artifacts directory of instance %q: %s", instance.instanceID, err)
+				continue
+			}
+		}
+		instanceOpts := opts.forInstance(instance, artifacts)
+
+		vmPrivateIP, err := pollGetVMPrivateIP(ctx, vmssName, instanceOpts)
+		if err != nil {
+			log.Printf("unable to get private IP of instance %q: %s", instance.instanceID, err)
+			continue
+		}
+		if err := pollExtractVMLogs(ctx, vmssName, vmPrivateIP, privateKeyBytes, instanceOpts); err != nil {
+			log.Printf("unable to extract logs of instance %q: %s", instance.instanceID, err)
+		}
+	}
+}
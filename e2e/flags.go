@@ -2,8 +2,25 @@ package e2e_test
 
 import "flag"
 
-var e2eMode string
+var (
+	e2eMode      string
+	runScenarios string
+	includeTags  string
+	excludeTags  string
+
+	scenarioDefinitionsDir string
+	artifactsDirFlag       string
+	updateGoldenFiles      bool
+	seedFlag               int64
+)
 
 func init() {
-	flag.StringVar(&e2eMode, "e2eMode", "", "specify mode for e2e tests - 'coverage' or 'validation' - default: 'validation'")
+	flag.StringVar(&e2eMode, "e2eMode", "", "specify mode for e2e tests - 'coverage', 'validation' or 'golden', which only compares each scenario's bootstrap payloads against its golden files - default: 'validation'")
+	flag.StringVar(&runScenarios, "run-scenarios", "", "comma-separated list of scenario names or regular expressions matching the names of the scenarios to run - default: all scenarios")
+	flag.StringVar(&includeTags, "include-tags", "", "comma-separated list of tags, of which scenarios must carry at least one to run - default: no restriction")
+	flag.StringVar(&excludeTags, "exclude-tags", "", "comma-separated list of tags, of which scenarios must carry none to run, taking precedence over -include-tags - default: no restriction")
+	flag.StringVar(&scenarioDefinitionsDir, "scenario-definitions-dir", "scenario/definitions", "directory containing YAML scenario definitions to run alongside the scenarios defined in Go")
+	flag.StringVar(&artifactsDirFlag, "artifacts-dir", "scenario-logs", "local directory within which each scenario's artifacts directory is created")
+	flag.Int64Var(&seedFlag, "seed", 0, "seed of the suite's randomness, e.g. of the names of clusters and VMSSes, which is logged at the start of each run such that a failing run can be reproduced - default: derived from the current time")
+	flag.BoolVar(&updateGoldenFiles, "update", false, "overwrite the golden files of each scenario's bootstrap payloads with those generated, rather than comparing against them, when -e2eMode is 'golden'")
 }
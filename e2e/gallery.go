@@ -0,0 +1,86 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Azure/agentbakere2e/scenario"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+)
+
+// Resolves the latest version of each scenario image definition within the test gallery and overwrites the
+// respective entry of scenario.DefaultImageVersionIDs. Images whose latest version cannot be resolved retain
+// their hard-coded default version.
+func resolveLatestImageVersions(ctx context.Context, cloud *azureClient, location string) {
+	for name, imageName := range scenario.ImageDefinitionNames {
+		versionID, err := getLatestImageVersionID(ctx, cloud, imageName, location)
+		if err != nil {
+			log.Printf("unable to resolve latest version of image %q, falling back to %q: %s", imageName, scenario.DefaultImageVersionIDs[name], err)
+			continue
+		}
+		log.Printf("resolved latest version of image %q: %q", imageName, versionID)
+		scenario.DefaultImageVersionIDs[name] = versionID
+	}
+}
+
+// Returns the resource ID of the most recently published image version of the specified image definition
+// which has succeeded provisioning, isn't excluded from latest, and has been replicated to the specified location
+func getLatestImageVersionID(ctx context.Context, cloud *azureClient, imageName, location string) (string, error) {
+	pager := cloud.galleryClient.NewListByGalleryImagePager(scenario.GalleryResourceGroupName, scenario.GalleryName, imageName, nil)
+
+	var (
+		latestID        string
+		latestPublished time.Time
+	)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to advance page: %w", err)
+		}
+		for _, version := range page.Value {
+			if !isViableImageVersion(version, location) {
+				continue
+			}
+			published := *version.Properties.PublishingProfile.PublishedDate
+			if latestID == "" || published.After(latestPublished) {
+				latestID = *version.ID
+				latestPublished = published
+			}
+		}
+	}
+
+	if latestID == "" {
+		return "", fmt.Errorf("no viable versions of image %q found in gallery %q", imageName, scenario.GalleryName)
+	}
+
+	return latestID, nil
+}
+
+func isViableImageVersion(version *armcompute.GalleryImageVersion, location string) bool {
+	if version == nil || version.ID == nil || version.Properties == nil || version.Properties.PublishingProfile == nil {
+		return false
+	}
+	if version.Properties.ProvisioningState == nil ||
+		*version.Properties.ProvisioningState != armcompute.GalleryImageVersionPropertiesProvisioningStateSucceeded {
+		return false
+	}
+
+	publishingProfile := version.Properties.PublishingProfile
+	if publishingProfile.PublishedDate == nil {
+		return false
+	}
+	if publishingProfile.ExcludeFromLatest != nil && *publishingProfile.ExcludeFromLatest {
+		return false
+	}
+
+	for _, region := range publishingProfile.TargetRegions {
+		// target region names are returned in display form, e.g. "East US"
+		if region != nil && region.Name != nil && strings.EqualFold(strings.ReplaceAll(*region.Name, " ", ""), location) {
+			return true
+		}
+	}
+	return false
+}
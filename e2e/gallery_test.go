@@ -0,0 +1,105 @@
+package e2e_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+)
+
+// Returns an image version which is viable in East US, which each case below breaks in some way
+func viableTestImageVersion() *armcompute.GalleryImageVersion {
+	return &armcompute.GalleryImageVersion{
+		ID: to.Ptr(testImageDefinitionID + "/versions/1.1677169694.31375"),
+		Properties: &armcompute.GalleryImageVersionProperties{
+			ProvisioningState: to.Ptr(armcompute.GalleryImageVersionPropertiesProvisioningStateSucceeded),
+			PublishingProfile: &armcompute.GalleryImageVersionPublishingProfile{
+				PublishedDate: to.Ptr(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)),
+				TargetRegions: []*armcompute.TargetRegion{
+					{Name: to.Ptr("West US 2")},
+					{Name: to.Ptr("East US")},
+				},
+			},
+		},
+	}
+}
+
+func TestIsViableImageVersion(t *testing.T) {
+	cases := []struct {
+		name     string
+		mutate   func(version *armcompute.GalleryImageVersion)
+		location string
+		viable   bool
+	}{
+		{
+			name:     "viable",
+			location: "eastus",
+			viable:   true,
+		},
+		{
+			name:     "location differs in case",
+			location: "EastUS",
+			viable:   true,
+		},
+		{
+			name:     "not replicated to the location",
+			location: "westeurope",
+		},
+		{
+			name:     "still provisioning",
+			location: "eastus",
+			mutate: func(version *armcompute.GalleryImageVersion) {
+				version.Properties.ProvisioningState = to.Ptr(armcompute.GalleryImageVersionPropertiesProvisioningStateCreating)
+			},
+		},
+		{
+			name:     "excluded from latest",
+			location: "eastus",
+			mutate: func(version *armcompute.GalleryImageVersion) {
+				version.Properties.PublishingProfile.ExcludeFromLatest = to.Ptr(true)
+			},
+		},
+		{
+			name:     "explicitly included in latest",
+			location: "eastus",
+			mutate: func(version *armcompute.GalleryImageVersion) {
+				version.Properties.PublishingProfile.ExcludeFromLatest = to.Ptr(false)
+			},
+			viable: true,
+		},
+		{
+			name:     "not yet published",
+			location: "eastus",
+			mutate: func(version *armcompute.GalleryImageVersion) {
+				version.Properties.PublishingProfile.PublishedDate = nil
+			},
+		},
+		{
+			name:     "no publishing profile",
+			location: "eastus",
+			mutate: func(version *armcompute.GalleryImageVersion) {
+				version.Properties.PublishingProfile = nil
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			version := viableTestImageVersion()
+			if c.mutate != nil {
+				c.mutate(version)
+			}
+			if viable := isViableImageVersion(version, c.location); viable != c.viable {
+				t.Errorf("expected isViableImageVersion to be %t, but got %t", c.viable, viable)
+			}
+		})
+	}
+
+	t.Run("no image version", func(t *testing.T) {
+		if isViableImageVersion(nil, "eastus") {
+			t.Errorf("expected isViableImageVersion to be false, but got true")
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// the age beyond which the objects of scenarios are considered stale by default, i.e. the lifetime of scenarios' VMSSes,
+// overridden by STALE_OBJECT_MAX_AGE
+const defaultStaleObjectMaxAge = vmssResourceTTL
+
+// Deletes the objects scenarios left behind on a reused cluster, e.g. the namespaces of runs which crashed, timed out or kept
+// their VMSSes, which are older than the max age, such that long-lived clusters don't gradually run out of resources. Objects are
+// identified by the label the suite stamps onto each scenario's namespace, so the pods, deployments and daemonsets within
+// them are deleted along with them. The suite's shared objects, e.g. its debug daemonset, aren't labeled, so are left in
+// place. Failures are only logged, since they don't prevent the cluster from being used
+func collectStaleObjects(ctx context.Context, kube *kubeclient, clusterName string, maxAge time.Duration) {
+	cutoff := time.Now().Add(-maxAge)
+	namespaces, err := kube.typed.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: scenarioTagKey})
+	if err != nil {
+		log.Printf("unable to list scenario namespaces of cluster %q to collect stale ones: %s", clusterName, err)
+		return
+	}
+
+	// deletion is left to complete in the background, as it only has to wait on the termination of pods
+	propagation := metav1.DeletePropagationBackground
+	deleteOpts := metav1.DeleteOptions{PropagationPolicy: &propagation}
+
+	var deleted int
+	for _, namespace := range namespaces.Items {
+		if namespace.DeletionTimestamp != nil || !namespace.CreationTimestamp.Time.Before(cutoff) {
+			continue
+		}
+		if err := kube.typed.CoreV1().Namespaces().Delete(ctx, namespace.Name, deleteOpts); err != nil && !apierrors.IsNotFound(err) {
+			log.Printf("failed to delete stale namespace %q of scenario %q: %s", namespace.Name, namespace.Labels[scenarioTagKey], err)
+			continue
+		}
+		log.Printf("deleted stale namespace %q of scenario %q, created %s", namespace.Name, namespace.Labels[scenarioTagKey], namespace.CreationTimestamp.UTC().Format(time.RFC3339))
+		deleted++
+	}
+	if deleted > 0 {
+		log.Printf("deleted %d namespaces of cluster %q left behind by scenarios more than %s ago", deleted, clusterName, maxAge)
+	}
+}
+
+// Parses the max age of the objects of scenarios, beyond which they're collected, where a max age of 0 disables collection
+func parseStaleObjectMaxAge(value string) (time.Duration, error) {
+	if value == "" {
+		return defaultStaleObjectMaxAge, nil
+	}
+	maxAge, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if maxAge < 0 {
+		return 0, fmt.Errorf("max age %s is negative", maxAge)
+	}
+	return maxAge, nil
+}
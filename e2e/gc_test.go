@@ -0,0 +1,52 @@
+package e2e_test
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseStaleObjectMaxAge(t *testing.T) {
+	cases := []struct {
+		name   string
+		value  string
+		maxAge time.Duration
+		err    bool
+	}{
+		{
+			name:   "unset",
+			maxAge: defaultStaleObjectMaxAge,
+		},
+		{
+			name:   "duration",
+			value:  "6h30m",
+			maxAge: 6*time.Hour + 30*time.Minute,
+		},
+		{
+			name:  "disabled",
+			value: "0",
+		},
+		{
+			name:  "negative",
+			value: "-1h",
+			err:   true,
+		},
+		{
+			name:  "missing unit",
+			value: "12",
+			err:   true,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			maxAge, err := parseStaleObjectMaxAge(c.value)
+			if c.err != (err != nil) {
+				t.Fatalf("expected error %t, but got: %v", c.err, err)
+			}
+			if maxAge != c.maxAge {
+				t.Errorf("expected max age %s, but got %s", c.maxAge, maxAge)
+			}
+		})
+	}
+}
@@ -0,0 +1,422 @@
+package e2e_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+	"github.com/Azure/agentbakere2e/scenario"
+	"github.com/barkimedes/go-deepcopy"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	goldenDir                = "testdata/golden"
+	goldenCustomDataFileName = "CustomData"
+	goldenCSECommandFileName = "CSECommand"
+
+	// placeholders of the values the suite otherwise derives from the chosen cluster and the suite's resources, such that
+	// the payloads generated for each scenario don't depend on the environment they're generated within
+	goldenLocation          = "westus3"
+	goldenSubscriptionID    = "00000000-0000-0000-0000-000000000000"
+	goldenResourceGroupName = "abe2e-golden"
+	goldenCACertificate     = "golden-ca-certificate"
+	goldenBootstrapToken    = "golden.bootstraptoken"
+	goldenFQDN              = "golden-cluster.hcp.westus3.azmk8s.io"
+	goldenIdentityClientID  = "11111111-1111-1111-1111-111111111111"
+	goldenHTTPProxyURL      = "http://10.244.0.10:3128/"
+	goldenVMSSName          = "abe2e-golden-vmss"
+
+	cloudConfigHeader = "#cloud-config"
+)
+
+// matches shell words assigning a variable, e.g. "KUBERNETES_VERSION=1.26.0"
+var assignmentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+// Asserts that the custom data and CSE command generated for each selected scenario match their golden files, or overwrites
+// the golden files when -update is set. Payloads are generated from a bootstrap config whose cluster-specific values are
+// placeholders, without creating or reading any Azure resources, to catch unintended changes of AgentBaker's templates
+func validateGoldenBootstrapPayloads(t *testing.T) {
+	selector := scenario.Selector{
+		IncludeTags: strToBoolMap(includeTags),
+		ExcludeTags: strToBoolMap(excludeTags),
+	}
+	if runScenarios != "" {
+		filter, err := compileScenarioFilter(runScenarios)
+		if err != nil {
+			t.Fatalf("invalid -run-scenarios value %q: %s", runScenarios, err)
+		}
+		selector.NamePattern = filter
+	}
+	scenarios, err := scenario.InitScenarioTable(selector, scenarioDefinitionsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defined, err := scenario.DefinedScenarios(scenarioDefinitionsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range scenarios.Roots() {
+		validateGoldenBootstrapPayload(t, scenarios, s, nil)
+	}
+
+	if err := checkStaleGoldenFiles(defined); err != nil {
+		t.Error(err)
+	}
+}
+
+// Validates the payloads of the scenario within its own subtest, followed by those of each of the scenarios depending on it,
+// which are bootstrapped according to a placeholder state published by the scenario
+func validateGoldenBootstrapPayload(t *testing.T, scenarios scenario.Table, s *scenario.Scenario, dependency *scenario.DependencyState) {
+	nbc, err := newScenarioBootstrapConfig(getGoldenNodeBootstrappingConfiguration(), s)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if dependency != nil && s.DependencyMutator != nil {
+		s.DependencyMutator(nbc, dependency)
+	}
+	if s.KubeletIdentity {
+		useKubeletIdentity(nbc, &userAssignedIdentity{
+			resourceID: fmt.Sprintf(userAssignedIdentityIDTemplate, goldenSubscriptionID, goldenResourceGroupName, kubeletIdentityName),
+			clientID:   goldenIdentityClientID,
+		})
+	}
+	if s.HTTPProxy {
+		useHTTPProxy(nbc, goldenHTTPProxyURL)
+	}
+
+	passed := t.Run(s.Name, func(t *testing.T) {
+		if len(s.Parameters) == 0 {
+			assertGoldenBootstrapPayloads(t, s, nbc)
+			return
+		}
+		// each parameter's payloads are golden within a subdirectory of the scenario's, just as it runs as a subtest
+		for _, parameter := range s.Parameters {
+			parameter := parameter
+			t.Run(parameter.Name, func(t *testing.T) {
+				copied, err := deepcopy.Anything(nbc)
+				if err != nil {
+					t.Fatal(err)
+				}
+				parameterNBC := copied.(*datamodel.NodeBootstrappingConfiguration)
+				if parameter.BootstrapConfigMutator != nil {
+					parameter.BootstrapConfigMutator(parameterNBC)
+				}
+				assertGoldenBootstrapPayloads(t, s.WithParameter(parameter), parameterNBC)
+			})
+		}
+	})
+	if dependents := scenarios.Dependents(s.Name); len(dependents) > 0 {
+		if !passed {
+			t.Logf("not validating the payloads of the scenarios depending on scenario %q, as it failed", s.Name)
+			return
+		}
+		state := &scenario.DependencyState{
+			ClusterName:     fmt.Sprintf(testClusterNameTemplate, "golden"),
+			VMSSName:        goldenVMSSName,
+			NodeNames:       []string{goldenVMSSName + "000000"},
+			BootstrapConfig: nbc,
+		}
+		for _, dependent := range dependents {
+			validateGoldenBootstrapPayload(t, scenarios, dependent, state)
+		}
+	}
+}
+
+// Asserts that the bootstrap payloads generated for the scenario from the bootstrap config match its golden files, within the
+// directory named by the scenario
+func assertGoldenBootstrapPayloads(t *testing.T, s *scenario.Scenario, nbc *datamodel.NodeBootstrappingConfiguration) {
+	nodeBootstrapping, err := getNodeBootstrappingForValidation(context.Background(), nbc)
+	if err != nil {
+		t.Fatalf("failed to generate the bootstrap payloads of scenario %q: %s", s.Name, err)
+	}
+	customData, err := normalizeCustomData(nodeBootstrapping.CustomData)
+	if err != nil {
+		t.Fatalf("failed to normalize the custom data of scenario %q: %s", s.Name, err)
+	}
+	cseCmd := normalizeCSECommand(nodeBootstrapping.CSE)
+
+	assertGoldenFile(t, filepath.Join(goldenDir, filepath.FromSlash(s.Name), goldenCustomDataFileName), customData)
+	assertGoldenFile(t, filepath.Join(goldenDir, filepath.FromSlash(s.Name), goldenCSECommandFileName), cseCmd)
+}
+
+// Returns the base bootstrap config of the golden payloads, i.e. that of getBaseNodeBootstrappingConfiguration with
+// placeholders in place of the chosen cluster's parameters
+func getGoldenNodeBootstrappingConfiguration() *datamodel.NodeBootstrappingConfiguration {
+	nbc := baseTemplate(goldenLocation, azurePublicCloudEnvironment)
+	nbc.ContainerService.Properties.CertificateProfile.CaCertificate = goldenCACertificate
+	bootstrapToken := goldenBootstrapToken
+	nbc.KubeletClientTLSBootstrapToken = &bootstrapToken
+	nbc.ContainerService.Properties.HostedMasterProfile.FQDN = goldenFQDN
+	return nbc
+}
+
+// Asserts that the golden file holds the actual contents, describing how they differ otherwise. When -update is set, the
+// golden file is instead overwritten with the actual contents
+func assertGoldenFile(t *testing.T, path, actual string) {
+	if updateGoldenFiles {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create the directory of golden file %s: %s", path, err)
+		}
+		if err := os.WriteFile(path, []byte(actual), 0644); err != nil {
+			t.Fatalf("failed to write golden file %s: %s", path, err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Errorf("golden file %s doesn't exist, run the suite with -e2eMode golden -update to create it", path)
+		return
+	}
+	if err != nil {
+		t.Fatalf("failed to read golden file %s: %s", path, err)
+	}
+	if string(expected) != actual {
+		t.Errorf("generated payload differs from golden file %s, in which lines only expected are prefixed with - and those only generated with +:\n%s"+
+			"if the change is intended, run the suite with -e2eMode golden -update to update the golden file", path, scenario.DiffLines(string(expected), actual))
+	}
+}
+
+// Returns an error naming the golden files of scenarios which are no longer defined, which are deleted when -update is set
+func checkStaleGoldenFiles(defined scenario.Table) error {
+	entries, err := os.ReadDir(goldenDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read golden files directory %s: %w", goldenDir, err)
+	}
+
+	var stale []string
+	for _, entry := range entries {
+		path := filepath.Join(goldenDir, entry.Name())
+		if s, ok := defined[entry.Name()]; ok {
+			// the subdirectories of a scenario's golden files are those of its parameters
+			paths, err := staleGoldenParameterDirs(path, s)
+			if err != nil {
+				return err
+			}
+			stale = append(stale, paths...)
+			continue
+		}
+		stale = append(stale, path)
+	}
+	if updateGoldenFiles {
+		for _, path := range stale {
+			if err := os.RemoveAll(path); err != nil {
+				return fmt.Errorf("failed to delete stale golden files %s: %w", path, err)
+			}
+		}
+		return nil
+	}
+	if len(stale) > 0 {
+		sort.Strings(stale)
+		return fmt.Errorf("found golden files of scenarios or parameters which aren't defined, run the suite with -e2eMode golden -update to delete them: %s", strings.Join(stale, ", "))
+	}
+	return nil
+}
+
+// Returns the subdirectories of the scenario's golden files directory which aren't named by any of its parameters
+func staleGoldenParameterDirs(dir string, s *scenario.Scenario) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read golden files directory %s: %w", dir, err)
+	}
+	parameters := map[string]bool{}
+	for _, parameter := range s.Parameters {
+		parameters[parameter.Name] = true
+	}
+	var stale []string
+	for _, entry := range entries {
+		if entry.IsDir() && !parameters[entry.Name()] {
+			stale = append(stale, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return stale, nil
+}
+
+// Decodes the custom data, and when it's a cloud-config, decompresses the contents of each of the files it writes such that
+// changes to them are legible within a diff. Each decompressed content is written in place of its base64-encoded content as a
+// literal block, leaving the rest of the cloud-config as generated
+func normalizeCustomData(customData string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(customData)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode custom data: %w", err)
+	}
+	if !bytes.HasPrefix(decoded, []byte(cloudConfigHeader)) {
+		return string(decoded), nil
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(decoded, &document); err != nil {
+		return "", fmt.Errorf("failed to parse cloud-config: %w", err)
+	}
+	if len(document.Content) == 0 {
+		return string(decoded), nil
+	}
+	decompressed, err := decompressWriteFiles(document.Content[0])
+	if err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(string(decoded), "\n")
+	var normalized strings.Builder
+	for i := 0; i < len(lines); i++ {
+		content, ok := decompressed[i+1]
+		if !ok {
+			normalized.WriteString(lines[i] + "\n")
+			continue
+		}
+		// the key's line is followed by the lines of its block, each indented further than the key
+		indent := lines[i][:len(lines[i])-len(strings.TrimLeft(lines[i], " "))]
+		for i+1 < len(lines) && strings.HasPrefix(lines[i+1], indent+" ") {
+			i++
+		}
+		fmt.Fprintf(&normalized, "%scontent: | # decompressed within the golden file\n", indent)
+		for _, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+			if line == "" {
+				normalized.WriteString("\n")
+				continue
+			}
+			normalized.WriteString(indent + "  " + line + "\n")
+		}
+	}
+	return strings.TrimSuffix(normalized.String(), "\n"), nil
+}
+
+// Returns the decompressed content of each of the gzip-compressed entries of the cloud-config's write_files, keyed by the line
+// of the cloud-config holding its content key. Entries rendered empty, e.g. the custom cloud init script outside of custom
+// clouds, aren't compressed at all, so are left as they are
+func decompressWriteFiles(cloudConfig *yaml.Node) (map[int]string, error) {
+	decompressed := map[int]string{}
+	writeFiles := mappingValue(cloudConfig, "write_files")
+	if writeFiles == nil || writeFiles.Kind != yaml.SequenceNode {
+		return decompressed, nil
+	}
+	for _, file := range writeFiles.Content {
+		encoding, key := mappingValue(file, "encoding"), mappingKey(file, "content")
+		if encoding == nil || encoding.Value != "gzip" || key == nil {
+			continue
+		}
+		path := ""
+		if node := mappingValue(file, "path"); node != nil {
+			path = node.Value
+		}
+		compressed, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(mappingValue(file, "content").Value), ""))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the content of %s: %w", path, err)
+		}
+		if len(compressed) == 0 {
+			continue
+		}
+		reader, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress the content of %s: %w", path, err)
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress the content of %s: %w", path, err)
+		}
+		decompressed[key.Line] = string(content)
+	}
+	return decompressed, nil
+}
+
+// Returns the key node of the key within the YAML mapping, or nil when the node isn't a mapping or the key isn't present
+func mappingKey(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i]
+		}
+	}
+	return nil
+}
+
+// Returns the value of the key within the YAML mapping, or nil when the node isn't a mapping or the key isn't present
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// Splits the CSE command into lines holding either a single statement or a single variable assignment, such that a change to
+// any one of the variables passed to the provisioning scripts is legible within a diff. The command's shell words are kept
+// whole, honoring quotes and escapes, while runs of unquoted whitespace between them are collapsed
+func normalizeCSECommand(cseCmd string) string {
+	var (
+		normalized strings.Builder
+		line       []string
+		word       strings.Builder
+		quote      rune
+		escaped    bool
+		terminated bool
+	)
+	endLine := func() {
+		if len(line) > 0 {
+			normalized.WriteString(strings.Join(line, " ") + "\n")
+			line = nil
+		}
+	}
+	endWord := func() {
+		if word.Len() == 0 {
+			return
+		}
+		// assignments only prefix a command, so are words which begin a statement or follow another assignment
+		if len(line) == 0 && assignmentPattern.MatchString(word.String()) {
+			normalized.WriteString(word.String() + "\n")
+		} else {
+			line = append(line, word.String())
+			if terminated {
+				endLine()
+			}
+		}
+		word.Reset()
+	}
+	for _, c := range cseCmd {
+		literal := escaped
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ' ' || c == '\t' || c == '\n':
+			endWord()
+			continue
+		}
+		word.WriteRune(c)
+		// a statement is terminated by an unquoted, unescaped semicolon ending its last word
+		terminated = c == ';' && quote == 0 && !literal
+	}
+	endWord()
+	endLine()
+	return normalized.String()
+}
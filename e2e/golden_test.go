@@ -0,0 +1,54 @@
+package e2e_test
+
+import "testing"
+
+func TestNormalizeCSECommand(t *testing.T) {
+	cases := []struct {
+		name       string
+		cseCmd     string
+		normalized string
+	}{
+		{
+			name:       "assignments prefixing a command are split onto lines of their own",
+			cseCmd:     `PATH="/usr/bin:/bin" API_SERVER_NAME=example.hcp.azmk8s.io /bin/bash /opt/azure/containers/provision_start.sh`,
+			normalized: "PATH=\"/usr/bin:/bin\"\nAPI_SERVER_NAME=example.hcp.azmk8s.io\n/bin/bash /opt/azure/containers/provision_start.sh\n",
+		},
+		{
+			name:       "statements are split onto lines of their own",
+			cseCmd:     "mkdir -p /var/log/azure; cd /tmp;   RUN=1 run",
+			normalized: "mkdir -p /var/log/azure;\ncd /tmp;\nRUN=1\nrun\n",
+		},
+		{
+			name:       "arguments resembling assignments are kept on the command's line",
+			cseCmd:     "echo KEY=value",
+			normalized: "echo KEY=value\n",
+		},
+		{
+			name:       "quoted words are kept whole",
+			cseCmd:     `A="one; two" echo 'three;  four' "five \" six;"`,
+			normalized: "A=\"one; two\"\necho 'three;  four' \"five \\\" six;\"\n",
+		},
+		{
+			name:       "escaped semicolons don't terminate statements",
+			cseCmd:     `find / -exec true {} \; -print`,
+			normalized: "find / -exec true {} \\; -print\n",
+		},
+		{
+			name:       "unquoted whitespace is collapsed",
+			cseCmd:     "echo \t one\n\ntwo  ",
+			normalized: "echo one two\n",
+		},
+		{
+			name: "empty command",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			if normalized := normalizeCSECommand(c.cseCmd); normalized != c.normalized {
+				t.Errorf("expected normalized command:\n%s\nbut got:\n%s", c.normalized, normalized)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// the extended resource the NVIDIA device plugin advertises each of the node's GPUs as
+	nvidiaGPUResourceName corev1.ResourceName = "nvidia.com/gpu"
+
+	nvidiaDevicePluginDaemonSetNameTemplate = "%s-nvidia-device-plugin"
+)
+
+// Ensures the NVIDIA device plugin advertises the expected number of GPUs as allocatable on the node. When the node runs the
+// device plugin managed by CSE, it's only validated, otherwise the upstream device plugin daemonset is first deployed onto the
+// node within the namespace
+func (k *kubeclient) ensureNvidiaDevicePlugin(ctx context.Context, namespace, nodeName string, managed bool, gpus int64) error {
+	if !managed {
+		name := fmt.Sprintf(nvidiaDevicePluginDaemonSetNameTemplate, nodeName)
+		log.Printf("deploying NVIDIA device plugin daemonset %s/%s onto node %q...", namespace, name, nodeName)
+		if _, err := applyDaemonSetManifest(ctx, k, getNvidiaDevicePluginDaemonSetTemplate(namespace, nodeName)); err != nil {
+			return fmt.Errorf("failed to apply NVIDIA device plugin daemonset: %w", err)
+		}
+		if _, err := waitUntilDaemonSetPodRunning(ctx, k, namespace, name, nodeName); err != nil {
+			return fmt.Errorf("failed waiting for NVIDIA device plugin to run on node %q: %w", nodeName, err)
+		}
+	}
+
+	if err := waitUntilNodeResourcesAllocatable(ctx, k, nodeName, map[corev1.ResourceName]int64{nvidiaGPUResourceName: gpus}); err != nil {
+		return fmt.Errorf("NVIDIA device plugin didn't advertise the node's GPUs: %w", err)
+	}
+	return nil
+}
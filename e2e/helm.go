@@ -0,0 +1,160 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/agentbakere2e/scenario"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// the helm CLI, which must be on the PATH of scenarios installing Helm charts
+	helmBinary = "helm"
+
+	// the time allowed for a chart's release to be installed, including for its workloads to become ready
+	helmInstallTimeout = 10 * time.Minute
+
+	helmKubeconfigFileName = "kubeconfig"
+	helmValuesFileName     = "values.yaml"
+)
+
+var (
+	// serializes the lookup of the locks of helm releases by concurrently-running scenarios
+	helmReleaseLocksMu sync.Mutex
+	// maps each helm release, keyed by its cluster's apiserver, namespace and name, to the lock serializing its installs, since
+	// helm rejects the install of a release while another operation on it is in progress
+	helmReleaseLocks = map[string]*sync.Mutex{}
+)
+
+// Returns the lock serializing the installs of the release by the cluster's concurrently-running scenarios, which share the
+// releases installed outside of their namespaces
+func (k *kubeclient) helmReleaseLock(namespace, release string) *sync.Mutex {
+	helmReleaseLocksMu.Lock()
+	defer helmReleaseLocksMu.Unlock()
+
+	key := k.rest.Host + "/" + namespace + "/" + release
+	lock, ok := helmReleaseLocks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		helmReleaseLocks[key] = lock
+	}
+	return lock
+}
+
+// Installs the chart, or upgrades its release when it's already installed, using the helm CLI, and waits until each of its
+// workloads, including its jobs, is ready. The chart's values are rendered as a Go template with the values, and the chart is
+// installed within their namespace unless it names its own. Installs of the same release are serialized, such that scenarios
+// sharing a release wait on each other rather than failing while another's install is in progress
+func (k *kubeclient) installHelmChart(ctx context.Context, chart scenario.HelmChart, values manifestValues) error {
+	if _, err := exec.LookPath(helmBinary); err != nil {
+		return fmt.Errorf("unable to install helm chart %q, the helm CLI isn't on the PATH: %w", chart.Chart, err)
+	}
+	namespace := chart.Namespace
+	if namespace == "" {
+		namespace = values.Namespace
+	}
+
+	dir, err := os.MkdirTemp("", "abe2e-helm-")
+	if err != nil {
+		return fmt.Errorf("unable to create working directory of helm release %q: %w", chart.Release, err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the kubeconfig holds the cluster's admin credentials, so is only readable by its owner
+	kubeconfig, err := kubeconfigFromRESTConfig(k.rest)
+	if err != nil {
+		return err
+	}
+	kubeconfigPath := filepath.Join(dir, helmKubeconfigFileName)
+	if err := os.WriteFile(kubeconfigPath, kubeconfig, 0600); err != nil {
+		return fmt.Errorf("unable to write kubeconfig of helm release %q: %w", chart.Release, err)
+	}
+
+	marshalled, err := yaml.Marshal(chart.Values)
+	if err != nil {
+		return fmt.Errorf("unable to marshal values of helm release %q: %w", chart.Release, err)
+	}
+	rendered, err := renderManifestTemplate(helmValuesFileName, string(marshalled), values)
+	if err != nil {
+		return fmt.Errorf("failed to render values of helm release %q: %w", chart.Release, err)
+	}
+	valuesPath := filepath.Join(dir, helmValuesFileName)
+	if err := os.WriteFile(valuesPath, rendered.Bytes(), 0600); err != nil {
+		return fmt.Errorf("unable to write values of helm release %q: %w", chart.Release, err)
+	}
+
+	args := []string{
+		"upgrade", chart.Release, chart.Chart,
+		"--install",
+		"--namespace", namespace,
+		"--create-namespace",
+		"--kubeconfig", kubeconfigPath,
+		"--values", valuesPath,
+		"--wait",
+		"--wait-for-jobs",
+		"--timeout", helmInstallTimeout.String(),
+	}
+	if chart.Repo != "" {
+		args = append(args, "--repo", chart.Repo)
+	}
+	if chart.Version != "" {
+		args = append(args, "--version", chart.Version)
+	}
+
+	lock := k.helmReleaseLock(namespace, chart.Release)
+	lock.Lock()
+	defer lock.Unlock()
+
+	log.Printf("installing helm chart %q as release %s/%s...", chart.Chart, namespace, chart.Release)
+	// the context outlives the wait by a margin, such that helm reports why its workloads didn't become ready
+	ctx, cancel := context.WithTimeout(ctx, helmInstallTimeout+time.Minute)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, helmBinary, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to install helm chart %q as release %s/%s: %w, output:\n%s", chart.Chart, namespace, chart.Release, err, strings.TrimSpace(string(output)))
+	}
+	log.Printf("installed helm release %s/%s", namespace, chart.Release)
+	return nil
+}
+
+// Returns a kubeconfig authenticating with the credentials of the REST config, for clients which can't be handed the
+// config itself, e.g. the helm CLI
+func kubeconfigFromRESTConfig(config *rest.Config) ([]byte, error) {
+	const name = "abe2e"
+	kubeconfig := clientcmdapi.NewConfig()
+	kubeconfig.Clusters[name] = &clientcmdapi.Cluster{
+		Server:                   config.Host,
+		TLSServerName:            config.ServerName,
+		InsecureSkipTLSVerify:    config.Insecure,
+		CertificateAuthority:     config.CAFile,
+		CertificateAuthorityData: config.CAData,
+	}
+	kubeconfig.AuthInfos[name] = &clientcmdapi.AuthInfo{
+		ClientCertificate:     config.CertFile,
+		ClientCertificateData: config.CertData,
+		ClientKey:             config.KeyFile,
+		ClientKeyData:         config.KeyData,
+		Token:                 config.BearerToken,
+		TokenFile:             config.BearerTokenFile,
+		Exec:                  config.ExecProvider,
+	}
+	kubeconfig.Contexts[name] = &clientcmdapi.Context{Cluster: name, AuthInfo: name}
+	kubeconfig.CurrentContext = name
+
+	data, err := clientcmd.Write(*kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to serialize kubeconfig: %w", err)
+	}
+	return data, nil
+}
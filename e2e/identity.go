@@ -0,0 +1,87 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+)
+
+const (
+	// the user-assigned identity shared by every scenario which bootstraps its nodes with a BYO kubelet identity
+	kubeletIdentityName = "agentbaker-e2e-kubelet-identity"
+
+	userAssignedIdentityIDTemplate = "/subscriptions/%s/resourceGroups/%s/providers/Microsoft.ManagedIdentity/userAssignedIdentities/%s"
+	userAssignedIdentityAPIVersion = "2023-01-31"
+)
+
+type userAssignedIdentity struct {
+	resourceID  string
+	clientID    string
+	principalID string
+}
+
+// Ensures the suite's user-assigned kubelet identity exists within the suite's resource group, returning its IDs. The identity
+// is put on every run, restamping the tags, and so the expiry, of an identity reused from previous runs
+func ensureKubeletIdentity(ctx context.Context, cloud *azureClient, suiteConfig *suiteConfig) (*userAssignedIdentity, error) {
+	resourceID := fmt.Sprintf(userAssignedIdentityIDTemplate, suiteConfig.subscription, suiteConfig.resourceGroupName, kubeletIdentityName)
+	log.Printf("ensuring user-assigned kubelet identity %q...", resourceID)
+
+	poller, err := cloud.resourceClient.BeginCreateOrUpdateByID(ctx, resourceID, userAssignedIdentityAPIVersion, armresources.GenericResource{
+		Location: to.Ptr(suiteConfig.location),
+		Tags:     getResourceTags(suiteConfig, "", clusterResourceTTL),
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin creating user-assigned identity %q: %w", resourceID, err)
+	}
+	resp, err := poller.PollUntilDone(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user-assigned identity %q: %w", resourceID, err)
+	}
+
+	properties, ok := resp.Properties.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected properties of user-assigned identity %q: %v", resourceID, resp.Properties)
+	}
+	clientID, _ := properties["clientId"].(string)
+	principalID, _ := properties["principalId"].(string)
+	if clientID == "" || principalID == "" {
+		return nil, fmt.Errorf("user-assigned identity %q is missing its client or principal ID: %v", resourceID, properties)
+	}
+
+	return &userAssignedIdentity{
+		resourceID:  resourceID,
+		clientID:    clientID,
+		principalID: principalID,
+	}, nil
+}
+
+// Assigns the user-assigned identity to the VMSS, such that it can be used to request tokens from IMDS
+func assignUserAssignedIdentity(vmss *armcompute.VirtualMachineScaleSet, identity *userAssignedIdentity) {
+	if vmss.Identity == nil {
+		vmss.Identity = &armcompute.VirtualMachineScaleSetIdentity{}
+	}
+	if vmss.Identity.Type != nil && *vmss.Identity.Type == armcompute.ResourceIdentityTypeSystemAssigned {
+		vmss.Identity.Type = to.Ptr(armcompute.ResourceIdentityTypeSystemAssignedUserAssigned)
+	} else {
+		vmss.Identity.Type = to.Ptr(armcompute.ResourceIdentityTypeUserAssigned)
+	}
+	if vmss.Identity.UserAssignedIdentities == nil {
+		vmss.Identity.UserAssignedIdentities = map[string]*armcompute.VirtualMachineScaleSetIdentityUserAssignedIdentitiesValue{}
+	}
+	vmss.Identity.UserAssignedIdentities[identity.resourceID] = &armcompute.VirtualMachineScaleSetIdentityUserAssignedIdentitiesValue{}
+}
+
+// Configures the bootstrap config such that kubelet, along with the credential provider, authenticates using the
+// user-assigned identity rather than a service principal
+func useKubeletIdentity(nbc *datamodel.NodeBootstrappingConfiguration, identity *userAssignedIdentity) {
+	kubernetesConfig := nbc.ContainerService.Properties.OrchestratorProfile.KubernetesConfig
+	kubernetesConfig.UseManagedIdentity = true
+	kubernetesConfig.UserAssignedID = identity.resourceID
+	kubernetesConfig.UserAssignedClientID = identity.clientID
+	nbc.UserAssignedIdentityClientID = identity.clientID
+}
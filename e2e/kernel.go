@@ -0,0 +1,61 @@
+package e2e_test
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	dmesgArtifactName = "dmesg.log"
+)
+
+// kernelFailure represents a kernel-level failure detected within the dmesg output of a VM, recorded within the scenario's result
+type kernelFailure struct {
+	InstanceID string `json:"instanceId,omitempty"`
+	// Category is the type of failure, e.g. "oom-kill"
+	Category string `json:"category"`
+	// Line is the dmesg line which matched the failure category
+	Line string `json:"line"`
+}
+
+func (f kernelFailure) String() string {
+	return fmt.Sprintf("%s: %s", f.Category, f.Line)
+}
+
+func (r *scenarioResult) recordKernelFailure(instance vmssInstance, failure kernelFailure) {
+	failure.InstanceID = instance.instanceID
+	r.KernelFailures = append(r.KernelFailures, failure)
+}
+
+// The kernel failure categories, along with the regexes used to detect them within individual lines of dmesg output, in the
+// order in which they're matched, such that a line matching several categories is always reported as the first of them.
+// Module signature verification failures aren't matched, since they only taint the kernel when out-of-tree modules, e.g.
+// NVIDIA's, are loaded
+var kernelFailureRegexes = []struct {
+	category string
+	regex    *regexp.Regexp
+}{
+	{category: "oom-kill", regex: regexp.MustCompile(`(?i)(out of memory: kill(ed)? process|oom-kill:|invoked oom-killer)`)},
+	{category: "kernel-panic", regex: regexp.MustCompile(`(?i)(kernel panic|kernel BUG at|general protection fault|Oops:)`)},
+	{category: "hung-task", regex: regexp.MustCompile(`(?i)blocked for more than \d+ seconds`)},
+	{category: "module-load-failure", regex: regexp.MustCompile(`(?i)(unknown symbol in module|modprobe: (FATAL|ERROR)|failed to (insert|load) module)`)},
+}
+
+// Scans the supplied dmesg output for kernel failures, returning a kernelFailure for each matching line
+func detectKernelFailures(dmesg string) []kernelFailure {
+	var failures []kernelFailure
+	for _, line := range strings.Split(dmesg, "\n") {
+		for _, candidate := range kernelFailureRegexes {
+			if candidate.regex.MatchString(line) {
+				failures = append(failures, kernelFailure{
+					Category: candidate.category,
+					Line:     strings.TrimSpace(line),
+				})
+				// only report each line once, even if it matches multiple categories
+				break
+			}
+		}
+	}
+	return failures
+}
@@ -0,0 +1,61 @@
+package e2e_test
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectKernelFailures(t *testing.T) {
+	cases := []struct {
+		name     string
+		dmesg    string
+		expected []kernelFailure
+	}{
+		{
+			name:  "clean boot",
+			dmesg: "[    0.000000] Linux version 5.15.0-1041-azure\n[    2.345678] EXT4-fs (sda1): mounted filesystem with ordered data mode\n",
+		},
+		{
+			name:  "oom kill",
+			dmesg: "[  120.123456] Out of memory: Killed process 1234 (stress) total-vm:123456kB\n",
+			expected: []kernelFailure{
+				{Category: "oom-kill", Line: "[  120.123456] Out of memory: Killed process 1234 (stress) total-vm:123456kB"},
+			},
+		},
+		{
+			name:  "each failing line is reported in order",
+			dmesg: "[   60.000000] INFO: task kworker/0:1:42 blocked for more than 120 seconds.\n[   61.000000] ok\n[   62.000000] BUG: unable to handle page fault, Oops: 0000 [#1] SMP\n",
+			expected: []kernelFailure{
+				{Category: "hung-task", Line: "[   60.000000] INFO: task kworker/0:1:42 blocked for more than 120 seconds."},
+				{Category: "kernel-panic", Line: "[   62.000000] BUG: unable to handle page fault, Oops: 0000 [#1] SMP"},
+			},
+		},
+		{
+			name:  "a line matching several categories is reported as the first",
+			dmesg: "[  300.000000] Kernel panic - not syncing: Out of memory: Killed process 1 (systemd)\n",
+			expected: []kernelFailure{
+				{Category: "oom-kill", Line: "[  300.000000] Kernel panic - not syncing: Out of memory: Killed process 1 (systemd)"},
+			},
+		},
+		{
+			name:  "module load failure",
+			dmesg: "[   10.000000] nvidia: Unknown symbol in module, or unknown parameter (see dmesg)\n",
+			expected: []kernelFailure{
+				{Category: "module-load-failure", Line: "[   10.000000] nvidia: Unknown symbol in module, or unknown parameter (see dmesg)"},
+			},
+		},
+		{
+			name:  "out-of-tree module tainting the kernel",
+			dmesg: "[   10.000000] nvidia: module verification failed: signature and/or required key missing - tainting kernel\n",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			if failures := detectKernelFailures(c.dmesg); !reflect.DeepEqual(failures, c.expected) {
+				t.Errorf("expected kernel failures %v, but got %v", c.expected, failures)
+			}
+		})
+	}
+}
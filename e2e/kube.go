@@ -3,48 +3,110 @@ package e2e_test
 import (
 	"context"
 	"fmt"
+	"log"
+	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// the time allowed for the caches of informers to sync once they've been started
+const informerSyncTimeout = 2 * time.Minute
+
 type kubeclient struct {
-	dynamic client.Client
+	dynamic client.WithWatch
 	typed   kubernetes.Interface
 	rest    *rest.Config
+
+	// informers share the watches of the typed clientset's objects between their consumers, and are started by startInformers
+	// once each consumer has requested its informer from the factory. They run for the lifetime of the kubeclient
+	informers     informers.SharedInformerFactory
+	informersStop chan struct{}
 }
 
 func newKubeclient(config *rest.Config) (*kubeclient, error) {
-	dynamic, err := client.New(config, client.Options{})
+	// every request of the kubeclient's clients, including those of its informers, retries transient apiserver errors
+	config = rest.CopyConfig(config)
+	config.Wrap(retryTransientKubeErrors)
+
+	dynamic, err := client.NewWithWatch(config, client.Options{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create dynamic kubeclient: %w", err)
 	}
 
-	restClient, err := rest.RESTClientFor(config)
+	// each of the clientset's API groups is configured from the config, overriding its API path and group version
+	typed, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create rest kube client: %w", err)
+		return nil, fmt.Errorf("failed to create typed kubeclient: %w", err)
 	}
 
-	typed := kubernetes.New(restClient)
-
 	return &kubeclient{
-		dynamic: dynamic,
-		typed:   typed,
-		rest:    config,
+		dynamic:       dynamic,
+		typed:         typed,
+		rest:          config,
+		informers:     informers.NewSharedInformerFactory(typed, 0),
+		informersStop: make(chan struct{}),
 	}, nil
 }
 
+// Starts each of the informers requested from the kubeclient's informer factory which hasn't yet been started, and waits
+// until their caches have synced
+func (k *kubeclient) startInformers(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, informerSyncTimeout)
+	defer cancel()
+
+	k.informers.Start(k.informersStop)
+	for informerType, synced := range k.informers.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return fmt.Errorf("failed to sync informer of %s: %w", informerType, ctx.Err())
+		}
+	}
+	return nil
+}
+
+// Returns a lister of the cluster's nodes, served from the cache of the shared node informer
+func (k *kubeclient) nodeLister(ctx context.Context) (corev1listers.NodeLister, error) {
+	lister := k.informers.Core().V1().Nodes().Lister()
+	if err := k.startInformers(ctx); err != nil {
+		return nil, err
+	}
+	return lister, nil
+}
+
+// Returns a client of the cluster, authenticated by its admin kubeconfig, which is reused from the cloud's kubeconfig cache
+// unless it's expired or the apiserver rejects it
 func getClusterKubeClient(ctx context.Context, cloud *azureClient, resourceGroupName, clusterName string) (*kubeclient, error) {
+	if data, ok := cloud.kubeconfigs.get(resourceGroupName, clusterName); ok {
+		kube, err := newClusterKubeClient(cloud, resourceGroupName, clusterName, data)
+		if err == nil {
+			// e.g. the cluster may have since been recreated with new certificates
+			_, err = kube.typed.CoreV1().Namespaces().Get(ctx, defaultNamespace, metav1.GetOptions{})
+		}
+		if err == nil {
+			return kube, nil
+		}
+		log.Printf("cached kubeconfig of cluster %q can't be used, listing its admin credentials again: %s", clusterName, err)
+		cloud.kubeconfigs.invalidate(resourceGroupName, clusterName)
+	}
+
 	data, err := getClusterKubeconfigBytes(ctx, cloud, resourceGroupName, clusterName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cluster kubeconfig bytes: %w", err)
 	}
+	cloud.kubeconfigs.put(resourceGroupName, clusterName, data)
+
+	return newClusterKubeClient(cloud, resourceGroupName, clusterName, data)
+}
 
+func newClusterKubeClient(cloud *azureClient, resourceGroupName, clusterName string, data []byte) (*kubeclient, error) {
 	restConfig, err := clientcmd.RESTConfigFromKubeConfig(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert kubeconfig bytes to rest config: %w", err)
@@ -54,6 +116,7 @@ func getClusterKubeClient(ctx context.Context, cloud *azureClient, resourceGroup
 	restConfig.GroupVersion = &schema.GroupVersion{
 		Version: "v1",
 	}
+	restConfig.Wrap(cloud.kubeconfigs.invalidateOnUnauthorized(resourceGroupName, clusterName))
 
 	return newKubeclient(restConfig)
 }
@@ -0,0 +1,176 @@
+package e2e_test
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// the time a cluster's kubeconfig is reused for before its admin credentials are listed again
+	kubeconfigCacheTTL = 1 * time.Hour
+
+	// the margin before the expiry of a kubeconfig's client certificate from which the kubeconfig is no longer reused
+	kubeconfigCertExpiryMargin = 10 * time.Minute
+
+	kubeconfigCacheFileTemplate = "%s_%s_%s.kubeconfig"
+)
+
+// kubeconfigCache caches the admin kubeconfigs of clusters, which are listed through the rate-limited
+// ListClusterAdminCredentials API, in memory and, when it has a directory, on disk, such that they're reused across runs.
+// Kubeconfigs expire after kubeconfigCacheTTL, or once their client certificate is about to expire, and are invalidated
+// whenever the apiserver rejects their credentials
+type kubeconfigCache struct {
+	subscription string
+	dir          string
+
+	mu      sync.Mutex
+	entries map[string]cachedKubeconfig
+}
+
+type cachedKubeconfig struct {
+	data    []byte
+	expires time.Time
+}
+
+func newKubeconfigCache(subscription, dir string) *kubeconfigCache {
+	return &kubeconfigCache{
+		subscription: subscription,
+		dir:          dir,
+		entries:      map[string]cachedKubeconfig{},
+	}
+}
+
+// Returns the cluster's cached kubeconfig, unless none is cached or it has expired
+func (c *kubeconfigCache) get(resourceGroupName, clusterName string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := c.key(resourceGroupName, clusterName)
+	entry, ok := c.entries[key]
+	if !ok && c.dir != "" {
+		entry, ok = c.read(resourceGroupName, clusterName)
+		if ok {
+			c.entries[key] = entry
+		}
+	}
+	if !ok || !time.Now().Before(entry.expires) {
+		return nil, false
+	}
+	return entry.data, true
+}
+
+// Caches the kubeconfig of the cluster, just listed from its admin credentials
+func (c *kubeconfigCache) put(resourceGroupName, clusterName string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[c.key(resourceGroupName, clusterName)] = cachedKubeconfig{
+		data:    data,
+		expires: kubeconfigExpiry(data, time.Now()),
+	}
+	if c.dir == "" {
+		return
+	}
+	// the kubeconfig holds the cluster's admin credentials, so is only readable by its owner
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		log.Printf("unable to create kubeconfig cache directory %q: %s", c.dir, err)
+		return
+	}
+	if err := os.WriteFile(c.path(resourceGroupName, clusterName), data, 0600); err != nil {
+		log.Printf("unable to cache kubeconfig of cluster %q on disk: %s", clusterName, err)
+	}
+}
+
+// Removes the cluster's kubeconfig from the cache, e.g. once the apiserver has rejected its credentials
+func (c *kubeconfigCache) invalidate(resourceGroupName, clusterName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, c.key(resourceGroupName, clusterName))
+	if c.dir == "" {
+		return
+	}
+	if err := os.Remove(c.path(resourceGroupName, clusterName)); err != nil && !os.IsNotExist(err) {
+		log.Printf("unable to remove cached kubeconfig of cluster %q from disk: %s", clusterName, err)
+	}
+}
+
+// Returns a wrapper of the transport of clients authenticated by the cluster's kubeconfig, which invalidates the cached
+// kubeconfig whenever the apiserver responds that the request was unauthorized
+func (c *kubeconfigCache) invalidateOnUnauthorized(resourceGroupName, clusterName string) func(http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := rt.RoundTrip(req)
+			if err == nil && resp.StatusCode == http.StatusUnauthorized {
+				log.Printf("apiserver of cluster %q rejected its cached kubeconfig's credentials, invalidating it", clusterName)
+				c.invalidate(resourceGroupName, clusterName)
+			}
+			return resp, err
+		})
+	}
+}
+
+// Reads the cluster's kubeconfig cached on disk by a previous run, which expires relative to when it was written
+func (c *kubeconfigCache) read(resourceGroupName, clusterName string) (cachedKubeconfig, bool) {
+	path := c.path(resourceGroupName, clusterName)
+	info, err := os.Stat(path)
+	if err != nil {
+		return cachedKubeconfig{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("unable to read cached kubeconfig %q: %s", path, err)
+		return cachedKubeconfig{}, false
+	}
+	return cachedKubeconfig{data: data, expires: kubeconfigExpiry(data, info.ModTime())}, true
+}
+
+func (c *kubeconfigCache) key(resourceGroupName, clusterName string) string {
+	return strings.ToLower(resourceGroupName + "/" + clusterName)
+}
+
+func (c *kubeconfigCache) path(resourceGroupName, clusterName string) string {
+	return filepath.Join(c.dir, strings.ToLower(fmt.Sprintf(kubeconfigCacheFileTemplate, c.subscription, resourceGroupName, clusterName)))
+}
+
+// Returns when the kubeconfig, fetched at the specified time, expires, i.e. after kubeconfigCacheTTL, or shortly before the
+// earliest expiry of its client certificates when that comes first
+func kubeconfigExpiry(data []byte, fetched time.Time) time.Time {
+	expires := fetched.Add(kubeconfigCacheTTL)
+
+	config, err := clientcmd.Load(data)
+	if err != nil {
+		// can't be used anyway, and will be listed again once it fails to load
+		return fetched
+	}
+	for _, authInfo := range config.AuthInfos {
+		block, _ := pem.Decode(authInfo.ClientCertificateData)
+		if block == nil {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+		if certExpires := cert.NotAfter.Add(-kubeconfigCertExpiryMargin); certExpires.Before(expires) {
+			expires = certExpires
+		}
+	}
+	return expires
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
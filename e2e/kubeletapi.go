@@ -0,0 +1,153 @@
+package e2e_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"k8s.io/client-go/rest"
+)
+
+// the port of kubelet's authenticated API, served over HTTPS on every address of the node
+const kubeletAPIPort = 10250
+
+// kubeletAPI queries the local endpoints of a node's kubelet, i.e. those of its authenticated API, such as /configz and /metrics,
+// and its loopback healthz endpoint, through port forwards to a hostNetwork debug pod scheduled onto the node. Unlike the
+// apiserver's node proxy, the node's endpoints are reached as the node's own processes reach them, such that validators can
+// assert the effective runtime state of kubelet, even when the apiserver can't reach the node
+type kubeletAPI struct {
+	nodeName string
+	api      *portForward
+	healthz  *portForward
+	client   *http.Client
+}
+
+// Returns a client of the local endpoints of the kubelet of the node onto which the debug pod is scheduled, which must be
+// closed once the caller is done with it
+func (k *kubeclient) kubeletAPI(ctx context.Context, namespace, debugPodName, nodeName string) (*kubeletAPI, error) {
+	// kubelet authenticates the cluster's admin credentials, but serves with a certificate the cluster's CA doesn't necessarily
+	// sign, e.g. when serving certificate rotation is disabled. Responses from kubelet mustn't invalidate the cached kubeconfig
+	// either, so the transport isn't wrapped
+	config := rest.CopyConfig(k.rest)
+	config.Insecure = true
+	config.CAData = nil
+	config.CAFile = ""
+	config.ServerName = ""
+	config.WrapTransport = nil
+	transport, err := rest.TransportFor(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create transport for kubelet API of node %q: %w", nodeName, err)
+	}
+
+	api, err := k.portForward(ctx, namespace, debugPodName, kubeletAPIPort)
+	if err != nil {
+		return nil, err
+	}
+	healthz, err := k.portForward(ctx, namespace, debugPodName, kubeletHealthzPort)
+	if err != nil {
+		api.close()
+		return nil, err
+	}
+	return &kubeletAPI{
+		nodeName: nodeName,
+		api:      api,
+		healthz:  healthz,
+		client:   &http.Client{Transport: transport},
+	}, nil
+}
+
+// Stops forwarding the kubelet's ports
+func (a *kubeletAPI) close() {
+	a.api.close()
+	a.healthz.close()
+}
+
+// Sends a GET request for the specified path to kubelet's authenticated API, returning the response's status code and body
+func (a *kubeletAPI) get(ctx context.Context, path string) (int, string, error) {
+	url := fmt.Sprintf("https://%s/%s", a.api.address(), strings.TrimPrefix(path, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, "", fmt.Errorf("unable to create request for %s: %w", url, err)
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to get %s from kubelet of node %q: %w", path, a.nodeName, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to read response to %s from kubelet of node %q: %w", path, a.nodeName, err)
+	}
+	return resp.StatusCode, string(body), nil
+}
+
+// Returns the response to a GET request for the path, unless kubelet responded with a status other than 200
+func (a *kubeletAPI) getOK(ctx context.Context, path string) ([]byte, error) {
+	code, body, err := a.get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if code != http.StatusOK {
+		return nil, fmt.Errorf("kubelet of node %q responded to %s with status %d: %s", a.nodeName, path, code, body)
+	}
+	return []byte(body), nil
+}
+
+// Returns the raw response of kubelet's /configz endpoint, along with the effective configuration of the running kubelet
+// decoded from it
+func (a *kubeletAPI) configz(ctx context.Context) ([]byte, map[string]any, error) {
+	configz, err := a.getOK(ctx, "/configz")
+	if err != nil {
+		return nil, nil, err
+	}
+	config, err := decodeKubeletConfigz(configz)
+	if err != nil {
+		return configz, nil, fmt.Errorf("unable to parse configz of node %q: %w", a.nodeName, err)
+	}
+	return configz, config, nil
+}
+
+// Returns kubelet's metrics, in the Prometheus text exposition format
+func (a *kubeletAPI) metrics(ctx context.Context) (string, error) {
+	metrics, err := a.getOK(ctx, "/metrics")
+	if err != nil {
+		return "", err
+	}
+	return string(metrics), nil
+}
+
+// Returns the raw response of kubelet's summary API, holding the resource usage of the node and each of its pods
+func (a *kubeletAPI) statsSummary(ctx context.Context) ([]byte, error) {
+	return a.getOK(ctx, "/stats/summary")
+}
+
+// Returns an error unless kubelet reports itself healthy on its loopback healthz endpoint
+func (a *kubeletAPI) checkHealthz(ctx context.Context) error {
+	code, body, err := a.healthz.get(ctx, "/healthz")
+	if err != nil {
+		return err
+	}
+	if code != http.StatusOK || strings.TrimSpace(body) != "ok" {
+		return fmt.Errorf("expected kubelet healthz to respond with status %d and body \"ok\", but it responded with status %d and body %q", http.StatusOK, code, body)
+	}
+	return nil
+}
+
+// Decodes the kubelet's effective configuration from the response of its /configz endpoint, whether served by kubelet itself or
+// by the apiserver's node proxy
+func decodeKubeletConfigz(configz []byte) (map[string]any, error) {
+	var response struct {
+		KubeletConfig map[string]any `json:"kubeletconfig"`
+	}
+	if err := json.Unmarshal(configz, &response); err != nil {
+		return nil, err
+	}
+	if response.KubeletConfig == nil {
+		return nil, fmt.Errorf("configz doesn't contain the kubelet's configuration: %s", string(configz))
+	}
+	return response.KubeletConfig, nil
+}
@@ -0,0 +1,207 @@
+package e2e_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+const (
+	kubeletMetricsArtifactName      = "kubelet-metrics.txt"
+	kubeletStatsSummaryArtifactName = "kubelet-stats-summary.json"
+)
+
+// the histograms of kubelet's metrics recorded within the scenario's result, those of the versions of kubelet which don't expose
+// them being omitted
+var kubeletStatsSeries = []string{
+	"kubelet_pleg_relist_duration_seconds",
+	"kubelet_pleg_relist_interval_seconds",
+	"kubelet_pod_start_duration_seconds",
+	"kubelet_pod_start_sli_duration_seconds",
+	"kubelet_pod_worker_start_duration_seconds",
+}
+
+// The selected metrics and resource usage of the kubelet of one of the scenario's instances, recorded within the scenario's
+// result such that the performance of kubelet can be compared across runs per VHD and VM size
+type kubeletStats struct {
+	InstanceID            string                      `json:"instanceId"`
+	Series                map[string]kubeletHistogram `json:"series,omitempty"`
+	CPUUsageNanoCores     uint64                      `json:"cpuUsageNanoCores,omitempty"`
+	MemoryWorkingSetBytes uint64                      `json:"memoryWorkingSetBytes,omitempty"`
+	RootFsUsedBytes       uint64                      `json:"rootFsUsedBytes,omitempty"`
+	ImageFsUsedBytes      uint64                      `json:"imageFsUsedBytes,omitempty"`
+	Pods                  int                         `json:"pods"`
+}
+
+// A histogram of kubelet's metrics, summed across its label values, with its quantiles estimated from its buckets as
+// Prometheus' histogram_quantile estimates them
+type kubeletHistogram struct {
+	Count       uint64  `json:"count"`
+	SumSeconds  float64 `json:"sumSeconds"`
+	MeanSeconds float64 `json:"meanSeconds"`
+	P50Seconds  float64 `json:"p50Seconds"`
+	P99Seconds  float64 `json:"p99Seconds"`
+}
+
+func (r *scenarioResult) recordKubeletStats(stats kubeletStats) {
+	r.KubeletStats = append(r.KubeletStats, stats)
+}
+
+// The parts of kubelet's summary API the scenario's result records
+type kubeletStatsSummary struct {
+	Node struct {
+		CPU struct {
+			UsageNanoCores uint64 `json:"usageNanoCores"`
+		} `json:"cpu"`
+		Memory struct {
+			WorkingSetBytes uint64 `json:"workingSetBytes"`
+		} `json:"memory"`
+		Fs struct {
+			UsedBytes uint64 `json:"usedBytes"`
+		} `json:"fs"`
+		Runtime struct {
+			ImageFs struct {
+				UsedBytes uint64 `json:"usedBytes"`
+			} `json:"imageFs"`
+		} `json:"runtime"`
+	} `json:"node"`
+	Pods []json.RawMessage `json:"pods"`
+}
+
+// Scrapes the node's kubelet metrics and summary API, recording their raw responses as artifacts, and the PLEG and pod start
+// latency histograms, along with the node's resource usage, within the scenario's result. The kubelet is scraped through the
+// node's debug pod, which is scheduled onto the node unless it already has been
+func scrapeKubeletStats(ctx context.Context, nodeName string, opts *scenarioRunOpts) error {
+	metrics, summary, err := getKubeletStats(ctx, nodeName, opts)
+	if err != nil {
+		return err
+	}
+	if err := opts.artifacts.writeFiles(map[string]string{
+		kubeletMetricsArtifactName:      string(metrics),
+		kubeletStatsSummaryArtifactName: string(summary),
+	}); err != nil {
+		log.Printf("failed to record kubelet stats: %s", err)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(strings.NewReader(string(metrics)))
+	if err != nil {
+		return fmt.Errorf("unable to parse metrics of kubelet of node %q: %w", nodeName, err)
+	}
+	var decoded kubeletStatsSummary
+	if err := json.Unmarshal(summary, &decoded); err != nil {
+		return fmt.Errorf("unable to parse stats summary of kubelet of node %q: %w", nodeName, err)
+	}
+
+	stats := kubeletStats{
+		InstanceID:            opts.instance.instanceID,
+		Series:                map[string]kubeletHistogram{},
+		CPUUsageNanoCores:     decoded.Node.CPU.UsageNanoCores,
+		MemoryWorkingSetBytes: decoded.Node.Memory.WorkingSetBytes,
+		RootFsUsedBytes:       decoded.Node.Fs.UsedBytes,
+		ImageFsUsedBytes:      decoded.Node.Runtime.ImageFs.UsedBytes,
+		Pods:                  len(decoded.Pods),
+	}
+	for _, name := range kubeletStatsSeries {
+		family, ok := families[name]
+		if !ok || family.GetType() != dto.MetricType_HISTOGRAM {
+			continue
+		}
+		stats.Series[name] = summarizeHistogram(family)
+	}
+	opts.result.recordKubeletStats(stats)
+
+	if plegRelist, ok := stats.Series["kubelet_pleg_relist_duration_seconds"]; ok {
+		log.Printf("kubelet of node %q relisted its pods %d times with a p99 latency of %.3fs", nodeName, plegRelist.Count, plegRelist.P99Seconds)
+	}
+	if podStart, ok := stats.Series["kubelet_pod_start_duration_seconds"]; ok {
+		log.Printf("kubelet of node %q started %d pods with a p99 latency of %.3fs", nodeName, podStart.Count, podStart.P99Seconds)
+	}
+	return nil
+}
+
+// Returns the raw responses of the node's kubelet metrics and summary API
+func getKubeletStats(ctx context.Context, nodeName string, opts *scenarioRunOpts) ([]byte, []byte, error) {
+	kube := opts.clusterConfig.kube
+	debugPodName := opts.nodeDebugPodName
+	if debugPodName == "" {
+		name, err := ensureNodeDebugPod(ctx, kube, opts.namespace, nodeName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to ensure node debug pod: %w", err)
+		}
+		debugPodName = name
+	}
+	kubelet, err := kube.kubeletAPI(ctx, opts.namespace, debugPodName, nodeName)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer kubelet.close()
+
+	metrics, err := kubelet.metrics(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	summary, err := kubelet.statsSummary(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []byte(metrics), summary, nil
+}
+
+// Sums the histogram's observations and buckets across each of its label values, estimating its median and 99th percentile
+func summarizeHistogram(family *dto.MetricFamily) kubeletHistogram {
+	var summary kubeletHistogram
+	buckets := map[float64]uint64{}
+	for _, metric := range family.GetMetric() {
+		histogram := metric.GetHistogram()
+		summary.Count += histogram.GetSampleCount()
+		summary.SumSeconds += histogram.GetSampleSum()
+		for _, bucket := range histogram.GetBucket() {
+			buckets[bucket.GetUpperBound()] += bucket.GetCumulativeCount()
+		}
+	}
+	if summary.Count == 0 {
+		return summary
+	}
+	summary.MeanSeconds = summary.SumSeconds / float64(summary.Count)
+	summary.P50Seconds = histogramQuantile(0.5, buckets, summary.Count)
+	summary.P99Seconds = histogramQuantile(0.99, buckets, summary.Count)
+	return summary
+}
+
+// Estimates the quantile of the histogram's observations from the cumulative counts of its buckets, keyed by their upper bounds,
+// interpolating linearly within the bucket holding the quantile. Observations beyond the largest finite bound are estimated to
+// be at that bound
+func histogramQuantile(q float64, buckets map[float64]uint64, count uint64) float64 {
+	bounds := make([]float64, 0, len(buckets))
+	for bound := range buckets {
+		if !math.IsInf(bound, 1) {
+			bounds = append(bounds, bound)
+		}
+	}
+	sort.Float64s(bounds)
+	if len(bounds) == 0 {
+		return 0
+	}
+
+	rank := q * float64(count)
+	lowerBound, lowerCount := 0.0, uint64(0)
+	for _, bound := range bounds {
+		cumulative := buckets[bound]
+		if float64(cumulative) >= rank {
+			if cumulative == lowerCount {
+				return bound
+			}
+			return lowerBound + (bound-lowerBound)*(rank-float64(lowerCount))/float64(cumulative-lowerCount)
+		}
+		lowerBound, lowerCount = bound, cumulative
+	}
+	return bounds[len(bounds)-1]
+}
@@ -0,0 +1,124 @@
+package e2e_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestHistogramQuantile(t *testing.T) {
+	buckets := map[float64]uint64{0.1: 2, 0.5: 6, 1: 8, math.Inf(1): 10}
+
+	cases := []struct {
+		name     string
+		q        float64
+		buckets  map[float64]uint64
+		count    uint64
+		quantile float64
+	}{
+		{
+			name:     "interpolated within the first bucket",
+			q:        0.1,
+			buckets:  buckets,
+			count:    10,
+			quantile: 0.05,
+		},
+		{
+			name:     "interpolated within a later bucket",
+			q:        0.5,
+			buckets:  buckets,
+			count:    10,
+			quantile: 0.4,
+		},
+		{
+			name:     "beyond the largest finite bound",
+			q:        0.99,
+			buckets:  buckets,
+			count:    10,
+			quantile: 1,
+		},
+		{
+			name:     "within an empty bucket",
+			q:        0,
+			buckets:  map[float64]uint64{0.1: 0, 0.5: 4, math.Inf(1): 4},
+			count:    4,
+			quantile: 0.1,
+		},
+		{
+			name:     "only the infinite bucket",
+			q:        0.5,
+			buckets:  map[float64]uint64{math.Inf(1): 4},
+			count:    4,
+			quantile: 0,
+		},
+		{
+			name: "no buckets",
+			q:    0.5,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			if quantile := histogramQuantile(c.q, c.buckets, c.count); math.Abs(quantile-c.quantile) > 1e-9 {
+				t.Errorf("expected quantile %v, but got %v", c.quantile, quantile)
+			}
+		})
+	}
+}
+
+func TestSummarizeHistogram(t *testing.T) {
+	histogram := func(count uint64, sum float64, buckets map[float64]uint64) *dto.Metric {
+		h := &dto.Histogram{SampleCount: to.Ptr(count), SampleSum: to.Ptr(sum)}
+		for bound, cumulative := range buckets {
+			h.Bucket = append(h.Bucket, &dto.Bucket{UpperBound: to.Ptr(bound), CumulativeCount: to.Ptr(cumulative)})
+		}
+		return &dto.Metric{Histogram: h}
+	}
+
+	cases := []struct {
+		name    string
+		family  *dto.MetricFamily
+		summary kubeletHistogram
+	}{
+		{
+			name: "summed across label values",
+			family: &dto.MetricFamily{Metric: []*dto.Metric{
+				histogram(4, 2, map[float64]uint64{0.5: 2, 1: 4, math.Inf(1): 4}),
+				histogram(6, 1, map[float64]uint64{0.5: 6, 1: 6, math.Inf(1): 6}),
+			}},
+			summary: kubeletHistogram{Count: 10, SumSeconds: 3, MeanSeconds: 0.3, P50Seconds: 0.3125, P99Seconds: 0.975},
+		},
+		{
+			name: "without observations",
+			family: &dto.MetricFamily{Metric: []*dto.Metric{
+				histogram(0, 0, map[float64]uint64{0.5: 0, math.Inf(1): 0}),
+			}},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			summary := summarizeHistogram(c.family)
+			if summary.Count != c.summary.Count {
+				t.Errorf("expected count %d, but got %d", c.summary.Count, summary.Count)
+			}
+			for _, value := range []struct {
+				name             string
+				expected, actual float64
+			}{
+				{"sum", c.summary.SumSeconds, summary.SumSeconds},
+				{"mean", c.summary.MeanSeconds, summary.MeanSeconds},
+				{"p50", c.summary.P50Seconds, summary.P50Seconds},
+				{"p99", c.summary.P99Seconds, summary.P99Seconds},
+			} {
+				if math.Abs(value.expected-value.actual) > 1e-9 {
+					t.Errorf("expected %s %v, but got %v", value.name, value.expected, value.actual)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The time taken for one of the scenario's instances to become a Ready node, recorded within the scenario's result such that
+// latencies can be tracked across runs per VHD and VM size
+type bootstrapLatency struct {
+	InstanceID       string  `json:"instanceId"`
+	VMSize           string  `json:"vmSize"`
+	Image            string  `json:"image"`
+	Latency          string  `json:"latency"`
+	LatencySeconds   float64 `json:"latencySeconds"`
+	Objective        string  `json:"objective"`
+	ObjectiveSeconds float64 `json:"objectiveSeconds"`
+	Met              bool    `json:"met"`
+}
+
+func (r *scenarioResult) recordBootstrapLatency(latency bootstrapLatency) {
+	r.BootstrapLatencies = append(r.BootstrapLatencies, latency)
+}
+
+// Measures the time taken for the instance to become a Ready node, from the request creating its VMSS through to the last
+// transition of the node's Ready condition, recording it within the scenario's result. Returns an error if the latency
+// exceeds the scenario's objective for the instance's VM size
+func validateBootstrapLatency(ctx context.Context, vmssName, nodeName string, opts *scenarioRunOpts) error {
+	vmResp, err := opts.cloud.vmssVMClient.Get(ctx, *opts.clusterConfig.cluster.Properties.NodeResourceGroup, vmssName, opts.instance.instanceID, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get instance %q of vmss %q: %w", opts.instance.instanceID, vmssName, err)
+	}
+	var vmSize, image string
+	if vmResp.SKU != nil && vmResp.SKU.Name != nil {
+		vmSize = *vmResp.SKU.Name
+	}
+	if properties := vmResp.Properties; properties != nil && properties.StorageProfile != nil && properties.StorageProfile.ImageReference != nil &&
+		properties.StorageProfile.ImageReference.ID != nil {
+		image = *properties.StorageProfile.ImageReference.ID
+	}
+
+	node, err := opts.clusterConfig.kube.typed.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get node %q: %w", nodeName, err)
+	}
+	var ready *corev1.NodeCondition
+	for i, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady && cond.Status == corev1.ConditionTrue {
+			ready = &node.Status.Conditions[i]
+		}
+	}
+	if ready == nil {
+		return fmt.Errorf("node %q is no longer Ready, so its bootstrap latency can't be measured", nodeName)
+	}
+
+	// the Ready condition's transition time only has a resolution of seconds
+	latency := ready.LastTransitionTime.Sub(opts.vmssCreationRequested).Round(time.Second)
+	objective := opts.scenario.BootstrapLatencySLO.Objective(vmSize)
+	opts.result.recordBootstrapLatency(bootstrapLatency{
+		InstanceID:       opts.instance.instanceID,
+		VMSize:           vmSize,
+		Image:            image,
+		Latency:          latency.String(),
+		LatencySeconds:   latency.Seconds(),
+		Objective:        objective.String(),
+		ObjectiveSeconds: objective.Seconds(),
+		Met:              latency <= objective,
+	})
+	log.Printf("node %q of VM size %s became Ready %s after the creation of vmss %q, within an objective of %s", nodeName, vmSize, latency, vmssName, objective)
+
+	if latency > objective {
+		return fmt.Errorf("node %q of VM size %s took %s to become Ready, exceeding the bootstrap latency objective of %s", nodeName, vmSize, latency, objective)
+	}
+	return nil
+}
@@ -3,11 +3,6 @@ package e2e_test
 import (
 	"bufio"
 	"os"
-	"path/filepath"
-)
-
-const (
-	e2eLogsDir = "scenario-logs"
 )
 
 func createDirIfNeeded(dir string) error {
@@ -19,15 +14,6 @@ func createDirIfNeeded(dir string) error {
 	return nil
 }
 
-func createE2ELoggingDir() error {
-	return createDirIfNeeded(e2eLogsDir)
-}
-
-func createVMLogsDir(caseName string) (string, error) {
-	logDir := filepath.Join(e2eLogsDir, caseName)
-	return logDir, createDirIfNeeded(logDir)
-}
-
 func writeToFile(fileName, content string) error {
 	outputFile, err := os.Create(fileName)
 	if err != nil {
@@ -46,15 +32,3 @@ func writeToFile(fileName, content string) error {
 
 	return nil
 }
-
-func dumpFileMapToDir(baseDir string, files map[string]string) error {
-	for path, contents := range files {
-		path = filepath.Base(path)
-		fullPath := filepath.Join(baseDir, path)
-		if err := writeToFile(fullPath, contents); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
@@ -0,0 +1,212 @@
+package e2e_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// the field manager of the objects the suite applies, which owns each of the fields set by their manifests
+const manifestFieldManager = "agentbaker-e2e"
+
+// The values available to the manifests applied by applyManifests, each of which is rendered as a Go template, e.g.
+// "nodeName: {{ .NodeName }}"
+type manifestValues struct {
+	// the namespace objects are applied within, unless their manifest names one
+	Namespace string
+
+	// the name of the node the manifests are applied for
+	NodeName string
+}
+
+// Renders and applies each of the manifests within the YAML files of the specified directory using server-side apply, in
+// the order of their file names and then of the documents within each file, waiting for each pod, deployment, daemonset,
+// statefulset and job applied to become ready once every manifest has been applied. Namespaced objects whose manifest
+// doesn't name a namespace are applied within the namespace of the values. Returns the applied objects
+func (k *kubeclient) applyManifests(ctx context.Context, dir string, values manifestValues) ([]*unstructured.Unstructured, error) {
+	objects, err := readManifests(dir, values)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, obj := range objects {
+		mapping, err := k.dynamic.RESTMapper().RESTMapping(obj.GroupVersionKind().GroupKind(), obj.GroupVersionKind().Version)
+		if err != nil {
+			return nil, fmt.Errorf("unable to determine resource of %s %q: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace && obj.GetNamespace() == "" {
+			obj.SetNamespace(values.Namespace)
+		}
+
+		if err := k.dynamic.Patch(ctx, obj, client.Apply, client.FieldOwner(manifestFieldManager), client.ForceOwnership); err != nil {
+			return nil, fmt.Errorf("failed to apply %s %q: %w", obj.GetKind(), describeObject(obj), err)
+		}
+		log.Printf("applied %s %q", obj.GetKind(), describeObject(obj))
+	}
+
+	for _, obj := range objects {
+		// the logs of pods are streamed while they're waited on, such that it's apparent why they aren't becoming ready
+		stopStreaming := func() {}
+		if obj.GroupVersionKind().GroupKind().String() == "Pod" {
+			stopStreaming = k.streamPodLogs(ctx, obj.GetNamespace(), obj.GetName())
+		}
+		err := k.waitUntilObjectReady(ctx, obj)
+		stopStreaming()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return objects, nil
+}
+
+// Reads and renders the manifests within the YAML files of the directory, skipping empty documents
+func readManifests(dir string, values manifestValues) ([]*unstructured.Unstructured, error) {
+	var paths []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list manifests within %s: %w", dir, err)
+		}
+		paths = append(paths, matches...)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("found no manifests within %s", dir)
+	}
+	sort.Strings(paths)
+
+	var objects []*unstructured.Unstructured
+	for _, path := range paths {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifests %s: %w", path, err)
+		}
+		rendered, err := renderManifestTemplate(filepath.Base(path), string(contents), values)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render manifests %s: %w", path, err)
+		}
+
+		decoder := utilyaml.NewYAMLOrJSONDecoder(rendered, rendered.Len())
+		for {
+			obj := &unstructured.Unstructured{}
+			if err := decoder.Decode(&obj.Object); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, fmt.Errorf("failed to decode manifests %s: %w", path, err)
+			}
+			if len(obj.Object) == 0 {
+				continue
+			}
+			if obj.GetKind() == "" || obj.GetName() == "" {
+				return nil, fmt.Errorf("manifest within %s is missing its kind or name", path)
+			}
+			objects = append(objects, obj)
+		}
+	}
+	return objects, nil
+}
+
+// Waits until the applied object is ready, for the kinds of objects which have a notion of readiness
+func (k *kubeclient) waitUntilObjectReady(ctx context.Context, obj *unstructured.Unstructured) error {
+	var ready func(current *unstructured.Unstructured) (bool, error)
+	switch obj.GroupVersionKind().GroupKind().String() {
+	case "Pod":
+		ready = isUnstructuredReady(func(pod *corev1.Pod) bool {
+			return pod.Status.Phase == corev1.PodRunning || pod.Status.Phase == corev1.PodSucceeded
+		})
+	case "Deployment.apps":
+		ready = isUnstructuredReady(isDeploymentRolledOut)
+	case "DaemonSet.apps":
+		ready = isUnstructuredReady(isDaemonSetRolledOut)
+	case "StatefulSet.apps":
+		ready = isUnstructuredReady(func(statefulSet *appsv1.StatefulSet) bool {
+			replicas := int32(1)
+			if statefulSet.Spec.Replicas != nil {
+				replicas = *statefulSet.Spec.Replicas
+			}
+			return statefulSet.Status.ObservedGeneration >= statefulSet.Generation && statefulSet.Status.ReadyReplicas == replicas
+		})
+	case "Job.batch":
+		ready = isUnstructuredReady(func(job *batchv1.Job) bool {
+			completions := int32(1)
+			if job.Spec.Completions != nil {
+				completions = *job.Spec.Completions
+			}
+			return job.Status.Succeeded >= completions
+		})
+	default:
+		return nil
+	}
+
+	log.Printf("waiting for %s %q to become ready...", obj.GetKind(), describeObject(obj))
+	gvk := obj.GroupVersionKind()
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	objType := &unstructured.Unstructured{}
+	objType.SetGroupVersionKind(gvk)
+
+	var last *unstructured.Unstructured
+	_, err := k.watchUntil(ctx, waitUntilManifestObjectReadyTimeout, list, objType, nil,
+		typedCondition(func(eventType watch.EventType, current *unstructured.Unstructured) (bool, error) {
+			if eventType == watch.Deleted {
+				return false, fmt.Errorf("%s %q was deleted", obj.GetKind(), describeObject(obj))
+			}
+			last = current
+			return ready(current)
+		}), watchedObject(obj.GetNamespace(), obj.GetName())...)
+	if err != nil {
+		status := "<unknown>"
+		if last != nil {
+			status = fmt.Sprintf("%v", last.Object["status"])
+		}
+		return fmt.Errorf("failed waiting for %s %q to become ready, its last status was %s: %w", obj.GetKind(), describeObject(obj), status, err)
+	}
+	return nil
+}
+
+// Returns a function converting the unstructured object into the typed object, before asserting whether it's ready
+func isUnstructuredReady[T any](isReady func(*T) bool) func(*unstructured.Unstructured) (bool, error) {
+	return func(current *unstructured.Unstructured) (bool, error) {
+		var typed T
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(current.Object, &typed); err != nil {
+			return false, fmt.Errorf("failed to convert %s %q: %w", current.GetKind(), describeObject(current), err)
+		}
+		return isReady(&typed), nil
+	}
+}
+
+// Describes the object by its namespace and name, or its name alone when it's cluster-scoped
+func describeObject(obj client.Object) string {
+	return strings.TrimPrefix(obj.GetNamespace()+"/"+obj.GetName(), "/")
+}
+
+// Renders the contents as a Go template with the values, failing on references to values which don't exist
+func renderManifestTemplate(name, contents string, values manifestValues) (*bytes.Buffer, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(contents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, values); err != nil {
+		return nil, err
+	}
+	return &rendered, nil
+}
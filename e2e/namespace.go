@@ -0,0 +1,74 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	mrand "math/rand"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// the name of each scenario's namespace, suffixed with a random string derived from the scenario's source of randomness
+const scenarioNamespaceNameTemplate = "abe2e-%s"
+
+// matches the runs of characters which aren't allowed within label values
+var invalidLabelValueCharsPattern = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Creates the namespace of the scenario, within which the pods it schedules onto its nodes are created, such that the objects
+// of scenarios running concurrently on the same cluster can't collide. The namespace is labeled with the suite's build ID and
+// the scenario's name, the same identifiers the suite tags its Azure resources with, and unless disabled, as exempt from the
+// cluster's default Pod Security Standard. Returns the namespace's name along with a
+// function deleting it, and thereby each of the scenario's objects
+func createScenarioNamespace(ctx context.Context, kube *kubeclient, r *mrand.Rand, suiteConfig *suiteConfig, scenarioName string) (string, func(), error) {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf(scenarioNamespaceNameTemplate, randomLowercaseString(r, 8)),
+			Labels: map[string]string{
+				buildIDTagKey:  toLabelValue(suiteConfig.buildID),
+				scenarioTagKey: toLabelValue(scenarioName),
+			},
+		},
+	}
+	if suiteConfig.podSecurityLabels {
+		// the scenario's node debug pods, and many of its validation pods, are privileged or share their node's network
+		for label, level := range privilegedPodSecurityLabels() {
+			namespace.Labels[label] = level
+		}
+	}
+	if _, err := kube.typed.CoreV1().Namespaces().Create(ctx, namespace, metav1.CreateOptions{}); err != nil {
+		return "", nil, fmt.Errorf("failed to create namespace %q of scenario %q: %w", namespace.Name, scenarioName, err)
+	}
+	log.Printf("created namespace %q of scenario %q", namespace.Name, scenarioName)
+
+	deleteNamespace := func() {
+		// the scenario's context may have already timed out, though its namespace still needs to be deleted
+		ctx := context.Background()
+
+		// deletion is left to complete in the background, as it only has to wait on the termination of the namespace's pods
+		propagation := metav1.DeletePropagationBackground
+		err := kube.typed.CoreV1().Namespaces().Delete(ctx, namespace.Name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+		if err != nil && !apierrors.IsNotFound(err) {
+			log.Printf("failed to delete namespace %q of scenario %q: %s", namespace.Name, scenarioName, err)
+			return
+		}
+		log.Printf("deleted namespace %q of scenario %q", namespace.Name, scenarioName)
+	}
+	return namespace.Name, deleteNamespace, nil
+}
+
+// Converts the value into a valid label value, replacing each run of disallowed characters, e.g. the "/" separating a
+// scenario's name from that of its parameter, with "." and truncating it to the maximum length of label values
+func toLabelValue(value string) string {
+	value = invalidLabelValueCharsPattern.ReplaceAllString(value, ".")
+	if len(value) > validation.LabelValueMaxLength {
+		value = value[:validation.LabelValueMaxLength]
+	}
+	// label values must begin and end with an alphanumeric character
+	return strings.Trim(value, "._-")
+}
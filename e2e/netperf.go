@@ -0,0 +1,137 @@
+package e2e_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// the image of the network performance probe's pods, overridden by NETWORK_PERF_IMAGE, which is pulled from MCR rather than
+	// Docker Hub, so isn't rate-limited. iperf3 is installed from the distro's package repository unless the image provides it
+	defaultNetworkPerfImage = "mcr.microsoft.com/cbl-mariner/base/core:2.0"
+
+	// run by the probe's pods through a shell, installing iperf3 when it isn't on the image's PATH, quietly, such that the
+	// client's logs only hold iperf3's JSON report
+	networkPerfCommandTemplate = "command -v iperf3 >/dev/null || tdnf install -y -q iperf3 >/dev/null 2>&1; exec iperf3 %s"
+
+	networkPerfPort            = 5201
+	networkPerfDurationSeconds = 10
+
+	networkPerfArtifactName = "iperf3.json"
+)
+
+// The pod network throughput and latency measured between one of the scenario's instances and one of the cluster's existing
+// nodes, recorded within the scenario's result such that they can be compared across runs per VHD and VM size
+type networkPerf struct {
+	InstanceID    string  `json:"instanceId"`
+	ServerNode    string  `json:"serverNode"`
+	Duration      string  `json:"duration"`
+	ThroughputBps float64 `json:"throughputBitsPerSecond"`
+	Retransmits   int64   `json:"retransmits"`
+	MeanRTT       string  `json:"meanRtt,omitempty"`
+}
+
+func (r *scenarioResult) recordNetworkPerf(perf networkPerf) {
+	r.NetworkPerf = append(r.NetworkPerf, perf)
+}
+
+// The parts of iperf3's JSON report the probe records
+type iperf3Report struct {
+	End struct {
+		Streams []struct {
+			Sender struct {
+				// in microseconds, only reported on Linux
+				MeanRTT int64 `json:"mean_rtt"`
+			} `json:"sender"`
+		} `json:"streams"`
+		SumSent struct {
+			Seconds     float64 `json:"seconds"`
+			Retransmits int64   `json:"retransmits"`
+		} `json:"sum_sent"`
+		SumReceived struct {
+			BitsPerSecond float64 `json:"bits_per_second"`
+		} `json:"sum_received"`
+	} `json:"end"`
+	Error string `json:"error"`
+}
+
+// Measures the TCP throughput, retransmits and round-trip time of the pod network from the node to an iperf3 server running
+// on one of the cluster's existing nodes, recording them within the scenario's result along with iperf3's full report
+func probeNetworkPerf(ctx context.Context, nodeName string, opts *scenarioRunOpts) error {
+	kube := opts.clusterConfig.kube
+	image := opts.suiteConfig.networkPerfImage
+
+	serverPodName := fmt.Sprintf("%s-iperf3-server", nodeName)
+	if err := ensurePod(ctx, kube, opts.namespace, serverPodName, getNetworkPerfServerPodTemplate(opts.namespace, nodeName, image)); err != nil {
+		return fmt.Errorf("failed to ensure iperf3 server pod %q: %w", serverPodName, err)
+	}
+	defer func() {
+		if err := waitUntilPodDeleted(ctx, kube, opts.namespace, serverPodName); err != nil {
+			log.Printf("unable to delete iperf3 server pod %q: %s", serverPodName, err)
+		}
+	}()
+	server, err := kube.typed.CoreV1().Pods(opts.namespace).Get(ctx, serverPodName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to get iperf3 server pod %q: %w", serverPodName, err)
+	}
+	if server.Status.PodIP == "" {
+		return fmt.Errorf("iperf3 server pod %q has yet to be assigned an IP", serverPodName)
+	}
+
+	clientPodName := fmt.Sprintf("%s-iperf3-client", nodeName)
+	if err := applyPodManifest(ctx, kube, getNetworkPerfClientPodTemplate(opts.namespace, nodeName, image, server.Status.PodIP)); err != nil {
+		return fmt.Errorf("failed to ensure iperf3 client pod %q: %w", clientPodName, err)
+	}
+	defer func() {
+		if err := waitUntilPodDeleted(ctx, kube, opts.namespace, clientPodName); err != nil {
+			log.Printf("unable to delete iperf3 client pod %q: %s", clientPodName, err)
+		}
+	}()
+
+	log.Printf("measuring pod network throughput from node %q to node %q for %ds...", nodeName, server.Spec.NodeName, networkPerfDurationSeconds)
+	// iperf3 reports its errors within its JSON report too, so the report is read regardless of whether the client succeeded
+	waitErr := waitUntilPodSucceeded(ctx, kube, opts.namespace, clientPodName)
+	logs, err := kube.typed.CoreV1().Pods(opts.namespace).GetLogs(clientPodName, &corev1.PodLogOptions{}).DoRaw(ctx)
+	if err != nil {
+		if waitErr != nil {
+			return waitErr
+		}
+		return fmt.Errorf("failed to get logs of iperf3 client pod %q: %w", clientPodName, err)
+	}
+	if _, err := opts.artifacts.writeFile(networkPerfArtifactName, string(logs)); err != nil {
+		log.Printf("failed to record iperf3 report: %s", err)
+	}
+
+	var report iperf3Report
+	if err := json.Unmarshal(logs, &report); err != nil {
+		return fmt.Errorf("unable to parse iperf3 report of pod %q: %w", clientPodName, err)
+	}
+	if report.Error != "" {
+		return fmt.Errorf("iperf3 client pod %q failed: %s", clientPodName, report.Error)
+	}
+	if waitErr != nil {
+		return waitErr
+	}
+
+	perf := networkPerf{
+		InstanceID:    opts.instance.instanceID,
+		ServerNode:    server.Spec.NodeName,
+		Duration:      time.Duration(report.End.SumSent.Seconds * float64(time.Second)).Round(time.Millisecond).String(),
+		ThroughputBps: report.End.SumReceived.BitsPerSecond,
+		Retransmits:   report.End.SumSent.Retransmits,
+	}
+	if len(report.End.Streams) > 0 && report.End.Streams[0].Sender.MeanRTT > 0 {
+		perf.MeanRTT = (time.Duration(report.End.Streams[0].Sender.MeanRTT) * time.Microsecond).String()
+	}
+	opts.result.recordNetworkPerf(perf)
+
+	log.Printf("measured pod network throughput of %.1f Mbit/s from node %q to node %q, with %d retransmits and a mean RTT of %s",
+		perf.ThroughputBps/1e6, nodeName, perf.ServerNode, perf.Retransmits, perf.MeanRTT)
+	return nil
+}
@@ -3,9 +3,18 @@ package e2e_test
 import (
 	"context"
 	"fmt"
+	"log"
+	"strings"
 
+	corev1 "k8s.io/api/core/v1"
 	nodev1 "k8s.io/api/node/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
@@ -42,3 +51,130 @@ func createRuntimeClass(ctx context.Context, kube *kubeclient, name, handler str
 
 	return nil
 }
+
+// Returns true if a node with the specified name has registered itself with the cluster, regardless of its readiness
+func isNodeRegistered(ctx context.Context, kube *kubeclient, nodeName string) (bool, error) {
+	if _, err := kube.typed.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get node %q: %w", nodeName, err)
+	}
+	return true, nil
+}
+
+// Returns the names of the nodes registered by the instances of the specified VMSS, whose computer names, and hence node names,
+// are prefixed by the VMSS' name
+func (k *kubeclient) listVMSSNodeNames(ctx context.Context, vmssName string) ([]string, error) {
+	// served from the informer's cache, since every scenario lists the cluster's nodes as its VMSS is cleaned up
+	lister, err := k.nodeLister(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := lister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	var nodeNames []string
+	for _, node := range nodes {
+		if strings.HasPrefix(node.Name, strings.ToLower(vmssName)) {
+			nodeNames = append(nodeNames, node.Name)
+		}
+	}
+	return nodeNames, nil
+}
+
+// Marks the node as unschedulable, such that no further pods are scheduled onto it
+func (k *kubeclient) cordonNode(ctx context.Context, nodeName string) error {
+	node := &corev1.Node{}
+	if err := k.dynamic.Get(ctx, client.ObjectKey{Name: nodeName}, node); err != nil {
+		return fmt.Errorf("failed to get node %q: %w", nodeName, err)
+	}
+	if node.Spec.Unschedulable {
+		return nil
+	}
+
+	patch := client.MergeFrom(node.DeepCopy())
+	node.Spec.Unschedulable = true
+	if err := k.dynamic.Patch(ctx, node, patch); err != nil {
+		return fmt.Errorf("failed to cordon node %q: %w", nodeName, err)
+	}
+	return nil
+}
+
+// Evicts each of the pods running on the node, other than those of daemonsets and static pods, as "kubectl drain" does, and
+// waits until they've terminated. Evictions blocked by pod disruption budgets are retried until the drain times out. When the
+// node isn't ready, its kubelet can't terminate the evicted pods, so, as with kubectl's --skip-wait-for-delete-timeout, pods
+// are only evicted rather than waited on, and are garbage-collected once the node is deleted
+func (k *kubeclient) drainNode(ctx context.Context, nodeName string) error {
+	node, err := k.typed.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get node %q: %w", nodeName, err)
+	}
+	waitForDelete := isNodeReady(node)
+	if !waitForDelete {
+		log.Printf("node %q isn't ready, its pods will be evicted without waiting for them to terminate", nodeName)
+	}
+
+	var remaining []string
+	err = wait.PollImmediateWithContext(ctx, drainNodePollInterval, drainNodePollingTimeout, func(ctx context.Context) (bool, error) {
+		pods, err := k.typed.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+		})
+		if err != nil {
+			log.Printf("unable to list pods of node %q: %s", nodeName, err)
+			return false, nil
+		}
+
+		remaining = remaining[:0]
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			if !isDrainablePod(pod) {
+				continue
+			}
+			if pod.DeletionTimestamp != nil {
+				if waitForDelete {
+					remaining = append(remaining, pod.Namespace+"/"+pod.Name)
+				}
+				continue
+			}
+			remaining = append(remaining, pod.Namespace+"/"+pod.Name)
+			err := k.typed.CoreV1().Pods(pod.Namespace).EvictV1(ctx, &policyv1.Eviction{
+				ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+			})
+			switch {
+			case err == nil, apierrors.IsNotFound(err):
+			case apierrors.IsTooManyRequests(err):
+				log.Printf("eviction of pod %s/%s is blocked by a disruption budget, will retry", pod.Namespace, pod.Name)
+			default:
+				log.Printf("unable to evict pod %s/%s from node %q: %s", pod.Namespace, pod.Name, nodeName, err)
+			}
+		}
+		return len(remaining) == 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to drain node %q, pods %v remained: %w", nodeName, remaining, err)
+	}
+	return nil
+}
+
+// Deletes the node, such that it doesn't linger within the cluster as NotReady once its VM has been deleted
+func (k *kubeclient) deleteNode(ctx context.Context, nodeName string) error {
+	if err := k.typed.CoreV1().Nodes().Delete(ctx, nodeName, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete node %q: %w", nodeName, err)
+	}
+	return nil
+}
+
+// Returns true if the pod is evicted when draining its node, i.e. unless it's managed by a daemonset, which would immediately
+// replace it, or it's a static pod's mirror, which can't be evicted through the apiserver
+func isDrainablePod(pod *corev1.Pod) bool {
+	if _, mirror := pod.Annotations[corev1.MirrorPodAnnotationKey]; mirror {
+		return false
+	}
+	if owner := metav1.GetControllerOf(pod); owner != nil && owner.Kind == "DaemonSet" {
+		return false
+	}
+	return pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed
+}
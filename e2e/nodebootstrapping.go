@@ -12,6 +12,8 @@ import (
 
 	"github.com/Azure/agentbaker/pkg/agent"
 	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+	"github.com/Azure/agentbakere2e/scenario"
+	"github.com/barkimedes/go-deepcopy"
 )
 
 type nodeBootstrappingFn func(ctx context.Context, nbc *datamodel.NodeBootstrappingConfiguration) (*datamodel.NodeBootstrapping, error)
@@ -65,7 +67,7 @@ func getBaseNodeBootstrappingConfiguration(
 	cloud *azureClient,
 	suiteConfig *suiteConfig,
 	clusterParams clusterParameters) (*datamodel.NodeBootstrappingConfiguration, error) {
-	nbc := baseTemplate(suiteConfig.location)
+	nbc := baseTemplate(suiteConfig.location, suiteConfig.cloudEnvironment)
 	nbc.ContainerService.Properties.CertificateProfile.CaCertificate = clusterParams["/etc/kubernetes/certs/ca.crt"]
 
 	bootstrapKubeconfig := clusterParams["/var/lib/kubelet/bootstrap-kubeconfig"]
@@ -96,3 +98,17 @@ func getBaseNodeBootstrappingConfiguration(
 
 	return nbc, nil
 }
+
+// Returns a copy of the cluster's base bootstrap config, mutated according to the scenario's requirements
+func newScenarioBootstrapConfig(baseConfig *datamodel.NodeBootstrappingConfiguration, scenario *scenario.Scenario) (*datamodel.NodeBootstrappingConfiguration, error) {
+	copied, err := deepcopy.Anything(baseConfig)
+	if err != nil {
+		return nil, err
+	}
+	nbc := copied.(*datamodel.NodeBootstrappingConfiguration)
+
+	if scenario.Config.BootstrapConfigMutator != nil {
+		scenario.Config.BootstrapConfigMutator(nbc)
+	}
+	return nbc, nil
+}
@@ -1,6 +1,8 @@
 package e2e_test
 
 import (
+	"time"
+
 	"github.com/Azure/agentbaker/pkg/agent/datamodel"
 	"github.com/Azure/agentbakere2e/scenario"
 )
@@ -10,6 +12,38 @@ type scenarioRunOpts struct {
 	cloud         *azureClient
 	suiteConfig   *suiteConfig
 	scenario      *scenario.Scenario
+	scenarios     scenario.Table
 	nbc           *datamodel.NodeBootstrappingConfiguration
-	loggingDir    string
+	artifacts     artifactsDir
+	instance      vmssInstance
+	capabilities  *capabilityProbe
+
+	// the scenario's own namespace, within which the pods it schedules onto its nodes are created
+	namespace string
+
+	// set when the scenario's nodes are bootstrapped with a user-assigned kubelet identity
+	kubeletIdentity *userAssignedIdentity
+
+	// set when the scenario's nodes pull from the suite's private ACR
+	privateACR *privateACR
+
+	// set once a debug pod has been scheduled onto the instance's node, when the node can't be reached over SSH
+	nodeDebugPodName string
+
+	// set once the serving certificate of the instance's kubelet has been issued, when the scenario validates it
+	kubeletServingCertFingerprint string
+
+	// set once the request creating the scenario's VMSS has been sent, from when the bootstrap latency of its instances is measured
+	vmssCreationRequested time.Time
+
+	// shared between the options of each of the scenario's instances
+	result *scenarioResult
+}
+
+// Returns a copy of the options scoped to the specified instance of the scenario's VMSS, with artifacts written to the specified directory
+func (o *scenarioRunOpts) forInstance(instance vmssInstance, artifacts artifactsDir) *scenarioRunOpts {
+	instanceOpts := *o
+	instanceOpts.instance = instance
+	instanceOpts.artifacts = artifacts
+	return &instanceOpts
 }
@@ -6,13 +6,18 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/yaml"
 )
 
-// Returns the name of a pod that's a member of the 'debug' daemonset, running on an aks-nodepool node.
+// the number of replicas of the scheduling smoke test deployment run on each node
+const smokeTestDeploymentReplicas int32 = 2
+
+// Returns the name of a pod that's a member of the 'debug' daemonset, preferring one that's running.
 func getDebugPodName(kube *kubeclient) (string, error) {
 	podList := corev1.PodList{}
 	if err := kube.dynamic.List(context.Background(), &podList, client.MatchingLabels{"app": "debug"}); err != nil {
@@ -23,6 +28,13 @@ func getDebugPodName(kube *kubeclient) (string, error) {
 		return "", fmt.Errorf("failed to find debug pod, list by selector returned no results")
 	}
 
+	// the daemonset may select several nodes, not all of whose pods are necessarily running
+	for _, pod := range podList.Items {
+		if pod.DeletionTimestamp == nil && pod.Status.Phase == corev1.PodRunning {
+			return pod.Name, nil
+		}
+	}
+
 	podName := podList.Items[0].Name
 	return podName, nil
 }
@@ -35,12 +47,29 @@ func getPodIP(ctx context.Context, kube *kubeclient, namespaceName, podName stri
 	return pod.Status.PodIP, nil
 }
 
-func ensureDebugDaemonset(ctx context.Context, kube *kubeclient) error {
-	manifest := getDebugDaemonset()
-	var ds appsv1.DaemonSet
+func ensureDebugDaemonset(ctx context.Context, kube *kubeclient, config debugDaemonsetConfig) error {
+	ds, err := applyDaemonSetManifest(ctx, kube, getDebugDaemonset(), func(spec *corev1.PodSpec) {
+		config.apply(spec)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply debug daemonset: %w", err)
+	}
 
+	if err := waitUntilDaemonSetRolledOut(ctx, kube, ds.Namespace, ds.Name); err != nil {
+		return fmt.Errorf("failed waiting for debug daemonset to roll out: %w", err)
+	}
+
+	return nil
+}
+
+// Applies the daemonset manifest, after applying each of the mutators to its pod template
+func applyDaemonSetManifest(ctx context.Context, kube *kubeclient, manifest string, podSpecMutators ...func(*corev1.PodSpec)) (*appsv1.DaemonSet, error) {
+	var ds appsv1.DaemonSet
 	if err := yaml.Unmarshal([]byte(manifest), &ds); err != nil {
-		return fmt.Errorf("failed to unmarshal debug daemonset manifest: %w", err)
+		return nil, fmt.Errorf("failed to unmarshal DaemonSet manifest: %w", err)
+	}
+	for _, mutate := range podSpecMutators {
+		mutate(&ds.Spec.Template.Spec)
 	}
 
 	desired := ds.DeepCopy()
@@ -50,25 +79,77 @@ func ensureDebugDaemonset(ctx context.Context, kube *kubeclient) error {
 	})
 
 	if err != nil {
-		return fmt.Errorf("failed to apply debug daemonset: %w", err)
+		return nil, fmt.Errorf("failed to apply DaemonSet manifest: %w", err)
 	}
 
+	return &ds, nil
+}
+
+// Deploys the scheduling smoke test deployment onto the node and waits until each of its replicas is running and ready on
+// it, such that the node is known to schedule and run workloads, rather than only to report itself ready. Returns the
+// deployment's name
+func ensureSmokeTestDeployment(ctx context.Context, kube *kubeclient, namespace, nodeName string) (string, error) {
+	var deployment appsv1.Deployment
+	if err := yaml.Unmarshal([]byte(getSmokeTestDeploymentTemplate(namespace, nodeName, smokeTestDeploymentReplicas)), &deployment); err != nil {
+		return "", fmt.Errorf("failed to unmarshal smoke test deployment manifest: %w", err)
+	}
+
+	desired := deployment.DeepCopy()
+	if _, err := controllerutil.CreateOrUpdate(ctx, kube.dynamic, &deployment, func() error {
+		deployment.Spec = desired.Spec
+		return nil
+	}); err != nil {
+		return "", fmt.Errorf("failed to apply smoke test deployment: %w", err)
+	}
+
+	if err := waitUntilDeploymentRolledOut(ctx, kube, namespace, deployment.Name); err != nil {
+		return "", err
+	}
+
+	pods, err := kube.typed.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(deployment.Spec.Selector.MatchLabels).String(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list pods of smoke test deployment %s/%s: %w", namespace, deployment.Name, err)
+	}
+	var running int32
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		if pod.Spec.NodeName != nodeName {
+			return "", fmt.Errorf("pod %s/%s of smoke test deployment was scheduled onto node %q rather than %q", namespace, pod.Name, pod.Spec.NodeName, nodeName)
+		}
+		running++
+	}
+	if running != smokeTestDeploymentReplicas {
+		return "", fmt.Errorf("expected %d running pods of smoke test deployment %s/%s on node %q, but found %d", smokeTestDeploymentReplicas, namespace, deployment.Name, nodeName, running)
+	}
+	return deployment.Name, nil
+}
+
+func deleteSmokeTestDeployment(ctx context.Context, kube *kubeclient, namespace, name string) error {
+	propagation := metav1.DeletePropagationBackground
+	err := kube.typed.AppsV1().Deployments(namespace).Delete(ctx, name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete smoke test deployment %s/%s: %w", namespace, name, err)
+	}
 	return nil
 }
 
-func ensureTestNginxPod(ctx context.Context, kube *kubeclient, nodeName string) (string, error) {
-	nginxPodName := fmt.Sprintf("%s-nginx", nodeName)
-	nginxPodManifest := getNginxPodTemplate(nodeName)
-	if err := ensurePod(ctx, kube, nginxPodName, nginxPodManifest); err != nil {
-		return "", fmt.Errorf("failed to ensure test nginx pod %q: %w", nginxPodName, err)
+func ensureNodeDebugPod(ctx context.Context, kube *kubeclient, namespace, nodeName string) (string, error) {
+	debugPodName := fmt.Sprintf("%s-debug", nodeName)
+	debugPodManifest := getNodeDebugPodTemplate(namespace, nodeName)
+	if err := ensurePod(ctx, kube, namespace, debugPodName, debugPodManifest); err != nil {
+		return "", fmt.Errorf("failed to ensure node debug pod %q: %w", debugPodName, err)
 	}
-	return nginxPodName, nil
+	return debugPodName, nil
 }
 
-func ensureWasmPods(ctx context.Context, kube *kubeclient, nodeName string) (string, error) {
+func ensureWasmPods(ctx context.Context, kube *kubeclient, namespace, nodeName string) (string, error) {
 	spinPodName := fmt.Sprintf("%s-wasm-spin", nodeName)
-	spinPodManifest := getWasmSpinPodTemplate(nodeName)
-	if err := ensurePod(ctx, kube, spinPodName, spinPodManifest); err != nil {
+	spinPodManifest := getWasmSpinPodTemplate(namespace, nodeName)
+	if err := ensurePod(ctx, kube, namespace, spinPodName, spinPodManifest); err != nil {
 		return "", fmt.Errorf("failed to ensure wasm spin pod %q: %w", spinPodName, err)
 	}
 	return spinPodName, nil
@@ -93,11 +174,11 @@ func applyPodManifest(ctx context.Context, kube *kubeclient, manifest string) er
 	return nil
 }
 
-func ensurePod(ctx context.Context, kube *kubeclient, podName, manifest string) error {
+func ensurePod(ctx context.Context, kube *kubeclient, namespace, podName, manifest string) error {
 	if err := applyPodManifest(ctx, kube, manifest); err != nil {
 		return fmt.Errorf("failed to ensure pod: %w", err)
 	}
-	if err := waitUntilPodRunning(ctx, kube, podName); err != nil {
+	if err := waitUntilPodRunning(ctx, kube, namespace, podName); err != nil {
 		return fmt.Errorf("failed to wait for pod to be in running state: %w", err)
 	}
 	return nil
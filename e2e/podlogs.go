@@ -0,0 +1,82 @@
+package e2e_test
+
+import (
+	"bufio"
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// the interval at which streaming a container's logs is retried until the container has started
+	podLogStreamRetryInterval = 5 * time.Second
+
+	// the longest log line streamed, beyond which a container's stream ends
+	podLogMaxLineBytes = 1024 * 1024
+
+	podLogLinePrefixTemplate = "[%s/%s/%s] %s"
+)
+
+// Streams the logs of the pod's containers into the suite's log as they're written, each line prefixed by the pod's namespace,
+// name and container, such that long-running operations within the pod, e.g. a validation script, are observable while they
+// run. Every container of the pod is streamed when none are specified. Streaming waits for each container to start, and ends
+// once the container terminates or the returned function is called, which waits for each stream to end
+func (k *kubeclient) streamPodLogs(ctx context.Context, namespace, podName string, containers ...string) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	stop := func() {
+		cancel()
+		wg.Wait()
+	}
+
+	if len(containers) == 0 {
+		pod, err := k.typed.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			log.Printf("unable to stream logs of pod %s/%s, failed to get its containers: %s", namespace, podName, err)
+			return stop
+		}
+		for _, container := range pod.Spec.Containers {
+			containers = append(containers, container.Name)
+		}
+	}
+
+	for _, container := range containers {
+		container := container
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			k.streamContainerLogs(ctx, namespace, podName, container)
+		}()
+	}
+	return stop
+}
+
+// Streams the logs of the pod's container until the container terminates or the context is done, retrying until the
+// container has started. Streams aren't resumed once they've started, so as not to log their lines more than once
+func (k *kubeclient) streamContainerLogs(ctx context.Context, namespace, podName, container string) {
+	for {
+		stream, err := k.typed.CoreV1().Pods(namespace).GetLogs(podName, &corev1.PodLogOptions{Container: container, Follow: true}).Stream(ctx)
+		if err == nil {
+			defer stream.Close()
+			scanner := bufio.NewScanner(stream)
+			scanner.Buffer(make([]byte, 0, 64*1024), podLogMaxLineBytes)
+			for scanner.Scan() {
+				log.Printf(podLogLinePrefixTemplate, namespace, podName, container, scanner.Text())
+			}
+			if err := scanner.Err(); err != nil && ctx.Err() == nil {
+				log.Printf("stopped streaming logs of container %q of pod %s/%s: %s", container, namespace, podName, err)
+			}
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(podLogStreamRetryInterval):
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package e2e_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// the labels through which Pod Security Admission is configured per namespace, overriding the cluster's defaults
+	podSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+	podSecurityAuditLabel   = "pod-security.kubernetes.io/audit"
+	podSecurityWarnLabel    = "pod-security.kubernetes.io/warn"
+
+	// the only level of the Pod Security Standards admitting the suite's debug pods, which are privileged and share the
+	// namespaces of their nodes
+	podSecurityLevelPrivileged = "privileged"
+)
+
+// Returns the labels exempting a namespace's pods from whichever Pod Security Standard the cluster enforces by default
+func privilegedPodSecurityLabels() map[string]string {
+	return map[string]string{
+		podSecurityEnforceLabel: podSecurityLevelPrivileged,
+		podSecurityAuditLabel:   podSecurityLevelPrivileged,
+		podSecurityWarnLabel:    podSecurityLevelPrivileged,
+	}
+}
+
+// Ensures Pod Security Admission admits the privileged pods the suite creates within the namespace, e.g. its debug daemonset,
+// which need access to their nodes so can't comply with the baseline or restricted standards. When label is set, the namespace
+// is labeled as privileged unless it already is. Otherwise, the namespace is left as is, and an error is returned if it
+// enforces a stricter standard, since pods created within it would be rejected
+func ensurePrivilegedPodSecurity(ctx context.Context, kube *kubeclient, namespace string, label bool) error {
+	ns, err := kube.typed.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get namespace %q: %w", namespace, err)
+	}
+	if isPrivilegedPodSecurity(ns) {
+		return nil
+	}
+
+	if !label {
+		if level, ok := ns.Labels[podSecurityEnforceLabel]; ok && level != podSecurityLevelPrivileged {
+			return fmt.Errorf("namespace %q enforces the %q pod security standard, which rejects the suite's privileged pods, "+
+				"label it as %s=%s, or leave POD_SECURITY_LABELS unset such that the suite labels it", namespace, level, podSecurityEnforceLabel, podSecurityLevelPrivileged)
+		}
+		return nil
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"labels": privilegedPodSecurityLabels(),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to marshal pod security labels patch: %w", err)
+	}
+	if _, err := kube.typed.CoreV1().Namespaces().Patch(ctx, namespace, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("failed to label namespace %q as %s=%s: %w", namespace, podSecurityEnforceLabel, podSecurityLevelPrivileged, err)
+	}
+	log.Printf("labeled namespace %q as %s=%s", namespace, podSecurityEnforceLabel, podSecurityLevelPrivileged)
+	return nil
+}
+
+// Returns whether the namespace's labels already admit privileged pods, and don't audit or warn about them
+func isPrivilegedPodSecurity(ns *corev1.Namespace) bool {
+	for label, level := range privilegedPodSecurityLabels() {
+		if ns.Labels[label] != level {
+			return false
+		}
+	}
+	return true
+}
@@ -4,34 +4,43 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 )
 
 const (
 	// Polling intervals
-	execOnVMPollInterval                    = 10 * time.Second
-	execOnPodPollInterval                   = 10 * time.Second
-	extractClusterParametersPollInterval    = 10 * time.Second
-	extractVMLogsPollInterval               = 10 * time.Second
-	getVMPrivateIPAddressPollInterval       = 5 * time.Second
-	waitUntilPodRunningPollInterval         = 5 * time.Second
-	waitUntilPodDeletedPollInterval         = 5 * time.Second
-	waitUntilClusterNotCreatingPollInterval = 10 * time.Second
+	execOnVMPollInterval                          = 10 * time.Second
+	execOnPodPollInterval                         = 10 * time.Second
+	extractClusterParametersPollInterval          = 10 * time.Second
+	extractVMLogsPollInterval                     = 10 * time.Second
+	getVMPrivateIPAddressPollInterval             = 5 * time.Second
+	waitUntilPodDeletedPollInterval               = 5 * time.Second
+	waitUntilClusterNotCreatingPollInterval       = 10 * time.Second
+	deleteVMSSPollInterval                        = 15 * time.Second
+	waitUntilSpotVMEvictedPollInterval            = 10 * time.Second
+	waitUntilKubeletServingCertIssuedPollInterval = 5 * time.Second
+	drainNodePollInterval                         = 5 * time.Second
+	validateServiceConnectivityPollInterval       = 5 * time.Second
 
 	// Polling timeouts
-	execOnVMPollingTimeout                 = 3 * time.Minute
-	execOnPodPollingTimeout                = 2 * time.Minute
-	extractClusterParametersPollingTimeout = 3 * time.Minute
-	extractVMLogsPollingTimeout            = 5 * time.Minute
-	getVMPrivateIPAddressPollingTimeout    = 1 * time.Minute
-	waitUntilPodRunningPollingTimeout      = 3 * time.Minute
-	waitUntilPodDeletedPollingTimeout      = 1 * time.Minute
+	execOnVMPollingTimeout                          = 3 * time.Minute
+	execOnPodPollingTimeout                         = podExecTimeout
+	extractClusterParametersPollingTimeout          = 3 * time.Minute
+	extractVMLogsPollingTimeout                     = 5 * time.Minute
+	getVMPrivateIPAddressPollingTimeout             = 1 * time.Minute
+	waitUntilPodDeletedPollingTimeout               = 1 * time.Minute
+	deleteVMSSPollingTimeout                        = 15 * time.Minute
+	waitUntilSpotVMEvictedPollingTimeout            = 10 * time.Minute
+	waitUntilKubeletServingCertIssuedPollingTimeout = 3 * time.Minute
+	drainNodePollingTimeout                         = 5 * time.Minute
+	validateServiceConnectivityPollingTimeout       = 2 * time.Minute
+
+	// the time allowed for a scenario's Cleanup to delete its resources
+	scenarioCleanupTimeout = 10 * time.Minute
 )
 
 func pollExecOnVM(ctx context.Context, kube *kubeclient, vmPrivateIP, jumpboxPodName string, sshPrivateKey, command string, isShellBuiltIn bool) (*podExecResult, error) {
@@ -40,11 +49,6 @@ func pollExecOnVM(ctx context.Context, kube *kubeclient, vmPrivateIP, jumpboxPod
 		res, err := execOnVM(ctx, kube, vmPrivateIP, jumpboxPodName, sshPrivateKey, command, isShellBuiltIn)
 		if err != nil {
 			log.Printf("unable to execute command on VM: %s", err)
-
-			// fail hard on non-retriable error
-			if strings.Contains(err.Error(), "error extracting exit code") {
-				return false, err
-			}
 			return false, nil
 		}
 
@@ -64,15 +68,40 @@ func pollExecOnVM(ctx context.Context, kube *kubeclient, vmPrivateIP, jumpboxPod
 	return execResult, nil
 }
 
+// Wraps execOnInstance in a poller, retrying commands executed over SSH upon transient SSH failures
+func pollExecOnInstance(ctx context.Context, vmssName, vmPrivateIP, jumpboxPodName, sshPrivateKey, command string, isShellBuiltIn bool, opts *scenarioRunOpts) (*podExecResult, error) {
+	if !isSSHDisabled(opts) {
+		return pollExecOnVM(ctx, opts.clusterConfig.kube, vmPrivateIP, jumpboxPodName, sshPrivateKey, command, isShellBuiltIn)
+	}
+	if opts.nodeDebugPodName != "" {
+		return pollExecOnPrivilegedPod(ctx, opts.clusterConfig.kube, opts.namespace, opts.nodeDebugPodName, command)
+	}
+	return runCommandOnVM(ctx, vmssName, command, opts)
+}
+
 func pollExecOnPod(ctx context.Context, kube *kubeclient, namespace, podName, command string) (*podExecResult, error) {
+	return pollExecOnPodCommand(ctx, kube, namespace, podName, append(bashCommandArray(), command))
+}
+
+func pollExecOnPrivilegedPod(ctx context.Context, kube *kubeclient, namespace, podName, command string) (*podExecResult, error) {
+	return pollExecOnPodCommand(ctx, kube, namespace, podName, append(nsenterCommandArray(), command))
+}
+
+// Retries executing the command within the pod until the exec succeeds, e.g. once the pod's container has started, failing
+// fast on errors which aren't transient. Each exec is attempted once, and the execs, including that of the command itself,
+// share the poll's timeout
+func pollExecOnPodCommand(ctx context.Context, kube *kubeclient, namespace, podName string, command []string) (*podExecResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, execOnPodPollingTimeout)
+	defer cancel()
+
 	var execResult *podExecResult
 	err := wait.PollImmediateWithContext(ctx, execOnPodPollInterval, execOnPodPollingTimeout, func(ctx context.Context) (bool, error) {
-		res, err := execOnPod(ctx, kube, namespace, podName, append(bashCommandArray(), command))
+		res, err := kube.execOnPodOnce(ctx, namespace, podName, command)
 		if err != nil {
-			log.Printf("unable to execute command on pod: %s", err)
+			log.Printf("unable to execute command on pod %s/%s: %s", namespace, podName, err)
 
 			// fail hard on non-retriable error
-			if strings.Contains(err.Error(), "error extracting exit code") {
+			if !isTransientPodExecError(err) && !isPodNotStartedExecError(err) {
 				return false, err
 			}
 			return false, nil
@@ -109,7 +138,7 @@ func pollExtractClusterParameters(ctx context.Context, kube *kubeclient) (map[st
 	return clusterParams, nil
 }
 
-// Wraps exctracLogsFromVM and dumpFileMapToDir in a poller with a 15-second wait interval and 5-minute timeout
+// Wraps exctracLogsFromVM and writing the extracted logs into the scenario's artifacts directory in a poller with a 15-second wait interval and 5-minute timeout
 func pollExtractVMLogs(ctx context.Context, vmssName, privateIP string, privateKeyBytes []byte, opts *scenarioRunOpts) error {
 	err := wait.PollImmediateWithContext(ctx, extractVMLogsPollInterval, extractVMLogsPollingTimeout, func(ctx context.Context) (bool, error) {
 		log.Println("attempting to extract VM logs")
@@ -120,8 +149,8 @@ func pollExtractVMLogs(ctx context.Context, vmssName, privateIP string, privateK
 			return false, nil
 		}
 
-		log.Printf("dumping VM logs to local directory: %s", opts.loggingDir)
-		if err = dumpFileMapToDir(opts.loggingDir, logFiles); err != nil {
+		log.Printf("dumping VM logs to artifacts directory: %s", opts.artifacts.path)
+		if err = opts.artifacts.writeFiles(logFiles); err != nil {
 			log.Printf("error extracting VM logs: %q", err)
 			return false, nil
 		}
@@ -139,7 +168,7 @@ func pollExtractVMLogs(ctx context.Context, vmssName, privateIP string, privateK
 func pollGetVMPrivateIP(ctx context.Context, vmssName string, opts *scenarioRunOpts) (string, error) {
 	var vmPrivateIP string
 	err := wait.PollImmediateWithContext(ctx, getVMPrivateIPAddressPollInterval, getVMPrivateIPAddressPollingTimeout, func(ctx context.Context) (bool, error) {
-		pip, err := getVMPrivateIPAddress(ctx, opts.cloud, opts.suiteConfig.subscription, *opts.clusterConfig.cluster.Properties.NodeResourceGroup, vmssName)
+		pip, err := getVMPrivateIPAddress(ctx, opts.cloud, opts.suiteConfig.subscription, *opts.clusterConfig.cluster.Properties.NodeResourceGroup, vmssName, opts.instance.instanceID)
 		if err != nil {
 			log.Printf("encountered an error while getting VM private IP address: %s", err)
 			return false, nil
@@ -182,49 +211,21 @@ func waitForClusterCreation(ctx context.Context, cloud *azureClient, resourceGro
 	return cluster, nil
 }
 
-func waitUntilNodeReady(ctx context.Context, kube *kubeclient, vmssName string) (string, error) {
-	var nodeName string
-	err := wait.PollImmediateWithContext(ctx, 5*time.Second, 5*time.Minute, func(ctx context.Context) (bool, error) {
-		nodes, err := kube.typed.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
-		if err != nil {
-			return false, err
-		}
-
-		for _, node := range nodes.Items {
-			if strings.HasPrefix(node.Name, vmssName) {
-				for _, cond := range node.Status.Conditions {
-					if cond.Type == corev1.NodeReady && cond.Status == corev1.ConditionTrue {
-						nodeName = node.Name
-						return true, nil
-					}
-				}
-			}
-		}
-
-		return false, nil
+func waitUntilPodDeleted(ctx context.Context, kube *kubeclient, namespace, podName string) error {
+	return wait.PollImmediateWithContext(ctx, waitUntilPodDeletedPollInterval, waitUntilPodDeletedPollingTimeout, func(ctx context.Context) (bool, error) {
+		err := kube.typed.CoreV1().Pods(namespace).Delete(ctx, podName, metav1.DeleteOptions{})
+		return err == nil, err
 	})
-
-	if err != nil {
-		return "", fmt.Errorf("failed to find or wait for node to be ready: %w", err)
-	}
-
-	return nodeName, nil
 }
 
-func waitUntilPodRunning(ctx context.Context, kube *kubeclient, podName string) error {
-	return wait.PollImmediateWithContext(ctx, waitUntilPodRunningPollInterval, waitUntilPodRunningPollingTimeout, func(ctx context.Context) (bool, error) {
-		pod, err := kube.typed.CoreV1().Pods(defaultNamespace).Get(ctx, podName, metav1.GetOptions{})
+func waitUntilSpotVMEvicted(ctx context.Context, vmssName string, opts *scenarioRunOpts) error {
+	mcResourceGroupName := *opts.clusterConfig.cluster.Properties.NodeResourceGroup
+	return wait.PollImmediateWithContext(ctx, waitUntilSpotVMEvictedPollInterval, waitUntilSpotVMEvictedPollingTimeout, func(ctx context.Context) (bool, error) {
+		evicted, err := isSpotInstanceEvicted(ctx, mcResourceGroupName, vmssName, opts.instance.instanceID, opts)
 		if err != nil {
-			return false, err
+			log.Printf("encountered an error while checking whether instance %q of vmss %q was evicted: %s", opts.instance.instanceID, vmssName, err)
+			return false, nil
 		}
-
-		return pod.Status.Phase == corev1.PodPhase("Running"), nil
-	})
-}
-
-func waitUntilPodDeleted(ctx context.Context, kube *kubeclient, podName string) error {
-	return wait.PollImmediateWithContext(ctx, waitUntilPodDeletedPollInterval, waitUntilPodDeletedPollingTimeout, func(ctx context.Context) (bool, error) {
-		err := kube.typed.CoreV1().Pods(defaultNamespace).Delete(ctx, podName, metav1.DeleteOptions{})
-		return err == nil, err
+		return evicted, nil
 	})
 }
@@ -0,0 +1,113 @@
+package e2e_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// A local port forwarded to a port of a pod, through which endpoints only reachable from within the pod, e.g. those of a
+// hostNetwork pod's node bound to its loopback address, can be reached from the suite. The forward lasts until it's closed,
+// or the context it was created with is done
+type portForward struct {
+	namespace, podName string
+	localPort          uint16
+	remotePort         uint16
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+// Forwards a local port, chosen by the OS, to the specified port of the pod, returning once the forward is ready to accept
+// connections. Connections are made within the pod's network namespace, such that a hostNetwork pod's port is that of its node
+func (k *kubeclient) portForward(ctx context.Context, namespace, podName string, remotePort uint16) (*portForward, error) {
+	roundTripper, upgrader, err := spdy.RoundTripperFor(k.rest)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create round tripper for port forward: %w", err)
+	}
+	req := k.typed.CoreV1().RESTClient().Post().Resource("pods").Name(podName).Namespace(namespace).SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: roundTripper}, "POST", req.URL())
+
+	forward := &portForward{
+		namespace:  namespace,
+		podName:    podName,
+		remotePort: remotePort,
+		stop:       make(chan struct{}),
+	}
+	ready := make(chan struct{})
+	var errOut bytes.Buffer
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"}, []string{fmt.Sprintf("0:%d", remotePort)}, forward.stop, ready, io.Discard, &errOut)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create port forward to port %d of pod %s/%s: %w", remotePort, namespace, podName, err)
+	}
+
+	forwarded := make(chan error, 1)
+	go func() {
+		forwarded <- forwarder.ForwardPorts()
+	}()
+	select {
+	case <-ready:
+	case err := <-forwarded:
+		return nil, fmt.Errorf("failed to forward port %d of pod %s/%s: %w, stderr: %q", remotePort, namespace, podName, err, errOut.String())
+	case <-ctx.Done():
+		forward.close()
+		return nil, fmt.Errorf("timed out forwarding port %d of pod %s/%s: %w", remotePort, namespace, podName, ctx.Err())
+	}
+
+	ports, err := forwarder.GetPorts()
+	if err != nil || len(ports) != 1 {
+		forward.close()
+		return nil, fmt.Errorf("unable to determine the local port forwarded to port %d of pod %s/%s: %v", remotePort, namespace, podName, err)
+	}
+	forward.localPort = ports[0].Local
+	log.Printf("forwarding local port %d to port %d of pod %s/%s", forward.localPort, remotePort, namespace, podName)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			forward.close()
+		case <-forward.stop:
+		}
+	}()
+	return forward, nil
+}
+
+// Returns the local address forwarded to the pod's port, e.g. "127.0.0.1:40123"
+func (f *portForward) address() string {
+	return fmt.Sprintf("127.0.0.1:%d", f.localPort)
+}
+
+// Stops forwarding the port, which is safe to call more than once
+func (f *portForward) close() {
+	f.closeOnce.Do(func() {
+		close(f.stop)
+	})
+}
+
+// Sends a GET request for the specified path to the forwarded port over plain HTTP, returning the response's status code and body
+func (f *portForward) get(ctx context.Context, path string) (int, string, error) {
+	url := fmt.Sprintf("http://%s/%s", f.address(), strings.TrimPrefix(path, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, "", fmt.Errorf("unable to create request for %s: %w", url, err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to get %s forwarded to port %d of pod %s/%s: %w", path, f.remotePort, f.namespace, f.podName, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to read response to %s forwarded to port %d of pod %s/%s: %w", path, f.remotePort, f.namespace, f.podName, err)
+	}
+	return resp.StatusCode, string(body), nil
+}
@@ -0,0 +1,103 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// the squid pod, and its config map, shared by every scenario whose nodes egress through the suite's HTTP proxy
+	httpProxyName = "http-proxy"
+	httpProxyPort = 3128
+
+	// the image of the HTTP proxy pod, overridden by HTTP_PROXY_IMAGE, which is pulled from MCR rather than Docker Hub, so isn't
+	// rate-limited. squid is installed from the distro's package repository unless the image provides it
+	defaultHTTPProxyImage = "mcr.microsoft.com/cbl-mariner/base/core:2.0"
+
+	// run by the proxy pod through a shell, installing squid when it isn't on the image's PATH, then running it in the
+	// foreground such that its access log is written to the pod's logs
+	httpProxyCommand = "command -v squid >/dev/null || tdnf install -y -q squid >/dev/null 2>&1; exec squid -N -f /etc/squid/squid.conf"
+)
+
+// serializes the creation of the HTTP proxy by concurrently-running scenarios
+var httpProxyMu sync.Mutex
+
+// Ensures the suite's HTTP proxy, running the specified image, is ready to serve requests within the cluster, returning the
+// URL through which nodes within the cluster's VNet can reach it
+func ensureHTTPProxy(ctx context.Context, kube *kubeclient, image string) (string, error) {
+	httpProxyMu.Lock()
+	defer httpProxyMu.Unlock()
+
+	log.Printf("ensuring HTTP proxy pod %q...", httpProxyName)
+
+	var configMap corev1.ConfigMap
+	if err := yaml.Unmarshal([]byte(getHTTPProxyConfigMapTemplate()), &configMap); err != nil {
+		return "", fmt.Errorf("failed to unmarshal HTTP proxy ConfigMap manifest: %w", err)
+	}
+	desired := configMap.DeepCopy()
+	if _, err := controllerutil.CreateOrUpdate(ctx, kube.dynamic, &configMap, func() error {
+		configMap.Data = desired.Data
+		return nil
+	}); err != nil {
+		return "", fmt.Errorf("failed to apply HTTP proxy ConfigMap manifest: %w", err)
+	}
+
+	if err := applyPodManifest(ctx, kube, getHTTPProxyPodTemplate(image)); err != nil {
+		return "", fmt.Errorf("failed to ensure HTTP proxy pod: %w", err)
+	}
+	// squid may still be being installed once the pod is running, so it's only reachable once the pod is ready
+	if err := waitUntilPodReady(ctx, kube, defaultNamespace, httpProxyName); err != nil {
+		return "", fmt.Errorf("failed to wait for HTTP proxy pod to be ready: %w", err)
+	}
+
+	podIP, err := getPodIP(ctx, kube, defaultNamespace, httpProxyName)
+	if err != nil {
+		return "", err
+	}
+	if podIP == "" {
+		return "", fmt.Errorf("HTTP proxy pod %q has yet to be assigned an IP", httpProxyName)
+	}
+
+	return fmt.Sprintf("http://%s:%d/", podIP, httpProxyPort), nil
+}
+
+// Configures the bootstrap config such that the node egresses through the HTTP proxy at the specified URL, retaining
+// the template's no-proxy list such that the node still reaches the apiserver, IMDS and wireserver directly
+func useHTTPProxy(nbc *datamodel.NodeBootstrappingConfiguration, proxyURL string) {
+	if nbc.HTTPProxyConfig == nil {
+		nbc.HTTPProxyConfig = &datamodel.HTTPProxyConfig{}
+	}
+	nbc.HTTPProxyConfig.HTTPProxy = &proxyURL
+	nbc.HTTPProxyConfig.HTTPSProxy = &proxyURL
+}
+
+// Validates that the node's requests were served by the HTTP proxy, i.e. that the proxy's access log, to which squid
+// writes one line per request of the form "<timestamp> <elapsed> <client IP> <code>/<status> <bytes> <method> <URL> ...",
+// contains at least one request from the node's private IP
+func validateHTTPProxyRequests(ctx context.Context, kube *kubeclient, vmPrivateIP string) error {
+	logs, err := kube.typed.CoreV1().Pods(defaultNamespace).GetLogs(httpProxyName, &corev1.PodLogOptions{}).DoRaw(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get logs of HTTP proxy pod %q: %w", httpProxyName, err)
+	}
+
+	var requests []string
+	for _, line := range strings.Split(string(logs), "\n") {
+		if fields := strings.Fields(line); len(fields) >= 7 && fields[2] == vmPrivateIP {
+			requests = append(requests, fmt.Sprintf("%s %s %s", fields[5], fields[6], fields[3]))
+		}
+	}
+	if len(requests) == 0 {
+		return fmt.Errorf("expected HTTP proxy pod %q to have served requests from %s, but found none within its access log", httpProxyName, vmPrivateIP)
+	}
+
+	log.Printf("HTTP proxy served %d requests from %s, e.g. %s", len(requests), vmPrivateIP, requests[0])
+	return nil
+}
@@ -1,6 +1,11 @@
 package e2e_test
 
-import mrand "math/rand"
+import (
+	crand "crypto/rand"
+	"hash/fnv"
+	"math/big"
+	mrand "math/rand"
+)
 
 const safeLowerBytes = "abcdefghijklmnopqrstuvwxyz0123456789"
 
@@ -11,3 +16,26 @@ func randomLowercaseString(r *mrand.Rand, n int) string {
 	}
 	return string(b)
 }
+
+// Returns a random string of lowercase letters and digits read from crypto/rand, for secrets which mustn't be reproducible
+// from the suite's seed, which is logged
+func randomLowercaseSecret(n int) (string, error) {
+	b := make([]byte, n)
+	for i := range b {
+		index, err := crand.Int(crand.Reader, big.NewInt(int64(len(safeLowerBytes))))
+		if err != nil {
+			return "", err
+		}
+		b[i] = safeLowerBytes[index.Int64()]
+	}
+	return string(b), nil
+}
+
+// Returns the source of randomness of the named scenario, derived from the suite's seed. Each scenario has a source of its
+// own, such that the names it generates are reproduced by a run with the same seed regardless of the order in which
+// parallel scenarios are scheduled
+func newScenarioRand(seed int64, name string) *mrand.Rand {
+	hash := fnv.New64a()
+	hash.Write([]byte(name))
+	return mrand.New(mrand.NewSource(seed ^ int64(hash.Sum64())))
+}
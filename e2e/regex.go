@@ -3,6 +3,18 @@ package e2e_test
 import (
 	"fmt"
 	"regexp"
+	"strconv"
+)
+
+var (
+	// matches resource IDs of the form /subscriptions/SUB/resourceGroups/RG/providers/Microsoft.Compute/galleries/GALLERY/images/IMAGE/versions/VERSION
+	sigImageVersionIDRegex = regexp.MustCompile(`(?i)^/subscriptions/[^/]+/resourceGroups/[^/]+/providers/Microsoft\.Compute/galleries/[^/]+/images/[^/]+/versions/[^/]+$`)
+
+	// matches resource IDs of the form /subscriptions/SUB/resourceGroups/RG/providers/Microsoft.Compute/galleries/GALLERY/images/IMAGE
+	sigImageDefinitionIDRegex = regexp.MustCompile(`(?i)^/subscriptions/[^/]+/resourceGroups/[^/]+/providers/Microsoft\.Compute/galleries/[^/]+/images/[^/]+$`)
+
+	// matches resource IDs of the form /subscriptions/SUB/resourceGroups/RG/providers/Microsoft.Compute/images/IMAGE
+	managedImageIDRegex = regexp.MustCompile(`(?i)^/subscriptions/[^/]+/resourceGroups/[^/]+/providers/Microsoft\.Compute/images/[^/]+$`)
 )
 
 const (
@@ -15,8 +27,9 @@ const (
 	*/
 	keyValuePairRegexTemplate = `%s: (\"[^\"]*\"|[^\s]*)`
 
-	// this regex looks for groups in the form of "command terminated with exit code CODE", returning CODE as a submatch
-	errMsgExitCodeRegex = "command terminated with exit code (25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?)"
+	// this regex looks for groups in the form of "command terminated with exit status=CODE", as reported by the CustomScript
+	// extension when the CSE fails, returning CODE as a submatch
+	cseErrMsgExitStatusRegex = "command terminated with exit status=([0-9]+)"
 )
 
 func extractKeyValuePair(key string, data string) (string, error) {
@@ -36,18 +49,17 @@ func extractKeyValuePair(key string, data string) (string, error) {
 	return matches[0][1], nil
 }
 
-func extractExitCode(errMsg string) (string, error) {
-	r, err := regexp.Compile(errMsgExitCodeRegex)
+func extractCSEExitCode(errMsg string) (int, error) {
+	r, err := regexp.Compile(cseErrMsgExitStatusRegex)
 	if err != nil {
-		return "", fmt.Errorf("failed to compile regex: %w", err)
+		return 0, fmt.Errorf("failed to compile regex: %w", err)
 	}
 
-	matches := r.FindAllStringSubmatch(errMsg, -1)
+	matches := r.FindStringSubmatch(errMsg)
 
-	if len(matches) < 1 || len(matches[0]) < 2 {
-		return "", fmt.Errorf("expected 1 match with 1 submatch from regex, result %q", matches)
+	if len(matches) < 2 {
+		return 0, fmt.Errorf("expected 1 match with 1 submatch from regex, result %q", matches)
 	}
 
-	exitCode := matches[0][1]
-	return exitCode, nil
+	return strconv.Atoi(matches[1])
 }
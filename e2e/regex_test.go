@@ -0,0 +1,54 @@
+package e2e_test
+
+import (
+	"testing"
+)
+
+func TestExtractCSEExitCode(t *testing.T) {
+	cases := []struct {
+		name      string
+		errMsg    string
+		expected  int
+		expectErr bool
+	}{
+		{
+			name:     "CSE failure",
+			errMsg:   `Code="VMExtensionProvisioningError" Message="VM has reported a failure when processing extension 'vmssCSE'. Error message: "Enable failed: failed to execute command: command terminated with exit status=50\n[stdout]\n\n[stderr]\n"`,
+			expected: 50,
+		},
+		{
+			name:     "first exit status is reported",
+			errMsg:   "command terminated with exit status=124, command terminated with exit status=1",
+			expected: 124,
+		},
+		{
+			name:      "no exit status",
+			errMsg:    `Code="OperationNotAllowed" Message="Operation could not be completed as it results in exceeding approved quota."`,
+			expectErr: true,
+		},
+		{
+			name:      "exit status overflowing an int",
+			errMsg:    "command terminated with exit status=99999999999999999999",
+			expectErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			exitCode, err := extractCSEExitCode(c.errMsg)
+			if c.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error, but got exit code %d", exitCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, but got: %s", err)
+			}
+			if exitCode != c.expected {
+				t.Errorf("expected exit code %d, but got %d", c.expected, exitCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package e2e_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"testing"
+	"time"
+)
+
+const (
+	scenarioResultFileName = "result.json"
+
+	scenarioOutcomePassed  = "passed"
+	scenarioOutcomeFailed  = "failed"
+	scenarioOutcomeSkipped = "skipped"
+)
+
+// Summary of a scenario's run, written into the scenario's artifacts directory once the run has finished
+type scenarioResult struct {
+	Scenario    string            `json:"scenario"`
+	Description string            `json:"description"`
+	Outcome     string            `json:"outcome"`
+	Timeout     string            `json:"timeout"`
+	Duration    string            `json:"duration"`
+	MaxAttempts int               `json:"maxAttempts"`
+	Seed        int64             `json:"seed"`
+	Attempts    []scenarioAttempt `json:"attempts"`
+	Warnings    []scenarioWarning `json:"warnings,omitempty"`
+
+	// set when the scenario has a bootstrap latency SLO, holding the latency of each of its instances
+	BootstrapLatencies []bootstrapLatency `json:"bootstrapLatencies,omitempty"`
+
+	// set when the scenario probes its network performance, holding the measurements of each of its instances
+	NetworkPerf []networkPerf `json:"networkPerf,omitempty"`
+
+	// set when the scenario scrapes its kubelets' stats, holding the selected metrics and resource usage of each of its instances
+	KubeletStats []kubeletStats `json:"kubeletStats,omitempty"`
+
+	// set when the scenario failed and kernel failures were found within the dmesg output of any of its instances
+	KernelFailures []kernelFailure `json:"kernelFailures,omitempty"`
+}
+
+// A single attempt at creating and bootstrapping the scenario's VMSS, of which there are several when the scenario is
+// retried due to transient failures
+type scenarioAttempt struct {
+	VMSSName string `json:"vmssName"`
+	Error    string `json:"error,omitempty"`
+}
+
+// The failure of an advisory validator against one of the scenario's instances, which didn't fail the scenario
+type scenarioWarning struct {
+	InstanceID string `json:"instanceId"`
+	Warning    string `json:"warning"`
+}
+
+func (r *scenarioResult) recordWarning(instance vmssInstance, warning string) {
+	log.Printf("WARNING: advisory validator failed against instance %s: %s", instance.instanceID, warning)
+	r.Warnings = append(r.Warnings, scenarioWarning{InstanceID: instance.instanceID, Warning: warning})
+}
+
+func (r *scenarioResult) recordAttempt(vmssName string, err error) {
+	attempt := scenarioAttempt{VMSSName: vmssName}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	r.Attempts = append(r.Attempts, attempt)
+}
+
+// Returns the scenario's result, to which attempts are recorded during the run, having registered a cleanup function with
+// the scenario's test which writes the result into the scenario's artifacts directory once the test has finished, regardless
+// of whether it passed
+func recordScenarioResult(t *testing.T, opts *scenarioRunOpts) *scenarioResult {
+	start := time.Now()
+	result := &scenarioResult{
+		Scenario:    opts.scenario.Name,
+		Description: opts.scenario.Description,
+		Timeout:     opts.scenario.EffectiveTimeout().String(),
+		MaxAttempts: opts.scenario.EffectiveMaxAttempts(),
+		Seed:        opts.suiteConfig.seed,
+	}
+	t.Cleanup(func() {
+		result.Outcome = getScenarioOutcome(t)
+		result.Duration = time.Since(start).Round(time.Second).String()
+		if len(result.Warnings) > 0 {
+			t.Logf("scenario %q has %d warnings of advisory validators, see %s", opts.scenario.Name, len(result.Warnings), opts.artifacts.filePath(scenarioResultFileName))
+		}
+		if err := writeScenarioResult(opts.artifacts, result); err != nil {
+			t.Errorf("failed to write result of scenario %q: %s", opts.scenario.Name, err)
+		}
+	})
+	return result
+}
+
+func getScenarioOutcome(t *testing.T) string {
+	switch {
+	case t.Failed():
+		return scenarioOutcomeFailed
+	case t.Skipped():
+		return scenarioOutcomeSkipped
+	default:
+		return scenarioOutcomePassed
+	}
+}
+
+func writeScenarioResult(artifacts artifactsDir, result *scenarioResult) error {
+	contents, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal scenario result: %w", err)
+	}
+	_, err = artifacts.writeFile(scenarioResultFileName, string(contents))
+	return err
+}
@@ -0,0 +1,105 @@
+package e2e_test
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+const (
+	// the number of attempts made at each apiserver request failing with a transient error, and the delay before the first
+	// retry, which doubles with each subsequent retry up to its maximum
+	kubeRequestMaxAttempts     = 5
+	kubeRequestRetryInterval   = 1 * time.Second
+	kubeRequestMaxRetryBackoff = 8 * time.Second
+)
+
+// Wraps the transport of a kubeclient such that requests failing with transient errors, which busy or upgrading shared clusters
+// respond with, e.g. while etcd elects a new leader or the apiserver's instances are rolled, are retried with exponential backoff.
+// Requests which may have been processed by the apiserver are only retried when they're idempotent, while responses carrying a
+// Retry-After header are passed through, since client-go already retries them. Upgraded requests, e.g. of exec and port
+// forwarding, and requests whose body can't be replayed are never retried
+func retryTransientKubeErrors(rt http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("Upgrade") != "" || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+			return rt.RoundTrip(req)
+		}
+
+		backoff := kubeRequestRetryInterval
+		for attempt := 1; ; attempt++ {
+			attemptReq := req
+			if attempt > 1 && req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, fmt.Errorf("unable to replay body of %s %s: %w", req.Method, req.URL.Path, err)
+				}
+				attemptReq = req.Clone(req.Context())
+				attemptReq.Body = body
+			}
+
+			resp, err := rt.RoundTrip(attemptReq)
+			reason := transientKubeRequestFailure(req, resp, err)
+			if reason == "" || attempt >= kubeRequestMaxAttempts {
+				return resp, err
+			}
+			if resp != nil {
+				// drained such that the connection can be reused by the retry
+				_, _ = io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+
+			log.Printf("attempt %d of %d at %s %s failed with a transient error, will retry in %s: %s", attempt, kubeRequestMaxAttempts, req.Method, req.URL.Path, backoff, reason)
+			select {
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > kubeRequestMaxRetryBackoff {
+				backoff = kubeRequestMaxRetryBackoff
+			}
+		}
+	})
+}
+
+// Describes why the request failed transiently, such that it's likely to succeed if retried, or returns an empty string when
+// it didn't. Requests whose connection was refused, or which were rate limited, weren't processed by the apiserver, so are
+// retried regardless of their method
+func transientKubeRequestFailure(req *http.Request, resp *http.Response, err error) string {
+	if err != nil {
+		if req.Context().Err() != nil {
+			return ""
+		}
+		if utilnet.IsConnectionRefused(err) || (isIdempotentRequest(req) && (utilnet.IsConnectionReset(err) || utilnet.IsProbableEOF(err))) {
+			return err.Error()
+		}
+		return ""
+	}
+	if resp.Header.Get("Retry-After") != "" {
+		return ""
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return resp.Status
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		// e.g. "etcdserver: leader changed", or the apiserver being unreachable through its load balancer
+		if isIdempotentRequest(req) {
+			return resp.Status
+		}
+	}
+	return ""
+}
+
+// Returns whether the request can be repeated without changing its outcome, where patches are considered idempotent since the
+// suite's patches set fields to absolute values
+func isIdempotentRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, http.MethodPatch:
+		return true
+	default:
+		return false
+	}
+}
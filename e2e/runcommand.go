@@ -0,0 +1,102 @@
+package e2e_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+)
+
+const (
+	runShellScriptCommandID = "RunShellScript"
+
+	// appended to each script executed via run command, since the run command API doesn't report the script's exit code
+	runCommandExitCodeEchoTemplate = "%s\necho \"%s$?\""
+	runCommandExitCodePrefix       = "runcommand-exit-code="
+
+	runCommandStdoutMarker = "[stdout]\n"
+	runCommandStderrMarker = "[stderr]\n"
+)
+
+// Executes the command on the VMSS's VM through the compute run command API rather than SSH, for use in cases where the VM
+// can't be reached from the debug pod. Scripts executed via run command are run as root, so no sudo is needed.
+func runCommandOnVM(ctx context.Context, vmssName, command string, opts *scenarioRunOpts) (*podExecResult, error) {
+	script := fmt.Sprintf(runCommandExitCodeEchoTemplate, command, runCommandExitCodePrefix)
+
+	poller, err := opts.cloud.vmssVMClient.BeginRunCommand(
+		ctx,
+		*opts.clusterConfig.cluster.Properties.NodeResourceGroup,
+		vmssName,
+		opts.instance.instanceID,
+		armcompute.RunCommandInput{
+			CommandID: to.Ptr(runShellScriptCommandID),
+			Script:    []*string{to.Ptr(script)},
+		},
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin run command on vmss %q: %w", vmssName, err)
+	}
+
+	resp, err := poller.PollUntilDone(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for run command on vmss %q: %w", vmssName, err)
+	}
+
+	if len(resp.Value) < 1 || resp.Value[0] == nil || resp.Value[0].Message == nil {
+		return nil, fmt.Errorf("run command on vmss %q returned no output", vmssName)
+	}
+
+	return parseRunCommandMessage(*resp.Value[0].Message)
+}
+
+// Parses the message returned by the run command API for Linux VMs, which is of the form:
+// "Enable succeeded: \n[stdout]\n<stdout>\n[stderr]\n<stderr>"
+func parseRunCommandMessage(message string) (*podExecResult, error) {
+	var stdout, stderr string
+	stdoutIdx := strings.Index(message, runCommandStdoutMarker)
+	stderrIdx := strings.Index(message, runCommandStderrMarker)
+	if stdoutIdx < 0 || stderrIdx < stdoutIdx {
+		return nil, fmt.Errorf("unable to parse run command output:\n%s", message)
+	}
+	stdout = message[stdoutIdx+len(runCommandStdoutMarker) : stderrIdx]
+	stderr = message[stderrIdx+len(runCommandStderrMarker):]
+
+	exitCodeIdx := strings.LastIndex(stdout, runCommandExitCodePrefix)
+	if exitCodeIdx < 0 {
+		return nil, fmt.Errorf("unable to find exit code within run command output:\n%s", message)
+	}
+	exitCode := strings.TrimSpace(stdout[exitCodeIdx+len(runCommandExitCodePrefix):])
+	stdout = stdout[:exitCodeIdx]
+
+	return &podExecResult{
+		exitCode: exitCode,
+		stdout:   bytes.NewBufferString(stdout),
+		stderr:   bytes.NewBufferString(stderr),
+	}, nil
+}
+
+// Executes the command on the VM via the debug pod, falling back to the run command API when the VM can't be reached through the debug pod,
+// e.g. in cases where the node's kubelet never registered or the VM's network configuration is broken
+func execOnVMWithRunCommandFallback(ctx context.Context, vmssName, privateIP, jumpboxPodName, sshPrivateKey, command string, isShellBuiltIn bool, opts *scenarioRunOpts) (*podExecResult, error) {
+	if isSSHDisabled(opts) {
+		// the VM is never reachable over SSH, so there's nothing to fall back from
+		return pollExecOnInstance(ctx, vmssName, privateIP, jumpboxPodName, sshPrivateKey, command, isShellBuiltIn, opts)
+	}
+	execResult, err := pollExecOnVM(ctx, opts.clusterConfig.kube, privateIP, jumpboxPodName, sshPrivateKey, command, isShellBuiltIn)
+	if err == nil {
+		return execResult, nil
+	}
+
+	log.Printf("unable to execute command %q on VM through debug pod, falling back to run command: %s", command, err)
+	execResult, runCommandErr := runCommandOnVM(ctx, vmssName, command, opts)
+	if runCommandErr != nil {
+		return nil, fmt.Errorf("unable to execute command through either debug pod (%s) or run command: %w", err, runCommandErr)
+	}
+
+	return execResult, nil
+}
@@ -0,0 +1,75 @@
+package e2e_test
+
+import (
+	"testing"
+)
+
+func TestParseRunCommandMessage(t *testing.T) {
+	cases := []struct {
+		name      string
+		message   string
+		exitCode  string
+		stdout    string
+		stderr    string
+		expectErr bool
+	}{
+		{
+			name:     "succeeding script",
+			message:  "Enable succeeded: \n[stdout]\nhello\nruncommand-exit-code=0\n\n[stderr]\n",
+			exitCode: "0",
+			stdout:   "hello\n",
+		},
+		{
+			name:     "failing script",
+			message:  "Enable succeeded: \n[stdout]\nruncommand-exit-code=127\n\n[stderr]\n/bin/sh: 1: foo: not found\n",
+			exitCode: "127",
+			stderr:   "/bin/sh: 1: foo: not found\n",
+		},
+		{
+			name:     "exit code prefix echoed by the script itself",
+			message:  "Enable succeeded: \n[stdout]\nruncommand-exit-code=bogus\nruncommand-exit-code=1\n[stderr]\n",
+			exitCode: "1",
+			stdout:   "runcommand-exit-code=bogus\n",
+		},
+		{
+			name:      "no stdout marker",
+			message:   "Enable failed: VM agent is not ready",
+			expectErr: true,
+		},
+		{
+			name:      "stderr marker before stdout marker",
+			message:   "Enable succeeded: \n[stderr]\n\n[stdout]\nruncommand-exit-code=0\n",
+			expectErr: true,
+		},
+		{
+			name:      "no exit code",
+			message:   "Enable succeeded: \n[stdout]\nhello\n[stderr]\n",
+			expectErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			result, err := parseRunCommandMessage(c.message)
+			if c.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error, but got result with exit code %q", result.exitCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, but got: %s", err)
+			}
+			if result.exitCode != c.exitCode {
+				t.Errorf("expected exit code %q, but got %q", c.exitCode, result.exitCode)
+			}
+			if stdout := result.stdout.String(); stdout != c.stdout {
+				t.Errorf("expected stdout %q, but got %q", c.stdout, stdout)
+			}
+			if stderr := result.stderr.String(); stderr != c.stderr {
+				t.Errorf("expected stderr %q, but got %q", c.stderr, stderr)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package scenario
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// PrivateRegistryResolutionValidator asserts that the specified registry login server resolves only to the specified private IPs
+// of the registry's private endpoint from the node, i.e. that it resolves through the private DNS zone linked to the cluster's
+// VNet rather than to the registry's public IPs
+func PrivateRegistryResolutionValidator(loginServer string, endpointIPs []string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s resolves to its private endpoint", loginServer),
+		Command:     fmt.Sprintf("getent ahostsv4 %s", loginServer),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("unable to resolve %s, validator command terminated with exit code %q", loginServer, code)
+			}
+			// each line holds an address followed by its socket type and, on the first line of each address, its canonical name
+			var addresses []string
+			for _, line := range strings.Split(stdout, "\n") {
+				fields := strings.Fields(line)
+				if len(fields) == 0 {
+					continue
+				}
+				ip := net.ParseIP(fields[0])
+				if ip == nil {
+					return fmt.Errorf("unable to parse address %q resolved for %s", fields[0], loginServer)
+				}
+				if !containsIP(endpointIPs, ip) {
+					return fmt.Errorf("expected %s to resolve to its private endpoint's IPs %v, but it resolved to %s", loginServer, endpointIPs, ip)
+				}
+				addresses = append(addresses, fields[0])
+			}
+			if len(addresses) == 0 {
+				return fmt.Errorf("expected %s to resolve to its private endpoint's IPs %v, but it resolved to no addresses", loginServer, endpointIPs)
+			}
+			return nil
+		},
+	}
+}
+
+func containsIP(ips []string, ip net.IP) bool {
+	for _, candidate := range ips {
+		if ip.Equal(net.ParseIP(candidate)) {
+			return true
+		}
+	}
+	return false
+}
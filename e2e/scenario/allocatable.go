@@ -0,0 +1,159 @@
+package scenario
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	kubeletEvictionHardFlag   = "--eviction-hard"
+	kubeletKubeReservedFlag   = "--kube-reserved"
+	kubeletSystemReservedFlag = "--system-reserved"
+
+	memoryAvailableSignal = "memory.available"
+
+	// set by containerd.service's OOMScoreAdjust, and by kubelet onto itself through the default of its --oom-score-adj flag,
+	// such that both are amongst the last processes to be OOM killed
+	criticalDaemonOOMScoreAdj = -999
+)
+
+// NodeAllocatableMutator returns a BootstrapConfigMutator which overrides kubelet's hard eviction thresholds, e.g.
+// "memory.available<500Mi,nodefs.available<15%", and the resources reserved for kubernetes and system daemons, e.g.
+// "cpu=200m,memory=1024Mi". Empty values leave the template's flags unchanged
+func NodeAllocatableMutator(evictionHard, kubeReserved, systemReserved string) func(*datamodel.NodeBootstrappingConfiguration) {
+	return func(nbc *datamodel.NodeBootstrappingConfiguration) {
+		for flag, value := range nodeAllocatableFlags(evictionHard, kubeReserved, systemReserved) {
+			nbc.KubeletConfig[flag] = value
+		}
+	}
+}
+
+// NodeAllocatableValidators returns validators asserting that kubelet was started with the specified hard eviction thresholds
+// and reserved resources, as applied by NodeAllocatableMutator, and that the critical daemons kubelet and containerd were given
+// the OOM score adjustment AgentBaker expects them to run with
+func NodeAllocatableValidators(evictionHard, kubeReserved, systemReserved string) []*LiveVMValidator {
+	return []*LiveVMValidator{
+		KubeletCommandLineValidator(nodeAllocatableFlags(evictionHard, kubeReserved, systemReserved)),
+		OOMScoreAdjValidator("kubelet", criticalDaemonOOMScoreAdj),
+		OOMScoreAdjValidator("containerd", criticalDaemonOOMScoreAdj),
+	}
+}
+
+// NodeAllocatableKubeletConfigValidators returns validators asserting that the running kubelet's effective configuration holds
+// each of the specified hard eviction thresholds and reserved resources
+func NodeAllocatableKubeletConfigValidators(evictionHard, kubeReserved, systemReserved string) []*KubeletConfigValidator {
+	expected := map[string]any{}
+	for field, pairs := range map[string]map[string]string{
+		"evictionHard":   parseKubeletMapFlag(evictionHard, "<"),
+		"kubeReserved":   parseKubeletMapFlag(kubeReserved, "="),
+		"systemReserved": parseKubeletMapFlag(systemReserved, "="),
+	} {
+		for key, value := range pairs {
+			expected[field+kubeletConfigPathSeparator+key] = value
+		}
+	}
+	return []*KubeletConfigValidator{
+		KubeletConfigzValidator(expected),
+	}
+}
+
+// NodeAllocatableNodeValidators returns validators asserting that the node's allocatable memory is its memory capacity less
+// the memory reserved for kubernetes and system daemons and the hard eviction threshold of available memory. Thresholds
+// relative to the node's capacity aren't supported, in which case no validators are returned
+func NodeAllocatableNodeValidators(evictionHard, kubeReserved, systemReserved string) []*NodeValidator {
+	threshold := parseKubeletMapFlag(evictionHard, "<")[memoryAvailableSignal]
+	if strings.HasSuffix(threshold, "%") {
+		return nil
+	}
+	return []*NodeValidator{
+		NodeAllocatableMemoryValidator(
+			threshold,
+			parseKubeletMapFlag(kubeReserved, "=")[string(corev1.ResourceMemory)],
+			parseKubeletMapFlag(systemReserved, "=")[string(corev1.ResourceMemory)],
+		),
+	}
+}
+
+// NodeAllocatableMemoryValidator asserts that the node's allocatable memory is its memory capacity less the sum of each of the
+// specified quantities, e.g. "1024Mi". Empty quantities are ignored
+func NodeAllocatableMemoryValidator(reserved ...string) *NodeValidator {
+	return &NodeValidator{
+		Description: fmt.Sprintf("assert node allocatable memory excludes %s", strings.Join(reserved, ", ")),
+		Asserter: func(node *corev1.Node) error {
+			capacity, found := node.Status.Capacity[corev1.ResourceMemory]
+			if !found {
+				return fmt.Errorf("expected node %q to have a memory capacity, but its capacity doesn't include memory", node.Name)
+			}
+			expected := capacity.DeepCopy()
+			for _, quantity := range reserved {
+				if quantity == "" {
+					continue
+				}
+				parsed, err := resource.ParseQuantity(quantity)
+				if err != nil {
+					return fmt.Errorf("unable to parse reserved memory quantity %q: %w", quantity, err)
+				}
+				expected.Sub(parsed)
+			}
+			allocatable := node.Status.Allocatable[corev1.ResourceMemory]
+			if allocatable.Cmp(expected) != 0 {
+				return fmt.Errorf("expected node %q to have %s of allocatable memory, i.e. its %s capacity less %s, but had %s",
+					node.Name, expected.String(), capacity.String(), strings.Join(reserved, ", "), allocatable.String())
+			}
+			return nil
+		},
+	}
+}
+
+// OOMScoreAdjValidator asserts that the oldest process with the specified name runs with the specified OOM score adjustment
+func OOMScoreAdjValidator(process string, expected int) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s runs with oom_score_adj %d", process, expected),
+		Command:     fmt.Sprintf("cat /proc/$(pgrep -o -x %s)/oom_score_adj", process),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0, stderr: %q", code, stderr)
+			}
+			actual, err := strconv.Atoi(strings.TrimSpace(stdout))
+			if err != nil {
+				return fmt.Errorf("unable to parse oom_score_adj of %s: %w", process, err)
+			}
+			if actual != expected {
+				return fmt.Errorf("expected %s to run with oom_score_adj %d, but it ran with %d", process, expected, actual)
+			}
+			return nil
+		},
+	}
+}
+
+// Maps each of the non-empty node allocatable settings to the kubelet flag setting it
+func nodeAllocatableFlags(evictionHard, kubeReserved, systemReserved string) map[string]string {
+	flags := map[string]string{}
+	for flag, value := range map[string]string{
+		kubeletEvictionHardFlag:   evictionHard,
+		kubeletKubeReservedFlag:   kubeReserved,
+		kubeletSystemReservedFlag: systemReserved,
+	} {
+		if value != "" {
+			flags[flag] = value
+		}
+	}
+	return flags
+}
+
+// Parses the value of a kubelet flag holding a comma-separated list of key-value pairs, e.g. "cpu=100m,memory=1638Mi" or
+// "memory.available<750Mi,nodefs.available<10%", into a mapping from each key to its value
+func parseKubeletMapFlag(value, separator string) map[string]string {
+	pairs := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		if key, value, found := strings.Cut(strings.TrimSpace(pair), separator); found {
+			pairs[key] = value
+		}
+	}
+	return pairs
+}
@@ -0,0 +1,106 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	cgroupMountPath = "/sys/fs/cgroup"
+
+	// the filesystem type of the unified cgroupv2 hierarchy, as reported by stat
+	cgroupV2FilesystemType = "cgroup2fs"
+
+	kubeletCgroupDriverFlag = "--cgroup-driver"
+	systemdCgroupDriver     = "systemd"
+)
+
+// kubepodsSlices are the systemd slices kubelet's systemd cgroup driver creates for pods of each QoS class
+var kubepodsSlices = []string{"kubepods.slice", "kubepods-burstable.slice", "kubepods-besteffort.slice"}
+
+// CgroupV2Validators returns the validators asserting that a node bootstrapped from a cgroupv2 distro, e.g. Ubuntu 2204 or
+// AzureLinux V2, mounts the unified cgroup hierarchy and that kubelet and containerd both manage cgroups through systemd.
+// The containerd assertions are made against the runtime handler with the specified name, e.g. "runc"
+func CgroupV2Validators(runtimeHandler string) []*LiveVMValidator {
+	return []*LiveVMValidator{
+		CgroupFilesystemValidator(cgroupV2FilesystemType),
+		KubeletCommandLineValidator(map[string]string{kubeletCgroupDriverFlag: systemdCgroupDriver}),
+		SystemdSlicesValidator(kubepodsSlices),
+		SystemdUnitControlGroupValidator("containerd.service", "/system.slice/containerd.service"),
+		SystemdUnitControlGroupValidator("kubelet.service", "/system.slice/kubelet.service"),
+		ContainerdConfigValidator(ContainerdRuntimeSystemdCgroup(runtimeHandler)),
+	}
+}
+
+// CgroupFilesystemValidator asserts the type of the filesystem mounted at /sys/fs/cgroup, i.e. "cgroup2fs" on nodes using
+// the unified cgroupv2 hierarchy or "tmpfs" on nodes using cgroupv1
+func CgroupFilesystemValidator(filesystemType string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s is mounted as %s", cgroupMountPath, filesystemType),
+		Command:     fmt.Sprintf("stat -fc %%T %s", cgroupMountPath),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0", code)
+			}
+			if actual := strings.TrimSpace(stdout); actual != filesystemType {
+				return fmt.Errorf("expected %s to be mounted as %s, but was mounted as %q", cgroupMountPath, filesystemType, actual)
+			}
+			return nil
+		},
+	}
+}
+
+// SystemdSlicesValidator asserts that each of the specified systemd slices is active
+func SystemdSlicesValidator(slices []string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert systemd slices %s are active", strings.Join(slices, ", ")),
+		Command:     fmt.Sprintf("systemctl show -p Id,ActiveState %s", strings.Join(slices, " ")),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0", code)
+			}
+			activeStates := parseSystemdProperties(stdout, "ActiveState")
+			var inactive []string
+			for _, slice := range slices {
+				if state := activeStates[slice]; state != "active" {
+					inactive = append(inactive, fmt.Sprintf("%s (%s)", slice, state))
+				}
+			}
+			if len(inactive) > 0 {
+				return fmt.Errorf("expected systemd slices to be active, but the following weren't: %s", strings.Join(inactive, ", "))
+			}
+			return nil
+		},
+	}
+}
+
+// SystemdUnitControlGroupValidator asserts that the processes of the specified systemd unit run within the expected
+// cgroup, e.g. "/system.slice/containerd.service"
+func SystemdUnitControlGroupValidator(unit, expectedControlGroup string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s runs within cgroup %s", unit, expectedControlGroup),
+		Command:     fmt.Sprintf("systemctl show -p Id,ControlGroup %s", unit),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0", code)
+			}
+			if actual := parseSystemdProperties(stdout, "ControlGroup")[unit]; actual != expectedControlGroup {
+				return fmt.Errorf("expected %s to run within cgroup %s, but ran within %q", unit, expectedControlGroup, actual)
+			}
+			return nil
+		},
+	}
+}
+
+// Parses the output of "systemctl show -p Id,<property>" for one or more units, in which the properties of each unit are
+// separated by blank lines, mapping each unit's ID to the value of the specified property
+func parseSystemdProperties(stdout, property string) map[string]string {
+	values := map[string]string{}
+	for _, block := range strings.Split(strings.TrimSpace(stdout), "\n\n") {
+		properties := parseEnvironmentFile(block)
+		if id, ok := properties["Id"]; ok {
+			values[id] = properties[property]
+		}
+	}
+	return values
+}
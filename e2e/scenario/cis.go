@@ -0,0 +1,167 @@
+package scenario
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+const (
+	cisSysctlConfigPath  = "/etc/sysctl.d/60-CIS.conf"
+	cisRsyslogConfigPath = "/etc/rsyslog.d/60-CIS.conf"
+
+	rootOwnership = "root:root"
+)
+
+// FilePermissions is the most permissive mode, and the expected ownership, of a file checked by FilePermissionsValidator
+type FilePermissions struct {
+	// Path is the path of the file on the node
+	Path string
+
+	// MaxMode is the most permissive mode the file may have, e.g. 0644 also allows 0600 but not 0664
+	MaxMode uint32
+
+	// Owner is the expected "<user>:<group>" ownership of the file, or empty if its ownership isn't checked
+	Owner string
+}
+
+// cisFilePermissions are the files whose permissions are covered by the CIS Kubernetes worker node and distro benchmarks,
+// being written either when the VHD is hardened or by AgentBaker during bootstrapping
+var cisFilePermissions = []FilePermissions{
+	// CIS Kubernetes benchmark 4.1: worker node configuration files
+	{Path: "/etc/systemd/system/kubelet.service", MaxMode: 0644, Owner: rootOwnership},
+	{Path: kubeletDefaultsFilePath, MaxMode: 0644, Owner: rootOwnership},
+	{Path: kubeletConfigFilePath, MaxMode: 0644, Owner: rootOwnership},
+	{Path: "/var/lib/kubelet/kubeconfig", MaxMode: 0644, Owner: rootOwnership},
+	{Path: "/etc/kubernetes/certs/ca.crt", MaxMode: 0644, Owner: rootOwnership},
+	{Path: "/etc/kubernetes/azure.json", MaxMode: 0600, Owner: rootOwnership},
+	// CIS distro benchmarks: system files and cron
+	{Path: "/etc/passwd-", MaxMode: 0600, Owner: rootOwnership},
+	{Path: "/etc/shadow-", MaxMode: 0600},
+	{Path: "/etc/group-", MaxMode: 0600, Owner: rootOwnership},
+	{Path: "/etc/crontab", MaxMode: 0600, Owner: rootOwnership},
+	{Path: "/etc/cron.allow", MaxMode: 0640, Owner: rootOwnership},
+	{Path: cisSysctlConfigPath, MaxMode: 0644, Owner: rootOwnership},
+}
+
+// cisSysctls are a subset of the network parameters set by the VHD's CIS sysctl config, which must not be overridden by
+// AgentBaker's own sysctl config
+var cisSysctls = map[string]string{
+	"net.ipv4.conf.all.send_redirects":      "0",
+	"net.ipv4.conf.default.send_redirects":  "0",
+	"net.ipv4.conf.all.accept_redirects":    "0",
+	"net.ipv4.conf.all.secure_redirects":    "0",
+	"net.ipv4.conf.all.accept_source_route": "0",
+	"net.ipv4.conf.all.log_martians":        "1",
+	"net.ipv4.icmp_echo_ignore_broadcasts":  "1",
+	"net.ipv4.tcp_syncookies":               "1",
+}
+
+// cisDisabledKernelModules are the uncommon network protocols and filesystems the VHD's CIS modprobe config prevents
+// from being loaded
+var cisDisabledKernelModules = []string{"dccp", "sctp", "rds", "tipc", "cramfs"}
+
+// CISMutator enables the kubelet config file, such that its permissions are also covered by CISValidators
+func CISMutator(nbc *datamodel.NodeBootstrappingConfiguration) {
+	nbc.EnableKubeletConfigFile = true
+}
+
+// CISValidators returns validators re-checking the key CIS benchmark items applied to hardened VHDs against the node once
+// it has been bootstrapped, i.e. the permissions of the kubelet's and system's configuration files, the hardened network
+// sysctls, the disabled kernel modules, and the logging rules. The Kubernetes worker node items assume the node was
+// bootstrapped by CISMutator
+func CISValidators() []*LiveVMValidator {
+	validators := []*LiveVMValidator{
+		FilePermissionsValidator(cisFilePermissions),
+		SysctlConfigValidator(cisSysctls),
+		FileContentsValidator(cisRsyslogConfigPath, "*.emerg"),
+	}
+	for _, module := range cisDisabledKernelModules {
+		validators = append(validators, DisabledKernelModuleValidator(module))
+	}
+	return validators
+}
+
+// FilePermissionsValidator asserts that none of the specified files are more permissive than their maximum mode, and that
+// each is owned by its expected user and group
+func FilePermissionsValidator(files []FilePermissions) *LiveVMValidator {
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+
+	return &LiveVMValidator{
+		Description: "assert file permissions",
+		Command:     fmt.Sprintf("stat -c '%%n %%a %%U:%%G' %s", strings.Join(paths, " ")),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0, stderr: %q", code, stderr)
+			}
+			actual, err := parseStatOutput(stdout)
+			if err != nil {
+				return err
+			}
+
+			var mismatches []string
+			for _, file := range files {
+				stat, ok := actual[file.Path]
+				if !ok {
+					mismatches = append(mismatches, fmt.Sprintf("unable to find permissions of %s", file.Path))
+					continue
+				}
+				if stat.mode&^file.MaxMode != 0 {
+					mismatches = append(mismatches, fmt.Sprintf("expected %s to have mode %o or more restrictive, but had mode %o", file.Path, file.MaxMode, stat.mode))
+				}
+				if file.Owner != "" && stat.owner != file.Owner {
+					mismatches = append(mismatches, fmt.Sprintf("expected %s to be owned by %s, but was owned by %s", file.Path, file.Owner, stat.owner))
+				}
+			}
+			if len(mismatches) > 0 {
+				return fmt.Errorf("file permissions did not match expectations:\n%s", strings.Join(mismatches, "\n"))
+			}
+			return nil
+		},
+	}
+}
+
+// DisabledKernelModuleValidator asserts that the specified kernel module is prevented from being loaded, i.e. that a dry
+// run of loading it would run the /bin/true install command configured by the CIS modprobe config instead
+func DisabledKernelModuleValidator(module string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert kernel module %s is disabled", module),
+		Command:     fmt.Sprintf("modprobe -n -v %s", module),
+		Asserter: func(code, stdout, stderr string) error {
+			if !strings.Contains(stdout, "install /bin/true") {
+				return fmt.Errorf("expected loading kernel module %s to be disabled, but it wasn't, exit code: %q, stdout: %q, stderr: %q", module, code, stdout, stderr)
+			}
+			return nil
+		},
+	}
+}
+
+type fileStat struct {
+	mode  uint32
+	owner string
+}
+
+// Parses the output of stat when formatted with "%n %a %U:%G", mapping each file's path to its mode and ownership
+func parseStatOutput(stdout string) (map[string]fileStat, error) {
+	stats := map[string]fileStat{}
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		mode, err := strconv.ParseUint(fields[1], 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse mode of %s from %q: %w", fields[0], line, err)
+		}
+		stats[fields[0]] = fileStat{
+			mode:  uint32(mode),
+			owner: fields[2],
+		}
+	}
+	return stats, nil
+}
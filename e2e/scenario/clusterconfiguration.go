@@ -12,9 +12,19 @@ const (
 
 // Selectors
 
+// NetworkPluginKubenetSelector selects single-stack kubenet clusters, such that scenarios whose nodes only have IPv4
+// addresses aren't run on dual-stack clusters
 func NetworkPluginKubenetSelector(cluster *armcontainerservice.ManagedCluster) bool {
 	if cluster != nil && cluster.Properties != nil && cluster.Properties.NetworkProfile != nil {
-		return *cluster.Properties.NetworkProfile.NetworkPlugin == armcontainerservice.NetworkPluginKubenet
+		return *cluster.Properties.NetworkProfile.NetworkPlugin == armcontainerservice.NetworkPluginKubenet && !isDualStack(cluster)
+	}
+	return false
+}
+
+// NetworkDualStackSelector selects dual-stack kubenet clusters, whose nodes are assigned both IPv4 and IPv6 pod CIDRs
+func NetworkDualStackSelector(cluster *armcontainerservice.ManagedCluster) bool {
+	if cluster != nil && cluster.Properties != nil && cluster.Properties.NetworkProfile != nil {
+		return *cluster.Properties.NetworkProfile.NetworkPlugin == armcontainerservice.NetworkPluginKubenet && isDualStack(cluster)
 	}
 	return false
 }
@@ -26,6 +36,15 @@ func NetworkPluginAzureSelector(cluster *armcontainerservice.ManagedCluster) boo
 	return false
 }
 
+func isDualStack(cluster *armcontainerservice.ManagedCluster) bool {
+	for _, family := range cluster.Properties.NetworkProfile.IPFamilies {
+		if family != nil && *family == armcontainerservice.IPFamilyIPv6 {
+			return true
+		}
+	}
+	return false
+}
+
 // Mutators
 
 func NetworkPluginKubenetMutator(cluster *armcontainerservice.ManagedCluster) {
@@ -47,3 +66,15 @@ func NetworkPluginAzureMutator(cluster *armcontainerservice.ManagedCluster) {
 		}
 	}
 }
+
+// NetworkDualStackMutator configures the cluster to use kubenet with both IPv4 and IPv6 address families, such that AKS
+// creates a dual-stack VNet and assigns each node a pod CIDR of each family
+func NetworkDualStackMutator(cluster *armcontainerservice.ManagedCluster) {
+	if cluster != nil && cluster.Properties != nil && cluster.Properties.NetworkProfile != nil {
+		cluster.Properties.NetworkProfile.NetworkPlugin = to.Ptr(armcontainerservice.NetworkPluginKubenet)
+		cluster.Properties.NetworkProfile.IPFamilies = []*armcontainerservice.IPFamily{
+			to.Ptr(armcontainerservice.IPFamilyIPv4),
+			to.Ptr(armcontainerservice.IPFamilyIPv6),
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package scenario
+
+import (
+	"fmt"
+	"time"
+)
+
+// AllOf returns a validator which passes when each of the supplied validators passes, reporting every one which fails
+func AllOf(description string, validators ...*LiveVMValidator) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: description,
+		AllOf:       validators,
+	}
+}
+
+// AnyOf returns a validator which passes as soon as any of the supplied validators passes, in order, e.g. to accept either of
+// the locations a file is written to depending on the VHD. The failures of every validator are reported when none pass. The
+// severities of the supplied validators are ignored, such that only that of the returned validator applies
+func AnyOf(description string, validators ...*LiveVMValidator) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: description,
+		AnyOf:       validators,
+	}
+}
+
+// WithTimeout returns a copy of the validator bounded by the specified timeout, after which it fails
+func WithTimeout(validator *LiveVMValidator, timeout time.Duration) *LiveVMValidator {
+	bounded := *validator
+	bounded.Timeout = timeout
+	return &bounded
+}
+
+// Advisory returns a copy of the validator marked with SeverityWarn, such that it warns rather than failing the scenario
+func Advisory(validator *LiveVMValidator) *LiveVMValidator {
+	advisory := *validator
+	advisory.Severity = SeverityWarn
+	return &advisory
+}
+
+// Validate returns an error if the validator, or any validator it composes, neither has a command nor composes others
+func (v *LiveVMValidator) Validate() error {
+	composed := append(append([]*LiveVMValidator{}, v.AllOf...), v.AnyOf...)
+	switch {
+	case v.Severity != "" && v.Severity != SeverityFail && v.Severity != SeverityWarn:
+		return fmt.Errorf("validator %q has unknown severity %q", v.Description, v.Severity)
+	case len(v.AllOf) > 0 && len(v.AnyOf) > 0:
+		return fmt.Errorf("validator %q composes validators through both AllOf and AnyOf", v.Description)
+	case len(composed) > 0 && v.Command != "":
+		return fmt.Errorf("validator %q both composes validators and has a command", v.Description)
+	case len(composed) == 0 && v.Command == "":
+		return fmt.Errorf("validator %q neither composes validators nor has a command", v.Description)
+	}
+	for _, validator := range composed {
+		if err := validator.Validate(); err != nil {
+			return fmt.Errorf("validator %q composes an invalid validator: %w", v.Description, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package scenario
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLiveVMValidatorValidate(t *testing.T) {
+	command := &LiveVMValidator{Description: "command", Command: "true"}
+	empty := &LiveVMValidator{Description: "empty"}
+
+	cases := []struct {
+		name      string
+		validator *LiveVMValidator
+		err       string
+	}{
+		{
+			name:      "command",
+			validator: command,
+		},
+		{
+			name:      "nested composition",
+			validator: AllOf("all", command, AnyOf("any", command, Advisory(command))),
+		},
+		{
+			name:      "neither command nor composition",
+			validator: empty,
+			err:       `validator "empty" neither composes validators nor has a command`,
+		},
+		{
+			name:      "both command and composition",
+			validator: &LiveVMValidator{Description: "both", Command: "true", AllOf: []*LiveVMValidator{command}},
+			err:       `validator "both" both composes validators and has a command`,
+		},
+		{
+			name:      "both AllOf and AnyOf",
+			validator: &LiveVMValidator{Description: "both", AllOf: []*LiveVMValidator{command}, AnyOf: []*LiveVMValidator{command}},
+			err:       "through both AllOf and AnyOf",
+		},
+		{
+			name:      "unknown severity",
+			validator: &LiveVMValidator{Description: "severe", Command: "true", Severity: "fatal"},
+			err:       `unknown severity "fatal"`,
+		},
+		{
+			name:      "invalid composed validator",
+			validator: AllOf("all", command, AnyOf("any", empty)),
+			err:       `validator "all" composes an invalid validator: validator "any" composes an invalid validator: validator "empty"`,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			err := c.validator.Validate()
+			if c.err == "" {
+				if err != nil {
+					t.Fatalf("expected validator to be valid, but got error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Fatalf("expected error containing %q, but got: %v", c.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	containerdConfigFilePath = "/etc/containerd/config.toml"
+)
+
+// ContainerdConfig is the subset of containerd's config.toml rendered by AgentBaker which scenarios make assertions on.
+type ContainerdConfig struct {
+	Root    string `toml:"root"`
+	Plugins struct {
+		CRI ContainerdCRIConfig `toml:"io.containerd.grpc.v1.cri"`
+	} `toml:"plugins"`
+}
+
+// ContainerdCRIConfig is the configuration of containerd's CRI plugin.
+type ContainerdCRIConfig struct {
+	Containerd struct {
+		DefaultRuntimeName string                       `toml:"default_runtime_name"`
+		Runtimes           map[string]ContainerdRuntime `toml:"runtimes"`
+	} `toml:"containerd"`
+}
+
+// ContainerdRuntime is a runtime handler registered with containerd's CRI plugin, e.g. runc, kata or nvidia-container-runtime.
+type ContainerdRuntime struct {
+	RuntimeType string `toml:"runtime_type"`
+	Options     struct {
+		BinaryName    string `toml:"BinaryName"`
+		SystemdCgroup bool   `toml:"SystemdCgroup"`
+	} `toml:"options"`
+}
+
+// ContainerdConfigAssertion asserts a property of the containerd config parsed from the node.
+type ContainerdConfigAssertion func(config *ContainerdConfig) error
+
+// ContainerdConfigValidator parses the node's containerd config.toml and runs each of the supplied assertions against it.
+func ContainerdConfigValidator(assertions ...ContainerdConfigAssertion) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s contents", containerdConfigFilePath),
+		Command:     fmt.Sprintf("cat %s", containerdConfigFilePath),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0", code)
+			}
+			config := &ContainerdConfig{}
+			if _, err := toml.Decode(stdout, config); err != nil {
+				return fmt.Errorf("unable to parse %s: %w", containerdConfigFilePath, err)
+			}
+
+			var failures []string
+			for _, assertion := range assertions {
+				if err := assertion(config); err != nil {
+					failures = append(failures, err.Error())
+				}
+			}
+			if len(failures) > 0 {
+				return fmt.Errorf("%s did not match expectations:\n%s", containerdConfigFilePath, strings.Join(failures, "\n"))
+			}
+			return nil
+		},
+	}
+}
+
+// ContainerdRoot asserts that containerd persists its data within the specified root directory.
+func ContainerdRoot(root string) ContainerdConfigAssertion {
+	return func(config *ContainerdConfig) error {
+		if config.Root != root {
+			return fmt.Errorf("expected containerd root to be %q, but was %q", root, config.Root)
+		}
+		return nil
+	}
+}
+
+// ContainerdDefaultRuntime asserts the runtime handler used for pods which don't specify a runtime class.
+func ContainerdDefaultRuntime(name string) ContainerdConfigAssertion {
+	return func(config *ContainerdConfig) error {
+		if actual := config.Plugins.CRI.Containerd.DefaultRuntimeName; actual != name {
+			return fmt.Errorf("expected default_runtime_name to be %q, but was %q", name, actual)
+		}
+		return nil
+	}
+}
+
+// ContainerdRuntimeHandler asserts that the named runtime handler is registered with the given runtime type,
+// along with the given binary when binaryName is non-empty.
+func ContainerdRuntimeHandler(name, runtimeType, binaryName string) ContainerdConfigAssertion {
+	return func(config *ContainerdConfig) error {
+		runtime, ok := config.Plugins.CRI.Containerd.Runtimes[name]
+		if !ok {
+			return fmt.Errorf("expected runtime handler %q to be registered, but it was not", name)
+		}
+		if runtime.RuntimeType != runtimeType {
+			return fmt.Errorf("expected runtime handler %q to have runtime_type %q, but was %q", name, runtimeType, runtime.RuntimeType)
+		}
+		if binaryName != "" && runtime.Options.BinaryName != binaryName {
+			return fmt.Errorf("expected runtime handler %q to use binary %q, but was %q", name, binaryName, runtime.Options.BinaryName)
+		}
+		return nil
+	}
+}
+
+// ContainerdRuntimeSystemdCgroup asserts that the named runtime handler delegates the management of container cgroups to
+// systemd, as is required alongside kubelet's systemd cgroup driver on cgroupv2 nodes.
+func ContainerdRuntimeSystemdCgroup(name string) ContainerdConfigAssertion {
+	return func(config *ContainerdConfig) error {
+		runtime, ok := config.Plugins.CRI.Containerd.Runtimes[name]
+		if !ok {
+			return fmt.Errorf("expected runtime handler %q to be registered, but it was not", name)
+		}
+		if !runtime.Options.SystemdCgroup {
+			return fmt.Errorf("expected runtime handler %q to set SystemdCgroup, but it did not", name)
+		}
+		return nil
+	}
+}
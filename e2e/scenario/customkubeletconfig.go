@@ -0,0 +1,63 @@
+package scenario
+
+import (
+	"strconv"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+const (
+	// the static policy requires CPUs to be reserved for system daemons, which the template does through --kube-reserved
+	customCPUManagerPolicy      = "static"
+	customTopologyManagerPolicy = "best-effort"
+	customMaxPods               = 60
+	customSerializeImagePulls   = false
+)
+
+// CustomKubeletConfigMutator bootstraps the node with a CustomKubeletConfig setting the CPU and topology manager policies,
+// along with kubelet flags overriding the node's max pods and allowing images to be pulled in parallel
+func CustomKubeletConfigMutator(nbc *datamodel.NodeBootstrappingConfiguration) {
+	customKubeletConfig := &datamodel.CustomKubeletConfig{
+		CPUManagerPolicy:      customCPUManagerPolicy,
+		TopologyManagerPolicy: customTopologyManagerPolicy,
+	}
+	nbc.AgentPoolProfile.CustomKubeletConfig = customKubeletConfig
+	nbc.ContainerService.Properties.AgentPoolProfiles[0].CustomKubeletConfig = customKubeletConfig
+	nbc.KubeletConfig["--max-pods"] = strconv.Itoa(customMaxPods)
+	nbc.KubeletConfig["--serialize-image-pulls"] = strconv.FormatBool(customSerializeImagePulls)
+}
+
+// CustomKubeletConfigValidators returns the validators asserting that the CustomKubeletConfig applied by
+// CustomKubeletConfigMutator was rendered into the kubelet config file, which AgentBaker writes whenever a
+// CustomKubeletConfig is supplied
+func CustomKubeletConfigValidators() []*LiveVMValidator {
+	return []*LiveVMValidator{
+		KubeletConfigFileValidator(map[string]any{
+			"cpuManagerPolicy":             customCPUManagerPolicy,
+			"topologyManagerPolicy":        customTopologyManagerPolicy,
+			"featureGates.TopologyManager": true,
+			"maxPods":                      customMaxPods,
+		}),
+	}
+}
+
+// CustomKubeletConfigKubeletConfigValidators returns the validators asserting that the running kubelet's effective
+// configuration reflects CustomKubeletConfigMutator, whether set through the kubelet config file or its command line
+func CustomKubeletConfigKubeletConfigValidators() []*KubeletConfigValidator {
+	return []*KubeletConfigValidator{
+		KubeletConfigzValidator(map[string]any{
+			"cpuManagerPolicy":      customCPUManagerPolicy,
+			"topologyManagerPolicy": customTopologyManagerPolicy,
+			"maxPods":               customMaxPods,
+			"serializeImagePulls":   customSerializeImagePulls,
+		}),
+	}
+}
+
+// CustomKubeletConfigNodeValidators returns the validators asserting that the node registered with the pod capacity
+// set by CustomKubeletConfigMutator
+func CustomKubeletConfigNodeValidators() []*NodeValidator {
+	return []*NodeValidator{
+		NodePodCapacityValidator(customMaxPods),
+	}
+}
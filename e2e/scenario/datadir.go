@@ -0,0 +1,119 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+const (
+	// the mount point of the temp disk, mounted by cloud-init through the fstab entry it writes during provisioning
+	tempDiskMountPoint = "/mnt"
+
+	kubeletDataDir = "/var/lib/kubelet"
+	// touched by bind-mount.sh once it has moved kubelet's data directory onto the temp disk
+	kubeletBindMountSentinelPath = "/opt/azure/containers/bind-sentinel"
+	kubeletBindMountUnit         = "bind-mount.service"
+)
+
+// ContainerdDataDirMutator returns a BootstrapConfigMutator which relocates containerd's data directory to the specified
+// path through the agentpool's container runtime config, taking precedence over the data directory of the kubelet disk type
+func ContainerdDataDirMutator(dataDir string) func(*datamodel.NodeBootstrappingConfiguration) {
+	return func(nbc *datamodel.NodeBootstrappingConfiguration) {
+		for _, profile := range []*datamodel.AgentPoolProfile{nbc.AgentPoolProfile, nbc.ContainerService.Properties.AgentPoolProfiles[0]} {
+			if profile.KubernetesConfig == nil {
+				profile.KubernetesConfig = &datamodel.KubernetesConfig{}
+			}
+			if profile.KubernetesConfig.ContainerRuntimeConfig == nil {
+				profile.KubernetesConfig.ContainerRuntimeConfig = map[string]string{}
+			}
+			profile.KubernetesConfig.ContainerRuntimeConfig[datamodel.ContainerDataDirKey] = dataDir
+		}
+	}
+}
+
+// TempDiskDataDirValidators returns validators asserting that both kubelet's data directory and the specified containerd
+// data directory reside on the temp disk. The temp disk is expected to be mounted through its fstab entry, while kubelet's
+// data directory is expected to be bind mounted from the temp disk by bind-mount.service on every boot, rather than through
+// fstab or a symlink, neither of which kubelet's ephemeral storage accounting would see through
+func TempDiskDataDirValidators(containerdDataDir string) []*LiveVMValidator {
+	return []*LiveVMValidator{
+		FstabEntryValidator(tempDiskMountPoint),
+		MountPointValidator(tempDiskMountPoint),
+		MountPointValidator(kubeletDataDir),
+		// bind-mount.service moves /var/lib/kubelet to /mnt/aks/kubelet and bind mounts it back in place
+		MountSourceValidator(kubeletDataDir, "[/aks/kubelet]"),
+		NoFstabEntryValidator(kubeletDataDir),
+		DirectoryNotSymlinkValidator(kubeletDataDir),
+		FileExistsValidator(kubeletBindMountSentinelPath),
+		SystemdUnitStatusValidator(kubeletBindMountUnit, SystemdUnitStatus{ActiveState: "active"}),
+		ContainerdConfigValidator(ContainerdRoot(containerdDataDir)),
+		DirectoryNotSymlinkValidator(containerdDataDir),
+		FilesystemMountPointValidator(containerdDataDir, tempDiskMountPoint),
+		NonEmptyDirectoryValidator(containerdDataDir),
+	}
+}
+
+// FstabEntryValidator asserts that /etc/fstab has an entry mounting a filesystem at the specified mount point
+func FstabEntryValidator(mountPoint string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert /etc/fstab has an entry for %s", mountPoint),
+		Command:     fmt.Sprintf("findmnt -n --fstab -o SOURCE --mountpoint %s", mountPoint),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" || strings.TrimSpace(stdout) == "" {
+				return fmt.Errorf("expected /etc/fstab to have an entry for %s, but it had none", mountPoint)
+			}
+			return nil
+		},
+	}
+}
+
+// NoFstabEntryValidator asserts that /etc/fstab has no entry mounting a filesystem at the specified mount point
+func NoFstabEntryValidator(mountPoint string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert /etc/fstab has no entry for %s", mountPoint),
+		Command:     fmt.Sprintf("findmnt -n --fstab -o SOURCE --mountpoint %s", mountPoint),
+		Asserter: func(code, stdout, stderr string) error {
+			// findmnt exits with 1 when no entry matches
+			if code != "1" {
+				return fmt.Errorf("expected /etc/fstab to have no entry for %s, but found %q", mountPoint, strings.TrimSpace(stdout))
+			}
+			return nil
+		},
+	}
+}
+
+// DirectoryNotSymlinkValidator asserts that the specified path is a directory, rather than a symlink to one
+func DirectoryNotSymlinkValidator(path string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s is a directory rather than a symlink", path),
+		Command:     fmt.Sprintf("stat -c %%F %s", path),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0, stderr: %q", code, stderr)
+			}
+			if fileType := strings.TrimSpace(stdout); fileType != "directory" {
+				return fmt.Errorf("expected %s to be a directory, but it was a %s", path, fileType)
+			}
+			return nil
+		},
+	}
+}
+
+// FilesystemMountPointValidator asserts that the specified path resides on the filesystem mounted at the specified mount point
+func FilesystemMountPointValidator(path, mountPoint string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s resides on the filesystem mounted at %s", path, mountPoint),
+		Command:     fmt.Sprintf("findmnt -n -o TARGET --target %s", path),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0", code)
+			}
+			if target := strings.TrimSpace(stdout); target != mountPoint {
+				return fmt.Errorf("expected %s to reside on the filesystem mounted at %s, but it resides on the one mounted at %s", path, mountPoint, target)
+			}
+			return nil
+		},
+	}
+}
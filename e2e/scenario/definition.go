@@ -0,0 +1,310 @@
+package scenario
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	networkPluginKubenet = "kubenet"
+	networkPluginAzure   = "azure"
+)
+
+// Definition is the YAML representation of a scenario, allowing routine scenarios to be added without a Go change.
+// Definitions are compiled into scenarios equivalent to the Go-defined ones by way of Compile.
+type Definition struct {
+	// Name is the name of the scenario
+	Name string `json:"name"`
+
+	// Description is a short description of what the scenario does and tests for
+	Description string `json:"description"`
+
+	// Tags are used to group scenarios, such that they can be selected to run by tag
+	Tags []string `json:"tags,omitempty"`
+
+	// Image is the name of the VHD the scenario's VMSS boots from, i.e. a key of DefaultImageVersionIDs such as "ubuntu2204"
+	Image string `json:"image"`
+
+	// VMSize is the size of the scenario's VMs, defaulting to that of the base VMSS model. The scenario is skipped when
+	// the size isn't available within the suite's region
+	VMSize string `json:"vmSize,omitempty"`
+
+	// NetworkPlugin is the network plugin of the cluster the scenario runs on, either "kubenet" (the default) or "azure"
+	NetworkPlugin string `json:"networkPlugin,omitempty"`
+
+	// ExclusiveCluster is set when the scenario needs exclusive use of its cluster, such that no other scenario runs on it concurrently
+	ExclusiveCluster bool `json:"exclusiveCluster,omitempty"`
+
+	// AgentPoolProfile contains overrides of the agent pool profile, applied to both the NodeBootstrappingConfiguration's
+	// agent pool profile and the first agent pool profile of its container service
+	AgentPoolProfile json.RawMessage `json:"agentPoolProfile,omitempty"`
+
+	// BootstrapConfig contains overrides of the base NodeBootstrappingConfiguration, keyed by Go field name. Objects are
+	// merged into the base config's, while all other values, including lists, replace the base config's
+	BootstrapConfig json.RawMessage `json:"bootstrapConfig,omitempty"`
+
+	// Validators are the live VM validators run against the scenario's node
+	Validators []ValidatorDefinition `json:"validators,omitempty"`
+
+	// ExpectedFailure marks the scenario as a negative scenario whose bootstrapping is expected to fail, in which case it
+	// can't specify any validators
+	ExpectedFailure *ExpectedFailure `json:"expectedFailure,omitempty"`
+}
+
+// ValidatorDefinition is the YAML representation of a live VM validator, with Type naming the validator and the
+// remaining fields holding its arguments
+type ValidatorDefinition struct {
+	// Type is one of "fileContents", "directory", "nonEmptyDirectory", "mountPoint", "sysctl", "ulimit",
+	// "kubeletFlags", "kubeletNodeLabels", "systemdUnit" or "file"
+	Type string `json:"type"`
+
+	// Severity is the severity of the validator failing, either "fail" (the default) or "warn"
+	Severity Severity `json:"severity,omitempty"`
+
+	// Unit is the full name of the systemd unit validated by "systemdUnit", e.g. "kubelet.service"
+	Unit string `json:"unit,omitempty"`
+
+	// Status is the expected status of the systemd unit of "systemdUnit"
+	Status *SystemdUnitStatus `json:"status,omitempty"`
+
+	// Expect are the expectations of the contents of the file of "file"
+	Expect *FileExpectations `json:"expect,omitempty"`
+
+	// Path is the path validated by "fileContents", "file", "directory", "nonEmptyDirectory" and "mountPoint"
+	Path string `json:"path,omitempty"`
+
+	// Contents are the contents expected within the file of "fileContents"
+	Contents string `json:"contents,omitempty"`
+
+	// Files are the files expected within the directory of "directory"
+	Files []string `json:"files,omitempty"`
+
+	// Values are the expected values of "sysctl", "ulimit", "kubeletFlags" and "kubeletNodeLabels"
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// LoadDefinitions loads and compiles the scenario definitions within each of the YAML files in the specified directory.
+// A directory which doesn't exist holds no definitions.
+func LoadDefinitions(dir string) ([]*Scenario, error) {
+	var paths []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list scenario definitions within %s: %w", dir, err)
+		}
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+
+	scenarios := make([]*Scenario, 0, len(paths))
+	for _, path := range paths {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read scenario definition %s: %w", path, err)
+		}
+		scenario, err := ParseDefinition(contents)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scenario definition %s: %w", path, err)
+		}
+		scenarios = append(scenarios, scenario)
+	}
+	return scenarios, nil
+}
+
+// ParseDefinition parses and compiles a single YAML scenario definition.
+func ParseDefinition(contents []byte) (*Scenario, error) {
+	var definition Definition
+	if err := yaml.UnmarshalStrict(contents, &definition); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario definition: %w", err)
+	}
+	return definition.Compile()
+}
+
+// Compile returns the scenario described by the definition, or an error if the definition is invalid.
+func (d *Definition) Compile() (*Scenario, error) {
+	if d.Name == "" {
+		return nil, fmt.Errorf("scenario definitions must specify a name")
+	}
+	if _, ok := DefaultImageVersionIDs[d.Image]; !ok {
+		return nil, fmt.Errorf("scenario %q specifies unknown image %q", d.Name, d.Image)
+	}
+	if d.ExpectedFailure != nil && len(d.Validators) > 0 {
+		return nil, fmt.Errorf("scenario %q specifies both an expected failure and validators, which are never run against negative scenarios", d.Name)
+	}
+
+	// apply the overrides to an empty config up front, such that type mismatches are reported here rather than at runtime
+	if err := d.applyBootstrapConfigOverrides(&datamodel.NodeBootstrappingConfiguration{
+		ContainerService: &datamodel.ContainerService{
+			Properties: &datamodel.Properties{
+				AgentPoolProfiles: []*datamodel.AgentPoolProfile{{}},
+			},
+		},
+		AgentPoolProfile: &datamodel.AgentPoolProfile{},
+	}); err != nil {
+		return nil, fmt.Errorf("scenario %q specifies invalid bootstrap config overrides: %w", d.Name, err)
+	}
+
+	validators := make([]*LiveVMValidator, 0, len(d.Validators))
+	for i, definition := range d.Validators {
+		validator, err := definition.compile()
+		if err != nil {
+			return nil, fmt.Errorf("scenario %q specifies invalid validator %d: %w", d.Name, i, err)
+		}
+		validator.Severity = definition.Severity
+		validators = append(validators, validator)
+	}
+
+	scenario := &Scenario{
+		Name:        d.Name,
+		Description: d.Description,
+		Tags:        d.Tags,
+		Config: Config{
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				if err := d.applyBootstrapConfigOverrides(nbc); err != nil {
+					// the overrides were already validated against an empty config within Compile
+					panic(fmt.Sprintf("failed to apply bootstrap config overrides of scenario %q: %s", d.Name, err))
+				}
+			},
+			VMSSMutator:      ComposeVMSSMutators(ImageReferenceMutator(d.Image), d.vmSizeMutator()),
+			LiveVMValidators: validators,
+			ExpectedFailure:  d.ExpectedFailure,
+			ExclusiveCluster: d.ExclusiveCluster,
+		},
+	}
+
+	if d.VMSize != "" {
+		scenario.Requirements.VMSizes = []string{d.VMSize}
+	}
+
+	switch d.NetworkPlugin {
+	case "", networkPluginKubenet:
+		scenario.ClusterSelector = NetworkPluginKubenetSelector
+		scenario.ClusterMutator = NetworkPluginKubenetMutator
+	case networkPluginAzure:
+		scenario.ClusterSelector = NetworkPluginAzureSelector
+		scenario.ClusterMutator = NetworkPluginAzureMutator
+	default:
+		return nil, fmt.Errorf("scenario %q specifies unknown network plugin %q", d.Name, d.NetworkPlugin)
+	}
+
+	return scenario, nil
+}
+
+func (d *Definition) applyBootstrapConfigOverrides(nbc *datamodel.NodeBootstrappingConfiguration) error {
+	if len(d.BootstrapConfig) > 0 {
+		if err := decodeOverrides(d.BootstrapConfig, nbc); err != nil {
+			return fmt.Errorf("bootstrapConfig: %w", err)
+		}
+	}
+	if len(d.AgentPoolProfile) > 0 || d.VMSize != "" {
+		for _, profile := range []*datamodel.AgentPoolProfile{nbc.AgentPoolProfile, nbc.ContainerService.Properties.AgentPoolProfiles[0]} {
+			if len(d.AgentPoolProfile) > 0 {
+				if err := decodeOverrides(d.AgentPoolProfile, profile); err != nil {
+					return fmt.Errorf("agentPoolProfile: %w", err)
+				}
+			}
+			if d.VMSize != "" {
+				profile.VMSize = d.VMSize
+			}
+		}
+	}
+	return nil
+}
+
+func (d *Definition) vmSizeMutator() func(*armcompute.VirtualMachineScaleSet) {
+	if d.VMSize == "" {
+		return nil
+	}
+	return VMSizeMutator(d.VMSize)
+}
+
+// Decodes the JSON overrides onto the existing value, such that objects are merged rather than replaced
+func decodeOverrides(overrides json.RawMessage, target any) error {
+	decoder := json.NewDecoder(bytes.NewReader(overrides))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(target)
+}
+
+func (v ValidatorDefinition) compile() (*LiveVMValidator, error) {
+	requirePath := func() error {
+		if v.Path == "" {
+			return fmt.Errorf("validators of type %q must specify a path", v.Type)
+		}
+		return nil
+	}
+	requireValues := func() error {
+		if len(v.Values) == 0 {
+			return fmt.Errorf("validators of type %q must specify values", v.Type)
+		}
+		return nil
+	}
+
+	switch v.Type {
+	case "fileContents":
+		if err := requirePath(); err != nil {
+			return nil, err
+		}
+		return FileContentsValidator(v.Path, v.Contents), nil
+	case "directory":
+		if err := requirePath(); err != nil {
+			return nil, err
+		}
+		return DirectoryValidator(v.Path, v.Files), nil
+	case "nonEmptyDirectory":
+		if err := requirePath(); err != nil {
+			return nil, err
+		}
+		return NonEmptyDirectoryValidator(v.Path), nil
+	case "mountPoint":
+		if err := requirePath(); err != nil {
+			return nil, err
+		}
+		return MountPointValidator(v.Path), nil
+	case "sysctl":
+		if err := requireValues(); err != nil {
+			return nil, err
+		}
+		return SysctlConfigValidator(v.Values), nil
+	case "ulimit":
+		if err := requireValues(); err != nil {
+			return nil, err
+		}
+		return UlimitValidator(v.Values), nil
+	case "kubeletFlags":
+		if err := requireValues(); err != nil {
+			return nil, err
+		}
+		return KubeletCommandLineValidator(v.Values), nil
+	case "kubeletNodeLabels":
+		if err := requireValues(); err != nil {
+			return nil, err
+		}
+		return KubeletNodeLabelsValidator(v.Values), nil
+	case "systemdUnit":
+		if v.Unit == "" || v.Status == nil {
+			return nil, fmt.Errorf("validators of type %q must specify a unit and its status", v.Type)
+		}
+		return SystemdUnitStatusValidator(v.Unit, *v.Status), nil
+	case "file":
+		if err := requirePath(); err != nil {
+			return nil, err
+		}
+		if v.Expect == nil {
+			return nil, fmt.Errorf("validators of type %q must specify expectations", v.Type)
+		}
+		if err := v.Expect.Validate(); err != nil {
+			return nil, err
+		}
+		return FileValidator(v.Path, *v.Expect), nil
+	default:
+		return nil, fmt.Errorf("unknown validator type %q", v.Type)
+	}
+}
@@ -0,0 +1,192 @@
+package scenario
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+)
+
+func TestParseDefinition(t *testing.T) {
+	cases := []struct {
+		name       string
+		definition string
+		err        string
+	}{
+		{
+			name: "valid definition",
+			definition: `
+name: valid
+description: a valid definition
+tags: [example]
+image: ubuntu2204
+vmSize: Standard_D2ds_v5
+networkPlugin: azure
+agentPoolProfile:
+  distro: aks-ubuntu-containerd-22.04-gen2
+bootstrapConfig:
+  EnableNvidia: true
+validators:
+  - type: sysctl
+    severity: warn
+    values:
+      net.ipv4.ip_forward: "1"
+  - type: systemdUnit
+    unit: kubelet.service
+    status:
+      activeState: active
+`,
+		},
+		{
+			name:       "missing name",
+			definition: "image: ubuntu2204\n",
+			err:        "must specify a name",
+		},
+		{
+			name:       "unknown image",
+			definition: "name: unknown-image\nimage: ubuntu1404\n",
+			err:        `unknown image "ubuntu1404"`,
+		},
+		{
+			name:       "unknown field",
+			definition: "name: unknown-field\nimage: ubuntu2204\nsize: Standard_D2ds_v5\n",
+			err:        "failed to parse scenario definition",
+		},
+		{
+			name:       "unknown network plugin",
+			definition: "name: unknown-plugin\nimage: ubuntu2204\nnetworkPlugin: calico\n",
+			err:        `unknown network plugin "calico"`,
+		},
+		{
+			name:       "unknown bootstrap config field",
+			definition: "name: unknown-override\nimage: ubuntu2204\nbootstrapConfig:\n  NotAField: true\n",
+			err:        "invalid bootstrap config overrides",
+		},
+		{
+			name:       "mistyped bootstrap config field",
+			definition: "name: mistyped-override\nimage: ubuntu2204\nbootstrapConfig:\n  EnableNvidia: enabled\n",
+			err:        "invalid bootstrap config overrides",
+		},
+		{
+			name: "expected failure with validators",
+			definition: `
+name: negative-with-validators
+image: ubuntu2204
+expectedFailure:
+  cseExitCode: 51
+validators:
+  - type: nonEmptyDirectory
+    path: /etc/kubernetes
+`,
+			err: "both an expected failure and validators",
+		},
+		{
+			name:       "unknown validator type",
+			definition: "name: unknown-validator\nimage: ubuntu2204\nvalidators:\n  - type: bogus\n",
+			err:        `unknown validator type "bogus"`,
+		},
+		{
+			name:       "validator missing its path",
+			definition: "name: missing-path\nimage: ubuntu2204\nvalidators:\n  - type: mountPoint\n",
+			err:        "must specify a path",
+		},
+		{
+			name:       "validator missing its values",
+			definition: "name: missing-values\nimage: ubuntu2204\nvalidators:\n  - type: ulimit\n",
+			err:        "must specify values",
+		},
+		{
+			name:       "systemd unit validator missing its status",
+			definition: "name: missing-status\nimage: ubuntu2204\nvalidators:\n  - type: systemdUnit\n    unit: kubelet.service\n",
+			err:        "must specify a unit and its status",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ParseDefinition([]byte(c.definition))
+			switch {
+			case c.err == "" && err != nil:
+				t.Fatalf("expected definition to be valid, but got error: %s", err)
+			case c.err != "" && err == nil:
+				t.Fatalf("expected error containing %q, but definition was valid", c.err)
+			case c.err != "" && !strings.Contains(err.Error(), c.err):
+				t.Fatalf("expected error containing %q, but got: %s", c.err, err)
+			}
+		})
+	}
+}
+
+func TestParseDefinitionCompilesScenario(t *testing.T) {
+	scenario, err := ParseDefinition([]byte(`
+name: compiled
+description: a compiled definition
+tags: [example]
+image: ubuntu2204
+vmSize: Standard_D2ds_v5
+agentPoolProfile:
+  distro: aks-ubuntu-containerd-22.04-gen2
+bootstrapConfig:
+  EnableNvidia: true
+validators:
+  - type: sysctl
+    severity: warn
+    values:
+      net.ipv4.ip_forward: "1"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if scenario.Name != "compiled" || scenario.Description != "a compiled definition" {
+		t.Errorf("unexpected scenario metadata: name %q, description %q", scenario.Name, scenario.Description)
+	}
+	if len(scenario.Tags) != 1 || scenario.Tags[0] != "example" {
+		t.Errorf("expected tags [example], but got %v", scenario.Tags)
+	}
+	if len(scenario.Requirements.VMSizes) != 1 || scenario.Requirements.VMSizes[0] != "Standard_D2ds_v5" {
+		t.Errorf("expected the VM size to be required, but got requirements %v", scenario.Requirements.VMSizes)
+	}
+	if len(scenario.LiveVMValidators) != 1 || scenario.LiveVMValidators[0].Severity != SeverityWarn {
+		t.Errorf("expected a single advisory validator, but got %d validators", len(scenario.LiveVMValidators))
+	}
+
+	nbc := &datamodel.NodeBootstrappingConfiguration{
+		ContainerService: &datamodel.ContainerService{
+			Properties: &datamodel.Properties{
+				AgentPoolProfiles: []*datamodel.AgentPoolProfile{{Name: "nodepool1", VMSize: "Standard_DS2_v2"}},
+			},
+		},
+		AgentPoolProfile: &datamodel.AgentPoolProfile{Name: "nodepool1", VMSize: "Standard_DS2_v2"},
+	}
+	scenario.BootstrapConfigMutator(nbc)
+	if !nbc.EnableNvidia {
+		t.Errorf("expected bootstrapConfig overrides to be applied")
+	}
+	for _, profile := range []*datamodel.AgentPoolProfile{nbc.AgentPoolProfile, nbc.ContainerService.Properties.AgentPoolProfiles[0]} {
+		if profile.Distro != datamodel.AKSUbuntuContainerd2204Gen2 || profile.VMSize != "Standard_D2ds_v5" {
+			t.Errorf("expected agent pool profile overrides to be applied, but got distro %q and VM size %q", profile.Distro, profile.VMSize)
+		}
+		if profile.Name != "nodepool1" {
+			t.Errorf("expected agent pool profile overrides to be merged into the base profile, but its name became %q", profile.Name)
+		}
+	}
+
+	vmss := &armcompute.VirtualMachineScaleSet{
+		SKU: &armcompute.SKU{},
+		Properties: &armcompute.VirtualMachineScaleSetProperties{
+			VirtualMachineProfile: &armcompute.VirtualMachineScaleSetVMProfile{
+				StorageProfile: &armcompute.VirtualMachineScaleSetStorageProfile{},
+			},
+		},
+	}
+	scenario.VMSSMutator(vmss)
+	if id := vmss.Properties.VirtualMachineProfile.StorageProfile.ImageReference.ID; id == nil || *id != DefaultImageVersionIDs["ubuntu2204"] {
+		t.Errorf("expected the VMSS to boot from the ubuntu2204 image, but got image reference %v", id)
+	}
+	if vmss.SKU.Name == nil || *vmss.SKU.Name != "Standard_D2ds_v5" {
+		t.Errorf("expected the VMSS SKU to be Standard_D2ds_v5, but got %v", vmss.SKU.Name)
+	}
+}
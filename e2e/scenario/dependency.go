@@ -0,0 +1,94 @@
+package scenario
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+// DependencyState is the state published by a scenario once it has been validated to each of the scenarios depending on it
+type DependencyState struct {
+	// ClusterName is the name of the cluster the scenario ran on, which its dependents also run on
+	ClusterName string
+
+	// VMSSName is the name of the scenario's VMSS, which is retained until each of its dependents has finished
+	VMSSName string
+
+	// NodeNames are the names of the nodes of the scenario's VMSS instances, ordered by instance ID
+	NodeNames []string
+
+	// BootstrapConfig is the bootstrap config the scenario's nodes were bootstrapped with, which mustn't be mutated by dependents
+	BootstrapConfig *datamodel.NodeBootstrappingConfiguration
+}
+
+// Sorted returns every scenario of the table, ordered by name, such that iterating over them is deterministic
+func (t Table) Sorted() []*Scenario {
+	return t.sorted(func(*Scenario) bool { return true })
+}
+
+// Roots returns the scenarios of the table which don't depend on another scenario, ordered by name
+func (t Table) Roots() []*Scenario {
+	return t.sorted(func(scenario *Scenario) bool {
+		return scenario.DependsOn == ""
+	})
+}
+
+// Dependents returns the scenarios of the table which depend on the named scenario, ordered by name
+func (t Table) Dependents(name string) []*Scenario {
+	return t.sorted(func(scenario *Scenario) bool {
+		return scenario.DependsOn == name
+	})
+}
+
+func (t Table) sorted(include func(*Scenario) bool) []*Scenario {
+	var scenarios []*Scenario
+	for _, scenario := range t {
+		if include(scenario) {
+			scenarios = append(scenarios, scenario)
+		}
+	}
+	sort.Slice(scenarios, func(i, j int) bool {
+		return scenarios[i].Name < scenarios[j].Name
+	})
+	return scenarios
+}
+
+// Adds the scenarios each selected scenario transitively depends on to the table, such that they run even when they weren't
+// selected themselves. Returns an error when a scenario depends on one which isn't defined, or on itself by way of a cycle
+func addDependencies(table Table, defined map[string]*Scenario) error {
+	for _, scenario := range table.Sorted() {
+		chain := []string{scenario.Name}
+		for current := scenario; current.DependsOn != ""; {
+			dependency, ok := defined[current.DependsOn]
+			if !ok {
+				return fmt.Errorf("scenario %q depends on scenario %q, which isn't defined", current.Name, current.DependsOn)
+			}
+			for _, name := range chain {
+				if name == dependency.Name {
+					return fmt.Errorf("scenario %q depends on itself: %s -> %s", name, strings.Join(chain, " -> "), name)
+				}
+			}
+			chain = append(chain, dependency.Name)
+
+			// each parameter of a parameterized scenario has a VMSS of its own, so there's no single state to publish
+			if len(dependency.Parameters) > 0 {
+				return fmt.Errorf("scenario %q depends on scenario %q, which is parameterized, so can't be depended on", current.Name, dependency.Name)
+			}
+
+			// dependents run within their dependency's use of its cluster, so can't use it any more exclusively than it does
+			if current.ExclusiveCluster && !dependency.ExclusiveCluster {
+				return fmt.Errorf("scenario %q needs exclusive use of its cluster, so can't depend on scenario %q, which doesn't", current.Name, dependency.Name)
+			}
+
+			if _, ok := table[dependency.Name]; !ok {
+				log.Printf("will run E2E scenario %q, as scenario %q depends on it: %s", dependency.Name, current.Name, dependency.Description)
+				table[dependency.Name] = dependency
+			}
+			current = dependency
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package scenario
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestAddDependencies(t *testing.T) {
+	cases := []struct {
+		name     string
+		defined  []*Scenario
+		selected []string
+		table    []string
+		err      string
+	}{
+		{
+			name:     "scenarios without dependencies are left as is",
+			defined:  []*Scenario{{Name: "a"}, {Name: "b"}},
+			selected: []string{"a"},
+			table:    []string{"a"},
+		},
+		{
+			name:     "transitive dependencies are added",
+			defined:  []*Scenario{{Name: "a", Config: Config{DependsOn: "b"}}, {Name: "b", Config: Config{DependsOn: "c"}}, {Name: "c"}, {Name: "d"}},
+			selected: []string{"a"},
+			table:    []string{"a", "b", "c"},
+		},
+		{
+			name:     "undefined dependencies are rejected",
+			defined:  []*Scenario{{Name: "a", Config: Config{DependsOn: "b"}}},
+			selected: []string{"a"},
+			err:      `scenario "a" depends on scenario "b", which isn't defined`,
+		},
+		{
+			name:     "scenarios depending on themselves are rejected",
+			defined:  []*Scenario{{Name: "a", Config: Config{DependsOn: "a"}}},
+			selected: []string{"a"},
+			err:      `scenario "a" depends on itself: a -> a`,
+		},
+		{
+			name:     "cycles are rejected",
+			defined:  []*Scenario{{Name: "a", Config: Config{DependsOn: "b"}}, {Name: "b", Config: Config{DependsOn: "c"}}, {Name: "c", Config: Config{DependsOn: "a"}}},
+			selected: []string{"a"},
+			err:      `scenario "a" depends on itself: a -> b -> c -> a`,
+		},
+		{
+			name:     "cycles not including the selected scenario are rejected",
+			defined:  []*Scenario{{Name: "a", Config: Config{DependsOn: "b"}}, {Name: "b", Config: Config{DependsOn: "c"}}, {Name: "c", Config: Config{DependsOn: "b"}}},
+			selected: []string{"a"},
+			err:      `scenario "b" depends on itself: a -> b -> c -> b`,
+		},
+		{
+			name:     "parameterized dependencies are rejected",
+			defined:  []*Scenario{{Name: "a", Config: Config{DependsOn: "b"}}, {Name: "b", Config: Config{Parameters: []Parameter{{Name: "one"}}}}},
+			selected: []string{"a"},
+			err:      `scenario "a" depends on scenario "b", which is parameterized`,
+		},
+		{
+			name:     "exclusive scenarios can't depend on shared ones",
+			defined:  []*Scenario{{Name: "a", Config: Config{DependsOn: "b", ExclusiveCluster: true}}, {Name: "b"}},
+			selected: []string{"a"},
+			err:      `scenario "a" needs exclusive use of its cluster`,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			defined := map[string]*Scenario{}
+			for _, scenario := range c.defined {
+				defined[scenario.Name] = scenario
+			}
+			table := Table{}
+			for _, name := range c.selected {
+				table[name] = defined[name]
+			}
+
+			err := addDependencies(table, defined)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expected error containing %q, but got: %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for name := range table {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			if strings.Join(names, ",") != strings.Join(c.table, ",") {
+				t.Errorf("expected table %v, but got %v", c.table, names)
+			}
+		})
+	}
+}
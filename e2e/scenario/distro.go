@@ -0,0 +1,128 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	osReleaseFilePath = "/etc/os-release"
+
+	// ID of /etc/os-release on both CBL-Mariner and AzureLinux
+	marinerOSReleaseID = "mariner"
+	ubuntuOSReleaseID  = "ubuntu"
+
+	// the dist tag suffixing the kernel release of CBL-Mariner/AzureLinux 2.0, e.g. "5.15.131.1-2.cm2"
+	marinerV2KernelDistTag = "cm2"
+	// the flavor of the kernel booted by kata VHDs, hosting pod sandboxes through the Microsoft Hypervisor
+	mshvKernelFlavor = "mshv"
+)
+
+// packages installed through dnf onto every CBL-Mariner/AzureLinux VHD, whether when building the VHD or during bootstrapping
+var marinerPackages = []string{"moby-containerd", "blobfuse2", "conntrack-tools", "ebtables", "ipset", "iptables", "socat"}
+
+// packages installed through apt onto every Ubuntu VHD, whether when building the VHD or during bootstrapping
+var ubuntuPackages = []string{"moby-containerd", "moby-runc", "blobfuse2", "conntrack", "ebtables", "ipset", "socat"}
+
+// MarinerV2Validators returns the validators asserting that a node was bootstrapped from a CBL-Mariner or AzureLinux 2.0 VHD
+// with the specified kernel flavor, e.g. "mshv" for kata VHDs, or the default kernel when the flavor is empty
+func MarinerV2Validators(kernelFlavor string) []*LiveVMValidator {
+	validators := []*LiveVMValidator{
+		OSReleaseValidator(marinerOSReleaseID, "2.0"),
+		KernelReleaseValidator(marinerV2KernelDistTag),
+		RPMPackagesValidator(marinerPackages),
+	}
+	if kernelFlavor != "" {
+		validators = append(validators, KernelReleaseValidator(kernelFlavor))
+	}
+	return validators
+}
+
+// UbuntuValidators returns the validators asserting that a node was bootstrapped from an Ubuntu VHD of the specified
+// version, e.g. "22.04"
+func UbuntuValidators(version string) []*LiveVMValidator {
+	return []*LiveVMValidator{
+		OSReleaseValidator(ubuntuOSReleaseID, version),
+		DebPackagesValidator(ubuntuPackages),
+	}
+}
+
+// OSReleaseValidator asserts the ID and VERSION_ID of /etc/os-release, e.g. "mariner" and "2.0"
+func OSReleaseValidator(id, versionID string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s identifies the OS as %s %s", osReleaseFilePath, id, versionID),
+		Command:     fmt.Sprintf("cat %s", osReleaseFilePath),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0", code)
+			}
+			expected := map[string]string{"ID": id, "VERSION_ID": versionID}
+			if mismatches := compareExpectedValues(expected, parseEnvironmentFile(stdout)); len(mismatches) > 0 {
+				return fmt.Errorf("%s did not match expectations:\n%s", osReleaseFilePath, strings.Join(mismatches, "\n"))
+			}
+			return nil
+		},
+	}
+}
+
+// KernelReleaseValidator asserts that the release of the running kernel contains the specified substring, e.g. a dist tag
+// such as "cm2" or a kernel flavor such as "mshv" or "azure"
+func KernelReleaseValidator(substring string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert kernel release contains %q", substring),
+		Command:     "uname -r",
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0", code)
+			}
+			if release := strings.TrimSpace(stdout); !strings.Contains(release, substring) {
+				return fmt.Errorf("expected kernel release to contain %q, but was %q", substring, release)
+			}
+			return nil
+		},
+	}
+}
+
+// RPMPackagesValidator asserts that each of the specified packages was installed through dnf
+func RPMPackagesValidator(packages []string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert rpm packages %s are installed", strings.Join(packages, ", ")),
+		Command:     fmt.Sprintf("rpm -q %s", strings.Join(packages, " ")),
+		Asserter: func(code, stdout, stderr string) error {
+			// rpm lists each package which isn't installed within its stdout, exiting with the number of such packages
+			if code != "0" {
+				return fmt.Errorf("expected rpm packages to be installed, but weren't:\n%s", missingPackages(stdout, "is not installed"))
+			}
+			return nil
+		},
+	}
+}
+
+// DebPackagesValidator asserts that each of the specified packages was installed through apt
+func DebPackagesValidator(packages []string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert deb packages %s are installed", strings.Join(packages, ", ")),
+		Command:     fmt.Sprintf("dpkg-query -W %s", strings.Join(packages, " ")),
+		Asserter: func(code, stdout, stderr string) error {
+			// dpkg-query lists each package which isn't installed within its stderr
+			if code != "0" {
+				return fmt.Errorf("expected deb packages to be installed, but weren't:\n%s", missingPackages(stderr, "no packages found"))
+			}
+			return nil
+		},
+	}
+}
+
+// Returns the lines of a package manager's output reporting packages which aren't installed
+func missingPackages(output, marker string) string {
+	var missing []string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.Contains(strings.ToLower(line), marker) {
+			missing = append(missing, strings.TrimSpace(line))
+		}
+	}
+	if len(missing) == 0 {
+		return strings.TrimSpace(output)
+	}
+	return strings.Join(missing, "\n")
+}
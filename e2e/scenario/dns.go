@@ -0,0 +1,120 @@
+package scenario
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+const (
+	// resolved through the node's own resolver, e.g. systemd-resolved, and hence through the VNet's upstream DNS servers
+	upstreamDNSName = "mcr.microsoft.com"
+
+	// resolved through the cluster's DNS service, which is only reachable through the service networking of the node
+	clusterDNSServiceName = "kubernetes.default.svc"
+
+	defaultClusterDomain = "cluster.local"
+)
+
+// A minimal DNS client, run by the node's python3 since dig isn't installed on every VHD, which queries the A or AAAA records of
+// a name from a specific server, retrying on timeouts, and prints the response code followed by each resolved address
+const dnsQueryScript = `import socket, struct, sys
+
+server, name = sys.argv[1], sys.argv[2]
+family = socket.AF_INET6 if ":" in server else socket.AF_INET
+qtype = 28 if family == socket.AF_INET6 else 1
+question = b"".join(bytes([len(label)]) + label.encode() for label in name.rstrip(".").split(".")) + b"\x00" + struct.pack(">HH", qtype, 1)
+sock = socket.socket(family, socket.SOCK_DGRAM)
+sock.settimeout(5)
+for _ in range(3):
+    sock.sendto(struct.pack(">HHHHHH", 0xabe2, 0x0100, 1, 0, 0, 0) + question, (server, 53))
+    try:
+        response = sock.recv(4096)
+        break
+    except socket.timeout:
+        continue
+else:
+    sys.exit("no response from %s" % server)
+
+print("rcode %d" % (response[3] & 0x0f))
+offset = 12 + len(question)
+for _ in range(struct.unpack(">H", response[6:8])[0]):
+    while True:
+        length = response[offset]
+        if length & 0xc0 == 0xc0:
+            offset += 2
+            break
+        offset += 1 + length
+        if length == 0:
+            break
+    rtype, _, _, rdlength = struct.unpack(">HHIH", response[offset:offset + 10])
+    offset += 10
+    if rtype == qtype:
+        print(socket.inet_ntop(family, response[offset:offset + rdlength]))
+    offset += rdlength
+`
+
+// DNSResolutionValidators asserts that the node resolves names through its own resolver, that the resolv.conf kubelet hands
+// to pods only lists nameservers reachable from within pods, rather than e.g. systemd-resolved's loopback stub, and that the
+// cluster's DNS service, as configured through kubelet's --cluster-dns, resolves the cluster's names from the node
+func DNSResolutionValidators(nbc *datamodel.NodeBootstrappingConfiguration) []*LiveVMValidator {
+	validators := []*LiveVMValidator{
+		{
+			Description: fmt.Sprintf("assert %s resolves through the node's resolver", upstreamDNSName),
+			Command:     fmt.Sprintf("getent ahosts %s", upstreamDNSName),
+			Asserter: func(code, stdout, stderr string) error {
+				if code != "0" {
+					return fmt.Errorf("unable to resolve %s through the node's resolver, validator command terminated with exit code %q", upstreamDNSName, code)
+				}
+				if len(strings.Fields(stdout)) == 0 {
+					return fmt.Errorf("expected %s to resolve to at least one address, but it resolved to none", upstreamDNSName)
+				}
+				return nil
+			},
+		},
+	}
+
+	if resolvConf := nbc.KubeletConfig["--resolv-conf"]; resolvConf != "" {
+		validator := FileValidator(resolvConf, FileExpectations{
+			Matches:    []string{`(?m)^nameserver\s+\S+`},
+			NotMatches: []string{`(?m)^nameserver\s+(127\.\S*|::1)\s*$`},
+		})
+		validator.Description = fmt.Sprintf("assert kubelet's resolv.conf %s lists nameservers reachable from pods", resolvConf)
+		validators = append(validators, validator)
+	}
+
+	if clusterDNS := strings.Split(nbc.KubeletConfig["--cluster-dns"], ",")[0]; net.ParseIP(clusterDNS) != nil {
+		clusterDomain := nbc.KubeletConfig["--cluster-domain"]
+		if clusterDomain == "" {
+			clusterDomain = defaultClusterDomain
+		}
+		validators = append(validators, DNSQueryValidator(clusterDNS, fmt.Sprintf("%s.%s", clusterDNSServiceName, clusterDomain)))
+	}
+	return validators
+}
+
+// DNSQueryValidator asserts that the DNS server at the specified IP, queried from the node, resolves the name to at least one
+// address of the server's IP family
+func DNSQueryValidator(server, name string) *LiveVMValidator {
+	script := base64.StdEncoding.EncodeToString([]byte(dnsQueryScript))
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s resolves %s", server, name),
+		Command:     fmt.Sprintf("echo %s | base64 -d | python3 - %s %s", script, server, name),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("unable to query %s for %s, validator command terminated with exit code %q, stderr: %q", server, name, code, stderr)
+			}
+			lines := strings.Split(strings.TrimSpace(stdout), "\n")
+			if lines[0] != "rcode 0" {
+				return fmt.Errorf("expected %s to resolve %s, but it responded with %q", server, name, lines[0])
+			}
+			if len(lines) < 2 {
+				return fmt.Errorf("expected %s to resolve %s to at least one address, but it resolved to none", server, name)
+			}
+			return nil
+		},
+	}
+}
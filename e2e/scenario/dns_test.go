@@ -0,0 +1,144 @@
+package scenario
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// a DNS answer served by fakeDNSServer, whose name refers to the question's name
+type fakeDNSAnswer struct {
+	rtype uint16
+	rdata []byte
+}
+
+// Serves a single response to each query received on the connection, echoing the query's ID and question
+func fakeDNSServer(conn net.PacketConn, rcode byte, answers []fakeDNSAnswer) {
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		query := buf[:n]
+
+		var response bytes.Buffer
+		response.Write(query[0:2])
+		response.Write([]byte{0x81, 0x80 | rcode})
+		_ = binary.Write(&response, binary.BigEndian, []uint16{1, uint16(len(answers)), 0, 0})
+		response.Write(query[12:])
+		for _, answer := range answers {
+			// the answer's name is compressed into a pointer to the question's name, which follows the 12 byte header
+			_ = binary.Write(&response, binary.BigEndian, []uint16{0xc00c, answer.rtype, 1})
+			_ = binary.Write(&response, binary.BigEndian, uint32(300))
+			_ = binary.Write(&response, binary.BigEndian, uint16(len(answer.rdata)))
+			response.Write(answer.rdata)
+		}
+		_, _ = conn.WriteTo(response.Bytes(), addr)
+	}
+}
+
+func TestDNSQueryScript(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 isn't installed")
+	}
+
+	cname := append([]byte{3}, append([]byte("www"), 0xc0, 0x0c)...)
+	cases := []struct {
+		name    string
+		rcode   byte
+		answers []fakeDNSAnswer
+		stdout  string
+	}{
+		{
+			name:    "addresses are printed",
+			answers: []fakeDNSAnswer{{rtype: 1, rdata: []byte{10, 0, 0, 10}}, {rtype: 1, rdata: []byte{10, 0, 0, 11}}},
+			stdout:  "rcode 0\n10.0.0.10\n10.0.0.11\n",
+		},
+		{
+			name:    "records of other types are skipped",
+			answers: []fakeDNSAnswer{{rtype: 5, rdata: cname}, {rtype: 1, rdata: []byte{10, 0, 0, 10}}},
+			stdout:  "rcode 0\n10.0.0.10\n",
+		},
+		{
+			name:   "response codes are printed",
+			rcode:  3,
+			stdout: "rcode 3\n",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			// the script always queries port 53
+			conn, err := net.ListenPacket("udp4", "127.0.0.1:53")
+			if err != nil {
+				t.Skipf("unable to listen on port 53: %s", err)
+			}
+			defer conn.Close()
+			go fakeDNSServer(conn, c.rcode, c.answers)
+
+			cmd := exec.Command("python3", "-", "127.0.0.1", "kubernetes.default.svc.cluster.local")
+			cmd.Stdin = strings.NewReader(dnsQueryScript)
+			stdout, err := cmd.Output()
+			if err != nil {
+				t.Fatalf("failed to run DNS query script: %s", err)
+			}
+			if string(stdout) != c.stdout {
+				t.Errorf("expected output %q, but got %q", c.stdout, stdout)
+			}
+		})
+	}
+}
+
+func TestDNSQueryValidator(t *testing.T) {
+	cases := []struct {
+		name   string
+		code   string
+		stdout string
+		err    string
+	}{
+		{
+			name:   "resolved",
+			code:   "0",
+			stdout: "rcode 0\n10.0.0.10\n",
+		},
+		{
+			name: "script failed",
+			code: "1",
+			err:  "terminated with exit code",
+		},
+		{
+			name:   "name not found",
+			code:   "0",
+			stdout: "rcode 3\n",
+			err:    `responded with "rcode 3"`,
+		},
+		{
+			name:   "no addresses",
+			code:   "0",
+			stdout: "rcode 0\n",
+			err:    "resolved to none",
+		},
+	}
+
+	validator := DNSQueryValidator("10.0.0.10", "kubernetes.default.svc.cluster.local")
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			err := validator.Asserter(c.code, c.stdout, "")
+			if c.err == "" {
+				if err != nil {
+					t.Fatalf("expected validator to pass, but got error: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Fatalf("expected error containing %q, but got: %v", c.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+const (
+	kubeletNodeIPFlag        = "--node-ip"
+	kubeletCloudProviderFlag = "--cloud-provider"
+	kubeletCloudConfigFlag   = "--cloud-config"
+
+	// the unspecified IPv6 address, with which kubelet prefers its default IPv6 address over its default IPv4 address
+	ipv6PreferredNodeIP = "::"
+
+	// rendered by containerd's CRI plugin from AgentBaker's kubenet CNI template once the node has been assigned its pod CIDRs
+	kubenetCNIConfigPath = "/etc/cni/net.d/10-containerd-net.conflist"
+
+	ipv4DefaultRoute = "0.0.0.0/0"
+	ipv6DefaultRoute = "::/0"
+)
+
+// IPv6PrimaryMutator configures kubelet to prefer the node's IPv6 address, such that it's reported as the node's primary
+// internal IP on dual-stack clusters
+func IPv6PrimaryMutator(nbc *datamodel.NodeBootstrappingConfiguration) {
+	nbc.KubeletConfig[kubeletNodeIPFlag] = ipv6PreferredNodeIP
+}
+
+// DualStackValidators returns validators asserting that kubelet was started with the cloud provider settings of the bootstrap
+// config, that the cloud provider config rendered by AgentBaker refers to the cluster's network resources, that IPv6
+// forwarding is enabled for pods, and that the node has been assigned a global IPv6 address. The node's IPs and pod CIDRs
+// are validated by the suite, since they're only known once the node has joined the cluster
+func DualStackValidators(nbc *datamodel.NodeBootstrappingConfiguration) []*LiveVMValidator {
+	return []*LiveVMValidator{
+		KubeletCommandLineValidator(map[string]string{
+			kubeletCloudProviderFlag: nbc.KubeletConfig[kubeletCloudProviderFlag],
+			kubeletCloudConfigFlag:   nbc.KubeletConfig[kubeletCloudConfigFlag],
+		}),
+		// kubenet routes the IPv6 pod CIDR of each node through the route table, just as it does the IPv4 pod CIDR
+		JSONFileValidator(azureJSONPath, map[string]any{
+			"subnetName":     nbc.ContainerService.Properties.GetSubnetName(),
+			"vnetName":       nbc.ContainerService.Properties.GetVirtualNetworkName(),
+			"routeTableName": nbc.ContainerService.Properties.GetRouteTableName(),
+		}),
+		SysctlConfigValidator(map[string]string{
+			"net.ipv6.conf.all.forwarding": "1",
+		}),
+		GlobalIPv6AddressValidator("eth0"),
+	}
+}
+
+// GlobalIPv6AddressValidator asserts that the specified interface has been assigned at least one global IPv6 address
+func GlobalIPv6AddressValidator(iface string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s has a global IPv6 address", iface),
+		Command:     fmt.Sprintf("ip -6 -o addr show dev %s scope global", iface),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0", code)
+			}
+			if strings.TrimSpace(stdout) == "" {
+				return fmt.Errorf("expected %s to have a global IPv6 address, but it had none", iface)
+			}
+			return nil
+		},
+	}
+}
+
+// KubenetCNIConfigValidator asserts that the kubenet CNI config rendered on the node allocates pod IPs from each of the
+// specified pod CIDRs, i.e. those assigned to the node, and routes the default route of each of their address families
+func KubenetCNIConfigValidator(podCIDRs []string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s pod CIDRs and routes", kubenetCNIConfigPath),
+		Command:     fmt.Sprintf("cat %s", kubenetCNIConfigPath),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0", code)
+			}
+			var config struct {
+				Plugins []struct {
+					Type string `json:"type"`
+					IPAM struct {
+						Ranges [][]struct {
+							Subnet string `json:"subnet"`
+						} `json:"ranges"`
+						Routes []struct {
+							Dst string `json:"dst"`
+						} `json:"routes"`
+					} `json:"ipam"`
+				} `json:"plugins"`
+			}
+			if err := json.Unmarshal([]byte(stdout), &config); err != nil {
+				return fmt.Errorf("unable to parse %s: %w", kubenetCNIConfigPath, err)
+			}
+
+			subnets := map[string]bool{}
+			routes := map[string]bool{}
+			for _, plugin := range config.Plugins {
+				if plugin.Type != "bridge" {
+					continue
+				}
+				for _, ranges := range plugin.IPAM.Ranges {
+					for _, r := range ranges {
+						subnets[r.Subnet] = true
+					}
+				}
+				for _, route := range plugin.IPAM.Routes {
+					routes[route.Dst] = true
+				}
+			}
+
+			var mismatches []string
+			for _, podCIDR := range podCIDRs {
+				if !subnets[podCIDR] {
+					mismatches = append(mismatches, fmt.Sprintf("expected pod CIDR %s to be allocated from, but it was not", podCIDR))
+				}
+				ip, _, err := net.ParseCIDR(podCIDR)
+				if err != nil {
+					return fmt.Errorf("unable to parse pod CIDR %q: %w", podCIDR, err)
+				}
+				defaultRoute := ipv4DefaultRoute
+				if ip.To4() == nil {
+					defaultRoute = ipv6DefaultRoute
+				}
+				if !routes[defaultRoute] {
+					mismatches = append(mismatches, fmt.Sprintf("expected default route %s to be routed, but it was not", defaultRoute))
+				}
+			}
+			if len(mismatches) > 0 {
+				return fmt.Errorf("%s did not match expectations:\n%s", kubenetCNIConfigPath, strings.Join(mismatches, "\n"))
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,222 @@
+package scenario
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// the number of unchanged lines shown around each changed line of a diff
+	diffContextLines = 3
+
+	// the number of lines of a file shown when its contents don't match expectations
+	fileExcerptLines = 40
+)
+
+// FileExpectations are the expectations of a file's contents asserted by FileValidator, each of which must be met
+type FileExpectations struct {
+	// Equals, when set, is the exact contents the file is expected to have, ignoring a trailing newline
+	Equals *string `json:"equals,omitempty"`
+
+	// Contains are substrings expected within the file
+	Contains []string `json:"contains,omitempty"`
+
+	// NotContains are substrings not expected within the file
+	NotContains []string `json:"notContains,omitempty"`
+
+	// Matches are regular expressions expected to match the file's contents, e.g. `(?m)^--max-pods=\d+$`. Patterns are
+	// matched against the whole file, so need the multi-line flag for ^ and $ to match at line boundaries
+	Matches []string `json:"matches,omitempty"`
+
+	// NotMatches are regular expressions not expected to match the file's contents
+	NotMatches []string `json:"notMatches,omitempty"`
+
+	// JSONFields are the fields expected within the file, which is parsed as a JSON or YAML object, keyed by their dot-separated
+	// path, e.g. "authentication.x509.clientCAFile", and compared against their JSON representation
+	JSONFields map[string]any `json:"jsonFields,omitempty"`
+}
+
+// Validate returns an error if any of the expectations' regular expressions are invalid
+func (e FileExpectations) Validate() error {
+	for _, pattern := range append(append([]string{}, e.Matches...), e.NotMatches...) {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid regular expression %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// FileValidator asserts that the contents of the specified file meet each of the expectations. When they don't, the error
+// describes each unmet expectation, followed by a diff against the expected contents when Equals is set, or an excerpt of the
+// file otherwise. Panics if any of the expectations' regular expressions are invalid, which FileExpectations.Validate reports
+func FileValidator(path string, expected FileExpectations) *LiveVMValidator {
+	if err := expected.Validate(); err != nil {
+		panic(fmt.Sprintf("invalid expectations of %s: %s", path, err))
+	}
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s contents", path),
+		Command:     fmt.Sprintf("cat %s", path),
+		Asserter:    fileExpectationsAsserter(path, expected),
+	}
+}
+
+// Returns an asserter of the output of a command printing the contents of the specified file
+func fileExpectationsAsserter(path string, expected FileExpectations) VMCommandOutputAsserterFn {
+	return func(code, stdout, stderr string) error {
+		if code != "0" {
+			return fmt.Errorf("validator command terminated with exit code %q but expected code 0, stderr: %q", code, stderr)
+		}
+		mismatches, err := compareFileContents(expected, stdout)
+		if err != nil {
+			return fmt.Errorf("unable to compare contents of %s: %w", path, err)
+		}
+		if len(mismatches) == 0 {
+			return nil
+		}
+
+		var message strings.Builder
+		fmt.Fprintf(&message, "%s did not match expectations:\n%s\n", path, strings.Join(mismatches, "\n"))
+		if expected.Equals != nil && trimTrailingNewline(*expected.Equals) != trimTrailingNewline(stdout) {
+			fmt.Fprintf(&message, "diff of %s, in which lines only expected are prefixed with - and those only found with +:\n%s", path, DiffLines(*expected.Equals, stdout))
+		} else {
+			fmt.Fprintf(&message, "contents of %s:\n%s", path, excerptLines(stdout, fileExcerptLines))
+		}
+		return errors.New(strings.TrimSuffix(message.String(), "\n"))
+	}
+}
+
+// Returns a description of each expectation unmet by the contents, in the order they're declared
+func compareFileContents(expected FileExpectations, contents string) ([]string, error) {
+	var mismatches []string
+	if expected.Equals != nil && trimTrailingNewline(*expected.Equals) != trimTrailingNewline(contents) {
+		mismatches = append(mismatches, "expected exact contents, but they differed")
+	}
+	for _, substring := range expected.Contains {
+		if !strings.Contains(contents, substring) {
+			mismatches = append(mismatches, fmt.Sprintf("expected to find %q, but did not", substring))
+		}
+	}
+	for _, substring := range expected.NotContains {
+		if index := strings.Index(contents, substring); index >= 0 {
+			mismatches = append(mismatches, fmt.Sprintf("expected not to find %q, but found it on line %d", substring, lineNumber(contents, index)))
+		}
+	}
+	for _, pattern := range expected.Matches {
+		if !regexp.MustCompile(pattern).MatchString(contents) {
+			mismatches = append(mismatches, fmt.Sprintf("expected to match %q, but did not", pattern))
+		}
+	}
+	for _, pattern := range expected.NotMatches {
+		if match := regexp.MustCompile(pattern).FindStringIndex(contents); match != nil {
+			mismatches = append(mismatches, fmt.Sprintf("expected not to match %q, but matched %q on line %d", pattern, contents[match[0]:match[1]], lineNumber(contents, match[0])))
+		}
+	}
+	if len(expected.JSONFields) > 0 {
+		var object map[string]any
+		if err := yaml.Unmarshal([]byte(contents), &object); err != nil {
+			return nil, fmt.Errorf("unable to parse contents as a JSON or YAML object: %w", err)
+		}
+		fieldMismatches, err := compareJSONFields(expected.JSONFields, object)
+		if err != nil {
+			return nil, err
+		}
+		mismatches = append(mismatches, fieldMismatches...)
+	}
+	return mismatches, nil
+}
+
+// Returns the 1-based number of the line of the contents holding the byte at the specified index
+func lineNumber(contents string, index int) int {
+	return strings.Count(contents[:index], "\n") + 1
+}
+
+// Returns the first maxLines lines of the contents prefixed with their line number, noting how many lines were omitted
+func excerptLines(contents string, maxLines int) string {
+	lines := strings.Split(trimTrailingNewline(contents), "\n")
+	var excerpt strings.Builder
+	for i, line := range lines {
+		if i == maxLines {
+			fmt.Fprintf(&excerpt, "... (%d more lines)\n", len(lines)-maxLines)
+			break
+		}
+		fmt.Fprintf(&excerpt, "%4d | %s\n", i+1, line)
+	}
+	return excerpt.String()
+}
+
+// DiffLines returns a line diff of the expected and actual contents, based on their longest common subsequence of lines. Lines
+// only expected are prefixed with "-", lines only found with "+", and unchanged lines further than diffContextLines from a
+// changed line are elided
+func DiffLines(expected, actual string) string {
+	a := strings.Split(trimTrailingNewline(expected), "\n")
+	b := strings.Split(trimTrailingNewline(actual), "\n")
+
+	// common[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				common[i][j] = common[i+1][j+1] + 1
+			case common[i+1][j] >= common[i][j+1]:
+				common[i][j] = common[i+1][j]
+			default:
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+
+	type diffLine struct {
+		op   byte
+		text string
+	}
+	var lines []diffLine
+	for i, j := 0, 0; i < len(a) || j < len(b); {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{op: ' ', text: a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || common[i+1][j] >= common[i][j+1]):
+			lines = append(lines, diffLine{op: '-', text: a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{op: '+', text: b[j]})
+			j++
+		}
+	}
+
+	changed := func(k int) bool {
+		for c := k - diffContextLines; c <= k+diffContextLines; c++ {
+			if c >= 0 && c < len(lines) && lines[c].op != ' ' {
+				return true
+			}
+		}
+		return false
+	}
+	var diff strings.Builder
+	elided := false
+	for k, line := range lines {
+		if !changed(k) {
+			if !elided {
+				diff.WriteString("  ...\n")
+				elided = true
+			}
+			continue
+		}
+		elided = false
+		fmt.Fprintf(&diff, "%c %s\n", line.op, line.text)
+	}
+	return diff.String()
+}
+
+func trimTrailingNewline(contents string) string {
+	return strings.TrimSuffix(contents, "\n")
+}
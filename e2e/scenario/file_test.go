@@ -0,0 +1,64 @@
+package scenario
+
+import "testing"
+
+func TestDiffLines(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected string
+		actual   string
+		diff     string
+	}{
+		{
+			name:     "identical contents are elided entirely",
+			expected: "a\nb\nc\n",
+			actual:   "a\nb\nc\n",
+			diff:     "  ...\n",
+		},
+		{
+			name:     "a changed line is removed then added",
+			expected: "a\nb\nc\n",
+			actual:   "a\nx\nc\n",
+			diff:     "  a\n- b\n+ x\n  c\n",
+		},
+		{
+			name:     "lines only found are added",
+			expected: "a\nc",
+			actual:   "a\nb\nc",
+			diff:     "  a\n+ b\n  c\n",
+		},
+		{
+			name:     "lines only expected are removed",
+			expected: "a\nb\nc",
+			actual:   "a\nc",
+			diff:     "  a\n- b\n  c\n",
+		},
+		{
+			name:     "a missing trailing newline isn't a difference",
+			expected: "a\nb\n",
+			actual:   "a\nb",
+			diff:     "  ...\n",
+		},
+		{
+			name:     "unchanged lines beyond the context are elided",
+			expected: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n",
+			actual:   "1\n2\n3\n4\n5\n6\n7\nx\n9\n10\n11\n12\n13\n14\n15\n",
+			diff:     "  ...\n  5\n  6\n  7\n- 8\n+ x\n  9\n  10\n  11\n  ...\n",
+		},
+		{
+			name:     "separate changes are elided between",
+			expected: "a\n1\n2\n3\n4\n5\n6\n7\nb\n",
+			actual:   "x\n1\n2\n3\n4\n5\n6\n7\ny\n",
+			diff:     "- a\n+ x\n  1\n  2\n  3\n  ...\n  5\n  6\n  7\n- b\n+ y\n",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			if diff := DiffLines(c.expected, c.actual); diff != c.diff {
+				t.Errorf("expected diff:\n%s\nbut got:\n%s", c.diff, diff)
+			}
+		})
+	}
+}
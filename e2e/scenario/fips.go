@@ -0,0 +1,70 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+const fipsKernelFlagPath = "/proc/sys/crypto/fips_enabled"
+
+// FIPSMutator returns a BootstrapConfigMutator which bootstraps the node from the specified FIPS distro, applying
+// FIPSEnabledMutator
+func FIPSMutator(distro datamodel.Distro) func(*datamodel.NodeBootstrappingConfiguration) {
+	return func(nbc *datamodel.NodeBootstrappingConfiguration) {
+		nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = distro
+		nbc.AgentPoolProfile.Distro = distro
+		FIPSEnabledMutator(nbc)
+	}
+}
+
+// FIPSEnabledMutator configures the agentpool as FIPS-enabled
+func FIPSEnabledMutator(nbc *datamodel.NodeBootstrappingConfiguration) {
+	nbc.FIPSEnabled = true
+}
+
+// FIPSValidators returns the validators asserting that a node bootstrapped by FIPSMutator or FIPSEnabledMutator runs in FIPS
+// mode, i.e. that the kernel was booted with FIPS enabled, and that OpenSSL refuses to use non-approved algorithms. The FIPS
+// node label isn't asserted, since it's applied by AKS rather than by bootstrapping
+func FIPSValidators() []*LiveVMValidator {
+	return []*LiveVMValidator{
+		FIPSKernelFlagValidator(),
+		OpenSSLFIPSModeValidator(),
+	}
+}
+
+// FIPSKernelFlagValidator asserts that the kernel is running in FIPS mode
+func FIPSKernelFlagValidator() *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: "assert kernel is running in FIPS mode",
+		Command:     fmt.Sprintf("cat %s", fipsKernelFlagPath),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0", code)
+			}
+			if flag := strings.TrimSpace(stdout); flag != "1" {
+				return fmt.Errorf("expected %s to be 1, but was %q", fipsKernelFlagPath, flag)
+			}
+			return nil
+		},
+	}
+}
+
+// OpenSSLFIPSModeValidator asserts that OpenSSL is running in FIPS mode by attempting to compute an MD5 digest,
+// which isn't a FIPS-approved algorithm and is therefore rejected
+func OpenSSLFIPSModeValidator() *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: "assert OpenSSL rejects non-FIPS-approved algorithms",
+		Command:     "openssl md5 /etc/hostname",
+		Asserter: func(code, stdout, stderr string) error {
+			if code == "0" {
+				return fmt.Errorf("expected OpenSSL to reject computing an MD5 digest in FIPS mode, but it succeeded: %q", stdout)
+			}
+			if strings.Contains(stderr, "command not found") {
+				return fmt.Errorf("unable to find openssl: %q", stderr)
+			}
+			return nil
+		},
+	}
+}
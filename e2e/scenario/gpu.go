@@ -0,0 +1,37 @@
+package scenario
+
+import (
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+)
+
+const (
+	// the VM tag CSE reads from IMDS to determine whether the GPU driver should be installed, set by AKS on agentpools
+	// created to bring their own GPU driver
+	skipGPUDriverInstallTag = "SkipGPUDriverInstall"
+
+	nvidiaDevicePluginUnit = "nvidia-device-plugin.service"
+
+	ensureGPUDriversTask  = "AKS.CSE.ensureGPUDrivers"
+	cleanUpGPUDriversTask = "AKS.CSE.cleanUpGPUDrivers"
+)
+
+// SkipGPUDriverInstallMutator tags the VMSS model such that CSE skips installing the GPU driver, leaving it to be brought
+// by the user, e.g. through the GPU operator
+func SkipGPUDriverInstallMutator(vmss *armcompute.VirtualMachineScaleSet) {
+	if vmss.Tags == nil {
+		vmss.Tags = map[string]*string{}
+	}
+	vmss.Tags[skipGPUDriverInstallTag] = to.Ptr("true")
+}
+
+// BYOGPUDriverValidators returns validators asserting that CSE skipped installing the GPU driver, instead removing the
+// driver cached on the VHD, and that the device plugin wasn't started despite being enabled, since it requires the driver
+func BYOGPUDriverValidators() []*LiveVMValidator {
+	return []*LiveVMValidator{
+		CSETaskSkippedValidator(ensureGPUDriversTask),
+		CSETaskRanValidator(cleanUpGPUDriversTask),
+		NvidiaSMINotInstalledValidator(),
+		SystemdUnitStatusValidator(nvidiaDevicePluginUnit, SystemdUnitStatus{ActiveState: "inactive"}),
+	}
+}
@@ -0,0 +1,83 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// installed onto the VHD alongside the systemd units which run it to monitor the health of each component
+	healthMonitorScriptPath = "/usr/local/bin/health-monitor.sh"
+
+	// enabled on the VHD, which starts containerd-monitor.service 10 minutes after boot
+	containerdMonitorTimer   = "containerd-monitor.timer"
+	containerdMonitorService = "containerd-monitor.service"
+
+	// logged by health-monitor.sh each time its health check of the container runtime fails, before it restarts the runtime
+	containerdMonitorFailureMessage = "Container runtime containerd failed!"
+)
+
+// HealthMonitorValidators returns validators asserting that the health monitor of the container runtime is installed, that its
+// timer is enabled and scheduled to start it, and that it hasn't had to restart containerd. Whether the monitor itself is
+// running isn't validated, since validators commonly run before the timer has elapsed. Kubelet's health monitor isn't
+// installed onto the VHDs, so isn't validated either
+func HealthMonitorValidators() []*LiveVMValidator {
+	return []*LiveVMValidator{
+		ExecutableFileValidator(healthMonitorScriptPath),
+		// the timer is waiting to elapse until 10 minutes after boot, after which it has elapsed, though remains active
+		SystemdUnitStatusValidator(containerdMonitorTimer, SystemdUnitStatus{ActiveState: "active", UnitFileState: "enabled"}),
+		SystemdTimerValidator(containerdMonitorTimer, containerdMonitorService),
+		HealthMonitorNoFailuresValidator(containerdMonitorService, containerdMonitorFailureMessage),
+	}
+}
+
+// ExecutableFileValidator asserts that the specified file exists and is executable
+func ExecutableFileValidator(fileName string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s is executable", fileName),
+		Command:     fmt.Sprintf("test -x %s", fileName),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("expected %s to exist and be executable, but it did not", fileName)
+			}
+			return nil
+		},
+	}
+}
+
+// SystemdTimerValidator asserts that the specified systemd timer is active, i.e. waiting to elapse or elapsed, and that
+// it triggers the specified unit
+func SystemdTimerValidator(timer, unit string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s is active and triggers %s", timer, unit),
+		Command:     fmt.Sprintf("systemctl show -p Id,ActiveState,Triggers %s", timer),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0", code)
+			}
+			if state := parseSystemdProperties(stdout, "ActiveState")[timer]; state != "active" {
+				return fmt.Errorf("expected %s to be active, but it was %q", timer, state)
+			}
+			if triggers := strings.Fields(parseSystemdProperties(stdout, "Triggers")[timer]); len(triggers) != 1 || triggers[0] != unit {
+				return fmt.Errorf("expected %s to trigger %s, but it triggers %q", timer, unit, strings.Join(triggers, " "))
+			}
+			return nil
+		},
+	}
+}
+
+// HealthMonitorNoFailuresValidator asserts that the specified health monitor service hasn't logged the specified message,
+// logged upon each failed health check of the component it monitors
+func HealthMonitorNoFailuresValidator(service, failureMessage string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s hasn't detected failed health checks", service),
+		Command:     fmt.Sprintf("journalctl -u %s --no-pager -o cat | grep -c -F '%s'", service, failureMessage),
+		Asserter: func(code, stdout, stderr string) error {
+			// grep exits with 1 when nothing matches
+			if code != "1" {
+				return fmt.Errorf("expected %s to have logged no failed health checks, but it logged %s", service, strings.TrimSpace(stdout))
+			}
+			return nil
+		},
+	}
+}
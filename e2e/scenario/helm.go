@@ -0,0 +1,49 @@
+package scenario
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// the maximum length of a Helm release's name
+const maxHelmReleaseNameLength = 53
+
+// HelmChart is a Helm chart installed as one of a scenario's supporting workloads
+type HelmChart struct {
+	// Release is the name of the chart's release, which must be a DNS label of at most 53 characters, e.g. "gpu-operator"
+	Release string
+
+	// Chart references the chart, either by its name within Repo, e.g. "gpu-operator", by an OCI reference or URL, or by the
+	// path of a chart directory relative to the e2e directory, e.g. "scenario/charts/<chart>"
+	Chart string
+
+	// Repo is the URL of the chart repository holding the chart, e.g. "https://helm.ngc.nvidia.com/nvidia", when the chart
+	// is referenced by name
+	Repo string
+
+	// Version is the version of the chart to install, the latest when empty
+	Version string
+
+	// Namespace is the namespace the chart is installed within, which is created when it doesn't exist, defaulting to the
+	// scenario's namespace
+	Namespace string
+
+	// Values are the values the chart is installed with, which are marshalled as YAML and rendered as a Go template with the
+	// values {{ .Namespace }} and {{ .NodeName }}, as the scenario's Manifests are
+	Values map[string]any
+}
+
+// Validate returns an error if the chart doesn't name its release or chart, or names an invalid release
+func (c HelmChart) Validate() error {
+	if c.Chart == "" {
+		return fmt.Errorf("helm chart of release %q doesn't reference a chart", c.Release)
+	}
+	if errs := validation.IsDNS1123Label(c.Release); len(errs) > 0 {
+		return fmt.Errorf("helm chart %q has an invalid release name %q: %v", c.Chart, c.Release, errs)
+	}
+	if len(c.Release) > maxHelmReleaseNameLength {
+		return fmt.Errorf("helm chart %q has a release name %q longer than %d characters", c.Chart, c.Release, maxHelmReleaseNameLength)
+	}
+	return nil
+}
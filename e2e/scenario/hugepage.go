@@ -0,0 +1,56 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	transparentHugePageSysfsDir = "/sys/kernel/mm/transparent_hugepage"
+
+	// read by sysfsutils on boot, to which CSE persists the transparent hugepage settings it applies
+	sysfsConfPath = "/etc/sysfs.conf"
+)
+
+// TransparentHugePageValidators returns validators asserting that the specified transparent hugepage enabled and defrag
+// settings, as specified within a CustomLinuxOSConfig, are in effect on the live node and persisted across reboots. Settings
+// which are empty aren't configured by CSE, so aren't validated
+func TransparentHugePageValidators(enabled, defrag string) []*LiveVMValidator {
+	var validators []*LiveVMValidator
+	for _, setting := range [][2]string{{"enabled", enabled}, {"defrag", defrag}} {
+		name, value := setting[0], setting[1]
+		if value == "" {
+			continue
+		}
+		validators = append(validators,
+			TransparentHugePageSettingValidator(name, value),
+			FileContentsValidator(sysfsConfPath, fmt.Sprintf("kernel/mm/transparent_hugepage/%s=%s", name, value)),
+		)
+	}
+	return validators
+}
+
+// TransparentHugePageSettingValidator asserts that the specified value is selected for the specified transparent hugepage
+// setting, e.g. "enabled", which the kernel reports by bracketing the selected value amongst all possible values,
+// e.g. "always [madvise] never"
+func TransparentHugePageSettingValidator(setting, expected string) *LiveVMValidator {
+	path := fmt.Sprintf("%s/%s", transparentHugePageSysfsDir, setting)
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert transparent hugepage %s is %s", setting, expected),
+		Command:     fmt.Sprintf("cat %s", path),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0", code)
+			}
+			for _, value := range strings.Fields(stdout) {
+				if selected := strings.TrimSuffix(strings.TrimPrefix(value, "["), "]"); selected != value {
+					if selected != expected {
+						return fmt.Errorf("expected transparent hugepage %s to be %s, but it was %s", setting, expected, selected)
+					}
+					return nil
+				}
+			}
+			return fmt.Errorf("unable to find the selected value within %s: %q", path, stdout)
+		},
+	}
+}
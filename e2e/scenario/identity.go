@@ -0,0 +1,94 @@
+package scenario
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	azureJSONPath = "/etc/kubernetes/azure.json"
+
+	// the query string is built by curl, since a literal one would need quoting to survive both the jumpbox's shell and the VM's
+	imdsManagedIdentityTokenCommandTemplate = "curl -s -G -H Metadata:true -d api-version=2018-02-01 -d resource=%s -d client_id=%s http://169.254.169.254/metadata/identity/oauth2/token"
+)
+
+// KubeletIdentityValidators return validators asserting that the node has been configured to authenticate using the
+// user-assigned identity with the specified client and principal IDs, and that the identity can be used to request tokens
+// from IMDS for the specified resource, i.e. the resource manager of the cloud the node runs within.
+func KubeletIdentityValidators(clientID, principalID, resource string) []*LiveVMValidator {
+	return []*LiveVMValidator{
+		// read by both the cloud provider and the credential provider used to authenticate with ACR
+		JSONFileValidator(azureJSONPath, map[string]any{
+			"useManagedIdentityExtension": true,
+			"userAssignedIdentityID":      clientID,
+		}),
+		IMDSManagedIdentityTokenValidator(clientID, principalID, resource),
+	}
+}
+
+// IMDSManagedIdentityTokenValidator asserts that an access token can be requested from IMDS on behalf of the
+// user-assigned identity with the specified client ID, for the specified resource. Since IMDS echoes the requested
+// client ID back, the token's own claims are asserted to have been issued to the identity's application and principal.
+func IMDSManagedIdentityTokenValidator(clientID, principalID, resource string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert an IMDS token can be requested for identity %s", clientID),
+		Command:     fmt.Sprintf(imdsManagedIdentityTokenCommandTemplate, resource, clientID),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0", code)
+			}
+			var token struct {
+				AccessToken string `json:"access_token"`
+				ClientID    string `json:"client_id"`
+			}
+			if err := json.Unmarshal([]byte(stdout), &token); err != nil {
+				return fmt.Errorf("unable to parse IMDS token response: %w", err)
+			}
+			if token.AccessToken == "" {
+				return fmt.Errorf("expected IMDS to issue an access token for identity %s, but it did not", clientID)
+			}
+			if token.ClientID != clientID {
+				return fmt.Errorf("expected IMDS to issue a token for identity %s, but it was issued for %s", clientID, token.ClientID)
+			}
+			claims, err := parseAccessTokenClaims(token.AccessToken)
+			if err != nil {
+				return err
+			}
+			if claims.AppID != clientID || claims.ObjectID != principalID {
+				return fmt.Errorf("expected the token to be issued to application %s and principal %s, but it was issued to application %s and principal %s",
+					clientID, principalID, claims.AppID, claims.ObjectID)
+			}
+			// resource manager audiences are registered both with and without a trailing slash
+			if strings.TrimSuffix(claims.Audience, "/") != strings.TrimSuffix(resource, "/") {
+				return fmt.Errorf("expected the token to be issued for %s, but it was issued for %s", resource, claims.Audience)
+			}
+			return nil
+		},
+	}
+}
+
+// the claims of a managed identity's access token identifying the identity and the resource the token was issued for
+type accessTokenClaims struct {
+	AppID    string `json:"appid"`
+	ObjectID string `json:"oid"`
+	Audience string `json:"aud"`
+}
+
+// Decodes the claims of the supplied JWT access token from its payload, without verifying the token's signature
+func parseAccessTokenClaims(accessToken string) (*accessTokenClaims, error) {
+	segments := strings.Split(accessToken, ".")
+	if len(segments) != 3 {
+		return nil, fmt.Errorf("expected access token to be a JWT of 3 segments, but it had %d", len(segments))
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(segments[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode access token payload: %w", err)
+	}
+	var claims accessTokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("unable to parse access token claims: %w", err)
+	}
+	return &claims, nil
+}
@@ -0,0 +1,123 @@
+package scenario
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+)
+
+// Returns a JWT with the supplied payload, whose header and signature aren't parsed
+func testAccessToken(payload string) string {
+	return "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".c2lnbmF0dXJl"
+}
+
+func TestParseAccessTokenClaims(t *testing.T) {
+	cases := []struct {
+		name        string
+		accessToken string
+		expected    *accessTokenClaims
+		expectErr   bool
+	}{
+		{
+			name:        "managed identity token",
+			accessToken: testAccessToken(`{"aud":"https://management.azure.com/","appid":"client-id","oid":"principal-id","idtyp":"app"}`),
+			expected: &accessTokenClaims{
+				AppID:    "client-id",
+				ObjectID: "principal-id",
+				Audience: "https://management.azure.com/",
+			},
+		},
+		{
+			name:        "padded payload",
+			accessToken: "header." + base64.URLEncoding.EncodeToString([]byte(`{"appid":"id"}`)) + ".signature",
+			expected:    &accessTokenClaims{AppID: "id"},
+		},
+		{
+			name:        "not a JWT",
+			accessToken: "opaque-token",
+			expectErr:   true,
+		},
+		{
+			name:        "payload isn't base64",
+			accessToken: "header.!!!.signature",
+			expectErr:   true,
+		},
+		{
+			name:        "payload isn't JSON",
+			accessToken: testAccessToken("claims"),
+			expectErr:   true,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			claims, err := parseAccessTokenClaims(c.accessToken)
+			if c.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error, but got claims %+v", claims)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, but got: %s", err)
+			}
+			if !reflect.DeepEqual(claims, c.expected) {
+				t.Errorf("expected claims %+v, but got %+v", c.expected, claims)
+			}
+		})
+	}
+}
+
+func TestIMDSManagedIdentityTokenValidator(t *testing.T) {
+	const resource = "https://management.azure.com/"
+	validator := IMDSManagedIdentityTokenValidator("client-id", "principal-id", resource)
+
+	cases := []struct {
+		name      string
+		response  string
+		expectErr bool
+	}{
+		{
+			name:     "token issued to the identity",
+			response: `{"client_id":"client-id","access_token":"` + testAccessToken(`{"aud":"https://management.azure.com","appid":"client-id","oid":"principal-id"}`) + `"}`,
+		},
+		{
+			name:      "token issued to another principal",
+			response:  `{"client_id":"client-id","access_token":"` + testAccessToken(`{"aud":"https://management.azure.com/","appid":"client-id","oid":"other-principal-id"}`) + `"}`,
+			expectErr: true,
+		},
+		{
+			name:      "token issued to another application",
+			response:  `{"client_id":"client-id","access_token":"` + testAccessToken(`{"aud":"https://management.azure.com/","appid":"other-client-id","oid":"principal-id"}`) + `"}`,
+			expectErr: true,
+		},
+		{
+			name:      "token issued for another resource",
+			response:  `{"client_id":"client-id","access_token":"` + testAccessToken(`{"aud":"https://storage.azure.com/","appid":"client-id","oid":"principal-id"}`) + `"}`,
+			expectErr: true,
+		},
+		{
+			name:      "token requested for another identity",
+			response:  `{"client_id":"other-client-id","access_token":"` + testAccessToken(`{"aud":"https://management.azure.com/","appid":"client-id","oid":"principal-id"}`) + `"}`,
+			expectErr: true,
+		},
+		{
+			name:      "no token",
+			response:  `{"error":"invalid_request","error_description":"Identity not found"}`,
+			expectErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			err := validator.Asserter("0", c.response, "")
+			if c.expectErr && err == nil {
+				t.Errorf("expected an error, but got none")
+			} else if !c.expectErr && err != nil {
+				t.Errorf("expected no error, but got: %s", err)
+			}
+		})
+	}
+}
@@ -1,6 +1,41 @@
 package scenario
 
-// These SIG image versions are stored in the ACS test subscription, guarded by resource deletion locks
+import "fmt"
+
+const (
+	// The shared image gallery within the ACS test subscription which contains the test VHDs
+	GallerySubscriptionID    = "8ecadfc9-d1a3-4ea4-b844-0d9f87e4d7c8"
+	GalleryResourceGroupName = "aksvhdtestbuildrg"
+	GalleryName              = "PackerSigGalleryEastUS"
+
+	imageDefinitionIDTemplate = "/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/galleries/%s/images/%s"
+	gen1ImageNameSuffix       = "-gen1"
+)
+
+// Maps each VHD name used by the scenarios to its respective SIG image definition within the test gallery,
+// used to resolve the latest version of each image at runtime
+var ImageDefinitionNames = map[string]string{
+	"ubuntu1804":         "1804Gen2",
+	"ubuntu2204":         "2204Gen2",
+	"marinerv2":          "CBLMarinerV2Gen2",
+	"azurelinuxv2":       "AzureLinuxV2Gen2",
+	"ubuntu2204-arm64":   "2204Gen2Arm64",
+	"marinerv2-arm64":    "CBLMarinerV2Gen2Arm64",
+	"azurelinuxv2-arm64": "AzureLinuxV2Gen2Arm64",
+	"ubuntu1804-gen1":    "1804",
+	"ubuntu2204-gen1":    "2204",
+	"marinerv2-gen1":     "CBLMarinerV2",
+	"azurelinuxv2-gen1":  "AzureLinuxV2",
+	"ubuntu2004-fips":    "2004FIPSGen2",
+	"azurelinuxv2-fips":  "AzureLinuxV2Gen2FIPS",
+	"marinerv2-fips":     "CBLMarinerV2Gen2FIPS",
+	"marinerv2-kata":     "CBLMarinerV2Gen2Kata",
+	"azurelinuxv2-kata":  "AzureLinuxV2Gen2Kata",
+}
+
+// These SIG image versions are stored in the ACS test subscription, guarded by resource deletion locks.
+// They are used as fallbacks in the case where the latest version of the respective image cannot be resolved at runtime,
+// otherwise the suite will overwrite each entry with the resource ID of the latest version before running any scenarios
 var DefaultImageVersionIDs = map[string]string{
 	"ubuntu1804":         "/subscriptions/8ecadfc9-d1a3-4ea4-b844-0d9f87e4d7c8/resourceGroups/aksvhdtestbuildrg/providers/Microsoft.Compute/galleries/PackerSigGalleryEastUS/images/1804Gen2/versions/1.1687293275.3742",
 	"ubuntu2204":         "/subscriptions/8ecadfc9-d1a3-4ea4-b844-0d9f87e4d7c8/resourceGroups/aksvhdtestbuildrg/providers/Microsoft.Compute/galleries/PackerSigGalleryEastUS/images/2204Gen2/versions/1.1687293262.1409",
@@ -9,4 +44,29 @@ var DefaultImageVersionIDs = map[string]string{
 	"ubuntu2204-arm64":   "/subscriptions/8ecadfc9-d1a3-4ea4-b844-0d9f87e4d7c8/resourceGroups/aksvhdtestbuildrg/providers/Microsoft.Compute/galleries/PackerSigGalleryEastUS/images/2204Gen2Arm64/versions/1.1687293304.23474",
 	"marinerv2-arm64":    "/subscriptions/8ecadfc9-d1a3-4ea4-b844-0d9f87e4d7c8/resourceGroups/aksvhdtestbuildrg/providers/Microsoft.Compute/galleries/PackerSigGalleryEastUS/images/CBLMarinerV2Gen2Arm64/versions/1.1687293288.20788",
 	"azurelinuxv2-arm64": "/subscriptions/8ecadfc9-d1a3-4ea4-b844-0d9f87e4d7c8/resourceGroups/aksvhdtestbuildrg/providers/Microsoft.Compute/galleries/PackerSigGalleryEastUS/images/AzureLinuxV2Gen2Arm64/versions/1.1694137200.8668",
+	// no Gen1 image versions are guarded by deletion locks, so the Gen1 images fall back to their image definitions,
+	// which VMs are created from the latest version of
+	"ubuntu1804-gen1":   imageDefinitionID("1804"),
+	"ubuntu2204-gen1":   imageDefinitionID("2204"),
+	"marinerv2-gen1":    imageDefinitionID("CBLMarinerV2"),
+	"azurelinuxv2-gen1": imageDefinitionID("AzureLinuxV2"),
+	// likewise, no FIPS or kata image versions are guarded by deletion locks
+	"ubuntu2004-fips":   imageDefinitionID("2004FIPSGen2"),
+	"azurelinuxv2-fips": imageDefinitionID("AzureLinuxV2Gen2FIPS"),
+	"marinerv2-fips":    imageDefinitionID("CBLMarinerV2Gen2FIPS"),
+	"marinerv2-kata":    imageDefinitionID("CBLMarinerV2Gen2Kata"),
+	"azurelinuxv2-kata": imageDefinitionID("AzureLinuxV2Gen2Kata"),
+}
+
+// ImageVersionID returns the resource ID of the image version to use for the named VHD, e.g. "ubuntu2204",
+// with the specified Hyper-V generation
+func ImageVersionID(name string, generation HyperVGeneration) string {
+	if generation == HyperVGenerationV1 {
+		name += gen1ImageNameSuffix
+	}
+	return DefaultImageVersionIDs[name]
+}
+
+func imageDefinitionID(imageName string) string {
+	return fmt.Sprintf(imageDefinitionIDTemplate, GallerySubscriptionID, GalleryResourceGroupName, GalleryName, imageName)
 }
@@ -1,40 +1,121 @@
 package scenario
 
 import (
+	"fmt"
 	"log"
+	"os"
+	"strings"
 )
 
-// Initializes and returns the set of scenarios comprising the E2E suite in table-form.
-func InitScenarioTable(include, exclude map[string]bool) Table {
+// Initializes and returns the set of scenarios comprising the E2E suite in table-form, including only those
+// matched by the supplied selector. Scenarios defined in YAML within definitionsDir are included alongside
+// those defined in Go.
+func InitScenarioTable(selector Selector, definitionsDir string) (Table, error) {
+	ordered, err := loadScenarios(definitionsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	defined := map[string]*Scenario{}
 	table := Table{}
-	for _, scenario := range scenarios() {
-		if include != nil {
-			if !include[scenario.Name] {
-				continue
-			}
-		} else if exclude != nil {
-			if exclude[scenario.Name] {
-				continue
-			}
+	for _, scenario := range ordered {
+		defined[scenario.Name] = scenario
+		if !selector.Matches(scenario) {
+			continue
 		}
 		log.Printf("will run E2E scenario %q: %s", scenario.Name, scenario.Description)
 		table[scenario.Name] = scenario
 	}
-	return table
+
+	if err := addDependencies(table, defined); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// DefinedScenarios returns every scenario of the E2E suite in table-form, regardless of whether it would be selected to run,
+// including those defined in YAML within definitionsDir
+func DefinedScenarios(definitionsDir string) (Table, error) {
+	ordered, err := loadScenarios(definitionsDir)
+	if err != nil {
+		return nil, err
+	}
+	table := Table{}
+	for _, scenario := range ordered {
+		table[scenario.Name] = scenario
+	}
+	return table, nil
+}
+
+// Returns the scenarios defined in Go followed by those defined in YAML within definitionsDir, returning an error when a
+// scenario is defined more than once or has an invalid live VM validator
+func loadScenarios(definitionsDir string) ([]*Scenario, error) {
+	definitions, err := LoadDefinitions(definitionsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := append(scenarios(), definitions...)
+	defined := map[string]bool{}
+	for _, scenario := range ordered {
+		if defined[scenario.Name] {
+			return nil, fmt.Errorf("scenario %q is defined more than once", scenario.Name)
+		}
+		defined[scenario.Name] = true
+
+		for _, validator := range scenario.LiveVMValidators {
+			if err := validator.Validate(); err != nil {
+				return nil, fmt.Errorf("scenario %q has an invalid live VM validator: %w", scenario.Name, err)
+			}
+		}
+
+		if scenario.Manifests != "" {
+			if info, err := os.Stat(scenario.Manifests); err != nil || !info.IsDir() {
+				return nil, fmt.Errorf("scenario %q has manifests directory %q, which isn't a directory", scenario.Name, scenario.Manifests)
+			}
+		}
+
+		for _, chart := range scenario.HelmCharts {
+			if err := chart.Validate(); err != nil {
+				return nil, fmt.Errorf("scenario %q has an invalid helm chart: %w", scenario.Name, err)
+			}
+		}
+
+		if scenario.NvidiaGPUs < 0 {
+			return nil, fmt.Errorf("scenario %q expects %d NVIDIA GPUs, which can't be negative", scenario.Name, scenario.NvidiaGPUs)
+		}
+
+		if scenario.BootstrapLatencySLO != nil {
+			if err := scenario.BootstrapLatencySLO.Validate(); err != nil {
+				return nil, fmt.Errorf("scenario %q has an invalid bootstrap latency SLO: %w", scenario.Name, err)
+			}
+		}
+
+		parameters := map[string]bool{}
+		for _, parameter := range scenario.Parameters {
+			if parameter.Name == "" || strings.Contains(parameter.Name, "/") {
+				return nil, fmt.Errorf("scenario %q has a parameter named %q, though parameters must be named without slashes", scenario.Name, parameter.Name)
+			}
+			if parameters[parameter.Name] {
+				return nil, fmt.Errorf("scenario %q has more than one parameter named %q", scenario.Name, parameter.Name)
+			}
+			parameters[parameter.Name] = true
+		}
+	}
+	return ordered, nil
 }
 
 // This function is called internally by the scenario package to get each e2e scenario's respective config as one long slice.
 // To add a sceneario, implement a new function in a separate file that returns a *Scenario and add
-// its return value to the slice returned by this function.
+// its return value to the slice returned by this function. Basic scenarios which only vary by distro, Kubernetes version
+// and network plugin are instead generated from the matrices defined in scenario_matrix.go.
 func scenarios() []*Scenario {
-	return []*Scenario{
-		ubuntu1804(),
-		ubuntu2204(),
-		marinerv2(),
-		azurelinuxv2(),
+	var scenarios []*Scenario
+	for _, matrix := range []Matrix{basicMatrix(), kubernetesVersionMatrix(), marinerV2VariantMatrix()} {
+		scenarios = append(scenarios, matrix.Scenarios()...)
+	}
+	return append(scenarios,
 		ubuntu2204ARM64(),
-		marinerv2ARM64(),
-		azurelinuxv2ARM64(),
 		ubuntu1804gpu(),
 		marinerv2gpu(),
 		azurelinuxv2gpu(),
@@ -44,13 +125,48 @@ func scenarios() []*Scenario {
 		ubuntu2204Wasm(),
 		marinerv2Wasm(),
 		azurelinuxv2Wasm(),
-		ubuntu1804_azurecni(),
-		marinerv2_azurecni(),
-		azurelinuxv2_azurecni(),
 		ubuntu1804gpu_azurecni(),
 		marinerv2gpu_azurecni(),
 		azurelinuxv2gpu_azurecni(),
 		ubuntu2204gpuNoDriver(),
+		ubuntu2204gpuMIG(),
+		ubuntu2204gpuBYODriver(),
 		ubuntu2204CustomCATrust(),
-	}
+		ubuntu2204Spot(),
+		ubuntu2204KubeletTempDisk(),
+		ubuntu2204MultiInstance(),
+		ubuntu2204SpotEviction(),
+		ubuntu2204EphemeralOSDisk(),
+		ubuntu2204DataDisks(),
+		ubuntu2204UltraSSD(),
+		ubuntu2204EncryptionAtHost(),
+		ubuntu2204Gen1(),
+		azurelinuxv2Gen1(),
+		ubuntu2204SSHKeyRotation(),
+		ubuntu2204KubeletIdentity(),
+		ubuntu2204UnreachableAPIServer(),
+		ubuntu2004FIPS(),
+		ubuntu2204CIS(),
+		ubuntu2204CgroupV2(),
+		azurelinuxv2CgroupV2(),
+		ubuntu2204HTTPProxy(),
+		ubuntu2204CustomKubeletConfig(),
+		azurelinuxv2CustomKubeletConfig(),
+		ubuntu2204KubeletServingCertRotation(),
+		ubuntu2204KubeletServingCertRotationDisabled(),
+		ubuntu2204PrivateACR(),
+		ubuntu2204IPv6Primary(),
+		ubuntu2204CustomDataDir(),
+		ubuntu2204SSHDisabled(),
+		ubuntu2204CustomLinuxOSConfig(),
+		ubuntu2204TransparentHugePage(),
+		ubuntu2204Swap(),
+		ubuntu2204NodeAllocatable(),
+		ubuntu2204Rebootstrap(),
+		ubuntu2204VMSizes(),
+		ubuntu2204BootstrapLatency(),
+		ubuntu2204WorkloadManifests(),
+		ubuntu2204HelmChart(),
+		ubuntu2204CSIDrivers(),
+	)
 }
@@ -0,0 +1,208 @@
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	kubeletDefaultsFilePath    = "/etc/default/kubelet"
+	kubeletConfigFilePath      = "/etc/default/kubeletconfig.json"
+	kubeletNodeLabelsEnvVar    = "KUBELET_NODE_LABELS"
+	kubeletConfigPathSeparator = "."
+)
+
+// KubeletCommandLineValidator asserts that the running kubelet process was started with the expected flags.
+// Flags are keyed by their full name, e.g. "--max-pods", matching the keys of datamodel.KubeletConfig.
+func KubeletCommandLineValidator(expectedFlags map[string]string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: "assert running kubelet command line flags",
+		Command:     "pgrep -a -x kubelet",
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("unable to find running kubelet process, validator command terminated with exit code %q", code)
+			}
+			if mismatches := compareExpectedValues(expectedFlags, parseKubeletFlags(stdout)); len(mismatches) > 0 {
+				return fmt.Errorf("running kubelet flags did not match expectations:\n%s", strings.Join(mismatches, "\n"))
+			}
+			return nil
+		},
+	}
+}
+
+// KubeletNodeLabelsValidator asserts that KUBELET_NODE_LABELS within /etc/default/kubelet contains the expected labels.
+func KubeletNodeLabelsValidator(expectedLabels map[string]string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s within %s", kubeletNodeLabelsEnvVar, kubeletDefaultsFilePath),
+		Command:     fmt.Sprintf("cat %s", kubeletDefaultsFilePath),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0", code)
+			}
+			labels := parseNodeLabels(parseEnvironmentFile(stdout)[kubeletNodeLabelsEnvVar])
+			if mismatches := compareExpectedValues(expectedLabels, labels); len(mismatches) > 0 {
+				return fmt.Errorf("%s did not match expectations:\n%s", kubeletNodeLabelsEnvVar, strings.Join(mismatches, "\n"))
+			}
+			return nil
+		},
+	}
+}
+
+// KubeletConfigFileValidator asserts that the kubelet config file written when KubeletConfigFileEnabled is set
+// contains the expected fields. Fields are keyed by their dot-separated path within the config, e.g.
+// "evictionHard.memory.available", and compared against their JSON representation.
+func KubeletConfigFileValidator(expectedFields map[string]any) *LiveVMValidator {
+	return JSONFileValidator(kubeletConfigFilePath, expectedFields)
+}
+
+// JSONFileValidator asserts that the JSON file at the specified path contains the expected fields, keyed by
+// their dot-separated path within the file.
+func JSONFileValidator(path string, expectedFields map[string]any) *LiveVMValidator {
+	validator := FileValidator(path, FileExpectations{JSONFields: expectedFields})
+	validator.Description = fmt.Sprintf("assert %s fields", path)
+	return validator
+}
+
+// KubeletConfigzValidator asserts that the effective configuration of the running kubelet, as served by its /configz
+// endpoint, contains the expected fields. Unlike KubeletConfigFileValidator, this also covers fields set through kubelet's
+// command line, which take precedence over the config file. Fields are keyed by their dot-separated path within the
+// config, e.g. "evictionHard.memory.available", and compared against their JSON representation.
+func KubeletConfigzValidator(expectedFields map[string]any) *KubeletConfigValidator {
+	return &KubeletConfigValidator{
+		Description: "assert effective kubelet configuration",
+		Asserter: func(config map[string]any) error {
+			mismatches, err := compareJSONFields(expectedFields, config)
+			if err != nil {
+				return err
+			}
+			if len(mismatches) > 0 {
+				return fmt.Errorf("effective kubelet configuration did not match expectations:\n%s", strings.Join(mismatches, "\n"))
+			}
+			return nil
+		},
+	}
+}
+
+// Compares the expected fields, keyed by their dot-separated path, against a decoded JSON object, returning a description
+// of each mismatch ordered by path
+func compareJSONFields(expectedFields map[string]any, object map[string]any) ([]string, error) {
+	fieldPaths := make([]string, 0, len(expectedFields))
+	for fieldPath := range expectedFields {
+		fieldPaths = append(fieldPaths, fieldPath)
+	}
+	sort.Strings(fieldPaths)
+
+	var mismatches []string
+	for _, fieldPath := range fieldPaths {
+		expected, err := normalizeJSONValue(expectedFields[fieldPath])
+		if err != nil {
+			return nil, fmt.Errorf("unable to normalize expected value of %s: %w", fieldPath, err)
+		}
+		actual, found := lookupJSONPath(object, fieldPath)
+		if !found {
+			mismatches = append(mismatches, fmt.Sprintf("expected %s to be %v, but it was not set", fieldPath, expected))
+		} else if !reflect.DeepEqual(expected, actual) {
+			mismatches = append(mismatches, fmt.Sprintf("expected %s to be %v, but was %v", fieldPath, expected, actual))
+		}
+	}
+	return mismatches, nil
+}
+
+// Parses each "--flag=value" or boolean "--flag" within the supplied command line into a mapping from flag to value
+func parseKubeletFlags(commandLine string) map[string]string {
+	flags := map[string]string{}
+	for _, field := range strings.Fields(commandLine) {
+		if !strings.HasPrefix(field, "--") {
+			continue
+		}
+		if name, value, found := strings.Cut(field, "="); found {
+			flags[name] = value
+		} else {
+			flags[name] = "true"
+		}
+	}
+	return flags
+}
+
+// Parses the KEY=VALUE lines of a systemd environment file, such as /etc/default/kubelet
+func parseEnvironmentFile(contents string) map[string]string {
+	env := map[string]string{}
+	for _, line := range strings.Split(contents, "\n") {
+		if key, value, found := strings.Cut(strings.TrimSpace(line), "="); found {
+			env[key] = strings.Trim(value, `"`)
+		}
+	}
+	return env
+}
+
+// Parses a comma-separated list of node labels, e.g. "kubernetes.azure.com/role=agent,agentpool=nodepool1"
+func parseNodeLabels(labelList string) map[string]string {
+	labels := map[string]string{}
+	for _, label := range strings.Split(labelList, ",") {
+		if key, value, found := strings.Cut(label, "="); found {
+			labels[key] = value
+		}
+	}
+	return labels
+}
+
+// Compares the expected values against their actual values, returning a description of each mismatch ordered by key
+func compareExpectedValues(expected, actual map[string]string) []string {
+	keys := make([]string, 0, len(expected))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var mismatches []string
+	for _, key := range keys {
+		actualValue, found := actual[key]
+		if !found {
+			mismatches = append(mismatches, fmt.Sprintf("expected to find %s set to %v, but it was not found", key, expected[key]))
+		} else if actualValue != expected[key] {
+			mismatches = append(mismatches, fmt.Sprintf("expected to find %s set to %v, but was set to %v", key, expected[key], actualValue))
+		}
+	}
+	return mismatches
+}
+
+// Round-trips the supplied value through JSON such that it can be compared against values decoded from a JSON file
+func normalizeJSONValue(value any) (any, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var normalized any
+	if err := json.Unmarshal(raw, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// Walks the supplied dot-separated path through a decoded JSON object. Since some keys contain the separator themselves, e.g.
+// the "memory.available" eviction signal of "evictionHard.memory.available", the longest key matching the remainder of the
+// path is preferred at each level
+func lookupJSONPath(object map[string]any, path string) (any, bool) {
+	var current any = object
+	segments := strings.Split(path, kubeletConfigPathSeparator)
+	for len(segments) > 0 {
+		fields, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		found := false
+		for i := len(segments); i > 0; i-- {
+			if value, ok := fields[strings.Join(segments[:i], kubeletConfigPathSeparator)]; ok {
+				current, segments, found = value, segments[i:], true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return current, true
+}
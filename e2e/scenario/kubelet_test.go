@@ -0,0 +1,286 @@
+package scenario
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// the effective kubelet configuration the JSON path tests below are evaluated against, as decoded from the kubelet's configz
+const testKubeletConfigz = `{
+	"maxPods": 30,
+	"serializeImagePulls": false,
+	"evictionHard": {"memory.available": "750Mi", "nodefs.available": "10%"},
+	"authentication": {"webhook": {"enabled": true, "cacheTTL": "2m0s"}},
+	"clusterDNS": ["10.0.0.10"]
+}`
+
+func decodeTestKubeletConfigz(t *testing.T) map[string]any {
+	var object map[string]any
+	if err := json.Unmarshal([]byte(testKubeletConfigz), &object); err != nil {
+		t.Fatalf("unable to unmarshal kubelet configz: %s", err)
+	}
+	return object
+}
+
+func TestParseKubeletFlags(t *testing.T) {
+	cases := []struct {
+		name        string
+		commandLine string
+		expected    map[string]string
+	}{
+		{
+			name:     "no command line",
+			expected: map[string]string{},
+		},
+		{
+			name:        "flags with values",
+			commandLine: "/usr/local/bin/kubelet --max-pods=30 --node-labels=agentpool=nodepool1,kubernetes.azure.com/role=agent",
+			expected: map[string]string{
+				"--max-pods":    "30",
+				"--node-labels": "agentpool=nodepool1,kubernetes.azure.com/role=agent",
+			},
+		},
+		{
+			name:        "boolean flags",
+			commandLine: "kubelet --enable-server --rotate-certificates=false",
+			expected: map[string]string{
+				"--enable-server":       "true",
+				"--rotate-certificates": "false",
+			},
+		},
+		{
+			name:        "arguments which aren't flags are ignored",
+			commandLine: "kubelet -v 2 --max-pods=30",
+			expected:    map[string]string{"--max-pods": "30"},
+		},
+		{
+			name:        "empty value",
+			commandLine: "kubelet --node-ip=",
+			expected:    map[string]string{"--node-ip": ""},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			if flags := parseKubeletFlags(c.commandLine); !reflect.DeepEqual(flags, c.expected) {
+				t.Errorf("expected flags %v, but got %v", c.expected, flags)
+			}
+		})
+	}
+}
+
+func TestParseEnvironmentFile(t *testing.T) {
+	cases := []struct {
+		name     string
+		contents string
+		expected map[string]string
+	}{
+		{
+			name:     "no contents",
+			expected: map[string]string{},
+		},
+		{
+			name:     "quoted and unquoted values",
+			contents: "KUBELET_FLAGS=--max-pods=30 --node-ip=10.224.0.4\nKUBELET_NODE_LABELS=\"agentpool=nodepool1\"\n",
+			expected: map[string]string{
+				"KUBELET_FLAGS":       "--max-pods=30 --node-ip=10.224.0.4",
+				"KUBELET_NODE_LABELS": "agentpool=nodepool1",
+			},
+		},
+		{
+			name:     "surrounding whitespace and lines without assignments",
+			contents: "\n  KUBELET_REGISTER_SCHEDULABLE=true  \nnot an assignment\n",
+			expected: map[string]string{"KUBELET_REGISTER_SCHEDULABLE": "true"},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			if env := parseEnvironmentFile(c.contents); !reflect.DeepEqual(env, c.expected) {
+				t.Errorf("expected environment %v, but got %v", c.expected, env)
+			}
+		})
+	}
+}
+
+func TestParseNodeLabels(t *testing.T) {
+	cases := []struct {
+		name      string
+		labelList string
+		expected  map[string]string
+	}{
+		{
+			name:     "no labels",
+			expected: map[string]string{},
+		},
+		{
+			name:      "several labels",
+			labelList: "kubernetes.azure.com/role=agent,agentpool=nodepool1",
+			expected: map[string]string{
+				"kubernetes.azure.com/role": "agent",
+				"agentpool":                 "nodepool1",
+			},
+		},
+		{
+			name:      "empty value and elements without assignments",
+			labelList: "kubernetes.azure.com/mode=,bogus",
+			expected:  map[string]string{"kubernetes.azure.com/mode": ""},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			if labels := parseNodeLabels(c.labelList); !reflect.DeepEqual(labels, c.expected) {
+				t.Errorf("expected labels %v, but got %v", c.expected, labels)
+			}
+		})
+	}
+}
+
+func TestLookupJSONPath(t *testing.T) {
+	cases := []struct {
+		name     string
+		path     string
+		expected any
+		found    bool
+	}{
+		{
+			name:     "top-level field",
+			path:     "maxPods",
+			expected: float64(30),
+			found:    true,
+		},
+		{
+			name:     "nested field",
+			path:     "authentication.webhook.cacheTTL",
+			expected: "2m0s",
+			found:    true,
+		},
+		{
+			name:     "key containing the separator",
+			path:     "evictionHard.memory.available",
+			expected: "750Mi",
+			found:    true,
+		},
+		{
+			name:     "object",
+			path:     "authentication.webhook",
+			expected: map[string]any{"enabled": true, "cacheTTL": "2m0s"},
+			found:    true,
+		},
+		{
+			name:     "array",
+			path:     "clusterDNS",
+			expected: []any{"10.0.0.10"},
+			found:    true,
+		},
+		{
+			name: "missing field",
+			path: "authentication.x509",
+		},
+		{
+			name: "path through a field which isn't an object",
+			path: "maxPods.value",
+		},
+	}
+
+	object := decodeTestKubeletConfigz(t)
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			value, found := lookupJSONPath(object, c.path)
+			if found != c.found {
+				t.Fatalf("expected found to be %t, but got %t", c.found, found)
+			}
+			if !reflect.DeepEqual(value, c.expected) {
+				t.Errorf("expected value %v, but got %v", c.expected, value)
+			}
+		})
+	}
+}
+
+func TestCompareJSONFields(t *testing.T) {
+	cases := []struct {
+		name           string
+		expectedFields map[string]any
+		mismatches     []string
+	}{
+		{
+			name: "matching fields of each type",
+			expectedFields: map[string]any{
+				"maxPods":                       30,
+				"serializeImagePulls":           false,
+				"evictionHard.memory.available": "750Mi",
+				"authentication.webhook":        map[string]any{"enabled": true, "cacheTTL": "2m0s"},
+				"clusterDNS":                    []string{"10.0.0.10"},
+			},
+		},
+		{
+			name: "mismatches are ordered by path",
+			expectedFields: map[string]any{
+				"maxPods":                       110,
+				"authentication.webhook.nope":   true,
+				"evictionHard.nodefs.available": "10%",
+			},
+			mismatches: []string{
+				"expected authentication.webhook.nope to be true, but it was not set",
+				"expected maxPods to be 110, but was 30",
+			},
+		},
+	}
+
+	object := decodeTestKubeletConfigz(t)
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			mismatches, err := compareJSONFields(c.expectedFields, object)
+			if err != nil {
+				t.Fatalf("expected no error, but got: %s", err)
+			}
+			if !reflect.DeepEqual(mismatches, c.mismatches) {
+				t.Errorf("expected mismatches %q, but got %q", c.mismatches, mismatches)
+			}
+		})
+	}
+}
+
+func TestCompareExpectedValues(t *testing.T) {
+	cases := []struct {
+		name       string
+		expected   map[string]string
+		actual     map[string]string
+		mismatches []string
+	}{
+		{
+			name:   "nothing expected",
+			actual: map[string]string{"--max-pods": "30"},
+		},
+		{
+			name:     "matching values, ignoring unexpected keys",
+			expected: map[string]string{"--max-pods": "30"},
+			actual:   map[string]string{"--max-pods": "30", "--node-ip": "10.224.0.4"},
+		},
+		{
+			name:     "mismatches are ordered by key",
+			expected: map[string]string{"--node-ip": "10.224.0.5", "--max-pods": "30", "--enable-server": "true"},
+			actual:   map[string]string{"--node-ip": "10.224.0.4", "--max-pods": "30"},
+			mismatches: []string{
+				"expected to find --enable-server set to true, but it was not found",
+				"expected to find --node-ip set to 10.224.0.5, but was set to 10.224.0.4",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			if mismatches := compareExpectedValues(c.expected, c.actual); !reflect.DeepEqual(mismatches, c.mismatches) {
+				t.Errorf("expected mismatches %q, but got %q", c.mismatches, mismatches)
+			}
+		})
+	}
+}
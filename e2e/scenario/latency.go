@@ -0,0 +1,38 @@
+package scenario
+
+import (
+	"fmt"
+	"time"
+)
+
+// BootstrapLatencySLO is the objective of the time taken for each of a scenario's nodes to become Ready, measured from the
+// request creating the scenario's VMSS, and thereby its instances. Scenarios are expected to set a separate objective per
+// VHD, as each boots from a single VHD, while objectives can be refined per VM size
+type BootstrapLatencySLO struct {
+	// Default is the objective of VM sizes without one of their own
+	Default time.Duration
+
+	// VMSizes are the objectives of specific VM sizes, keyed by VM size, e.g. "Standard_D2ds_v5"
+	VMSizes map[string]time.Duration
+}
+
+// Objective returns the objective of nodes of the specified VM size
+func (s *BootstrapLatencySLO) Objective(vmSize string) time.Duration {
+	if objective, ok := s.VMSizes[vmSize]; ok {
+		return objective
+	}
+	return s.Default
+}
+
+// Validate returns an error if any of the objectives aren't positive
+func (s *BootstrapLatencySLO) Validate() error {
+	if s.Default <= 0 {
+		return fmt.Errorf("default objective must be positive, but was %s", s.Default)
+	}
+	for vmSize, objective := range s.VMSizes {
+		if objective <= 0 {
+			return fmt.Errorf("objective of VM size %s must be positive, but was %s", vmSize, objective)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package scenario
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+)
+
+const (
+	// reserved by AgentBaker whenever the custom local port range would otherwise include it
+	reservedLocalPort = 65330
+
+	// the mount point of the resource disk, on which CSE creates the swap file when it has enough free space, or otherwise the OS disk
+	resourceDiskSwapFilePath = "/mnt/swapfile"
+	osDiskSwapFilePath       = "/swapfile"
+)
+
+// CustomLinuxOSConfigMutator returns a BootstrapConfigMutator which bootstraps the node with the specified CustomLinuxOSConfig. Since
+// AgentBaker only creates a swap file when kubelet is configured not to fail when swap is enabled, failSwapOn is also turned off
+// when the config specifies a swap file size
+func CustomLinuxOSConfigMutator(config *datamodel.CustomLinuxOSConfig) func(*datamodel.NodeBootstrappingConfiguration) {
+	return func(nbc *datamodel.NodeBootstrappingConfiguration) {
+		for _, profile := range []*datamodel.AgentPoolProfile{nbc.AgentPoolProfile, nbc.ContainerService.Properties.AgentPoolProfiles[0]} {
+			profile.CustomLinuxOSConfig = config
+			if config.SwapFileSizeMB != nil {
+				if profile.CustomKubeletConfig == nil {
+					profile.CustomKubeletConfig = &datamodel.CustomKubeletConfig{}
+				}
+				profile.CustomKubeletConfig.FailSwapOn = to.Ptr(false)
+			}
+		}
+	}
+}
+
+// CustomLinuxOSConfigValidators returns validators asserting each of the settings of the specified CustomLinuxOSConfig on the node,
+// such that scenarios can declare their expectations using the same config given to AgentBaker. Settings which aren't specified
+// aren't validated
+func CustomLinuxOSConfigValidators(config *datamodel.CustomLinuxOSConfig) []*LiveVMValidator {
+	var validators []*LiveVMValidator
+	if config == nil {
+		return validators
+	}
+	if sysctls := CustomLinuxOSConfigSysctls(config); len(sysctls) > 0 {
+		validators = append(validators, SysctlConfigValidator(sysctls))
+	}
+	if ulimits := UlimitConfigToMap(config.UlimitConfig); len(ulimits) > 0 {
+		validators = append(validators, UlimitValidator(ulimits))
+	}
+	validators = append(validators, TransparentHugePageValidators(config.TransparentHugePageEnabled, config.TransparentHugePageDefrag)...)
+	if config.SwapFileSizeMB != nil && *config.SwapFileSizeMB > 0 {
+		validators = append(validators, SwapValidators(*config.SwapFileSizeMB)...)
+	}
+	return validators
+}
+
+// CustomLinuxOSConfigSysctls returns the sysctls AgentBaker is expected to set on the node for the specified CustomLinuxOSConfig,
+// i.e. those explicitly set by its SysctlConfig along with the local port AgentBaker reserves when the local port range includes it
+func CustomLinuxOSConfigSysctls(config *datamodel.CustomLinuxOSConfig) map[string]string {
+	if config == nil || config.Sysctls == nil {
+		return map[string]string{}
+	}
+	sysctls := SysctlConfigToMap(config.Sysctls)
+	if portRange := strings.Fields(config.Sysctls.NetIpv4IpLocalPortRange); len(portRange) == 2 {
+		if end, err := strconv.Atoi(portRange[1]); err == nil && end >= reservedLocalPort {
+			sysctls["net.ipv4.ip_local_reserved_ports"] = strconv.Itoa(reservedLocalPort)
+		}
+	}
+	return sysctls
+}
+
+// UlimitConfigToMap converts the supplied UlimitConfig into a mapping from each explicitly set limit's containerd service
+// directive to its expected value, which can be passed directly to UlimitValidator
+func UlimitConfigToMap(config *datamodel.UlimitConfig) map[string]string {
+	ulimits := map[string]string{}
+	if config == nil {
+		return ulimits
+	}
+	if config.MaxLockedMemory != "" {
+		ulimits["LimitMEMLOCK"] = config.MaxLockedMemory
+	}
+	if config.NoFile != "" {
+		ulimits["LimitNOFILE"] = config.NoFile
+	}
+	return ulimits
+}
+
+// SwapValidators returns validators asserting that a swap file of the specified size was created and enabled by CSE, and that
+// it's persisted through /etc/fstab such that it's enabled again on reboot
+func SwapValidators(swapFileSizeMB int32) []*LiveVMValidator {
+	return []*LiveVMValidator{
+		SwapFileValidator(swapFileSizeMB),
+		FstabSwapEntryValidator(),
+	}
+}
+
+// SwapKubeletValidators returns validators asserting that kubelet's config file, written by AgentBaker whenever a CustomKubeletConfig
+// is supplied, turns off failSwapOn, such that kubelet starts on a node with swap enabled
+func SwapKubeletValidators() []*LiveVMValidator {
+	return []*LiveVMValidator{
+		KubeletConfigFileValidator(map[string]any{
+			"failSwapOn": false,
+		}),
+	}
+}
+
+// SwapKubeletConfigValidators returns validators asserting that the running kubelet's effective configuration turns off
+// failSwapOn, i.e. that kubelet was started with the swap behavior configured by AgentBaker rather than its default
+func SwapKubeletConfigValidators() []*KubeletConfigValidator {
+	return []*KubeletConfigValidator{
+		KubeletConfigzValidator(map[string]any{
+			"failSwapOn": false,
+		}),
+	}
+}
+
+// SwapFileValidator asserts that a swap file of the specified size is enabled on either the resource disk or the OS disk. CSE sizes
+// the file in units of 1000 KiB per MB, of which the kernel reserves the first page for the swap header
+func SwapFileValidator(swapFileSizeMB int32) *LiveVMValidator {
+	expectedFileSize := int64(swapFileSizeMB) * 1000 * 1024
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert a %dMB swap file is enabled", swapFileSizeMB),
+		Command:     "swapon --show=NAME,TYPE,SIZE --bytes --noheadings",
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0", code)
+			}
+			for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+				fields := strings.Fields(line)
+				if len(fields) != 3 || fields[1] != "file" {
+					continue
+				}
+				if fields[0] != resourceDiskSwapFilePath && fields[0] != osDiskSwapFilePath {
+					return fmt.Errorf("expected swap file to be created at either %s or %s, but found %s", resourceDiskSwapFilePath, osDiskSwapFilePath, fields[0])
+				}
+				size, err := strconv.ParseInt(fields[2], 10, 64)
+				if err != nil {
+					return fmt.Errorf("unable to parse size of swap file %s: %w", fields[0], err)
+				}
+				// the size reported excludes the swap header, which occupies a single page of up to 64KiB
+				if size > expectedFileSize || size < expectedFileSize-64*1024 {
+					return fmt.Errorf("expected swap file %s to be %d bytes, but it was %d bytes", fields[0], expectedFileSize, size)
+				}
+				return nil
+			}
+			return fmt.Errorf("expected a swap file to be enabled, but found none:\n%s", stdout)
+		},
+	}
+}
+
+// FstabSwapEntryValidator asserts that /etc/fstab has an entry enabling the swap file created by CSE
+func FstabSwapEntryValidator() *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: "assert /etc/fstab has a swap file entry",
+		Command:     fmt.Sprintf("grep -E '^(%s|%s) none swap sw 0 0$' /etc/fstab", resourceDiskSwapFilePath, osDiskSwapFilePath),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("expected /etc/fstab to have an entry for the swap file, but it had none")
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,177 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice"
+)
+
+const (
+	kubeBinaryURLTemplate = "https://acs-mirror.azureedge.net/kubernetes/v%s/binaries/kubernetes-node-linux-%s.tar.gz"
+	amd64Arch             = "amd64"
+	arm64Arch             = "arm64"
+
+	azureCNIScenarioNameSuffix          = "-azurecni"
+	kubernetesVersionScenarioNameFormat = "-k8s-%s"
+)
+
+// Matrix is a compact specification of a set of basic bootstrapping scenarios, which is expanded into a scenario for each
+// combination of its distros, Kubernetes versions and network plugins by Scenarios
+type Matrix struct {
+	// Distros are the VHDs to bootstrap nodes from
+	Distros []MatrixDistro
+
+	// KubernetesVersions are the versions of the Kubernetes node components to bootstrap with, e.g. "1.27.3" - the version of
+	// the base NodeBootstrappingConfiguration is used when empty
+	KubernetesVersions []string
+
+	// NetworkPlugins are the network plugins of the clusters to bootstrap nodes within - kubenet is used when empty
+	NetworkPlugins []armcontainerservice.NetworkPlugin
+
+	// Tags are applied to every scenario generated from the matrix, in addition to those implied by its dimensions
+	Tags []string
+}
+
+// MatrixDistro is a single VHD within the distro dimension of a Matrix
+type MatrixDistro struct {
+	// Name is the name of the VHD, i.e. a key of DefaultImageVersionIDs such as "ubuntu2204", which also prefixes the
+	// names of the scenarios generated for it
+	Name string
+
+	// Description refers to the VHD within the descriptions of the scenarios generated for it, e.g. "the Ubuntu 2204 VHD"
+	Description string
+
+	// Distro is the agent pool distro corresponding to the VHD, e.g. "aks-ubuntu-containerd-22.04-gen2"
+	Distro datamodel.Distro
+
+	// ARM64 is set for VHDs built for arm64, which bootstrap nodes with the arm64 builds of the Kubernetes node components
+	ARM64 bool
+
+	// VMSize is the size of the VMs to bootstrap from the VHD, defaulting to that of the base VMSS model. Scenarios generated
+	// for the distro are skipped when the size isn't available within the suite's region
+	VMSize string
+
+	// Tags are applied to every scenario generated for the distro, e.g. TagARM64
+	Tags []string
+
+	// Requirements are the requirements of every scenario generated for the distro, in addition to its VMSize
+	Requirements Requirements
+
+	// BootstrapConfigMutator further mutates the bootstrap config of every scenario generated for the distro once its distro
+	// has been set, e.g. FIPSEnabledMutator
+	BootstrapConfigMutator func(*datamodel.NodeBootstrappingConfiguration)
+
+	// LiveVMValidators are the distro-specific validators of every scenario generated for the distro, e.g. MarinerV2Validators
+	LiveVMValidators []*LiveVMValidator
+
+	// NodeValidators are the distro-specific node validators of every scenario generated for the distro
+	NodeValidators []*NodeValidator
+}
+
+// Scenarios expands the matrix into a scenario for each combination of its dimensions. Scenario names are derived from the
+// distro's name, suffixed with "-azurecni" on Azure CNI clusters and with "-k8s-<version>" when a Kubernetes version is
+// specified, such that the kubenet scenario using the base Kubernetes version is simply named after its distro.
+func (m Matrix) Scenarios() []*Scenario {
+	versions := m.KubernetesVersions
+	if len(versions) == 0 {
+		versions = []string{""}
+	}
+	plugins := m.NetworkPlugins
+	if len(plugins) == 0 {
+		plugins = []armcontainerservice.NetworkPlugin{armcontainerservice.NetworkPluginKubenet}
+	}
+
+	var scenarios []*Scenario
+	for _, distro := range m.Distros {
+		for _, plugin := range plugins {
+			for _, version := range versions {
+				scenarios = append(scenarios, m.scenario(distro, plugin, version))
+			}
+		}
+	}
+	return scenarios
+}
+
+func (m Matrix) scenario(distro MatrixDistro, plugin armcontainerservice.NetworkPlugin, version string) *Scenario {
+	var (
+		name        strings.Builder
+		description strings.Builder
+		tags        = append(append([]string{}, m.Tags...), distro.Tags...)
+	)
+	name.WriteString(distro.Name)
+	fmt.Fprintf(&description, "Tests that a node using %s can be properly bootstrapped", distro.Description)
+
+	scenario := &Scenario{
+		Config: Config{
+			ClusterSelector:  NetworkPluginKubenetSelector,
+			ClusterMutator:   NetworkPluginKubenetMutator,
+			VMSSMutator:      ImageReferenceMutator(distro.Name),
+			Requirements:     distro.Requirements,
+			LiveVMValidators: distro.LiveVMValidators,
+			NodeValidators:   distro.NodeValidators,
+			// the basic scenarios cover every network plugin, so validate the service networking bootstrap configures for each
+			ValidateServiceConnectivity: true,
+		},
+	}
+	if distro.VMSize != "" {
+		scenario.VMSSMutator = ComposeVMSSMutators(scenario.VMSSMutator, VMSizeMutator(distro.VMSize))
+		scenario.Requirements.VMSizes = append(append([]string{}, distro.Requirements.VMSizes...), distro.VMSize)
+	}
+	if plugin == armcontainerservice.NetworkPluginAzure {
+		scenario.ClusterSelector = NetworkPluginAzureSelector
+		scenario.ClusterMutator = NetworkPluginAzureMutator
+		name.WriteString(azureCNIScenarioNameSuffix)
+		description.WriteString(" on a cluster configured with Azure CNI")
+		tags = append(tags, TagAzureCNI)
+	}
+	if version != "" {
+		fmt.Fprintf(&name, kubernetesVersionScenarioNameFormat, version)
+		fmt.Fprintf(&description, " with v%s of the Kubernetes node components", version)
+	}
+
+	scenario.Name = name.String()
+	scenario.Description = description.String()
+	if len(tags) > 0 {
+		scenario.Tags = tags
+	}
+	scenario.BootstrapConfigMutator = func(nbc *datamodel.NodeBootstrappingConfiguration) {
+		nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = distro.Distro
+		nbc.AgentPoolProfile.Distro = distro.Distro
+		if plugin == armcontainerservice.NetworkPluginAzure {
+			nbc.ContainerService.Properties.OrchestratorProfile.KubernetesConfig.NetworkPlugin = string(armcontainerservice.NetworkPluginAzure)
+			nbc.AgentPoolProfile.KubernetesConfig.NetworkPlugin = string(armcontainerservice.NetworkPluginAzure)
+		}
+		if distro.VMSize != "" {
+			nbc.ContainerService.Properties.AgentPoolProfiles[0].VMSize = distro.VMSize
+			nbc.AgentPoolProfile.VMSize = distro.VMSize
+		}
+		if distro.ARM64 {
+			nbc.IsARM64 = true
+		}
+		if version != "" || distro.ARM64 {
+			kubernetesVersionMutator(version, distro.ARM64)(nbc)
+		}
+		if distro.BootstrapConfigMutator != nil {
+			distro.BootstrapConfigMutator(nbc)
+		}
+	}
+	return scenario
+}
+
+// Returns a BootstrapConfigMutator which bootstraps the node with the amd64 or arm64 build of the specified version of the
+// Kubernetes node components, or of the base bootstrap config's version when empty
+func kubernetesVersionMutator(version string, arm64 bool) func(*datamodel.NodeBootstrappingConfiguration) {
+	arch := amd64Arch
+	if arm64 {
+		arch = arm64Arch
+	}
+	return func(nbc *datamodel.NodeBootstrappingConfiguration) {
+		orchestratorProfile := nbc.ContainerService.Properties.OrchestratorProfile
+		if version != "" {
+			orchestratorProfile.OrchestratorVersion = version
+		}
+		orchestratorProfile.KubernetesConfig.CustomKubeBinaryURL = fmt.Sprintf(kubeBinaryURLTemplate, orchestratorProfile.OrchestratorVersion, arch)
+	}
+}
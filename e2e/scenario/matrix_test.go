@@ -0,0 +1,132 @@
+package scenario
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice"
+)
+
+func TestMatrixScenarios(t *testing.T) {
+	ubuntu := MatrixDistro{Name: "ubuntu2204", Description: "the Ubuntu 2204 VHD", Distro: datamodel.AKSUbuntuContainerd2204Gen2}
+	arm64 := MatrixDistro{Name: "ubuntu2204-arm64", Description: "the Ubuntu 2204 ARM64 VHD", Distro: datamodel.AKSUbuntuArm64Containerd2204Gen2, ARM64: true, Tags: []string{TagARM64}}
+
+	cases := []struct {
+		name   string
+		matrix Matrix
+		names  []string
+		tags   [][]string
+	}{
+		{
+			name:   "a single distro is named after the distro",
+			matrix: Matrix{Distros: []MatrixDistro{ubuntu}},
+			names:  []string{"ubuntu2204"},
+			tags:   [][]string{nil},
+		},
+		{
+			name: "each dimension multiplies the scenarios",
+			matrix: Matrix{
+				Distros:            []MatrixDistro{ubuntu, arm64},
+				KubernetesVersions: []string{"1.27.3", "1.28.0"},
+				NetworkPlugins:     []armcontainerservice.NetworkPlugin{armcontainerservice.NetworkPluginKubenet, armcontainerservice.NetworkPluginAzure},
+				Tags:               []string{"matrix"},
+			},
+			names: []string{
+				"ubuntu2204-k8s-1.27.3",
+				"ubuntu2204-k8s-1.28.0",
+				"ubuntu2204-azurecni-k8s-1.27.3",
+				"ubuntu2204-azurecni-k8s-1.28.0",
+				"ubuntu2204-arm64-k8s-1.27.3",
+				"ubuntu2204-arm64-k8s-1.28.0",
+				"ubuntu2204-arm64-azurecni-k8s-1.27.3",
+				"ubuntu2204-arm64-azurecni-k8s-1.28.0",
+			},
+			tags: [][]string{
+				{"matrix"},
+				{"matrix"},
+				{"matrix", TagAzureCNI},
+				{"matrix", TagAzureCNI},
+				{"matrix", TagARM64},
+				{"matrix", TagARM64},
+				{"matrix", TagARM64, TagAzureCNI},
+				{"matrix", TagARM64, TagAzureCNI},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			var names []string
+			var tags [][]string
+			for _, scenario := range c.matrix.Scenarios() {
+				names = append(names, scenario.Name)
+				tags = append(tags, scenario.Tags)
+			}
+			if !reflect.DeepEqual(names, c.names) {
+				t.Errorf("expected scenarios %v, but got %v", c.names, names)
+			}
+			if !reflect.DeepEqual(tags, c.tags) {
+				t.Errorf("expected tags %v, but got %v", c.tags, tags)
+			}
+		})
+	}
+}
+
+func TestMatrixScenarioBootstrapConfig(t *testing.T) {
+	matrix := Matrix{
+		Distros: []MatrixDistro{{
+			Name:        "ubuntu2204-arm64",
+			Description: "the Ubuntu 2204 ARM64 VHD",
+			Distro:      datamodel.AKSUbuntuArm64Containerd2204Gen2,
+			ARM64:       true,
+			VMSize:      "Standard_D2pds_v5",
+		}},
+		KubernetesVersions: []string{"1.27.3"},
+		NetworkPlugins:     []armcontainerservice.NetworkPlugin{armcontainerservice.NetworkPluginAzure},
+	}
+	scenarios := matrix.Scenarios()
+	if len(scenarios) != 1 {
+		t.Fatalf("expected a single scenario, but got %d", len(scenarios))
+	}
+	scenario := scenarios[0]
+	if !reflect.DeepEqual(scenario.Requirements.VMSizes, []string{"Standard_D2pds_v5"}) {
+		t.Errorf("expected the distro's VM size to be required, but got %v", scenario.Requirements.VMSizes)
+	}
+
+	nbc := &datamodel.NodeBootstrappingConfiguration{
+		ContainerService: &datamodel.ContainerService{
+			Properties: &datamodel.Properties{
+				OrchestratorProfile: &datamodel.OrchestratorProfile{
+					OrchestratorVersion: "1.26.6",
+					KubernetesConfig:    &datamodel.KubernetesConfig{NetworkPlugin: "kubenet"},
+				},
+				AgentPoolProfiles: []*datamodel.AgentPoolProfile{{}},
+			},
+		},
+		AgentPoolProfile: &datamodel.AgentPoolProfile{KubernetesConfig: &datamodel.KubernetesConfig{NetworkPlugin: "kubenet"}},
+	}
+	scenario.BootstrapConfigMutator(nbc)
+
+	orchestratorProfile := nbc.ContainerService.Properties.OrchestratorProfile
+	if orchestratorProfile.OrchestratorVersion != "1.27.3" {
+		t.Errorf("expected Kubernetes version 1.27.3, but got %q", orchestratorProfile.OrchestratorVersion)
+	}
+	if url := "https://acs-mirror.azureedge.net/kubernetes/v1.27.3/binaries/kubernetes-node-linux-arm64.tar.gz"; orchestratorProfile.KubernetesConfig.CustomKubeBinaryURL != url {
+		t.Errorf("expected kube binary URL %q, but got %q", url, orchestratorProfile.KubernetesConfig.CustomKubeBinaryURL)
+	}
+	if !nbc.IsARM64 {
+		t.Errorf("expected the bootstrap config to be for arm64")
+	}
+	for _, plugin := range []string{orchestratorProfile.KubernetesConfig.NetworkPlugin, nbc.AgentPoolProfile.KubernetesConfig.NetworkPlugin} {
+		if plugin != string(armcontainerservice.NetworkPluginAzure) {
+			t.Errorf("expected network plugin azure, but got %q", plugin)
+		}
+	}
+	for _, profile := range []*datamodel.AgentPoolProfile{nbc.AgentPoolProfile, nbc.ContainerService.Properties.AgentPoolProfiles[0]} {
+		if profile.Distro != datamodel.AKSUbuntuArm64Containerd2204Gen2 || profile.VMSize != "Standard_D2pds_v5" {
+			t.Errorf("expected the distro and VM size to be set, but got distro %q and VM size %q", profile.Distro, profile.VMSize)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package scenario
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// written by CSE on MIG nodes, such that the device plugin advertises each of the GPU's MIG devices as a whole GPU
+	migStrategyDropInPath = "/etc/systemd/system/nvidia-device-plugin.service.d/10-mig_strategy.conf"
+	migStrategy           = "--mig-strategy single"
+
+	// the resource advertised by the device plugin for each MIG device when using the single MIG strategy
+	nvidiaGPUResourceName corev1.ResourceName = "nvidia.com/gpu"
+)
+
+// migPartitioning describes the GPU instances mig-partition.sh creates on an A100 80GB GPU for a GPU instance profile
+type migPartitioning struct {
+	// profileID is the ID of the GPU instance profile passed to "nvidia-smi mig -cgi"
+	profileID int
+
+	// count is the number of GPU instances created
+	count int
+}
+
+// migPartitionings maps each GPU instance profile of the bootstrap config to the partitioning applied by mig-partition.sh
+var migPartitionings = map[string]migPartitioning{
+	"MIG1g": {profileID: 19, count: 7},
+	"MIG2g": {profileID: 14, count: 3},
+	"MIG3g": {profileID: 9, count: 2},
+	"MIG4g": {profileID: 5, count: 1},
+	"MIG7g": {profileID: 0, count: 1},
+}
+
+// MIGMutator returns a BootstrapConfigMutator which configures the bootstrap config to partition the MIG VM size's GPU with the
+// specified GPU instance profile, e.g. "MIG1g", installing the GPU driver and device plugin such that the partitions are
+// advertised as allocatable GPUs
+func MIGMutator(gpuInstanceProfile string) func(*datamodel.NodeBootstrappingConfiguration) {
+	return func(nbc *datamodel.NodeBootstrappingConfiguration) {
+		nbc.ContainerService.Properties.AgentPoolProfiles[0].VMSize = migVMSize
+		nbc.AgentPoolProfile.VMSize = migVMSize
+		nbc.GPUInstanceProfile = gpuInstanceProfile
+		nbc.ConfigGPUDriverIfNeeded = true
+		nbc.EnableGPUDevicePluginIfNeeded = true
+		nbc.EnableNvidia = true
+	}
+}
+
+// MIGAllocatableResources returns the allocatable resources the device plugin is expected to advertise once the GPU has been
+// partitioned with the specified GPU instance profile, i.e. one GPU per partition
+func MIGAllocatableResources(gpuInstanceProfile string) map[corev1.ResourceName]int64 {
+	return map[corev1.ResourceName]int64{
+		nvidiaGPUResourceName: int64(migPartitionings[gpuInstanceProfile].count),
+	}
+}
+
+// MIGValidators returns validators asserting that MIG mode is enabled on the node's GPU, that it was partitioned into the
+// GPU instances of the specified GPU instance profile during bootstrapping, and that the device plugin uses the single MIG strategy
+func MIGValidators(gpuInstanceProfile string) []*LiveVMValidator {
+	return []*LiveVMValidator{
+		MIGModeValidator(),
+		MIGGPUInstancesValidator(gpuInstanceProfile),
+		FileContentsValidator(migStrategyDropInPath, migStrategy),
+	}
+}
+
+// MIGModeValidator asserts that MIG mode is currently enabled on each of the node's GPUs. Enabling MIG mode only takes
+// effect once the VM has been rebooted, which CSE requests on MIG nodes
+func MIGModeValidator() *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: "assert MIG mode is enabled",
+		Command:     "nvidia-smi --query-gpu=index,mig.mode.current --format=csv,noheader",
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0, stderr: %q", code, stderr)
+			}
+			gpus := 0
+			for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+				index, mode, found := strings.Cut(line, ",")
+				if !found {
+					continue
+				}
+				gpus++
+				if mode = strings.TrimSpace(mode); mode != "Enabled" {
+					return fmt.Errorf("expected MIG mode to be enabled on GPU %s, but it was %q", strings.TrimSpace(index), mode)
+				}
+			}
+			if gpus == 0 {
+				return fmt.Errorf("expected nvidia-smi to list at least one GPU, but it listed none: %q", stdout)
+			}
+			return nil
+		},
+	}
+}
+
+// MIGGPUInstancesValidator asserts that the node's GPU was partitioned into the GPU instances of the specified GPU instance profile
+func MIGGPUInstancesValidator(gpuInstanceProfile string) *LiveVMValidator {
+	expected := migPartitionings[gpuInstanceProfile]
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert GPU instances of profile %s", gpuInstanceProfile),
+		Command:     "nvidia-smi mig -lgi",
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0, stderr: %q", code, stderr)
+			}
+			// each GPU instance is listed within a table row, e.g. "|   0  MIG 1g.10gb       19        7          0:1     |",
+			// holding the GPU index, profile name, profile ID, instance ID and placement
+			instances := 0
+			for _, line := range strings.Split(stdout, "\n") {
+				fields := strings.Fields(strings.Trim(strings.TrimSpace(line), "|"))
+				if len(fields) < 4 || fields[1] != "MIG" {
+					continue
+				}
+				profileID, err := strconv.Atoi(fields[3])
+				if err != nil {
+					return fmt.Errorf("unable to parse profile ID of GPU instance %q: %w", line, err)
+				}
+				if profileID != expected.profileID {
+					return fmt.Errorf("expected GPU instances to have profile ID %d, but found GPU instance of profile %s with ID %d", expected.profileID, fields[2], profileID)
+				}
+				instances++
+			}
+			if instances != expected.count {
+				return fmt.Errorf("expected %d GPU instances of profile %s, but found %d:\n%s", expected.count, gpuInstanceProfile, instances, stdout)
+			}
+			return nil
+		},
+	}
+}
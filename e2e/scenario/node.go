@@ -0,0 +1,126 @@
+package scenario
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	registerWithTaintsFlag = "--register-with-taints"
+)
+
+// NodeLabelsValidator asserts that the node carries each of the expected labels.
+func NodeLabelsValidator(expectedLabels map[string]string) *NodeValidator {
+	return &NodeValidator{
+		Description: "assert node labels",
+		Asserter: func(node *corev1.Node) error {
+			if mismatches := compareExpectedValues(expectedLabels, node.Labels); len(mismatches) > 0 {
+				return fmt.Errorf("labels of node %q did not match expectations:\n%s", node.Name, strings.Join(mismatches, "\n"))
+			}
+			return nil
+		},
+	}
+}
+
+// NodeTaintsValidator asserts that the node carries each of the expected taints, matched on key, value and effect.
+func NodeTaintsValidator(expectedTaints []corev1.Taint) *NodeValidator {
+	return &NodeValidator{
+		Description: "assert node taints",
+		Asserter: func(node *corev1.Node) error {
+			var missing []string
+			for _, expected := range expectedTaints {
+				if !hasTaint(node.Spec.Taints, expected) {
+					missing = append(missing, expected.ToString())
+				}
+			}
+			if len(missing) > 0 {
+				return fmt.Errorf("expected node %q to have taints %v, but its taints were %v", node.Name, missing, taintStrings(node.Spec.Taints))
+			}
+			return nil
+		},
+	}
+}
+
+// NodePodCapacityValidator asserts that the node registered with a capacity of the specified number of pods.
+func NodePodCapacityValidator(maxPods int) *NodeValidator {
+	return &NodeValidator{
+		Description: fmt.Sprintf("assert node pod capacity is %d", maxPods),
+		Asserter: func(node *corev1.Node) error {
+			capacity, found := node.Status.Capacity[corev1.ResourcePods]
+			if !found {
+				return fmt.Errorf("expected node %q to have a pod capacity of %d, but its capacity doesn't include pods", node.Name, maxPods)
+			}
+			if capacity.Value() != int64(maxPods) {
+				return fmt.Errorf("expected node %q to have a pod capacity of %d, but had a capacity of %s", node.Name, maxPods, capacity.String())
+			}
+			return nil
+		},
+	}
+}
+
+// BootstrapConfigNodeValidators returns validators asserting that the node carries the labels and taints
+// AgentBaker configures kubelet to register it with, according to the supplied bootstrap config.
+func BootstrapConfigNodeValidators(nbc *datamodel.NodeBootstrappingConfiguration) []*NodeValidator {
+	validators := []*NodeValidator{
+		NodeLabelsValidator(parseNodeLabels(nbc.AgentPoolProfile.GetKubernetesLabels())),
+	}
+	if taints := parseTaints(nbc.KubeletConfig[registerWithTaintsFlag]); len(taints) > 0 {
+		validators = append(validators, NodeTaintsValidator(taints))
+	}
+	return validators
+}
+
+// Parses a comma-separated list of taints in the form accepted by kubelet's --register-with-taints flag,
+// e.g. "sku=gpu:NoSchedule,kubernetes.azure.com/scalesetpriority=spot:NoSchedule"
+func parseTaints(taintList string) []corev1.Taint {
+	var taints []corev1.Taint
+	for _, spec := range strings.Split(taintList, ",") {
+		keyValue, effect, found := strings.Cut(strings.TrimSpace(spec), ":")
+		if !found {
+			continue
+		}
+		key, value, _ := strings.Cut(keyValue, "=")
+		taints = append(taints, corev1.Taint{
+			Key:    key,
+			Value:  value,
+			Effect: corev1.TaintEffect(effect),
+		})
+	}
+	return taints
+}
+
+func hasTaint(taints []corev1.Taint, expected corev1.Taint) bool {
+	for _, taint := range taints {
+		if taint.Key == expected.Key && taint.Value == expected.Value && taint.Effect == expected.Effect {
+			return true
+		}
+	}
+	return false
+}
+
+func taintStrings(taints []corev1.Taint) []string {
+	strs := make([]string, 0, len(taints))
+	for _, taint := range taints {
+		strs = append(strs, taint.ToString())
+	}
+	sort.Strings(strs)
+	return strs
+}
+
+// NodeResourceNotAdvertisedValidator asserts that the node doesn't advertise any capacity of the specified extended resource,
+// e.g. "nvidia.com/gpu" when the device plugin isn't running
+func NodeResourceNotAdvertisedValidator(resource corev1.ResourceName) *NodeValidator {
+	return &NodeValidator{
+		Description: fmt.Sprintf("assert node doesn't advertise %s", resource),
+		Asserter: func(node *corev1.Node) error {
+			if capacity, found := node.Status.Capacity[resource]; found && !capacity.IsZero() {
+				return fmt.Errorf("expected node %q not to advertise %s, but it had a capacity of %s", node.Name, resource, capacity.String())
+			}
+			return nil
+		},
+	}
+}
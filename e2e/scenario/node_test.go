@@ -0,0 +1,51 @@
+package scenario
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParseTaints(t *testing.T) {
+	cases := []struct {
+		name      string
+		taintList string
+		expected  []corev1.Taint
+	}{
+		{
+			name: "no taints",
+		},
+		{
+			name:      "several taints",
+			taintList: "sku=gpu:NoSchedule,kubernetes.azure.com/scalesetpriority=spot:NoExecute",
+			expected: []corev1.Taint{
+				{Key: "sku", Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "kubernetes.azure.com/scalesetpriority", Value: "spot", Effect: corev1.TaintEffectNoExecute},
+			},
+		},
+		{
+			name:      "taint without a value",
+			taintList: "CriticalAddonsOnly:PreferNoSchedule",
+			expected: []corev1.Taint{
+				{Key: "CriticalAddonsOnly", Effect: corev1.TaintEffectPreferNoSchedule},
+			},
+		},
+		{
+			name:      "surrounding whitespace and elements without an effect",
+			taintList: " sku=gpu:NoSchedule , bogus=taint",
+			expected: []corev1.Taint{
+				{Key: "sku", Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			if taints := parseTaints(c.taintList); !reflect.DeepEqual(taints, c.expected) {
+				t.Errorf("expected taints %+v, but got %+v", c.expected, taints)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package scenario
+
+import (
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+)
+
+// Parameter is a single value of a parameterized scenario's Parameters, e.g. one of several VM sizes, with which the
+// scenario is run as a subtest of its own
+type Parameter struct {
+	// Name names the parameter's subtest, e.g. "Standard_D2s_v3", and must be unique among the scenario's parameters
+	Name string
+
+	// BootstrapConfigMutator further mutates the scenario's bootstrap config, after the scenario's own BootstrapConfigMutator
+	BootstrapConfigMutator func(*datamodel.NodeBootstrappingConfiguration)
+
+	// VMSSMutator further mutates the scenario's VMSS model, after the scenario's own VMSSMutator
+	VMSSMutator func(*armcompute.VirtualMachineScaleSet)
+
+	// Requirements are the parameter's requirements, in addition to the scenario's own. Only the parameter's subtest is
+	// skipped when any of them aren't met
+	Requirements Requirements
+}
+
+// VMSizeParameters returns a parameter per VM size, each of which runs the scenario on VMs of its size, bootstrapped as such,
+// and requires the size to be available within the suite's region
+func VMSizeParameters(vmSizes ...string) []Parameter {
+	parameters := make([]Parameter, 0, len(vmSizes))
+	for _, vmSize := range vmSizes {
+		vmSize := vmSize
+		parameters = append(parameters, Parameter{
+			Name: vmSize,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].VMSize = vmSize
+				nbc.AgentPoolProfile.VMSize = vmSize
+			},
+			VMSSMutator:  VMSizeMutator(vmSize),
+			Requirements: Requirements{VMSizes: []string{vmSize}},
+		})
+	}
+	return parameters
+}
+
+// WithParameter returns the scenario bound to the parameter, named "<scenario>/<parameter>", whose mutators are the
+// scenario's followed by the parameter's, and whose requirements include the parameter's. The returned scenario has no
+// parameters of its own
+func (s *Scenario) WithParameter(parameter Parameter) *Scenario {
+	bound := *s
+	bound.Name = s.Name + "/" + parameter.Name
+	bound.Parameters = nil
+
+	if parameter.BootstrapConfigMutator != nil {
+		mutator := s.BootstrapConfigMutator
+		bound.BootstrapConfigMutator = func(nbc *datamodel.NodeBootstrappingConfiguration) {
+			if mutator != nil {
+				mutator(nbc)
+			}
+			parameter.BootstrapConfigMutator(nbc)
+		}
+	}
+	if parameter.VMSSMutator != nil {
+		bound.VMSSMutator = ComposeVMSSMutators(s.VMSSMutator, parameter.VMSSMutator)
+	}
+
+	bound.Requirements = Requirements{
+		VMSizes:  append(append([]string{}, s.Requirements.VMSizes...), parameter.Requirements.VMSizes...),
+		Features: append(append([]string{}, s.Requirements.Features...), parameter.Requirements.Features...),
+	}
+	if len(s.Requirements.VCPUQuotas) > 0 || len(parameter.Requirements.VCPUQuotas) > 0 {
+		bound.Requirements.VCPUQuotas = map[string]int32{}
+		for _, quotas := range []map[string]int32{s.Requirements.VCPUQuotas, parameter.Requirements.VCPUQuotas} {
+			for family, vCPUs := range quotas {
+				if vCPUs > bound.Requirements.VCPUQuotas[family] {
+					bound.Requirements.VCPUQuotas[family] = vCPUs
+				}
+			}
+		}
+	}
+	return &bound
+}
@@ -0,0 +1,115 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+const (
+	etcEnvironmentPath           = "/etc/environment"
+	systemdProxyConfigPath       = "/etc/systemd/system.conf.d/proxy.conf"
+	aptProxyConfigPath           = "/etc/apt/apt.conf.d/95proxy"
+	systemdDefaultEnvironmentKey = "DefaultEnvironment"
+)
+
+// HTTPProxyValidators returns validators asserting that the node was configured to egress through the HTTP proxy of the
+// specified config, i.e. that the proxy variables and no-proxy list were rendered into /etc/environment and into the
+// default environment of systemd units such as containerd, that apt uses the proxy, and that kubelet loads /etc/environment
+func HTTPProxyValidators(config *datamodel.HTTPProxyConfig) []*LiveVMValidator {
+	expected := map[string]string{}
+	var aptConfig []string
+	if config.HTTPProxy != nil {
+		expected["HTTP_PROXY"] = *config.HTTPProxy
+		expected["http_proxy"] = *config.HTTPProxy
+		aptConfig = append(aptConfig, fmt.Sprintf("Acquire::http::proxy %q;", *config.HTTPProxy))
+	}
+	if config.HTTPSProxy != nil {
+		expected["HTTPS_PROXY"] = *config.HTTPSProxy
+		expected["https_proxy"] = *config.HTTPSProxy
+		aptConfig = append(aptConfig, fmt.Sprintf("Acquire::https::proxy %q;", *config.HTTPSProxy))
+	}
+	if config.NoProxy != nil {
+		expected["NO_PROXY"] = strings.Join(*config.NoProxy, ",")
+		expected["no_proxy"] = strings.Join(*config.NoProxy, ",")
+	}
+
+	validators := []*LiveVMValidator{
+		EnvironmentFileValidator(etcEnvironmentPath, expected),
+		SystemdDefaultEnvironmentValidator(systemdProxyConfigPath, expected),
+		SystemdUnitEnvironmentFileValidator("kubelet", etcEnvironmentPath),
+	}
+	for _, line := range aptConfig {
+		validators = append(validators, FileContentsValidator(aptProxyConfigPath, line))
+	}
+	return validators
+}
+
+// EnvironmentFileValidator asserts that the KEY=VALUE lines of the specified environment file set each of the expected
+// variables to its expected value
+func EnvironmentFileValidator(path string, expected map[string]string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s environment variables", path),
+		Command:     fmt.Sprintf("cat %s", path),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0", code)
+			}
+			if mismatches := compareExpectedValues(expected, parseEnvironmentFile(stdout)); len(mismatches) > 0 {
+				return fmt.Errorf("%s did not match expectations:\n%s", path, strings.Join(mismatches, "\n"))
+			}
+			return nil
+		},
+	}
+}
+
+// SystemdDefaultEnvironmentValidator asserts that the DefaultEnvironment="KEY=VALUE" lines of the specified systemd
+// manager config set each of the expected variables to its expected value
+func SystemdDefaultEnvironmentValidator(path string, expected map[string]string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s default environment", path),
+		Command:     fmt.Sprintf("cat %s", path),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0", code)
+			}
+			actual := map[string]string{}
+			for _, line := range strings.Split(stdout, "\n") {
+				key, value, found := strings.Cut(strings.TrimSpace(line), "=")
+				if !found || key != systemdDefaultEnvironmentKey {
+					continue
+				}
+				if name, variable, found := strings.Cut(strings.Trim(value, `"`), "="); found {
+					actual[name] = variable
+				}
+			}
+			if mismatches := compareExpectedValues(expected, actual); len(mismatches) > 0 {
+				return fmt.Errorf("%s did not match expectations:\n%s", path, strings.Join(mismatches, "\n"))
+			}
+			return nil
+		},
+	}
+}
+
+// SystemdUnitEnvironmentFileValidator asserts that the specified unit, once its drop-ins have been applied, loads its
+// environment from the specified file
+func SystemdUnitEnvironmentFileValidator(unit, path string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s loads environment file %s", unit, path),
+		Command:     fmt.Sprintf("systemctl show -p EnvironmentFiles %s", unit),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0", code)
+			}
+			// each file is listed on its own line along with whether errors loading it are ignored, e.g.
+			// "EnvironmentFiles=/etc/environment (ignore_errors=no)"
+			for _, line := range strings.Split(stdout, "\n") {
+				if fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), "EnvironmentFiles=")); len(fields) > 0 && fields[0] == path {
+					return nil
+				}
+			}
+			return fmt.Errorf("expected %s to load environment file %s, but its environment files were %q", unit, path, strings.TrimSpace(stdout))
+		},
+	}
+}
@@ -0,0 +1,22 @@
+package scenario
+
+import (
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+func azurelinuxv2CgroupV2() *Scenario {
+	return &Scenario{
+		Name:        "azurelinuxv2-cgroupv2",
+		Description: "Tests that a node using the AzureLinux V2 (CgroupV2) VHD can be properly bootstrapped on cgroupv2, with kubelet and containerd managing cgroups through systemd",
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-azurelinux-v2-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-azurelinux-v2-gen2"
+			},
+			VMSSMutator:      ImageReferenceMutator("azurelinuxv2"),
+			LiveVMValidators: CgroupV2Validators("runc"),
+		},
+	}
+}
@@ -0,0 +1,23 @@
+package scenario
+
+import "github.com/Azure/agentbaker/pkg/agent/datamodel"
+
+func azurelinuxv2CustomKubeletConfig() *Scenario {
+	return &Scenario{
+		Name:        "azurelinuxv2-custom-kubelet-config",
+		Description: "Tests that a node using the AzureLinuxV2 VHD can be properly bootstrapped when supplied a custom kubelet config, with the running kubelet's effective configuration reflecting it",
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				CustomKubeletConfigMutator(nbc)
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-azurelinux-v2-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-azurelinux-v2-gen2"
+			},
+			VMSSMutator:             ImageReferenceMutator("azurelinuxv2"),
+			LiveVMValidators:        CustomKubeletConfigValidators(),
+			NodeValidators:          CustomKubeletConfigNodeValidators(),
+			KubeletConfigValidators: CustomKubeletConfigKubeletConfigValidators(),
+		},
+	}
+}
@@ -42,7 +42,7 @@ func azurelinuxv2CustomSysctls() *Scenario {
 				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-azurelinux-v2-gen2"
 				nbc.AgentPoolProfile.Distro = "aks-azurelinux-v2-gen2"
 			},
-			VMConfigMutator: func(vmss *armcompute.VirtualMachineScaleSet) {
+			VMSSMutator: func(vmss *armcompute.VirtualMachineScaleSet) {
 				vmss.Properties.VirtualMachineProfile.StorageProfile.ImageReference = &armcompute.ImageReference{
 					ID: to.Ptr(DefaultImageVersionIDs["azurelinuxv2"]),
 				}
@@ -0,0 +1,28 @@
+package scenario
+
+import (
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+)
+
+func azurelinuxv2Gen1() *Scenario {
+	return &Scenario{
+		Name:        "azurelinuxv2-gen1",
+		Description: "Tests that a node using the Gen1 AzureLinux V2 VHD can be properly bootstrapped",
+		Config: Config{
+			ClusterSelector:  NetworkPluginKubenetSelector,
+			ClusterMutator:   NetworkPluginKubenetMutator,
+			HyperVGeneration: HyperVGenerationV1,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-azurelinux-v2"
+				nbc.AgentPoolProfile.Distro = "aks-azurelinux-v2"
+			},
+			VMSSMutator: func(vmss *armcompute.VirtualMachineScaleSet) {
+				vmss.Properties.VirtualMachineProfile.StorageProfile.ImageReference = &armcompute.ImageReference{
+					ID: to.Ptr(ImageVersionID("azurelinuxv2", HyperVGenerationV1)),
+				}
+			},
+		},
+	}
+}
@@ -10,6 +10,7 @@ func azurelinuxv2Wasm() *Scenario {
 	return &Scenario{
 		Name:        "azurelinuxv2-wasm",
 		Description: "tests that a new AzureLinuxV2 (CgroupV2) node using krustlet can be properly bootstrapped",
+		Tags:        []string{TagWasm},
 		Config: Config{
 			ClusterSelector: NetworkPluginKubenetSelector,
 			ClusterMutator:  NetworkPluginKubenetMutator,
@@ -19,7 +20,7 @@ func azurelinuxv2Wasm() *Scenario {
 				nbc.AgentPoolProfile.WorkloadRuntime = datamodel.WasmWasi
 				nbc.AgentPoolProfile.Distro = "aks-azurelinux-v2-gen2"
 			},
-			VMConfigMutator: func(vmss *armcompute.VirtualMachineScaleSet) {
+			VMSSMutator: func(vmss *armcompute.VirtualMachineScaleSet) {
 				vmss.Properties.VirtualMachineProfile.StorageProfile.ImageReference = &armcompute.ImageReference{
 					ID: to.Ptr(DefaultImageVersionIDs["azurelinuxv2"]),
 				}
@@ -10,6 +10,7 @@ func marinerv2Wasm() *Scenario {
 	return &Scenario{
 		Name:        "marinerv2-wasm",
 		Description: "tests that a new marinerv2 node using krustlet can be properly bootstrapped",
+		Tags:        []string{TagWasm},
 		Config: Config{
 			ClusterSelector: NetworkPluginKubenetSelector,
 			ClusterMutator:  NetworkPluginKubenetMutator,
@@ -19,7 +20,7 @@ func marinerv2Wasm() *Scenario {
 				nbc.AgentPoolProfile.WorkloadRuntime = datamodel.WasmWasi
 				nbc.AgentPoolProfile.Distro = "aks-cblmariner-v2-gen2"
 			},
-			VMConfigMutator: func(vmss *armcompute.VirtualMachineScaleSet) {
+			VMSSMutator: func(vmss *armcompute.VirtualMachineScaleSet) {
 				vmss.Properties.VirtualMachineProfile.StorageProfile.ImageReference = &armcompute.ImageReference{
 					ID: to.Ptr(DefaultImageVersionIDs["marinerv2"]),
 				}
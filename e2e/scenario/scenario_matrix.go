@@ -0,0 +1,118 @@
+package scenario
+
+import (
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice"
+)
+
+const (
+	// VM size of the kata scenarios, which must support nested virtualization to host pod sandboxes
+	kataVMSize = "Standard_D4s_v3"
+
+	kataRuntimeHandler = "kata"
+	kataRuntimeType    = "io.containerd.kata.v2"
+)
+
+var (
+	ubuntu1804MatrixDistro = MatrixDistro{
+		Name:             "ubuntu1804",
+		Description:      "an Ubuntu 1804 VHD",
+		Distro:           "aks-ubuntu-containerd-18.04-gen2",
+		LiveVMValidators: UbuntuValidators("18.04"),
+	}
+	ubuntu2204MatrixDistro = MatrixDistro{
+		Name:             "ubuntu2204",
+		Description:      "the Ubuntu 2204 VHD",
+		Distro:           "aks-ubuntu-containerd-22.04-gen2",
+		LiveVMValidators: UbuntuValidators("22.04"),
+	}
+	marinerv2MatrixDistro = MatrixDistro{
+		Name:             "marinerv2",
+		Description:      "a MarinerV2 VHD",
+		Distro:           "aks-cblmariner-v2-gen2",
+		LiveVMValidators: MarinerV2Validators(""),
+	}
+	azurelinuxv2MatrixDistro = MatrixDistro{
+		Name:             "azurelinuxv2",
+		Description:      "an AzureLinuxV2 (CgroupV2) VHD",
+		Distro:           "aks-azurelinux-v2-gen2",
+		LiveVMValidators: MarinerV2Validators(""),
+	}
+)
+
+// Returns the variants of the named CBL-Mariner/AzureLinux 2.0 VHD, e.g. "azurelinuxv2", i.e. its arm64, FIPS and kata
+// builds, bootstrapped from the specified distros
+func marinerV2VariantMatrixDistros(name, description string, arm64Distro, fipsDistro, kataDistro datamodel.Distro) []MatrixDistro {
+	return []MatrixDistro{
+		{
+			Name:             name + "-arm64",
+			Description:      description + " VHD on ARM64 architecture",
+			Distro:           arm64Distro,
+			ARM64:            true,
+			VMSize:           arm64VMSize,
+			Tags:             []string{TagARM64},
+			LiveVMValidators: MarinerV2Validators(""),
+		},
+		{
+			Name:                   name + "-fips",
+			Description:            description + " FIPS VHD, running the kernel and OpenSSL in FIPS mode",
+			Distro:                 fipsDistro,
+			BootstrapConfigMutator: FIPSEnabledMutator,
+			LiveVMValidators:       append(MarinerV2Validators(""), FIPSValidators()...),
+		},
+		{
+			Name:        name + "-kata",
+			Description: description + " kata VHD, booting the mshv kernel and registering the kata runtime handler with containerd",
+			Distro:      kataDistro,
+			VMSize:      kataVMSize,
+			LiveVMValidators: append(
+				MarinerV2Validators(mshvKernelFlavor),
+				ContainerdConfigValidator(ContainerdRuntimeHandler(kataRuntimeHandler, kataRuntimeType, "")),
+			),
+		},
+	}
+}
+
+// Returns the matrix of basic scenarios, bootstrapping each of the amd64 Gen2 VHDs within both kubenet and Azure CNI clusters
+func basicMatrix() Matrix {
+	return Matrix{
+		Distros: []MatrixDistro{
+			ubuntu1804MatrixDistro,
+			ubuntu2204MatrixDistro,
+			marinerv2MatrixDistro,
+			azurelinuxv2MatrixDistro,
+		},
+		NetworkPlugins: []armcontainerservice.NetworkPlugin{
+			armcontainerservice.NetworkPluginKubenet,
+			armcontainerservice.NetworkPluginAzure,
+		},
+	}
+}
+
+// Returns the matrix of version skew scenarios, bootstrapping nodes with Kubernetes node components older and newer than
+// those of the base bootstrap config
+func kubernetesVersionMatrix() Matrix {
+	return Matrix{
+		Distros: []MatrixDistro{
+			ubuntu2204MatrixDistro,
+		},
+		KubernetesVersions: []string{
+			"1.25.6",
+			"1.27.3",
+		},
+		Tags: []string{TagNightly},
+	}
+}
+
+// Returns the matrix of the CBL-Mariner/AzureLinux family's variant scenarios, bootstrapping the arm64, FIPS and kata builds
+// of each of the family's VHDs. The family's regular VHDs are covered by the basic matrix
+func marinerV2VariantMatrix() Matrix {
+	return Matrix{
+		Distros: append(
+			marinerV2VariantMatrixDistros(marinerv2MatrixDistro.Name, "a MarinerV2",
+				datamodel.AKSCBLMarinerV2Arm64Gen2, datamodel.AKSCBLMarinerV2Gen2FIPS, datamodel.AKSCBLMarinerV2Gen2Kata),
+			marinerV2VariantMatrixDistros(azurelinuxv2MatrixDistro.Name, "an AzureLinuxV2 (CgroupV2)",
+				datamodel.AKSAzureLinuxV2Arm64Gen2, datamodel.AKSAzureLinuxV2Gen2FIPS, datamodel.AKSAzureLinuxV2Gen2Kata)...,
+		),
+	}
+}
@@ -11,9 +11,12 @@ func ubuntu1804gpu_azurecni() *Scenario {
 	return &Scenario{
 		Name:        "ubuntu1804-gpu-azurecni",
 		Description: "Ubuntu1804 gpu scenario on cluster configured with Azure CNI",
+		Tags:        []string{TagGPU, TagAzureCNI},
 		Config: Config{
 			ClusterSelector: NetworkPluginAzureSelector,
 			ClusterMutator:  NetworkPluginAzureMutator,
+			Requirements:    GPURequirements(),
+			NvidiaGPUs:      gpuVMSizeGPUs,
 			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
 				nbc.ContainerService.Properties.OrchestratorProfile.KubernetesConfig.NetworkPlugin = string(armcontainerservice.NetworkPluginAzure)
 				nbc.AgentPoolProfile.KubernetesConfig.NetworkPlugin = string(armcontainerservice.NetworkPluginAzure)
@@ -25,7 +28,7 @@ func ubuntu1804gpu_azurecni() *Scenario {
 				nbc.EnableGPUDevicePluginIfNeeded = false
 				nbc.EnableNvidia = true
 			},
-			VMConfigMutator: func(vmss *armcompute.VirtualMachineScaleSet) {
+			VMSSMutator: func(vmss *armcompute.VirtualMachineScaleSet) {
 				vmss.SKU.Name = to.Ptr("Standard_NC6s_v3")
 			},
 		},
@@ -11,9 +11,12 @@ func ubuntu1804gpu() *Scenario {
 	return &Scenario{
 		Name:        "ubuntu1804-gpu",
 		Description: "Tests that a GPU-enabled node using an Ubuntu 1804 VHD can be properly bootstrapped",
+		Tags:        []string{TagGPU},
 		Config: Config{
 			ClusterSelector: NetworkPluginKubenetSelector,
 			ClusterMutator:  NetworkPluginKubenetMutator,
+			Requirements:    GPURequirements(),
+			NvidiaGPUs:      gpuVMSizeGPUs,
 			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
 				nbc.ContainerService.Properties.AgentPoolProfiles[0].VMSize = "Standard_NC6s_v3"
 				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-18.04-gen2"
@@ -23,9 +26,15 @@ func ubuntu1804gpu() *Scenario {
 				nbc.EnableGPUDevicePluginIfNeeded = false
 				nbc.EnableNvidia = true
 			},
-			VMConfigMutator: func(vmss *armcompute.VirtualMachineScaleSet) {
+			VMSSMutator: func(vmss *armcompute.VirtualMachineScaleSet) {
 				vmss.SKU.Name = to.Ptr("Standard_NC6s_v3")
 			},
+			LiveVMValidators: []*LiveVMValidator{
+				ContainerdConfigValidator(
+					ContainerdDefaultRuntime("nvidia-container-runtime"),
+					ContainerdRuntimeHandler("nvidia-container-runtime", "io.containerd.runc.v2", "/usr/bin/nvidia-container-runtime"),
+				),
+			},
 		},
 	}
 }
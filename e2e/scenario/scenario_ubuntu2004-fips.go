@@ -0,0 +1,19 @@
+package scenario
+
+import (
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+func ubuntu2004FIPS() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2004-fips",
+		Description: "Tests that a node using the Ubuntu 2004 FIPS VHD can be properly bootstrapped, running the kernel and OpenSSL in FIPS mode",
+		Config: Config{
+			ClusterSelector:        NetworkPluginKubenetSelector,
+			ClusterMutator:         NetworkPluginKubenetMutator,
+			BootstrapConfigMutator: FIPSMutator(datamodel.AKSUbuntuFipsContainerd2004Gen2),
+			VMSSMutator:            ImageReferenceMutator("ubuntu2004-fips"),
+			LiveVMValidators:       FIPSValidators(),
+		},
+	}
+}
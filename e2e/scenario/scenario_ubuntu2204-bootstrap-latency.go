@@ -0,0 +1,33 @@
+package scenario
+
+import (
+	"time"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+func ubuntu2204BootstrapLatency() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2204-bootstrap-latency",
+		Description: "Tests that nodes of several VM sizes using the Ubuntu 2204 VHD become Ready within the bootstrap latency SLO of their VM size",
+		Tags:        []string{TagNightly},
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+			},
+			VMSSMutator:        ImageReferenceMutator("ubuntu2204"),
+			ScrapeKubeletStats: true,
+			Parameters:         VMSizeParameters("Standard_D2ds_v5", "Standard_D8ds_v5"),
+			BootstrapLatencySLO: &BootstrapLatencySLO{
+				Default: 5 * time.Minute,
+				VMSizes: map[string]time.Duration{
+					// bootstrapping is mostly bound by pulling and unpacking, which is slowest on the smallest sizes
+					"Standard_D2ds_v5": 6 * time.Minute,
+				},
+			},
+		},
+	}
+}
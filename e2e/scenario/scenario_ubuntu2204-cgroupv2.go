@@ -0,0 +1,22 @@
+package scenario
+
+import (
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+func ubuntu2204CgroupV2() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2204-cgroupv2",
+		Description: "Tests that a node using the Ubuntu 2204 VHD can be properly bootstrapped on cgroupv2, with kubelet and containerd managing cgroups through systemd",
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+			},
+			VMSSMutator:      ImageReferenceMutator("ubuntu2204"),
+			LiveVMValidators: CgroupV2Validators("runc"),
+		},
+	}
+}
@@ -0,0 +1,23 @@
+package scenario
+
+import (
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+func ubuntu2204CIS() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2204-cis",
+		Description: "Tests that a node using the CIS-hardened Ubuntu 2204 VHD can be properly bootstrapped without regressing the CIS benchmark items applied to the VHD",
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+				CISMutator(nbc)
+			},
+			VMSSMutator:      ImageReferenceMutator("ubuntu2204"),
+			LiveVMValidators: CISValidators(),
+		},
+	}
+}
@@ -0,0 +1,20 @@
+package scenario
+
+import "github.com/Azure/agentbaker/pkg/agent/datamodel"
+
+func ubuntu2204CSIDrivers() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2204-csi-drivers",
+		Description: "Tests that a node using the Ubuntu 2204 VHD can be properly bootstrapped and can mount volumes provisioned through the Azure Disk and Azure File CSI drivers",
+		Config: Config{
+			ClusterSelector:    NetworkPluginKubenetSelector,
+			ClusterMutator:     NetworkPluginKubenetMutator,
+			ValidateCSIDrivers: true,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+			},
+			VMSSMutator: ImageReferenceMutator("ubuntu2204"),
+		},
+	}
+}
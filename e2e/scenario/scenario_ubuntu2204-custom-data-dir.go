@@ -0,0 +1,34 @@
+package scenario
+
+import (
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+func ubuntu2204CustomDataDir() *Scenario {
+	// VM size with a large local temp disk, mounted at /mnt by the VM agent
+	vmSize := "Standard_D4ds_v5"
+	// a containerd data directory on the temp disk other than the one used by default for the temp disk kubelet disk type
+	containerdDataDir := "/mnt/containerd"
+	return &Scenario{
+		Name:        "ubuntu2204-custom-data-dir",
+		Description: "Tests that a node using the Ubuntu 2204 VHD with kubelet disk type set to the temp disk and a custom containerd data directory can be properly bootstrapped with kubelet and containerd data relocated onto the temp disk",
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].VMSize = vmSize
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].KubeletDiskType = datamodel.TempDisk
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.VMSize = vmSize
+				nbc.AgentPoolProfile.KubeletDiskType = datamodel.TempDisk
+				ContainerdDataDirMutator(containerdDataDir)(nbc)
+			},
+			VMSSMutator: ComposeVMSSMutators(
+				ImageReferenceMutator("ubuntu2204"),
+				VMSizeMutator(vmSize),
+			),
+			LiveVMValidators: TempDiskDataDirValidators(containerdDataDir),
+		},
+	}
+}
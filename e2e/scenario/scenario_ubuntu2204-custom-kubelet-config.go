@@ -0,0 +1,23 @@
+package scenario
+
+import "github.com/Azure/agentbaker/pkg/agent/datamodel"
+
+func ubuntu2204CustomKubeletConfig() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2204-custom-kubelet-config",
+		Description: "Tests that a node using the Ubuntu 2204 VHD can be properly bootstrapped when supplied a custom kubelet config, with the running kubelet's effective configuration reflecting it",
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				CustomKubeletConfigMutator(nbc)
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+			},
+			VMSSMutator:             ImageReferenceMutator("ubuntu2204"),
+			LiveVMValidators:        CustomKubeletConfigValidators(),
+			NodeValidators:          CustomKubeletConfigNodeValidators(),
+			KubeletConfigValidators: CustomKubeletConfigKubeletConfigValidators(),
+		},
+	}
+}
@@ -0,0 +1,51 @@
+package scenario
+
+import (
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+)
+
+func ubuntu2204CustomLinuxOSConfig() *Scenario {
+	customLinuxOSConfig := &datamodel.CustomLinuxOSConfig{
+		Sysctls: &datamodel.SysctlConfig{
+			NetCoreSomaxconn:          to.Ptr[int32](32768),
+			NetCoreNetdevMaxBacklog:   to.Ptr[int32](2000),
+			NetCoreRmemMax:            to.Ptr[int32](16777216),
+			NetCoreWmemMax:            to.Ptr[int32](16777216),
+			NetIpv4TcpMaxSynBacklog:   to.Ptr[int32](32768),
+			NetIpv4TcpFinTimeout:      to.Ptr[int32](30),
+			NetIpv4TcpKeepaliveTime:   to.Ptr[int32](600),
+			NetIpv4TcpKeepaliveProbes: to.Ptr[int32](5),
+			// the sysctl template renders any explicitly set value as enabled, so only enabling it can be validated
+			NetIpv4TcpTwReuse:       to.Ptr(true),
+			NetIpv4IpLocalPortRange: "16384 65535",
+			FsInotifyMaxUserWatches: to.Ptr[int32](1048576),
+			FsFileMax:               to.Ptr[int32](2097152),
+			FsAioMaxNr:              to.Ptr[int32](131072),
+			KernelThreadsMax:        to.Ptr[int32](200000),
+			VMMaxMapCount:           to.Ptr[int32](262144),
+			VMSwappiness:            to.Ptr[int32](10),
+			VMVfsCachePressure:      to.Ptr[int32](80),
+		},
+		UlimitConfig: &datamodel.UlimitConfig{
+			MaxLockedMemory: "infinity",
+			NoFile:          "1048576",
+		},
+		SwapFileSizeMB: to.Ptr[int32](1024),
+	}
+	return &Scenario{
+		Name:        "ubuntu2204-custom-linux-os-config",
+		Description: "Tests that a node using the Ubuntu 2204 VHD can be properly bootstrapped when supplied a CustomLinuxOSConfig setting sysctls, containerd ulimits and a swap file, asserting each of its settings on the node",
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+				CustomLinuxOSConfigMutator(customLinuxOSConfig)(nbc)
+			},
+			VMSSMutator:      ImageReferenceMutator("ubuntu2204"),
+			LiveVMValidators: CustomLinuxOSConfigValidators(customLinuxOSConfig),
+		},
+	}
+}
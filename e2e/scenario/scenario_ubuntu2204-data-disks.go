@@ -0,0 +1,23 @@
+package scenario
+
+import "github.com/Azure/agentbaker/pkg/agent/datamodel"
+
+func ubuntu2204DataDisks() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2204-data-disks",
+		Description: "Tests that a node using the Ubuntu 2204 VHD can be properly bootstrapped with data disks attached, without formatting or mounting them",
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			DataDisks: []DataDisk{
+				{SizeGB: 32, StorageType: DataDiskTypePremiumSSD},
+				{SizeGB: 64, StorageType: DataDiskTypeStandardSSD},
+			},
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+			},
+			VMSSMutator: ImageReferenceMutator("ubuntu2204"),
+		},
+	}
+}
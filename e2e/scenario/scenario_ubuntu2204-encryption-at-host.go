@@ -0,0 +1,21 @@
+package scenario
+
+import "github.com/Azure/agentbaker/pkg/agent/datamodel"
+
+func ubuntu2204EncryptionAtHost() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2204-encryption-at-host",
+		Description: "Tests that a node using the Ubuntu 2204 VHD can be properly bootstrapped with encryption at host enabled",
+		Tags:        []string{TagNightly},
+		Config: Config{
+			ClusterSelector:  NetworkPluginKubenetSelector,
+			ClusterMutator:   NetworkPluginKubenetMutator,
+			EncryptionAtHost: true,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+			},
+			VMSSMutator: ImageReferenceMutator("ubuntu2204"),
+		},
+	}
+}
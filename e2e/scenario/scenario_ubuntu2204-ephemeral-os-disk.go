@@ -0,0 +1,29 @@
+package scenario
+
+import "github.com/Azure/agentbaker/pkg/agent/datamodel"
+
+func ubuntu2204EphemeralOSDisk() *Scenario {
+	// VM size whose local resource disk is large enough to hold the ephemeral OS disk
+	vmSize := "Standard_D4ds_v5"
+	var osDiskSizeGB int32 = 128
+	return &Scenario{
+		Name:        "ubuntu2204-ephemeral-os-disk",
+		Description: "Tests that a node using the Ubuntu 2204 VHD with an ephemeral OS disk can be properly bootstrapped with its root filesystem spanning the OS disk",
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			OSDiskSizeGB:    osDiskSizeGB,
+			OSDiskType:      OSDiskTypeEphemeral,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].VMSize = vmSize
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.VMSize = vmSize
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+			},
+			VMSSMutator: ComposeVMSSMutators(ImageReferenceMutator("ubuntu2204"), VMSizeMutator(vmSize)),
+			LiveVMValidators: []*LiveVMValidator{
+				RootFilesystemSizeValidator(osDiskSizeGB),
+			},
+		},
+	}
+}
@@ -0,0 +1,28 @@
+package scenario
+
+import (
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+)
+
+func ubuntu2204Gen1() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2204-gen1",
+		Description: "Tests that a node using the Gen1 Ubuntu 2204 VHD can be properly bootstrapped",
+		Config: Config{
+			ClusterSelector:  NetworkPluginKubenetSelector,
+			ClusterMutator:   NetworkPluginKubenetMutator,
+			HyperVGeneration: HyperVGenerationV1,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04"
+			},
+			VMSSMutator: func(vmss *armcompute.VirtualMachineScaleSet) {
+				vmss.Properties.VirtualMachineProfile.StorageProfile.ImageReference = &armcompute.ImageReference{
+					ID: to.Ptr(ImageVersionID("ubuntu2204", HyperVGenerationV1)),
+				}
+			},
+		},
+	}
+}
@@ -0,0 +1,36 @@
+package scenario
+
+import (
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+func ubuntu2204gpuBYODriver() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2204-gpu-byo-driver",
+		Description: "Tests that a GPU-enabled node using the Ubuntu 2204 VHD whose agentpool brings its own GPU driver becomes ready without the driver being installed, and with the device plugin left stopped despite being enabled",
+		Tags:        []string{TagGPU},
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			Requirements:    GPURequirements(),
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].VMSize = gpuVMSize
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.VMSize = gpuVMSize
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.ConfigGPUDriverIfNeeded = true
+				nbc.EnableGPUDevicePluginIfNeeded = true
+				nbc.EnableNvidia = true
+			},
+			VMSSMutator: ComposeVMSSMutators(
+				ImageReferenceMutator("ubuntu2204"),
+				VMSizeMutator(gpuVMSize),
+				SkipGPUDriverInstallMutator,
+			),
+			LiveVMValidators: BYOGPUDriverValidators(),
+			NodeValidators: []*NodeValidator{
+				NodeResourceNotAdvertisedValidator(nvidiaGPUResourceName),
+			},
+		},
+	}
+}
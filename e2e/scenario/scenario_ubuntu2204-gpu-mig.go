@@ -0,0 +1,37 @@
+package scenario
+
+import (
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+// the GPU instance profile partitioning the scenario's A100 into the largest number of GPU instances
+const ubuntu2204MIGGPUInstanceProfile = "MIG1g"
+
+func ubuntu2204gpuMIG() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2204-gpu-mig",
+		Description: "Tests that a MIG-capable GPU node using the Ubuntu 2204 VHD can be properly bootstrapped with a GPU instance profile, partitioning its A100 such that each partition is advertised as an allocatable GPU",
+		Tags:        []string{TagGPU, TagNightly},
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			Requirements:    MIGRequirements(),
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+				MIGMutator(ubuntu2204MIGGPUInstanceProfile)(nbc)
+			},
+			VMSSMutator: ComposeVMSSMutators(
+				ImageReferenceMutator("ubuntu2204"),
+				VMSizeMutator(migVMSize),
+			),
+			ExpectedAllocatableResources: MIGAllocatableResources(ubuntu2204MIGGPUInstanceProfile),
+			LiveVMValidators: append([]*LiveVMValidator{
+				ContainerdConfigValidator(
+					ContainerdDefaultRuntime("nvidia-container-runtime"),
+					ContainerdRuntimeHandler("nvidia-container-runtime", "io.containerd.runc.v2", "/usr/bin/nvidia-container-runtime"),
+				),
+			}, MIGValidators(ubuntu2204MIGGPUInstanceProfile)...),
+		},
+	}
+}
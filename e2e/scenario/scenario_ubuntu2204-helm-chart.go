@@ -2,14 +2,12 @@ package scenario
 
 import (
 	"github.com/Azure/agentbaker/pkg/agent/datamodel"
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
 )
 
-func ubuntu2204() *Scenario {
+func ubuntu2204HelmChart() *Scenario {
 	return &Scenario{
-		Name:        "ubuntu2204",
-		Description: "Tests that a node using the Ubuntu 2204 VHD can be properly bootstrapped",
+		Name:        "ubuntu2204-helm-chart",
+		Description: "Tests that a node using the Ubuntu 2204 VHD can run the workloads of a Helm chart installed with values rendered for the node, each of which becomes ready",
 		Config: Config{
 			ClusterSelector: NetworkPluginKubenetSelector,
 			ClusterMutator:  NetworkPluginKubenetMutator,
@@ -17,10 +15,15 @@ func ubuntu2204() *Scenario {
 				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
 				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
 			},
-			VMConfigMutator: func(vmss *armcompute.VirtualMachineScaleSet) {
-				vmss.Properties.VirtualMachineProfile.StorageProfile.ImageReference = &armcompute.ImageReference{
-					ID: to.Ptr(DefaultImageVersionIDs["ubuntu2204"]),
-				}
+			VMSSMutator: ImageReferenceMutator("ubuntu2204"),
+			HelmCharts: []HelmChart{
+				{
+					Release: "node-workload",
+					Chart:   "scenario/charts/node-workload",
+					Values: map[string]any{
+						"nodeName": "{{ .NodeName }}",
+					},
+				},
 			},
 		},
 	}
@@ -0,0 +1,20 @@
+package scenario
+
+import "github.com/Azure/agentbaker/pkg/agent/datamodel"
+
+func ubuntu2204HTTPProxy() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2204-http-proxy",
+		Description: "Tests that a node using the Ubuntu 2204 VHD can be properly bootstrapped while egressing through an HTTP proxy, with the proxy and no-proxy list rendered into the environment of kubelet and containerd",
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			HTTPProxy:       true,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+			},
+			VMSSMutator: ImageReferenceMutator("ubuntu2204"),
+		},
+	}
+}
@@ -0,0 +1,25 @@
+package scenario
+
+import (
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+func ubuntu2204IPv6Primary() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2204-ipv6-primary",
+		Description: "Tests that a node using the Ubuntu 2204 VHD can be properly bootstrapped on a dual-stack kubenet cluster with kubelet preferring its IPv6 address, such that the node reports IPv6 as its primary IP",
+		Config: Config{
+			ClusterSelector: NetworkDualStackSelector,
+			ClusterMutator:  NetworkDualStackMutator,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+				IPv6PrimaryMutator(nbc)
+			},
+			VMSSMutator: ComposeVMSSMutators(
+				ImageReferenceMutator("ubuntu2204"),
+				DualStackVMSSMutator,
+			),
+		},
+	}
+}
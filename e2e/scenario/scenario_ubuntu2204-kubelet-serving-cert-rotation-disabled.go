@@ -0,0 +1,20 @@
+package scenario
+
+import "github.com/Azure/agentbaker/pkg/agent/datamodel"
+
+func ubuntu2204KubeletServingCertRotationDisabled() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2204-kubelet-serving-cert-rotation-disabled",
+		Description: "Tests that a node using the Ubuntu 2204 VHD bootstrapped with kubelet serving certificate rotation disabled doesn't request a serving certificate, serving with its self-signed certificate instead",
+		Config: Config{
+			ClusterSelector:            NetworkPluginKubenetSelector,
+			ClusterMutator:             NetworkPluginKubenetMutator,
+			ValidateKubeletServingCert: true,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+			},
+			VMSSMutator: ImageReferenceMutator("ubuntu2204"),
+		},
+	}
+}
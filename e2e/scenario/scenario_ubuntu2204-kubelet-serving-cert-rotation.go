@@ -0,0 +1,21 @@
+package scenario
+
+import "github.com/Azure/agentbaker/pkg/agent/datamodel"
+
+func ubuntu2204KubeletServingCertRotation() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2204-kubelet-serving-cert-rotation",
+		Description: "Tests that a node using the Ubuntu 2204 VHD can be properly bootstrapped with kubelet serving certificate rotation enabled, with kubelet serving with the certificate issued for its serving CSR",
+		Config: Config{
+			ClusterSelector:            NetworkPluginKubenetSelector,
+			ClusterMutator:             NetworkPluginKubenetMutator,
+			ValidateKubeletServingCert: true,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+				KubeletServingCertRotationMutator(nbc)
+			},
+			VMSSMutator: ImageReferenceMutator("ubuntu2204"),
+		},
+	}
+}
@@ -0,0 +1,34 @@
+package scenario
+
+import "github.com/Azure/agentbaker/pkg/agent/datamodel"
+
+func ubuntu2204KubeletTempDisk() *Scenario {
+	// VM size with a large local temp disk, mounted at /mnt by the VM agent. Sizes whose local disks are NVMe, e.g. the v6
+	// series, are out of scope, since their disks are left raw, and neither the VM agent nor AgentBaker mounts them at /mnt
+	vmSize := "Standard_D4ds_v5"
+	return &Scenario{
+		Name:        "ubuntu2204-kubelet-tempdisk",
+		Description: "Tests that a node using the Ubuntu 2204 VHD with kubelet disk type set to the temp disk can be properly bootstrapped with kubelet and containerd data placed on the temp disk",
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].VMSize = vmSize
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].KubeletDiskType = datamodel.TempDisk
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.VMSize = vmSize
+				nbc.AgentPoolProfile.KubeletDiskType = datamodel.TempDisk
+			},
+			VMSSMutator: ComposeVMSSMutators(ImageReferenceMutator("ubuntu2204"), VMSizeMutator(vmSize)),
+			LiveVMValidators: []*LiveVMValidator{
+				MountPointValidator("/mnt"),
+				MountPointValidator("/var/lib/kubelet"),
+				// bind-mount.service moves /var/lib/kubelet to /mnt/aks/kubelet and bind mounts it back in place
+				MountSourceValidator("/var/lib/kubelet", "[/aks/kubelet]"),
+				ContainerdConfigValidator(ContainerdRoot(datamodel.TempDiskContainerDataDir)),
+				NonEmptyDirectoryValidator(datamodel.TempDiskContainerDataDir),
+			},
+		},
+	}
+}
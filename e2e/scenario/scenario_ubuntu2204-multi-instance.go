@@ -0,0 +1,27 @@
+package scenario
+
+import (
+	"time"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+func ubuntu2204MultiInstance() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2204-multi-instance",
+		Description: "Tests that several nodes using the Ubuntu 2204 VHD can be properly bootstrapped when joining the cluster concurrently",
+		Tags:        []string{TagNightly},
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			VMSSCapacity:    3,
+			// each instance is validated in turn
+			Timeout: 30 * time.Minute,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+			},
+			VMSSMutator: ImageReferenceMutator("ubuntu2204"),
+		},
+	}
+}
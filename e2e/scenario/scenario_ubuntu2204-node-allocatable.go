@@ -0,0 +1,29 @@
+package scenario
+
+import (
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+func ubuntu2204NodeAllocatable() *Scenario {
+	// each differs from the template's flags, which don't reserve resources for system daemons
+	evictionHard := "memory.available<500Mi,nodefs.available<15%,nodefs.inodesFree<10%,imagefs.available<20%"
+	kubeReserved := "cpu=200m,memory=1024Mi"
+	systemReserved := "cpu=100m,memory=512Mi"
+	return &Scenario{
+		Name:        "ubuntu2204-node-allocatable",
+		Description: "Tests that a node using the Ubuntu 2204 VHD can be properly bootstrapped with custom hard eviction thresholds and resources reserved for kubernetes and system daemons, which are reflected by kubelet's effective configuration and the node's allocatable memory, with kubelet and containerd protected from the OOM killer",
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+				NodeAllocatableMutator(evictionHard, kubeReserved, systemReserved)(nbc)
+			},
+			VMSSMutator:             ImageReferenceMutator("ubuntu2204"),
+			LiveVMValidators:        NodeAllocatableValidators(evictionHard, kubeReserved, systemReserved),
+			KubeletConfigValidators: NodeAllocatableKubeletConfigValidators(evictionHard, kubeReserved, systemReserved),
+			NodeValidators:          NodeAllocatableNodeValidators(evictionHard, kubeReserved, systemReserved),
+		},
+	}
+}
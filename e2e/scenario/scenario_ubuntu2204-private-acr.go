@@ -0,0 +1,21 @@
+package scenario
+
+import "github.com/Azure/agentbaker/pkg/agent/datamodel"
+
+func ubuntu2204PrivateACR() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2204-private-acr",
+		Description: "Tests that a node using the Ubuntu 2204 VHD can pull images from a private ACR through its private endpoint within the cluster VNet, authenticating with a BYO user-assigned kubelet identity granted AcrPull",
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			KubeletIdentity: true,
+			PrivateACR:      true,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+			},
+			VMSSMutator: ImageReferenceMutator("ubuntu2204"),
+		},
+	}
+}
@@ -0,0 +1,45 @@
+package scenario
+
+import (
+	"fmt"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+	"github.com/barkimedes/go-deepcopy"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func ubuntu2204Rebootstrap() *Scenario {
+	// published by the ubuntu2204 scenario once its node has been validated
+	var dependency *DependencyState
+	return &Scenario{
+		Name:        "ubuntu2204-rebootstrap",
+		Description: "Tests that a node using the Ubuntu 2204 VHD can be bootstrapped with the same bootstrap config as an existing node of the cluster, joining it as a node of its own",
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			DependsOn:       "ubuntu2204",
+			DependencyMutator: func(nbc *datamodel.NodeBootstrappingConfiguration, state *DependencyState) {
+				copied, err := deepcopy.Anything(state.BootstrapConfig)
+				if err != nil {
+					panic(fmt.Sprintf("failed to copy the bootstrap config of vmss %q: %s", state.VMSSName, err))
+				}
+				*nbc = *copied.(*datamodel.NodeBootstrappingConfiguration)
+				dependency = state
+			},
+			VMSSMutator: ImageReferenceMutator("ubuntu2204"),
+			NodeValidators: []*NodeValidator{
+				{
+					Description: "assert node is distinct from the nodes of the scenario it depends on",
+					Asserter: func(node *corev1.Node) error {
+						for _, name := range dependency.NodeNames {
+							if node.Name == name {
+								return fmt.Errorf("expected node %q to be distinct from the nodes of vmss %q, but it was one of them", node.Name, dependency.VMSSName)
+							}
+						}
+						return nil
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,30 @@
+package scenario
+
+import (
+	"time"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+func ubuntu2204SpotEviction() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2204-spot-eviction",
+		Description: "Tests that a spot priority node using the Ubuntu 2204 VHD becomes unavailable once its VM is evicted",
+		Tags:        []string{TagSpot, TagNightly},
+		Config: Config{
+			ClusterSelector:      NetworkPluginKubenetSelector,
+			ClusterMutator:       NetworkPluginKubenetMutator,
+			SimulateSpotEviction: true,
+			// waiting for the eviction and for the node to become unavailable can take up to 20 minutes
+			Timeout: 40 * time.Minute,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+			},
+			VMSSMutator: ComposeVMSSMutators(
+				ImageReferenceMutator("ubuntu2204"),
+				SpotVMSSMutator,
+			),
+		},
+	}
+}
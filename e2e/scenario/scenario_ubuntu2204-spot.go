@@ -0,0 +1,30 @@
+package scenario
+
+import (
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+func ubuntu2204Spot() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2204-spot",
+		Description: "Tests that a spot priority node using the Ubuntu 2204 VHD can be properly bootstrapped and runs with spot priority",
+		Tags:        []string{TagSpot},
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			// spot capacity isn't guaranteed, so allocation failures are common
+			MaxAttempts: 3,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+			},
+			VMSSMutator: ComposeVMSSMutators(
+				ImageReferenceMutator("ubuntu2204"),
+				SpotVMSSMutator,
+			),
+			LiveVMValidators: []*LiveVMValidator{
+				SpotPriorityValidator(),
+			},
+		},
+	}
+}
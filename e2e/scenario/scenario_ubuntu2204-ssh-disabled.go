@@ -0,0 +1,23 @@
+package scenario
+
+import (
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+func ubuntu2204SSHDisabled() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2204-ssh-disabled",
+		Description: "Tests that a node using the Ubuntu 2204 VHD bootstrapped with SSH turned off has its SSH daemon stopped and disabled, while still being validated through a debug pod scheduled onto the node",
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+				SSHDisabledMutator(nbc)
+			},
+			VMSSMutator:      ImageReferenceMutator("ubuntu2204"),
+			LiveVMValidators: SSHDisabledValidators("ssh.service"),
+		},
+	}
+}
@@ -0,0 +1,27 @@
+package scenario
+
+import (
+	"time"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+func ubuntu2204SSHKeyRotation() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2204-ssh-key-rotation",
+		Description: "Tests that a node using the Ubuntu 2204 VHD remains healthy and reachable after the SSH key of its VMSS has been rotated",
+		Tags:        []string{TagNightly},
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			RotateSSHKey:    true,
+			// includes upgrading the VMSS instance to the rotated key
+			Timeout: 30 * time.Minute,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+			},
+			VMSSMutator: ImageReferenceMutator("ubuntu2204"),
+		},
+	}
+}
@@ -0,0 +1,30 @@
+package scenario
+
+import (
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+)
+
+func ubuntu2204Swap() *Scenario {
+	// small enough to fit on the resource disk of the default VM size, such that CSE doesn't fall back to the OS disk
+	customLinuxOSConfig := &datamodel.CustomLinuxOSConfig{
+		SwapFileSizeMB: to.Ptr[int32](2048),
+	}
+	return &Scenario{
+		Name:        "ubuntu2204-swap",
+		Description: "Tests that a node using the Ubuntu 2204 VHD can be properly bootstrapped with swap enabled through a swap file created by CSE, with kubelet configured not to fail when swap is enabled",
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+				// also turns off failSwapOn, without which CSE doesn't create the swap file
+				CustomLinuxOSConfigMutator(customLinuxOSConfig)(nbc)
+			},
+			VMSSMutator:             ImageReferenceMutator("ubuntu2204"),
+			LiveVMValidators:        append(CustomLinuxOSConfigValidators(customLinuxOSConfig), SwapKubeletValidators()...),
+			KubeletConfigValidators: SwapKubeletConfigValidators(),
+		},
+	}
+}
@@ -0,0 +1,28 @@
+package scenario
+
+import (
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+func ubuntu2204TransparentHugePage() *Scenario {
+	// both differ from the kernel's defaults on Ubuntu, which are "madvise" for each
+	customLinuxOSConfig := &datamodel.CustomLinuxOSConfig{
+		TransparentHugePageEnabled: "never",
+		TransparentHugePageDefrag:  "defer+madvise",
+	}
+	return &Scenario{
+		Name:        "ubuntu2204-transparent-hugepage",
+		Description: "Tests that a node using the Ubuntu 2204 VHD can be properly bootstrapped when supplied a CustomLinuxOSConfig setting the transparent hugepage enabled and defrag settings, which are in effect on the node and persisted across reboots",
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+				CustomLinuxOSConfigMutator(customLinuxOSConfig)(nbc)
+			},
+			VMSSMutator:      ImageReferenceMutator("ubuntu2204"),
+			LiveVMValidators: CustomLinuxOSConfigValidators(customLinuxOSConfig),
+		},
+	}
+}
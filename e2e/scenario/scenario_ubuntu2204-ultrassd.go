@@ -0,0 +1,36 @@
+package scenario
+
+import (
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+)
+
+func ubuntu2204UltraSSD() *Scenario {
+	// VM size supporting ultra disks
+	vmSize := "Standard_D4s_v5"
+	return &Scenario{
+		Name:        "ubuntu2204-ultrassd",
+		Description: "Tests that a node using the Ubuntu 2204 VHD can be properly bootstrapped with an UltraSSD data disk attached, without formatting or mounting it",
+		Tags:        []string{TagNightly},
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			// ultra disk capacity is limited to specific zones, so allocation failures are common
+			MaxAttempts: 3,
+			DataDisks: []DataDisk{
+				{SizeGB: 32, StorageType: DataDiskTypeUltraSSD},
+			},
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].VMSize = vmSize
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.VMSize = vmSize
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+			},
+			VMSSMutator: ComposeVMSSMutators(ImageReferenceMutator("ubuntu2204"), VMSizeMutator(vmSize), func(vmss *armcompute.VirtualMachineScaleSet) {
+				// ultra disks can only be attached to zonal VMs
+				vmss.Zones = []*string{to.Ptr("1")}
+			}),
+		},
+	}
+}
@@ -0,0 +1,39 @@
+package scenario
+
+import (
+	"time"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+const (
+	// an address within TEST-NET-1 (RFC 5737), which is guaranteed to be unroutable
+	unreachableAPIServerIPAddress = "192.0.2.1"
+
+	// ERR_K8S_API_SERVER_CONN_FAIL, returned by the CSE when it's unable to establish a connection to the API server
+	cseExitCodeAPIServerConnFail = 51
+)
+
+func ubuntu2204UnreachableAPIServer() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2204-unreachable-apiserver",
+		Description: "Tests that bootstrapping a node using the Ubuntu 2204 VHD fails with ERR_K8S_API_SERVER_CONN_FAIL when the API server endpoint is unreachable",
+		Tags:        []string{TagNegative},
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			ExpectedFailure: &ExpectedFailure{
+				CSEExitCode: cseExitCodeAPIServerConnFail,
+			},
+			// the CSE retries connecting to the API server for several minutes before giving up
+			Timeout: 30 * time.Minute,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+				// the API server's IP address takes precedence over its FQDN
+				nbc.ContainerService.Properties.HostedMasterProfile.IPAddress = unreachableAPIServerIPAddress
+			},
+			VMSSMutator: ImageReferenceMutator("ubuntu2204"),
+		},
+	}
+}
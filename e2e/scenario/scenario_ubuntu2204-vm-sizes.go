@@ -0,0 +1,21 @@
+package scenario
+
+import "github.com/Azure/agentbaker/pkg/agent/datamodel"
+
+func ubuntu2204VMSizes() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2204-vm-sizes",
+		Description: "Tests that nodes of each of several general purpose VM sizes using the Ubuntu 2204 VHD can be properly bootstrapped",
+		Tags:        []string{TagNightly},
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+			},
+			VMSSMutator: ImageReferenceMutator("ubuntu2204"),
+			Parameters:  VMSizeParameters("Standard_D2ds_v5", "Standard_D4s_v5", "Standard_E2s_v5"),
+		},
+	}
+}
@@ -10,6 +10,7 @@ func ubuntu2204Wasm() *Scenario {
 	return &Scenario{
 		Name:        "ubuntu2204-wasm",
 		Description: "tests that a new ubuntu 2204 node using krustlet can be properly bootstrapepd",
+		Tags:        []string{TagWasm},
 		Config: Config{
 			ClusterSelector: NetworkPluginKubenetSelector,
 			ClusterMutator:  NetworkPluginKubenetMutator,
@@ -19,7 +20,7 @@ func ubuntu2204Wasm() *Scenario {
 				nbc.AgentPoolProfile.WorkloadRuntime = datamodel.WasmWasi
 				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
 			},
-			VMConfigMutator: func(vmss *armcompute.VirtualMachineScaleSet) {
+			VMSSMutator: func(vmss *armcompute.VirtualMachineScaleSet) {
 				vmss.Properties.VirtualMachineProfile.StorageProfile.ImageReference = &armcompute.ImageReference{
 					ID: to.Ptr(DefaultImageVersionIDs["ubuntu2204"]),
 				}
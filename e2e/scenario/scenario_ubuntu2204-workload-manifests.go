@@ -0,0 +1,20 @@
+package scenario
+
+import "github.com/Azure/agentbaker/pkg/agent/datamodel"
+
+func ubuntu2204WorkloadManifests() *Scenario {
+	return &Scenario{
+		Name:        "ubuntu2204-workload-manifests",
+		Description: "Tests that a node using the Ubuntu 2204 VHD can run a job applied from the scenario's manifests, which asserts that the config map applied alongside it was rendered with the namespace and node name of the job's pod",
+		Config: Config{
+			ClusterSelector: NetworkPluginKubenetSelector,
+			ClusterMutator:  NetworkPluginKubenetMutator,
+			BootstrapConfigMutator: func(nbc *datamodel.NodeBootstrappingConfiguration) {
+				nbc.ContainerService.Properties.AgentPoolProfiles[0].Distro = "aks-ubuntu-containerd-22.04-gen2"
+				nbc.AgentPoolProfile.Distro = "aks-ubuntu-containerd-22.04-gen2"
+			},
+			VMSSMutator: ImageReferenceMutator("ubuntu2204"),
+			Manifests:   "scenario/manifests/ubuntu2204-workload-manifests",
+		},
+	}
+}
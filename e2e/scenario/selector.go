@@ -0,0 +1,54 @@
+package scenario
+
+import (
+	"regexp"
+)
+
+// Selector determines which of the suite's scenarios are included in the scenario table. Nil or empty sets and
+// patterns don't restrict the selection.
+type Selector struct {
+	// Include is the set of names of the scenarios to run, taking precedence over Exclude
+	Include map[string]bool
+
+	// Exclude is the set of names of the scenarios not to run
+	Exclude map[string]bool
+
+	// NamePattern must match a scenario's name in full for the scenario to run
+	NamePattern *regexp.Regexp
+
+	// IncludeTags is the set of tags of which a scenario must carry at least one to run
+	IncludeTags map[string]bool
+
+	// ExcludeTags is the set of tags of which a scenario must carry none to run, taking precedence over IncludeTags
+	ExcludeTags map[string]bool
+}
+
+// Matches returns true if the supplied scenario is selected to run.
+func (s Selector) Matches(scenario *Scenario) bool {
+	if s.NamePattern != nil && !s.NamePattern.MatchString(scenario.Name) {
+		return false
+	}
+	if s.Include != nil {
+		if !s.Include[scenario.Name] {
+			return false
+		}
+	} else if s.Exclude != nil {
+		if s.Exclude[scenario.Name] {
+			return false
+		}
+	}
+	for _, tag := range scenario.Tags {
+		if s.ExcludeTags[tag] {
+			return false
+		}
+	}
+	if len(s.IncludeTags) > 0 {
+		for _, tag := range scenario.Tags {
+			if s.IncludeTags[tag] {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package scenario
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestSelectorMatches(t *testing.T) {
+	gpu := &Scenario{Name: "ubuntu2204-gpu", Tags: []string{TagGPU, TagNightly}}
+	untagged := &Scenario{Name: "ubuntu2204"}
+
+	cases := []struct {
+		name     string
+		selector Selector
+		scenario *Scenario
+		matches  bool
+	}{
+		{
+			name:     "empty selector matches every scenario",
+			scenario: gpu,
+			matches:  true,
+		},
+		{
+			name:     "included by name",
+			selector: Selector{Include: map[string]bool{"ubuntu2204-gpu": true}},
+			scenario: gpu,
+			matches:  true,
+		},
+		{
+			name:     "not included by name",
+			selector: Selector{Include: map[string]bool{"ubuntu2204-gpu": true}},
+			scenario: untagged,
+		},
+		{
+			name:     "excluded by name",
+			selector: Selector{Exclude: map[string]bool{"ubuntu2204": true}},
+			scenario: untagged,
+		},
+		{
+			name: "inclusion takes precedence over exclusion",
+			selector: Selector{
+				Include: map[string]bool{"ubuntu2204": true},
+				Exclude: map[string]bool{"ubuntu2204": true},
+			},
+			scenario: untagged,
+			matches:  true,
+		},
+		{
+			name:     "name pattern matches",
+			selector: Selector{NamePattern: regexp.MustCompile(`^(ubuntu2204-.*)$`)},
+			scenario: gpu,
+			matches:  true,
+		},
+		{
+			name:     "name pattern doesn't match",
+			selector: Selector{NamePattern: regexp.MustCompile(`^(ubuntu2204-.*)$`)},
+			scenario: untagged,
+		},
+		{
+			name: "name pattern applies to included scenarios",
+			selector: Selector{
+				Include:     map[string]bool{"ubuntu2204": true},
+				NamePattern: regexp.MustCompile(`^(ubuntu2204-.*)$`),
+			},
+			scenario: untagged,
+		},
+		{
+			name:     "carries an included tag",
+			selector: Selector{IncludeTags: map[string]bool{TagGPU: true, TagARM64: true}},
+			scenario: gpu,
+			matches:  true,
+		},
+		{
+			name:     "carries no included tag",
+			selector: Selector{IncludeTags: map[string]bool{TagGPU: true}},
+			scenario: untagged,
+		},
+		{
+			name:     "carries an excluded tag",
+			selector: Selector{ExcludeTags: map[string]bool{TagNightly: true}},
+			scenario: gpu,
+		},
+		{
+			name: "excluded tags take precedence over included tags",
+			selector: Selector{
+				IncludeTags: map[string]bool{TagGPU: true},
+				ExcludeTags: map[string]bool{TagNightly: true},
+			},
+			scenario: gpu,
+		},
+		{
+			name: "excluded tags apply to included scenarios",
+			selector: Selector{
+				Include:     map[string]bool{"ubuntu2204-gpu": true},
+				ExcludeTags: map[string]bool{TagNightly: true},
+			},
+			scenario: gpu,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			if matches := c.selector.Matches(c.scenario); matches != c.matches {
+				t.Errorf("expected Matches to be %t, but got %t", c.matches, matches)
+			}
+		})
+	}
+}
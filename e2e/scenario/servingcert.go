@@ -0,0 +1,109 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+const (
+	rotateServerCertificatesFlag = "--rotate-server-certificates"
+
+	// written by kubelet once its serving CSR has been issued, pointing at the latest issued certificate
+	kubeletServingCertPath = "/var/lib/kubelet/pki/kubelet-server-current.pem"
+	// generated during bootstrapping, and served by kubelet unless serving certificate rotation is enabled
+	kubeletSelfSignedServingCertPath = "/etc/kubernetes/certs/kubeletserver.crt"
+
+	// curl is used rather than openssl s_client, since the latter waits on stdin once the handshake has completed
+	kubeletServingCertCommand = "curl -sSvk -o /dev/null https://127.0.0.1:10250/healthz"
+)
+
+// KubeletServingCertRotationMutator configures kubelet to request its serving certificate from the cluster through a
+// CSR, rotating it as it nears expiry, rather than serving with the self-signed certificate generated during bootstrapping
+func KubeletServingCertRotationMutator(nbc *datamodel.NodeBootstrappingConfiguration) {
+	nbc.KubeletConfig[rotateServerCertificatesFlag] = "true"
+}
+
+// IsKubeletServingCertRotationEnabled returns true if the bootstrap config configures kubelet to request its serving
+// certificate through a CSR
+func IsKubeletServingCertRotationEnabled(nbc *datamodel.NodeBootstrappingConfiguration) bool {
+	return nbc.KubeletConfig[rotateServerCertificatesFlag] == "true"
+}
+
+// KubeletRotatedServingCertValidators returns validators asserting that kubelet has written the certificate with the
+// specified SHA-256 fingerprint, issued for the specified node's serving CSR, and that it serves with it
+func KubeletRotatedServingCertValidators(nodeName, fingerprint string) []*LiveVMValidator {
+	return []*LiveVMValidator{
+		CertificateFingerprintValidator(kubeletServingCertPath, fingerprint),
+		KubeletServingCertSubjectValidator(fmt.Sprintf("CN=system:node:%s", nodeName)),
+	}
+}
+
+// KubeletSelfSignedServingCertValidators returns validators asserting that kubelet serves with the self-signed certificate
+// generated for the specified node during bootstrapping, not having been issued a serving certificate
+func KubeletSelfSignedServingCertValidators(nodeName string) []*LiveVMValidator {
+	return []*LiveVMValidator{
+		NonEmptyFileValidator(kubeletSelfSignedServingCertPath),
+		{
+			Description: fmt.Sprintf("assert %s doesn't exist", kubeletServingCertPath),
+			Command:     fmt.Sprintf("test -e %s", kubeletServingCertPath),
+			Asserter: func(code, stdout, stderr string) error {
+				if code == "0" {
+					return fmt.Errorf("expected kubelet not to have been issued a serving certificate, but found %s", kubeletServingCertPath)
+				}
+				return nil
+			},
+		},
+		KubeletServingCertSubjectValidator(fmt.Sprintf("CN=%s", nodeName)),
+	}
+}
+
+// CertificateFingerprintValidator asserts that the PEM-encoded certificate at the specified path has the specified SHA-256
+// fingerprint, formatted as colon-separated hex bytes
+func CertificateFingerprintValidator(path, fingerprint string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s fingerprint", path),
+		Command:     fmt.Sprintf("openssl x509 -in %s -noout -fingerprint -sha256", path),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0, stderr: %q", code, stderr)
+			}
+			// of the form "sha256 Fingerprint=AB:CD:...", though older versions of openssl capitalize the digest
+			_, actual, found := strings.Cut(strings.TrimSpace(stdout), "=")
+			if !found {
+				return fmt.Errorf("unable to parse fingerprint of %s from %q", path, stdout)
+			}
+			if !strings.EqualFold(actual, fingerprint) {
+				return fmt.Errorf("expected %s to have fingerprint %s, but had fingerprint %s", path, fingerprint, actual)
+			}
+			return nil
+		},
+	}
+}
+
+// KubeletServingCertSubjectValidator asserts that the subject of the certificate kubelet serves with contains the specified
+// substring, e.g. "CN=system:node:<node name>" for certificates issued for kubelet's serving CSR
+func KubeletServingCertSubjectValidator(subject string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert kubelet serves with a certificate of subject %s", subject),
+		Command:     kubeletServingCertCommand,
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("unable to connect to kubelet, validator command terminated with exit code %q, stderr: %q", code, stderr)
+			}
+			// curl writes the certificate's details to stderr, e.g. "*  subject: O=system:nodes; CN=system:node:<node name>"
+			for _, line := range strings.Split(stderr, "\n") {
+				_, actual, found := strings.Cut(line, "subject:")
+				if !found {
+					continue
+				}
+				if actual = strings.TrimSpace(actual); !strings.Contains(actual, subject) {
+					return fmt.Errorf("expected kubelet to serve with a certificate of subject %s, but its subject was %s", subject, actual)
+				}
+				return nil
+			}
+			return fmt.Errorf("unable to find the subject of kubelet's serving certificate within %q", stderr)
+		},
+	}
+}
@@ -0,0 +1,42 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+const sshPort = 22
+
+// SSHDisabledMutator configures the bootstrap config to turn SSH off, such that CSE stops and disables the node's SSH daemon.
+// The suite executes the commands of such scenarios through a debug pod scheduled onto the node rather than over SSH
+func SSHDisabledMutator(nbc *datamodel.NodeBootstrappingConfiguration) {
+	nbc.SSHStatus = datamodel.SSHOff
+}
+
+// SSHDisabledValidators returns validators asserting that the specified SSH daemon unit, e.g. "ssh.service" on Ubuntu, has been
+// stopped and disabled by CSE, and that nothing else is listening on the SSH port in its place
+func SSHDisabledValidators(unit string) []*LiveVMValidator {
+	return []*LiveVMValidator{
+		SystemdUnitStatusValidator(unit, SystemdUnitStatus{ActiveState: "inactive", UnitFileState: "disabled"}),
+		NoListeningTCPPortValidator(sshPort),
+	}
+}
+
+// NoListeningTCPPortValidator asserts that no process is listening on the specified TCP port
+func NoListeningTCPPortValidator(port int) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert nothing is listening on TCP port %d", port),
+		Command:     fmt.Sprintf("ss -H -t -l -n 'sport = :%d'", port),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0", code)
+			}
+			if listeners := strings.TrimSpace(stdout); listeners != "" {
+				return fmt.Errorf("expected nothing to be listening on TCP port %d, but found:\n%s", port, listeners)
+			}
+			return nil
+		},
+	}
+}
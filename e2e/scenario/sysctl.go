@@ -0,0 +1,75 @@
+package scenario
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+)
+
+// SysctlConfigToMap converts the supplied SysctlConfig, as specified within a CustomLinuxOSConfig, into a mapping
+// from each explicitly set sysctl's key to its expected value on the node. The result can be passed directly to
+// SysctlConfigValidator, such that scenarios can declare their expectations using the same config given to AgentBaker.
+func SysctlConfigToMap(config *datamodel.SysctlConfig) map[string]string {
+	sysctls := map[string]string{}
+	if config == nil {
+		return sysctls
+	}
+
+	int32Sysctls := map[string]*int32{
+		"net.core.somaxconn":                 config.NetCoreSomaxconn,
+		"net.core.netdev_max_backlog":        config.NetCoreNetdevMaxBacklog,
+		"net.core.rmem_default":              config.NetCoreRmemDefault,
+		"net.core.rmem_max":                  config.NetCoreRmemMax,
+		"net.core.wmem_default":              config.NetCoreWmemDefault,
+		"net.core.wmem_max":                  config.NetCoreWmemMax,
+		"net.core.optmem_max":                config.NetCoreOptmemMax,
+		"net.ipv4.tcp_max_syn_backlog":       config.NetIpv4TcpMaxSynBacklog,
+		"net.ipv4.tcp_max_tw_buckets":        config.NetIpv4TcpMaxTwBuckets,
+		"net.ipv4.tcp_fin_timeout":           config.NetIpv4TcpFinTimeout,
+		"net.ipv4.tcp_keepalive_time":        config.NetIpv4TcpKeepaliveTime,
+		"net.ipv4.tcp_keepalive_probes":      config.NetIpv4TcpKeepaliveProbes,
+		"net.ipv4.tcp_keepalive_intvl":       config.NetIpv4TcpkeepaliveIntvl,
+		"net.ipv4.neigh.default.gc_thresh1":  config.NetIpv4NeighDefaultGcThresh1,
+		"net.ipv4.neigh.default.gc_thresh2":  config.NetIpv4NeighDefaultGcThresh2,
+		"net.ipv4.neigh.default.gc_thresh3":  config.NetIpv4NeighDefaultGcThresh3,
+		"net.netfilter.nf_conntrack_max":     config.NetNetfilterNfConntrackMax,
+		"net.netfilter.nf_conntrack_buckets": config.NetNetfilterNfConntrackBuckets,
+		"fs.inotify.max_user_watches":        config.FsInotifyMaxUserWatches,
+		"fs.file-max":                        config.FsFileMax,
+		"fs.aio-max-nr":                      config.FsAioMaxNr,
+		"fs.nr_open":                         config.FsNrOpen,
+		"kernel.threads-max":                 config.KernelThreadsMax,
+		"vm.max_map_count":                   config.VMMaxMapCount,
+		"vm.swappiness":                      config.VMSwappiness,
+		"vm.vfs_cache_pressure":              config.VMVfsCachePressure,
+	}
+	for key, value := range int32Sysctls {
+		if value != nil {
+			sysctls[key] = strconv.Itoa(int(*value))
+		}
+	}
+
+	if config.NetIpv4TcpTwReuse != nil {
+		sysctls["net.ipv4.tcp_tw_reuse"] = "0"
+		if *config.NetIpv4TcpTwReuse {
+			sysctls["net.ipv4.tcp_tw_reuse"] = "1"
+		}
+	}
+	if config.NetIpv4IpLocalPortRange != "" {
+		sysctls["net.ipv4.ip_local_port_range"] = config.NetIpv4IpLocalPortRange
+	}
+
+	return sysctls
+}
+
+// Parses the output of "sysctl KEY..." into a mapping from each sysctl's key to its value
+func parseSysctlOutput(output string) map[string]string {
+	sysctls := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		if key, value, found := strings.Cut(line, " = "); found {
+			sysctls[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return sysctls
+}
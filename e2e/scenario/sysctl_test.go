@@ -0,0 +1,100 @@
+package scenario
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+)
+
+func TestSysctlConfigToMap(t *testing.T) {
+	cases := []struct {
+		name     string
+		config   *datamodel.SysctlConfig
+		expected map[string]string
+	}{
+		{
+			name:     "no config",
+			expected: map[string]string{},
+		},
+		{
+			name:     "nothing set",
+			config:   &datamodel.SysctlConfig{},
+			expected: map[string]string{},
+		},
+		{
+			name: "only set sysctls are expected",
+			config: &datamodel.SysctlConfig{
+				NetCoreSomaxconn:        to.Ptr[int32](163849),
+				VMSwappiness:            to.Ptr[int32](0),
+				NetIpv4IpLocalPortRange: "32768 62535",
+			},
+			expected: map[string]string{
+				"net.core.somaxconn":           "163849",
+				"vm.swappiness":                "0",
+				"net.ipv4.ip_local_port_range": "32768 62535",
+			},
+		},
+		{
+			name:     "enabled tcp_tw_reuse",
+			config:   &datamodel.SysctlConfig{NetIpv4TcpTwReuse: to.Ptr(true)},
+			expected: map[string]string{"net.ipv4.tcp_tw_reuse": "1"},
+		},
+		{
+			name:     "disabled tcp_tw_reuse",
+			config:   &datamodel.SysctlConfig{NetIpv4TcpTwReuse: to.Ptr(false)},
+			expected: map[string]string{"net.ipv4.tcp_tw_reuse": "0"},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			if sysctls := SysctlConfigToMap(c.config); !reflect.DeepEqual(sysctls, c.expected) {
+				t.Errorf("expected sysctls %v, but got %v", c.expected, sysctls)
+			}
+		})
+	}
+}
+
+func TestParseSysctlOutput(t *testing.T) {
+	cases := []struct {
+		name     string
+		output   string
+		expected map[string]string
+	}{
+		{
+			name:     "no output",
+			expected: map[string]string{},
+		},
+		{
+			name:   "several sysctls",
+			output: "net.core.somaxconn = 163849\nnet.ipv4.ip_local_port_range = 32768 62535\nvm.swappiness = 0\n",
+			expected: map[string]string{
+				"net.core.somaxconn":           "163849",
+				"net.ipv4.ip_local_port_range": "32768 62535",
+				"vm.swappiness":                "0",
+			},
+		},
+		{
+			name:     "lines which aren't sysctls are ignored",
+			output:   "sysctl: cannot stat /proc/sys/net/bogus: No such file or directory\nvm.swappiness = 60\n",
+			expected: map[string]string{"vm.swappiness": "60"},
+		},
+		{
+			name:     "empty value",
+			output:   "kernel.core_pattern = \n",
+			expected: map[string]string{"kernel.core_pattern": ""},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			if sysctls := parseSysctlOutput(c.output); !reflect.DeepEqual(sysctls, c.expected) {
+				t.Errorf("expected sysctls %v, but got %v", c.expected, sysctls)
+			}
+		})
+	}
+}
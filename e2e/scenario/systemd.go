@@ -0,0 +1,66 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SystemdUnitStatus is the expected status of a systemd unit, of which only the fields which are set are asserted
+type SystemdUnitStatus struct {
+	// ActiveState is the unit's expected high-level state, e.g. "active", "inactive" or "failed"
+	ActiveState string `json:"activeState,omitempty"`
+
+	// SubState is the unit's expected type-specific state, e.g. "running" or "exited" for services, and "waiting" or
+	// "elapsed" for timers
+	SubState string `json:"subState,omitempty"`
+
+	// UnitFileState is the expected enablement of the unit's unit file, e.g. "enabled", "disabled", "masked" or "static"
+	UnitFileState string `json:"unitFileState,omitempty"`
+}
+
+// SystemdServiceRunning returns the status of a long-running service which was started and is enabled to start on boot,
+// e.g. kubelet or containerd
+func SystemdServiceRunning() SystemdUnitStatus {
+	return SystemdUnitStatus{
+		ActiveState:   "active",
+		SubState:      "running",
+		UnitFileState: "enabled",
+	}
+}
+
+// SystemdUnitStatusValidator asserts that the specified systemd unit, e.g. "kubelet.service", is loaded and has the
+// expected status. Units must be named by their full name, including their type suffix
+func SystemdUnitStatusValidator(unit string, expected SystemdUnitStatus) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s status", unit),
+		Command:     fmt.Sprintf("systemctl show -p Id,LoadState,ActiveState,SubState,UnitFileState %s", unit),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0, stderr: %q", code, stderr)
+			}
+			if state := parseSystemdProperties(stdout, "LoadState")[unit]; state != "loaded" {
+				return fmt.Errorf("expected %s to be loaded, but its load state was %q", unit, state)
+			}
+
+			var mismatches []string
+			for _, property := range []struct {
+				name, expected string
+			}{
+				{name: "ActiveState", expected: expected.ActiveState},
+				{name: "SubState", expected: expected.SubState},
+				{name: "UnitFileState", expected: expected.UnitFileState},
+			} {
+				if property.expected == "" {
+					continue
+				}
+				if actual := parseSystemdProperties(stdout, property.name)[unit]; actual != property.expected {
+					mismatches = append(mismatches, fmt.Sprintf("expected %s to be %q, but it was %q", property.name, property.expected, actual))
+				}
+			}
+			if len(mismatches) > 0 {
+				return fmt.Errorf("status of %s did not match expectations:\n%s", unit, strings.Join(mismatches, "\n"))
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,26 @@
+package scenario
+
+// Tags used to group scenarios, such that different slices of the scenario table can be selected to run, e.g. by
+// PR-gate and nightly pipelines
+const (
+	// TagGPU is carried by scenarios running on GPU-enabled VM sizes
+	TagGPU = "gpu"
+
+	// TagARM64 is carried by scenarios running on arm64 VM sizes
+	TagARM64 = "arm64"
+
+	// TagWasm is carried by scenarios running WebAssembly workloads
+	TagWasm = "wasm"
+
+	// TagAzureCNI is carried by scenarios requiring a cluster using Azure CNI
+	TagAzureCNI = "azurecni"
+
+	// TagSpot is carried by scenarios running on spot priority VMs
+	TagSpot = "spot"
+
+	// TagNegative is carried by scenarios whose bootstrapping is expected to fail, i.e. those with an ExpectedFailure
+	TagNegative = "negative"
+
+	// TagNightly is carried by scenarios which are too slow or expensive to run on every PR
+	TagNightly = "nightly"
+)
@@ -1,9 +1,14 @@
 package scenario
 
 import (
+	"context"
+	"time"
+
 	"github.com/Azure/agentbaker/pkg/agent/datamodel"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice"
+	corev1 "k8s.io/api/core/v1"
 )
 
 // Table represents a set of mappings from scenario name -> Scenario to
@@ -18,6 +23,9 @@ type Scenario struct {
 	// Description is a short description of what the scenario does and tests for
 	Description string
 
+	// Tags are used to group scenarios, such that they can be selected to run by tag, e.g. TagGPU
+	Tags []string
+
 	// Config contains the configuration of the scenario
 	Config
 }
@@ -35,14 +43,278 @@ type Config struct {
 	// BootstrapConfigMutator is a function which mutates the base NodeBootstrappingConfig according to the scenario's requirements
 	BootstrapConfigMutator func(*datamodel.NodeBootstrappingConfiguration)
 
-	// VMConfigMutator is a function which mutates the base VMSS model according to the scenario's requirements
-	VMConfigMutator func(*armcompute.VirtualMachineScaleSet)
+	// DependsOn is the name of the scenario which must have been validated before the scenario runs, e.g. a scenario re-bootstrapping
+	// a node depending on the scenario which created it. The scenario runs on the same cluster as its dependency, while the
+	// dependency's VMSS still exists, and isn't run at all when the dependency fails. Selecting the scenario also selects its dependency
+	DependsOn string
+
+	// DependencyMutator is a function which mutates the scenario's bootstrap config after BootstrapConfigMutator, according to
+	// the state published by the scenario it depends on
+	DependencyMutator func(*datamodel.NodeBootstrappingConfiguration, *DependencyState)
+
+	// ExclusiveCluster is set for scenarios which need exclusive use of their cluster, e.g. because they mutate cluster-level
+	// settings, such that no other scenario runs on the cluster concurrently. Scenarios depending on such a scenario run within
+	// its exclusive use, while an exclusive scenario may only depend on another exclusive scenario
+	ExclusiveCluster bool
+
+	// Parameters are the values the scenario is parameterized by, e.g. VMSizeParameters, each of which is run as a parallel
+	// subtest of the scenario's test with a VMSS of its own, e.g. "Test_All/<scenario>/<parameter>". The scenario's cluster
+	// and bootstrap config are shared by each of its parameters, whose results are reported individually
+	Parameters []Parameter
+
+	// VMSSMutator is a function which mutates the base VMSS model according to the scenario's requirements, analogous to ClusterMutator.
+	// It's applied after the VMSS knobs below, so can be used to further customize e.g. the VMSS's priority, disks, identity, and
+	// extensions - ComposeVMSSMutators can be used to combine several reusable mutators
+	VMSSMutator func(*armcompute.VirtualMachineScaleSet)
+
+	// Requirements are the capabilities of the suite's subscription and region which the scenario depends on, e.g. the
+	// availability of its VM size. The scenario is skipped, rather than failed, when any of them aren't met
+	Requirements Requirements
+
+	// Timeout bounds the duration of the scenario's run, from the creation of its VMSS through to the end of its validation -
+	// defaults to DefaultTimeout when unset
+	Timeout time.Duration
+
+	// MaxAttempts is the maximum number of times the scenario's VMSS is created when its creation fails with an error classified as
+	// transient, e.g. due to a capacity shortage or throttling, each time with a new VMSS - defaults to a single attempt when unset.
+	// Bootstrapping and validation failures are never retried
+	MaxAttempts int
+
+	// VMSSCapacity is the number of instances to create within the scenario's VMSS, each of which will bootstrap concurrently and be
+	// validated individually - defaults to a single instance when unset
+	VMSSCapacity int
+
+	// HyperVGeneration is the Hyper-V generation of the VHD the scenario's VMSS is created from, which the node is validated
+	// as having booted with - defaults to V2 when unset. Scenarios are responsible for selecting a VHD of the same generation,
+	// e.g. via ImageVersionID
+	HyperVGeneration HyperVGeneration
+
+	// OSDiskSizeGB overrides the size of the OS disk of the scenario's VMSS in GB - the size of the base VMSS model is used when unset
+	OSDiskSizeGB int32
+
+	// OSDiskType overrides the storage type of the OS disk of the scenario's VMSS - the platform default is used when unset
+	OSDiskType OSDiskType
+
+	// DataDisks are empty managed data disks to attach to the scenario's VMSS, at LUNs matching their index. Bootstrapping is expected
+	// to leave each of them unformatted and unmounted
+	DataDisks []DataDisk
+
+	// EncryptionAtHost indicates whether the scenario's VMSS should be created with encryption at host enabled. The scenario
+	// implicitly requires the EncryptionAtHost feature, so is skipped when it isn't registered on the suite's subscription
+	EncryptionAtHost bool
+
+	// SimulateSpotEviction indicates whether the scenario's spot VM should be evicted once it has been validated, after which its node
+	// is expected to become unavailable - requires the VMSS to use spot priority, e.g. by way of SpotVMSSMutator
+	SimulateSpotEviction bool
+
+	// RotateSSHKey indicates whether the SSH key of the scenario's VMSS should be rotated once its nodes have been validated, after which
+	// each node is expected to remain healthy and to only be reachable using the new key
+	RotateSSHKey bool
+
+	// KubeletIdentity indicates whether the scenario's VMSS should be assigned the suite's user-assigned kubelet identity, with
+	// the bootstrap config updated such that kubelet authenticates using it rather than a service principal
+	KubeletIdentity bool
+
+	// HTTPProxy indicates whether the scenario's nodes should be bootstrapped to egress through the suite's HTTP proxy, which
+	// runs within the cluster. Each node is expected to be configured to use the proxy, and the proxy to have served its requests
+	HTTPProxy bool
+
+	// ValidateKubeletServingCert indicates whether the certificate kubelet serves with should be validated. When the bootstrap
+	// config enables serving certificate rotation, the node's serving CSR is approved, and kubelet is expected to serve with the
+	// issued certificate. Otherwise, kubelet is expected not to request one, serving with its self-signed certificate instead
+	ValidateKubeletServingCert bool
+
+	// ValidateServiceConnectivity indicates whether the node's service networking, as configured by bootstrap for kube-proxy,
+	// should be validated. A NodePort service is exposed in front of pods pinned to the node, which is expected to reach the
+	// service through its cluster IP, and to be reachable by the rest of the cluster through its node port
+	ValidateServiceConnectivity bool
+
+	// ValidateCSIDrivers indicates whether the Azure Disk and Azure File CSI node drivers should be validated on the scenario's
+	// nodes, by running a pod on each node which writes to a volume of each, dynamically provisioned through the cluster's
+	// built-in storage classes
+	ValidateCSIDrivers bool
+
+	// ProbeNetworkPerf indicates whether the pod network throughput and latency between each of the scenario's nodes and one of
+	// the cluster's existing nodes should be measured and recorded within the scenario's result, providing a performance signal
+	// for bootstrap changes affecting networking, e.g. of the MTU or NIC offloads. The measurements don't fail the scenario
+	ProbeNetworkPerf bool
+
+	// ScrapeKubeletStats indicates whether the metrics and summary API of each of the scenario's kubelets should be scraped once
+	// the suite's validation pods have run on the node, recording their PLEG and pod start latencies, along with the node's resource usage, within
+	// the scenario's result, providing a performance signal for performance-sensitive scenarios. The stats don't fail the scenario
+	ScrapeKubeletStats bool
+
+	// Manifests is the directory of the YAML manifests of the scenario's supporting workloads, relative to the e2e directory, e.g.
+	// "scenario/manifests/<scenario>". They're applied once each of the scenario's nodes is Ready, within the scenario's
+	// namespace unless they name their own, and waited on to become ready before the scenario's nodes are validated. Each
+	// manifest is rendered as a Go template with the values {{ .Namespace }} and {{ .NodeName }}, the latter of which should
+	// be included within the names of the objects of scenarios with more than one instance
+	Manifests string
+
+	// HelmCharts are Helm charts of the scenario's supporting workloads, e.g. operators, installed with the helm CLI once each of
+	// the scenario's nodes is Ready, before its Manifests are applied, and waited on to become ready. Each chart is installed, or
+	// upgraded, once per node, so releases installed outside the scenario's namespace are shared by its instances, and remain
+	// installed once the scenario has finished
+	HelmCharts []HelmChart
+
+	// BootstrapLatencySLO, when set, is the objective of the time taken for each of the scenario's nodes to become Ready, from
+	// the creation of its VMSS. Each node's latency is recorded within the scenario's result, and fails the scenario when it
+	// exceeds the objective of the node's VM size
+	BootstrapLatencySLO *BootstrapLatencySLO
+
+	// ExpectedAllocatableResources are the extended resources, e.g. those advertised by device plugins, which the scenario's nodes
+	// are expected to report as allocatable, keyed by resource name. Each node is given time for its device plugins to register
+	ExpectedAllocatableResources map[corev1.ResourceName]int64
+
+	// NvidiaGPUs, when set, is the number of NVIDIA GPUs each of the scenario's nodes is expected to advertise as allocatable
+	// through the NVIDIA device plugin. Unless the bootstrap config enables the device plugin managed by CSE, through
+	// EnableGPUDevicePluginIfNeeded, the suite deploys the upstream device plugin daemonset onto each node beforehand
+	NvidiaGPUs int64
+
+	// PrivateACR indicates whether the scenario's nodes should pull an image from the suite's private ACR, which is reachable
+	// from the cluster's VNet through a private endpoint, authenticating with the suite's kubelet identity. Requires KubeletIdentity
+	PrivateACR bool
+
+	// ExpectedFailure, when set, marks the scenario as a negative scenario whose bootstrapping is expected to fail in the specified
+	// way, e.g. with a particular CSE exit code. The scenario fails if its VMSS is created successfully, and none of its node or
+	// live VM validators are run
+	ExpectedFailure *ExpectedFailure
+
+	// Cleanup is a function which deletes the scenario-specific resources the scenario created, e.g. ACRs, NSGs or extra VMSSes,
+	// once the scenario's validation has finished, regardless of whether it passed. It runs after the scenario's VMSS has been
+	// deleted, and not at all when VMSSes are retained for debugging. Errors are logged, without affecting the scenario's outcome
+	Cleanup func(context.Context, *CleanupContext) error
 
 	// LiveVMValidators is a slice of LiveVMValidator objects for performing any live VM validation
 	// specific to the scenario that isn't covered in the set of common validators run with all scenarios
 	LiveVMValidators []*LiveVMValidator
+
+	// NodeValidators is a slice of NodeValidator objects for performing any validation of the scenario's Kubernetes node object
+	// that isn't covered in the set of common node validators run with all scenarios
+	NodeValidators []*NodeValidator
+
+	// KubeletConfigValidators is a slice of KubeletConfigValidator objects for performing any validation of the effective
+	// configuration of the scenario's running kubelet, as served by its /configz endpoint
+	KubeletConfigValidators []*KubeletConfigValidator
+}
+
+// CleanupContext locates the resources of a scenario's run, passed to the scenario's Cleanup
+type CleanupContext struct {
+	// SubscriptionID is the ID of the suite's subscription
+	SubscriptionID string
+
+	// ResourceGroupName is the name of the suite's resource group, holding its clusters and shared resources
+	ResourceGroupName string
+
+	// NodeResourceGroupName is the name of the node resource group of the cluster the scenario ran on, holding its VMSS
+	NodeResourceGroupName string
+
+	// ClusterName is the name of the cluster the scenario ran on
+	ClusterName string
+
+	// VMSSName is the name of the scenario's VMSS, which has already been deleted - empty when it was never created
+	VMSSName string
+
+	// Credential authenticates with the suite's subscription, with which the clients deleting the resources can be created
+	Credential azcore.TokenCredential
+
+	// Failed indicates whether the scenario failed
+	Failed bool
+}
+
+// Requirements are capabilities of the suite's subscription and region, each of which must be met for a scenario to run
+type Requirements struct {
+	// VMSizes are the VM sizes which must be available to the subscription within the region, e.g. "Standard_NC6s_v3"
+	VMSizes []string
+
+	// Features are the subscription features which must be registered, of the form "<namespace>/<name>", e.g.
+	// "Microsoft.Compute/EncryptionAtHost"
+	Features []string
+
+	// VCPUQuotas maps the names of the VM families whose vCPU quota the scenario consumes, e.g. "standardNCSv3Family",
+	// to the minimum number of vCPUs which must remain available within the region
+	VCPUQuotas map[string]int32
+}
+
+// EncryptionAtHostFeature is the subscription feature required to create VMs with encryption at host enabled
+const EncryptionAtHostFeature = "Microsoft.Compute/EncryptionAtHost"
+
+// EffectiveRequirements returns the scenario's requirements, including those implied by the rest of its config.
+func (c *Config) EffectiveRequirements() Requirements {
+	requirements := c.Requirements
+	if c.EncryptionAtHost {
+		requirements.Features = append(append([]string{}, requirements.Features...), EncryptionAtHostFeature)
+	}
+	return requirements
+}
+
+// DefaultTimeout is the timeout of scenarios which don't specify their own, long enough for a single VMSS instance to be
+// created, bootstrapped and validated
+const DefaultTimeout = 20 * time.Minute
+
+// EffectiveTimeout returns the scenario's timeout, or DefaultTimeout if it doesn't specify one.
+func (c *Config) EffectiveTimeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return DefaultTimeout
+}
+
+// EffectiveMaxAttempts returns the maximum number of attempts at creating the scenario's VMSS, which is at least 1.
+func (c *Config) EffectiveMaxAttempts() int {
+	if c.MaxAttempts > 1 {
+		return c.MaxAttempts
+	}
+	return 1
+}
+
+// ExpectedFailure describes the way in which a negative scenario's bootstrapping is expected to fail
+type ExpectedFailure struct {
+	// CSEExitCode is the exit code the CSE is expected to terminate with, e.g. 51 (ERR_K8S_API_SERVER_CONN_FAIL) - any non-zero
+	// exit code is accepted when unset
+	CSEExitCode int `json:"cseExitCode,omitempty"`
+
+	// ErrorMessage is a substring expected within the CSE's error message, which includes the tail of its output
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// HyperVGeneration is the Hyper-V generation of a VHD, which determines whether VMs boot via BIOS (V1) or UEFI (V2)
+type HyperVGeneration string
+
+const (
+	HyperVGenerationV1 HyperVGeneration = "V1"
+	HyperVGenerationV2 HyperVGeneration = "V2"
+)
+
+// OSDiskType is the storage type of the OS disk of a scenario's VMSS
+type OSDiskType string
+
+const (
+	OSDiskTypePremiumSSD  OSDiskType = "Premium_LRS"
+	OSDiskTypeStandardSSD OSDiskType = "StandardSSD_LRS"
+	// OSDiskTypeEphemeral places the OS disk on the VM's local resource disk, which must be at least as large as the OS disk
+	OSDiskTypeEphemeral OSDiskType = "Ephemeral"
+)
+
+// DataDisk is an empty managed data disk attached to a scenario's VMSS
+type DataDisk struct {
+	// SizeGB is the size of the data disk in GB
+	SizeGB int32
+
+	// StorageType is the storage type of the data disk, e.g. Premium_LRS or UltraSSD_LRS - the latter additionally requires
+	// a VM size supporting ultra disks and the VMSS to be deployed into an availability zone
+	StorageType DataDiskType
 }
 
+// DataDiskType is the storage type of a data disk attached to a scenario's VMSS
+type DataDiskType string
+
+const (
+	DataDiskTypePremiumSSD  DataDiskType = "Premium_LRS"
+	DataDiskTypeStandardSSD DataDiskType = "StandardSSD_LRS"
+	DataDiskTypeUltraSSD    DataDiskType = "UltraSSD_LRS"
+)
+
 // VMCommandOutputAsserterFn is a function which takes in stdout and stderr stream content
 // as strings and performs arbitrary assertions on them, returning an error in the case where the assertion fails
 type VMCommandOutputAsserterFn func(code, stdout, stderr string) error
@@ -63,4 +335,63 @@ type LiveVMValidator struct {
 	// IsShellBuiltIn is a boolean flag which indicates whether or not the command is a shell built-in
 	// that will fail when executed with sudo - requires separate command to avoid command not found error on node
 	IsShellBuiltIn bool
+
+	// Severity is the severity of the validator failing - SeverityFail when unset
+	Severity Severity
+
+	// Timeout bounds the duration of the validator, including any retries of the execution of its command, after which it
+	// fails rather than failing the scenario's validation as a whole - the validator is only bounded by the scenario's timeout when unset
+	Timeout time.Duration
+
+	// AllOf and AnyOf are the validators composed by a validator returned by AllOf or AnyOf respectively, which has no command
+	// of its own
+	AllOf []*LiveVMValidator
+	AnyOf []*LiveVMValidator
+}
+
+// NodeAsserterFn is a function which takes in the Kubernetes node object of a live VM and performs
+// arbitrary assertions on it, returning an error in the case where the assertion fails
+type NodeAsserterFn func(node *corev1.Node) error
+
+// NodeValidator represents an assertion to be made against the Kubernetes node object of a live VM
+// after it has joined the cluster and become ready
+type NodeValidator struct {
+	// Description is the description of the validator and what it actually validates on the node
+	Description string
+
+	// Asserter is the validator's NodeAsserterFn which will be run against the node object
+	Asserter NodeAsserterFn
+
+	// Severity is the severity of the validator failing - SeverityFail when unset
+	Severity Severity
 }
+
+// KubeletConfigAsserterFn is a function which takes in the effective configuration of a live VM's running kubelet,
+// decoded from its /configz endpoint, and performs arbitrary assertions on it, returning an error in the case where
+// the assertion fails
+type KubeletConfigAsserterFn func(config map[string]any) error
+
+// KubeletConfigValidator represents an assertion to be made against the effective configuration of a live VM's running
+// kubelet after it has joined the cluster and become ready
+type KubeletConfigValidator struct {
+	// Description is the description of the validator and what it actually validates within the kubelet's configuration
+	Description string
+
+	// Asserter is the validator's KubeletConfigAsserterFn which will be run against the kubelet's configuration
+	Asserter KubeletConfigAsserterFn
+
+	// Severity is the severity of the validator failing - SeverityFail when unset
+	Severity Severity
+}
+
+// Severity is the severity of a validator failing, which determines whether it fails the scenario
+type Severity string
+
+const (
+	// SeverityFail fails the scenario when the validator fails
+	SeverityFail Severity = "fail"
+
+	// SeverityWarn marks the validator as advisory, such that it's reported as a warning within the scenario's result rather
+	// than failing the scenario, e.g. to roll out a new check across every scenario before promoting it to SeverityFail
+	SeverityWarn Severity = "warn"
+)
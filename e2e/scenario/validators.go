@@ -2,9 +2,18 @@ package scenario
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 )
 
+const (
+	// CSE runs with xtrace enabled, so the invocation of each task it logs an event for is traced within its provisioning log,
+	// e.g. "+ logs_to_events AKS.CSE.ensureGPUDrivers ensureGPUDrivers"
+	cseProvisioningLogPath = "/var/log/azure/cluster-provision.log"
+	cseTaskCommandTemplate = "grep -c -F 'logs_to_events %s ' %s"
+)
+
 func DirectoryValidator(path string, files []string) *LiveVMValidator {
 	return &LiveVMValidator{
 		Description: fmt.Sprintf("assert %s contents", path),
@@ -24,10 +33,11 @@ func DirectoryValidator(path string, files []string) *LiveVMValidator {
 }
 
 func SysctlConfigValidator(customSysctls map[string]string) *LiveVMValidator {
-	keysToCheck := make([]string, len(customSysctls))
-	for k, _ := range customSysctls {
+	keysToCheck := make([]string, 0, len(customSysctls))
+	for k := range customSysctls {
 		keysToCheck = append(keysToCheck, k)
 	}
+	sort.Strings(keysToCheck)
 	// regex used in sed command to remove extra spaces between two numerical values, used to verify correct values for
 	// sysctls that have string values, e.g. net.ipv4.ip_local_port_range, which would be printed with extra spaces
 	return &LiveVMValidator{
@@ -37,10 +47,8 @@ func SysctlConfigValidator(customSysctls map[string]string) *LiveVMValidator {
 			if code != "0" {
 				return fmt.Errorf("validator command terminated with exit code %q but expected code 0", code)
 			}
-			for name, value := range customSysctls {
-				if !strings.Contains(stdout, fmt.Sprintf("%s = %v", name, value)) {
-					return fmt.Errorf(fmt.Sprintf("expected to find %s set to %v, but was not", name, value))
-				}
+			if mismatches := compareExpectedValues(customSysctls, parseSysctlOutput(stdout)); len(mismatches) > 0 {
+				return fmt.Errorf("sysctl settings did not match expectations:\n%s", strings.Join(mismatches, "\n"))
 			}
 			return nil
 		},
@@ -85,6 +93,33 @@ func NonEmptyDirectoryValidator(dirName string) *LiveVMValidator {
 	}
 }
 
+func NonEmptyFileValidator(fileName string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s exists and is non-empty", fileName),
+		Command:     fmt.Sprintf("test -s %s", fileName),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("expected %s to exist and be non-empty, but it did not", fileName)
+			}
+			return nil
+		},
+	}
+}
+
+// FileExistsValidator asserts that the specified file exists, such as a sentinel file which may be empty
+func FileExistsValidator(fileName string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s exists", fileName),
+		Command:     fmt.Sprintf("test -e %s", fileName),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("expected %s to exist, but it did not", fileName)
+			}
+			return nil
+		},
+	}
+}
+
 func UlimitValidator(ulimits map[string]string) *LiveVMValidator {
 	ulimitKeys := make([]string, 0, len(ulimits))
 	for k := range ulimits {
@@ -107,3 +142,150 @@ func UlimitValidator(ulimits map[string]string) *LiveVMValidator {
 		},
 	}
 }
+
+// FileContentsValidator asserts that the specified file contains the expected contents
+func FileContentsValidator(fileName string, contents string) *LiveVMValidator {
+	return FileValidator(fileName, FileExpectations{Contains: []string{contents}})
+}
+
+func MountPointValidator(path string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s is a mount point", path),
+		Command:     fmt.Sprintf("mountpoint %s", path),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("expected %s to be a mount point, but was not: %s", path, stdout)
+			}
+			return nil
+		},
+	}
+}
+
+// MountSourceValidator asserts that the filesystem mounted at the specified path originates from the expected source,
+// e.g. "/dev/sdb1" for a temp disk mount or "[/aks/kubelet]" for a bind mount of /mnt/aks/kubelet
+func MountSourceValidator(path, expectedSource string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert %s is mounted from %s", path, expectedSource),
+		Command:     fmt.Sprintf("findmnt -n -o SOURCE --mountpoint %s", path),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0", code)
+			}
+			if !strings.Contains(stdout, expectedSource) {
+				return fmt.Errorf("expected %s to be mounted from %s, but was mounted from %q", path, expectedSource, strings.TrimSpace(stdout))
+			}
+			return nil
+		},
+	}
+}
+
+// RootFilesystemSizeValidator asserts that the root filesystem was grown to make use of the OS disk during provisioning.
+// Since the OS disk also holds the boot partitions, the filesystem is only required to span 90% of the disk's size.
+func RootFilesystemSizeValidator(osDiskSizeGB int32) *LiveVMValidator {
+	minSizeGB := int(osDiskSizeGB) * 9 / 10
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert root filesystem spans the %dGB OS disk", osDiskSizeGB),
+		Command:     "df --block-size=1G --output=size /",
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0", code)
+			}
+			// output consists of a header line followed by the filesystem's size
+			fields := strings.Fields(stdout)
+			if len(fields) < 2 {
+				return fmt.Errorf("unable to parse root filesystem size from %q", stdout)
+			}
+			sizeGB, err := strconv.Atoi(strings.TrimSuffix(fields[len(fields)-1], "G"))
+			if err != nil {
+				return fmt.Errorf("unable to parse root filesystem size from %q: %w", stdout, err)
+			}
+			if sizeGB < minSizeGB {
+				return fmt.Errorf("expected root filesystem to be at least %dGB, but was %dGB", minSizeGB, sizeGB)
+			}
+			return nil
+		},
+	}
+}
+
+// UnformattedDataDiskValidator asserts that bootstrapping left the data disk attached at the specified LUN
+// without any partitions, filesystem or mount point.
+func UnformattedDataDiskValidator(lun int) *LiveVMValidator {
+	devicePath := fmt.Sprintf("/dev/disk/azure/scsi1/lun%d", lun)
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert data disk at LUN %d is unformatted and unmounted", lun),
+		Command:     fmt.Sprintf("lsblk --noheadings --output NAME,FSTYPE,MOUNTPOINT %s", devicePath),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("unable to find data disk %s, validator command terminated with exit code %q", devicePath, code)
+			}
+			// an untouched disk is listed as a single device with neither a filesystem type nor a mount point
+			lines := strings.Split(strings.TrimSpace(stdout), "\n")
+			if len(lines) != 1 || len(strings.Fields(lines[0])) != 1 {
+				return fmt.Errorf("expected data disk %s to be unformatted and unmounted, but found:\n%s", devicePath, stdout)
+			}
+			return nil
+		},
+	}
+}
+
+// DataDiskValidators returns an UnformattedDataDiskValidator for each of the supplied data disks.
+func DataDiskValidators(dataDisks []DataDisk) []*LiveVMValidator {
+	validators := make([]*LiveVMValidator, 0, len(dataDisks))
+	for lun := range dataDisks {
+		validators = append(validators, UnformattedDataDiskValidator(lun))
+	}
+	return validators
+}
+
+// HyperVGenerationValidator asserts that the VM booted via the firmware of the specified Hyper-V generation,
+// i.e. UEFI for Gen2 VHDs and BIOS for Gen1 VHDs, defaulting to Gen2 when unset.
+func HyperVGenerationValidator(generation HyperVGeneration) *LiveVMValidator {
+	if generation == "" {
+		generation = HyperVGenerationV2
+	}
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert VM booted as Hyper-V generation %s", generation),
+		// the EFI firmware interface is only exposed to VMs which booted via UEFI
+		Command: "test -d /sys/firmware/efi",
+		Asserter: func(code, stdout, stderr string) error {
+			bootedWithUEFI := code == "0"
+			if generation == HyperVGenerationV2 && !bootedWithUEFI {
+				return fmt.Errorf("expected a Gen2 VM to have booted via UEFI, but /sys/firmware/efi does not exist")
+			}
+			if generation == HyperVGenerationV1 && bootedWithUEFI {
+				return fmt.Errorf("expected a Gen1 VM to have booted via BIOS, but /sys/firmware/efi exists")
+			}
+			return nil
+		},
+	}
+}
+
+// CSETaskRanValidator asserts that CSE ran the specified task, e.g. "AKS.CSE.ensureGPUDrivers", as traced within its
+// provisioning log when logging the task's event
+func CSETaskRanValidator(task string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert CSE ran task %s", task),
+		Command:     fmt.Sprintf(cseTaskCommandTemplate, task, cseProvisioningLogPath),
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("expected CSE to have run task %s, but it wasn't traced within %s", task, cseProvisioningLogPath)
+			}
+			return nil
+		},
+	}
+}
+
+// CSETaskSkippedValidator asserts that CSE didn't run the specified task, e.g. "AKS.CSE.ensureGPUDrivers"
+func CSETaskSkippedValidator(task string) *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: fmt.Sprintf("assert CSE skipped task %s", task),
+		Command:     fmt.Sprintf(cseTaskCommandTemplate, task, cseProvisioningLogPath),
+		Asserter: func(code, stdout, stderr string) error {
+			// grep exits with 1 when nothing matches, and with 2 when the log can't be read
+			if code != "1" {
+				return fmt.Errorf("expected CSE to have skipped task %s, but grep of %s terminated with exit code %q, stdout: %q, stderr: %q", task, cseProvisioningLogPath, code, stdout, stderr)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,152 @@
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+)
+
+const (
+	// VM size and family of the GPU scenarios, in which each VM consumes 6 vCPUs of the family's quota
+	gpuVMSize            = "Standard_NC6s_v3"
+	gpuVMFamily          = "standardNCSv3Family"
+	gpuVMSizeVCPUs int32 = 6
+	// the number of NVIDIA GPUs of the GPU VM size
+	gpuVMSizeGPUs int64 = 1
+
+	// VM size and family of the MIG scenarios, each VM of which has a single MIG-capable A100 GPU
+	migVMSize            = "Standard_NC24ads_A100_v4"
+	migVMFamily          = "StandardNCADSA100v4Family"
+	migVMSizeVCPUs int32 = 24
+
+	// VM size of the arm64 scenarios
+	arm64VMSize = "Standard_D2pds_V5"
+
+	// the query string is built by curl, as with the IMDS token request of the identity validators
+	imdsComputeMetadataCommand = "curl -s -G -H Metadata:true -d api-version=2021-02-01 http://169.254.169.254/metadata/instance/compute"
+)
+
+// Mutators
+
+// ComposeVMSSMutators returns a VMSSMutator which applies each of the supplied mutators to the VMSS model in order,
+// allowing scenarios to declare their VMSS customizations as a list of reusable mutators
+func ComposeVMSSMutators(mutators ...func(*armcompute.VirtualMachineScaleSet)) func(*armcompute.VirtualMachineScaleSet) {
+	return func(vmss *armcompute.VirtualMachineScaleSet) {
+		for _, mutator := range mutators {
+			if mutator != nil {
+				mutator(vmss)
+			}
+		}
+	}
+}
+
+// ImageReferenceMutator returns a VMSSMutator which sets the VMSS model's image reference to the version of the named VHD,
+// e.g. "ubuntu2204". The version is looked up when the mutator is applied, such that versions resolved at runtime are used
+func ImageReferenceMutator(imageName string) func(*armcompute.VirtualMachineScaleSet) {
+	return func(vmss *armcompute.VirtualMachineScaleSet) {
+		vmss.Properties.VirtualMachineProfile.StorageProfile.ImageReference = &armcompute.ImageReference{
+			ID: to.Ptr(DefaultImageVersionIDs[imageName]),
+		}
+	}
+}
+
+// VMSizeMutator returns a VMSSMutator which sets the size of the VMSS model's VMs
+func VMSizeMutator(vmSize string) func(*armcompute.VirtualMachineScaleSet) {
+	return func(vmss *armcompute.VirtualMachineScaleSet) {
+		vmss.SKU.Name = to.Ptr(vmSize)
+	}
+}
+
+// SpotVMSSMutator configures the VMSS model to use spot priority VMs which are deallocated upon eviction.
+// A max price of -1 indicates that VMs shouldn't be evicted for pricing reasons, only for capacity reasons.
+func SpotVMSSMutator(vmss *armcompute.VirtualMachineScaleSet) {
+	if vmss != nil && vmss.Properties != nil && vmss.Properties.VirtualMachineProfile != nil {
+		vmss.Properties.VirtualMachineProfile.Priority = to.Ptr(armcompute.VirtualMachinePriorityTypesSpot)
+		vmss.Properties.VirtualMachineProfile.EvictionPolicy = to.Ptr(armcompute.VirtualMachineEvictionPolicyTypesDeallocate)
+		vmss.Properties.VirtualMachineProfile.BillingProfile = &armcompute.BillingProfile{
+			MaxPrice: to.Ptr[float64](-1),
+		}
+	}
+}
+
+// DualStackVMSSMutator adds an IPv6 IP config to the VMSS model's NIC, within the same dual-stack subnet as its primary IPv4
+// IP config. Azure requires the primary IP config of a NIC to be IPv4, so IPv6 is made primary by kubelet instead
+func DualStackVMSSMutator(vmss *armcompute.VirtualMachineScaleSet) {
+	if vmss == nil || vmss.Properties == nil || vmss.Properties.VirtualMachineProfile == nil || vmss.Properties.VirtualMachineProfile.NetworkProfile == nil {
+		return
+	}
+	for _, nic := range vmss.Properties.VirtualMachineProfile.NetworkProfile.NetworkInterfaceConfigurations {
+		if nic.Properties == nil || len(nic.Properties.IPConfigurations) == 0 {
+			continue
+		}
+		primary := nic.Properties.IPConfigurations[0]
+		nic.Properties.IPConfigurations = append(nic.Properties.IPConfigurations, &armcompute.VirtualMachineScaleSetIPConfiguration{
+			Name: to.Ptr(fmt.Sprintf("%s-ipv6", *primary.Name)),
+			Properties: &armcompute.VirtualMachineScaleSetIPConfigurationProperties{
+				PrivateIPAddressVersion: to.Ptr(armcompute.IPVersionIPv6),
+				Subnet:                  primary.Properties.Subnet,
+			},
+		})
+		return
+	}
+}
+
+// Validators
+
+// SpotPriorityValidator asserts that IMDS reports the VM as a spot priority VM which is deallocated upon eviction,
+// as configured by SpotVMSSMutator
+func SpotPriorityValidator() *LiveVMValidator {
+	return &LiveVMValidator{
+		Description: "assert IMDS reports spot priority",
+		Command:     imdsComputeMetadataCommand,
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("validator command terminated with exit code %q but expected code 0", code)
+			}
+			var compute struct {
+				Priority       string `json:"priority"`
+				EvictionPolicy string `json:"evictionPolicy"`
+			}
+			if err := json.Unmarshal([]byte(stdout), &compute); err != nil {
+				return fmt.Errorf("unable to parse IMDS compute metadata: %w", err)
+			}
+			if !strings.EqualFold(compute.Priority, string(armcompute.VirtualMachinePriorityTypesSpot)) {
+				return fmt.Errorf("expected IMDS to report priority %q, but it reported %q", armcompute.VirtualMachinePriorityTypesSpot, compute.Priority)
+			}
+			if !strings.EqualFold(compute.EvictionPolicy, string(armcompute.VirtualMachineEvictionPolicyTypesDeallocate)) {
+				return fmt.Errorf("expected IMDS to report eviction policy %q, but it reported %q", armcompute.VirtualMachineEvictionPolicyTypesDeallocate, compute.EvictionPolicy)
+			}
+			return nil
+		},
+	}
+}
+
+// Requirements
+
+// GPURequirements returns the requirements of scenarios running on the GPU VM size, which is commonly unavailable or lacking
+// quota in the suite's region
+func GPURequirements() Requirements {
+	return Requirements{
+		VMSizes:    []string{gpuVMSize},
+		VCPUQuotas: map[string]int32{gpuVMFamily: gpuVMSizeVCPUs},
+	}
+}
+
+// MIGRequirements returns the requirements of scenarios running on the MIG VM size, which has far less capacity than the GPU
+// VM size, and is available in fewer regions
+func MIGRequirements() Requirements {
+	return Requirements{
+		VMSizes:    []string{migVMSize},
+		VCPUQuotas: map[string]int32{migVMFamily: migVMSizeVCPUs},
+	}
+}
+
+// ARM64Requirements returns the requirements of scenarios running on the arm64 VM size, which isn't available in every region
+func ARM64Requirements() Requirements {
+	return Requirements{
+		VMSizes: []string{arm64VMSize},
+	}
+}
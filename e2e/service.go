@@ -0,0 +1,132 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/yaml"
+)
+
+// curls the URL bypassing any HTTP proxy the node was bootstrapped with, such that the request is only served through the
+// node's service networking, exiting non-zero unless it's served successfully
+const serviceConnectivityCommandTemplate = "curl --noproxy '*' --silent --show-error --fail --max-time 10 --output /dev/null %s"
+
+// Validates the service networking bootstrap configures for kube-proxy on the node, by exposing pods pinned to the node through
+// a NodePort service, which the node is expected to reach through its cluster IP, and which the jumpbox debug pod, running on
+// another of the cluster's nodes, is expected to reach through the node's own IP and the service's node port
+func validateServiceConnectivity(ctx context.Context, vmssName, vmPrivateIP, sshPrivateKey, nodeName string, opts *scenarioRunOpts) error {
+	kube := opts.clusterConfig.kube
+
+	deploymentName, err := ensureSmokeTestDeployment(ctx, kube, opts.namespace, nodeName)
+	if err != nil {
+		return fmt.Errorf("failed to ensure service connectivity backend: %w", err)
+	}
+	defer func() {
+		if err := deleteSmokeTestDeployment(ctx, kube, opts.namespace, deploymentName); err != nil {
+			log.Printf("unable to delete service connectivity backend: %s", err)
+		}
+	}()
+
+	service, err := ensureSmokeTestService(ctx, kube, opts.namespace, nodeName)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := deleteSmokeTestService(ctx, kube, service.Namespace, service.Name); err != nil {
+			log.Printf("unable to delete service connectivity service: %s", err)
+		}
+	}()
+	if service.Spec.ClusterIP == "" || len(service.Spec.Ports) == 0 || service.Spec.Ports[0].NodePort == 0 {
+		return fmt.Errorf("expected service %s/%s to be assigned a cluster IP and node port, but it had %q and %v", service.Namespace, service.Name, service.Spec.ClusterIP, service.Spec.Ports)
+	}
+	port := service.Spec.Ports[0]
+
+	jumpboxPodName, err := getDebugPodName(kube)
+	if err != nil {
+		return fmt.Errorf("unable to get debug pod name: %w", err)
+	}
+
+	clusterIPURL := fmt.Sprintf("http://%s/", net.JoinHostPort(service.Spec.ClusterIP, strconv.Itoa(int(port.Port))))
+	log.Printf("validating node %q reaches service %s/%s through its cluster IP at %s...", nodeName, service.Namespace, service.Name, clusterIPURL)
+	if err := pollServiceConnectivity(ctx, clusterIPURL, func(ctx context.Context, command string) (*podExecResult, error) {
+		return execOnVMWithRunCommandFallback(ctx, vmssName, vmPrivateIP, jumpboxPodName, sshPrivateKey, command, false, opts)
+	}); err != nil {
+		return fmt.Errorf("node %q failed to reach service %s/%s through its cluster IP: %w", nodeName, service.Namespace, service.Name, err)
+	}
+
+	nodePortURL := fmt.Sprintf("http://%s/", net.JoinHostPort(vmPrivateIP, strconv.Itoa(int(port.NodePort))))
+	log.Printf("validating service %s/%s is reachable through node %q at %s...", service.Namespace, service.Name, nodeName, nodePortURL)
+	if err := pollServiceConnectivity(ctx, nodePortURL, func(ctx context.Context, command string) (*podExecResult, error) {
+		return execOnPrivilegedPod(ctx, kube, defaultNamespace, jumpboxPodName, command)
+	}); err != nil {
+		return fmt.Errorf("service %s/%s was unreachable through node %q's node port: %w", service.Namespace, service.Name, nodeName, err)
+	}
+
+	return nil
+}
+
+// Requests the URL using the exec func until the request succeeds, as kube-proxy only serves the service once it has
+// observed its endpoints
+func pollServiceConnectivity(ctx context.Context, url string, exec func(context.Context, string) (*podExecResult, error)) error {
+	var lastErr error
+	err := wait.PollImmediateWithContext(ctx, validateServiceConnectivityPollInterval, validateServiceConnectivityPollingTimeout, func(ctx context.Context) (bool, error) {
+		res, err := exec(ctx, fmt.Sprintf(serviceConnectivityCommandTemplate, url))
+		if err != nil {
+			lastErr = err
+			log.Printf("unable to request %s: %s", url, err)
+			return false, nil
+		}
+		if res.exitCode != "0" {
+			lastErr = fmt.Errorf("request terminated with exit code %q, stderr: %q", res.exitCode, res.stderr.String())
+			log.Printf("request to %s failed, will retry: %s", url, lastErr)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil && lastErr != nil {
+		return fmt.Errorf("%w, last error: %s", err, lastErr)
+	}
+	return err
+}
+
+// Exposes the smoke test deployment pinned to the node through a NodePort service, returning the service once it has been
+// assigned its cluster IP and node port
+func ensureSmokeTestService(ctx context.Context, kube *kubeclient, namespace, nodeName string) (*corev1.Service, error) {
+	var service corev1.Service
+	if err := yaml.Unmarshal([]byte(getSmokeTestServiceTemplate(namespace, nodeName)), &service); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal smoke test service manifest: %w", err)
+	}
+
+	desired := service.DeepCopy()
+	if _, err := controllerutil.CreateOrUpdate(ctx, kube.dynamic, &service, func() error {
+		// the cluster IP and node port are allocated by the apiserver, so are retained across updates
+		service.Spec.Type = desired.Spec.Type
+		service.Spec.Selector = desired.Spec.Selector
+		for i := range desired.Spec.Ports {
+			if i < len(service.Spec.Ports) {
+				desired.Spec.Ports[i].NodePort = service.Spec.Ports[i].NodePort
+			}
+		}
+		service.Spec.Ports = desired.Spec.Ports
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to apply smoke test service: %w", err)
+	}
+	return &service, nil
+}
+
+func deleteSmokeTestService(ctx context.Context, kube *kubeclient, namespace, name string) error {
+	err := kube.typed.CoreV1().Services(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete smoke test service %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
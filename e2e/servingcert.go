@@ -0,0 +1,123 @@
+package e2e_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"strings"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// kubelet serving CSRs aren't approved by kube-controller-manager, so the suite approves them as AKS would
+	kubeletServingCSRApprovalReason = "AgentBakerE2EApprove"
+
+	// username prefix of requests authenticated with a node's client certificate
+	nodeUserPrefix = "system:node:"
+)
+
+// Validates that the node's kubelet requested its serving certificate through a CSR, approving the CSR if it hasn't already
+// been approved, and waiting for its certificate to be issued. Returns the SHA-256 fingerprint of the issued certificate,
+// formatted as colon-separated hex bytes, such that it can be compared against the certificate kubelet serves with
+func validateKubeletServingCertRotation(ctx context.Context, kube *kubeclient, nodeName string) (string, error) {
+	var certificate []byte
+	err := wait.PollImmediateWithContext(ctx, waitUntilKubeletServingCertIssuedPollInterval, waitUntilKubeletServingCertIssuedPollingTimeout, func(ctx context.Context) (bool, error) {
+		csr, err := getKubeletServingCSR(ctx, kube, nodeName)
+		if err != nil {
+			return false, err
+		}
+		if csr == nil {
+			log.Printf("waiting for kubelet of node %q to create its serving CSR...", nodeName)
+			return false, nil
+		}
+		if len(csr.Status.Certificate) > 0 {
+			certificate = csr.Status.Certificate
+			return true, nil
+		}
+		if hasCSRCondition(csr, certificatesv1.CertificateDenied) || hasCSRCondition(csr, certificatesv1.CertificateFailed) {
+			return false, fmt.Errorf("kubelet serving CSR %q of node %q was denied or failed to be issued: %v", csr.Name, nodeName, csr.Status.Conditions)
+		}
+		if !hasCSRCondition(csr, certificatesv1.CertificateApproved) {
+			log.Printf("approving kubelet serving CSR %q of node %q", csr.Name, nodeName)
+			csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+				Type:    certificatesv1.CertificateApproved,
+				Status:  corev1.ConditionTrue,
+				Reason:  kubeletServingCSRApprovalReason,
+				Message: "approved by the AgentBaker e2e suite",
+			})
+			if _, err := kube.typed.CertificatesV1().CertificateSigningRequests().UpdateApproval(ctx, csr.Name, csr, metav1.UpdateOptions{}); err != nil {
+				return false, fmt.Errorf("failed to approve kubelet serving CSR %q: %w", csr.Name, err)
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed waiting for the kubelet serving certificate of node %q to be issued: %w", nodeName, err)
+	}
+
+	block, _ := pem.Decode(certificate)
+	if block == nil {
+		return "", fmt.Errorf("kubelet serving certificate of node %q is not PEM-encoded", nodeName)
+	}
+	if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+		return "", fmt.Errorf("failed to parse kubelet serving certificate of node %q: %w", nodeName, err)
+	}
+	return formatFingerprint(sha256.Sum256(block.Bytes)), nil
+}
+
+// Validates that the node's kubelet didn't request a serving certificate, as expected when serving certificate rotation
+// is disabled
+func validateNoKubeletServingCSR(ctx context.Context, kube *kubeclient, nodeName string) error {
+	csr, err := getKubeletServingCSR(ctx, kube, nodeName)
+	if err != nil {
+		return err
+	}
+	if csr != nil {
+		return fmt.Errorf("expected kubelet of node %q not to request a serving certificate, but found CSR %q", nodeName, csr.Name)
+	}
+	return nil
+}
+
+// Returns the latest serving CSR requested by the node's kubelet, or nil if it has yet to request one
+func getKubeletServingCSR(ctx context.Context, kube *kubeclient, nodeName string) (*certificatesv1.CertificateSigningRequest, error) {
+	csrs, err := kube.typed.CertificatesV1().CertificateSigningRequests().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list certificate signing requests: %w", err)
+	}
+	var latest *certificatesv1.CertificateSigningRequest
+	for i := range csrs.Items {
+		csr := &csrs.Items[i]
+		if csr.Spec.SignerName != certificatesv1.KubeletServingSignerName || csr.Spec.Username != nodeUserPrefix+nodeName {
+			continue
+		}
+		if latest == nil || latest.CreationTimestamp.Before(&csr.CreationTimestamp) {
+			latest = csr
+		}
+	}
+	return latest, nil
+}
+
+func hasCSRCondition(csr *certificatesv1.CertificateSigningRequest, conditionType certificatesv1.RequestConditionType) bool {
+	for _, condition := range csr.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// Formats a fingerprint as colon-separated, uppercase hex bytes, as printed by openssl
+func formatFingerprint(fingerprint [sha256.Size]byte) string {
+	bytes := make([]string, 0, len(fingerprint))
+	for _, b := range fingerprint {
+		bytes = append(bytes, fmt.Sprintf("%02X", b))
+	}
+	return strings.Join(bytes, ":")
+}
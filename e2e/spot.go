@@ -0,0 +1,123 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	deallocatedPowerStateCode = "PowerState/deallocated"
+)
+
+// Taints applied to nodes whose VM has shut down or stopped reporting status, either by the node lifecycle controller or the cloud provider
+var unavailableNodeTaintKeys = map[string]bool{
+	corev1.TaintNodeUnreachable:                 true,
+	corev1.TaintNodeNotReady:                    true,
+	"node.cloudprovider.kubernetes.io/shutdown": true,
+}
+
+// Returns true if the specified VMSS uses spot priority VMs and its VM, or that of opts.instance when set, has since
+// been evicted, either by being deallocated or deleted depending on the VMSS's eviction policy
+func isSpotVMSSEvicted(ctx context.Context, vmssName string, opts *scenarioRunOpts) (bool, error) {
+	mcResourceGroupName := *opts.clusterConfig.cluster.Properties.NodeResourceGroup
+
+	vmssResp, err := opts.cloud.vmssClient.Get(ctx, mcResourceGroupName, vmssName, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to get vmss %q: %w", vmssName, err)
+	}
+	if !isSpotVMSS(&vmssResp.VirtualMachineScaleSet) {
+		return false, nil
+	}
+
+	// before the run has been scoped to a particular instance, any of the VMSS's instances having been evicted is
+	// enough to explain a failure
+	instanceIDs := []string{opts.instance.instanceID}
+	if opts.instance.instanceID == "" {
+		instances, err := listVMSSInstances(ctx, vmssName, opts)
+		if err != nil {
+			return false, err
+		}
+		if len(instances) == 0 {
+			return true, nil
+		}
+		instanceIDs = instanceIDs[:0]
+		for _, instance := range instances {
+			instanceIDs = append(instanceIDs, instance.instanceID)
+		}
+	}
+
+	for _, instanceID := range instanceIDs {
+		evicted, err := isSpotInstanceEvicted(ctx, mcResourceGroupName, vmssName, instanceID, opts)
+		if err != nil || evicted {
+			return evicted, err
+		}
+	}
+
+	return false, nil
+}
+
+func isSpotInstanceEvicted(ctx context.Context, mcResourceGroupName, vmssName, instanceID string, opts *scenarioRunOpts) (bool, error) {
+	instanceView, err := opts.cloud.vmssVMClient.GetInstanceView(ctx, mcResourceGroupName, vmssName, instanceID, nil)
+	if err != nil {
+		// VMs of spot VMSSes using the "Delete" eviction policy will no longer exist after eviction
+		if isNotFoundError(err) || isResourceNotFoundError(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to get instance view of instance %q of vmss %q: %w", instanceID, vmssName, err)
+	}
+
+	for _, status := range instanceView.Statuses {
+		if status != nil && status.Code != nil && strings.EqualFold(*status.Code, deallocatedPowerStateCode) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func isSpotVMSS(vmss *armcompute.VirtualMachineScaleSet) bool {
+	if vmss == nil || vmss.Properties == nil || vmss.Properties.VirtualMachineProfile == nil {
+		return false
+	}
+	priority := vmss.Properties.VirtualMachineProfile.Priority
+	return priority != nil && *priority == armcompute.VirtualMachinePriorityTypesSpot
+}
+
+// Skips the current test if the scenario's spot VM was evicted during the run, since the resulting failure
+// isn't indicative of a node bootstrapping issue. Otherwise, this is a no-op.
+func skipIfSpotVMSSEvicted(ctx context.Context, t *testing.T, vmssName string, opts *scenarioRunOpts) {
+	evicted, err := isSpotVMSSEvicted(ctx, vmssName, opts)
+	if err != nil {
+		log.Printf("unable to determine whether vmss %q was evicted: %s", vmssName, err)
+		return
+	}
+	if evicted {
+		t.Skipf("spot vm of vmss %q was evicted during the run, skipping scenario %q", vmssName, opts.scenario.Name)
+	}
+}
+
+// Simulates the eviction of the scenario's spot VM, then waits for the VM to be evicted and its node to be marked as unavailable
+func simulateSpotEviction(ctx context.Context, vmssName, nodeName string, opts *scenarioRunOpts) error {
+	mcResourceGroupName := *opts.clusterConfig.cluster.Properties.NodeResourceGroup
+
+	log.Printf("simulating eviction of instance %q of vmss %q", opts.instance.instanceID, vmssName)
+	if _, err := opts.cloud.vmssVMClient.SimulateEviction(ctx, mcResourceGroupName, vmssName, opts.instance.instanceID, nil); err != nil {
+		return fmt.Errorf("failed to simulate eviction of instance %q of vmss %q: %w", opts.instance.instanceID, vmssName, err)
+	}
+
+	if err := waitUntilSpotVMEvicted(ctx, vmssName, opts); err != nil {
+		return fmt.Errorf("failed to wait for instance %q of vmss %q to be evicted: %w", opts.instance.instanceID, vmssName, err)
+	}
+
+	if err := waitUntilNodeUnavailable(ctx, opts.clusterConfig.kube, nodeName); err != nil {
+		return fmt.Errorf("failed to wait for node %q to become unavailable after eviction: %w", nodeName, err)
+	}
+
+	return nil
+}
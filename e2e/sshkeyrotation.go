@@ -0,0 +1,108 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+)
+
+const (
+	vmAccessExtensionName      = "vmssVMAccess"
+	vmAccessExtensionPublisher = "Microsoft.OSTCExtensions"
+	vmAccessExtensionType      = "VMAccessForLinux"
+	vmAccessExtensionVersion   = "1.5"
+
+	// exit code returned by ssh when it's unable to connect or authenticate
+	sshFailureExitCode = "255"
+)
+
+// Rotates the SSH public key of every instance of the specified VMSS, replacing any prior keys, by adding the VMAccess
+// extension to the VMSS model and upgrading each instance to it
+func rotateVMSSSSHKey(ctx context.Context, vmssName string, instances []vmssInstance, publicKeyBytes []byte, opts *scenarioRunOpts) error {
+	mcResourceGroupName := *opts.clusterConfig.cluster.Properties.NodeResourceGroup
+
+	extension := armcompute.VirtualMachineScaleSetExtension{
+		Properties: &armcompute.VirtualMachineScaleSetExtensionProperties{
+			Publisher:               to.Ptr(vmAccessExtensionPublisher),
+			Type:                    to.Ptr(vmAccessExtensionType),
+			TypeHandlerVersion:      to.Ptr(vmAccessExtensionVersion),
+			AutoUpgradeMinorVersion: to.Ptr(true),
+			ProtectedSettings: map[string]interface{}{
+				"username":          "azureuser",
+				"ssh_key":           string(publicKeyBytes),
+				"remove_prior_keys": true,
+			},
+		},
+	}
+
+	log.Printf("rotating ssh key of vmss %q", vmssName)
+	extensionPoller, err := opts.cloud.vmssExtensionClient.BeginCreateOrUpdate(ctx, mcResourceGroupName, vmssName, vmAccessExtensionName, extension, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin adding extension %q to vmss %q: %w", vmAccessExtensionName, vmssName, err)
+	}
+	if _, err := extensionPoller.PollUntilDone(ctx, nil); err != nil {
+		return fmt.Errorf("failed to add extension %q to vmss %q: %w", vmAccessExtensionName, vmssName, err)
+	}
+
+	// the VMSS uses a manual upgrade policy, so its instances won't pick up the new extension until explicitly upgraded
+	instanceIDs := make([]*string, 0, len(instances))
+	for _, instance := range instances {
+		instanceIDs = append(instanceIDs, to.Ptr(instance.instanceID))
+	}
+	upgradePoller, err := opts.cloud.vmssClient.BeginUpdateInstances(ctx, mcResourceGroupName, vmssName, armcompute.VirtualMachineScaleSetVMInstanceRequiredIDs{
+		InstanceIDs: instanceIDs,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin upgrading instances of vmss %q: %w", vmssName, err)
+	}
+	if _, err := upgradePoller.PollUntilDone(ctx, nil); err != nil {
+		return fmt.Errorf("failed to upgrade instances of vmss %q: %w", vmssName, err)
+	}
+
+	return nil
+}
+
+// Rotates the SSH key of the specified VMSS, then validates that each of its nodes remain healthy, can be reached
+// using the new key, and can no longer be reached using the old one
+func validateSSHKeyRotation(ctx context.Context, vmssName string, instances []vmssInstance, oldPrivateKeyBytes, newPrivateKeyBytes, newPublicKeyBytes []byte, opts *scenarioRunOpts) error {
+	if err := rotateVMSSSSHKey(ctx, vmssName, instances, newPublicKeyBytes, opts); err != nil {
+		return err
+	}
+
+	podName, err := getDebugPodName(opts.clusterConfig.kube)
+	if err != nil {
+		return fmt.Errorf("unable to get debug pod name: %w", err)
+	}
+
+	for _, instance := range instances {
+		if _, err := validateNodeHealth(ctx, opts.clusterConfig.kube, opts.namespace, instance.nodeName()); err != nil {
+			return fmt.Errorf("node of instance %q is unhealthy after ssh key rotation: %w", instance.instanceID, err)
+		}
+
+		vmPrivateIP, err := getVMPrivateIPAddress(ctx, opts.cloud, opts.suiteConfig.subscription, *opts.clusterConfig.cluster.Properties.NodeResourceGroup, vmssName, instance.instanceID)
+		if err != nil {
+			return fmt.Errorf("failed to get private IP of instance %q: %w", instance.instanceID, err)
+		}
+
+		res, err := pollExecOnVM(ctx, opts.clusterConfig.kube, vmPrivateIP, podName, string(newPrivateKeyBytes), "hostname", false)
+		if err != nil {
+			return fmt.Errorf("unable to reach instance %q using the rotated ssh key: %w", instance.instanceID, err)
+		}
+		if res.exitCode != "0" {
+			return fmt.Errorf("command executed on instance %q using the rotated ssh key terminated with exit code %q", instance.instanceID, res.exitCode)
+		}
+
+		res, err = execOnVM(ctx, opts.clusterConfig.kube, vmPrivateIP, podName, string(oldPrivateKeyBytes), "hostname", false)
+		if err != nil {
+			return fmt.Errorf("unable to attempt reaching instance %q using the prior ssh key: %w", instance.instanceID, err)
+		}
+		if res.exitCode != sshFailureExitCode {
+			return fmt.Errorf("expected the prior ssh key to be rejected by instance %q, but command terminated with exit code %q", instance.instanceID, res.exitCode)
+		}
+	}
+
+	return nil
+}
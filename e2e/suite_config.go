@@ -3,15 +3,47 @@ package e2e_test
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Azure/agentbakere2e/scenario"
+)
+
+const (
+	defaultBuildID = "local"
+	defaultOwner   = "agentbaker-e2e"
 )
 
 type suiteConfig struct {
-	subscription       string
-	location           string
-	resourceGroupName  string
-	scenariosToRun     map[string]bool
-	scenariosToExclude map[string]bool
-	keepVMSS           bool
+	subscription          string
+	location              string
+	resourceGroupName     string
+	scenariosToRun        map[string]bool
+	scenariosToExclude    map[string]bool
+	keepVMSS              bool
+	mintBootstrapTokens   bool
+	scenarioFilter        *regexp.Regexp
+	includeTags           map[string]bool
+	excludeTags           map[string]bool
+	definitionsDir        string
+	artifactsDir          string
+	artifactsContainerURL string
+	nodeImageVersion      string
+	vhdResourceID         string
+	buildID               string
+	owner                 string
+	seed                  int64
+	debugDaemonset        debugDaemonsetConfig
+	kubeconfigCacheDir    string
+	networkPerfProbe      bool
+	networkPerfImage      string
+	httpProxyImage        string
+	podSecurityLabels     bool
+	staleObjectMaxAge     time.Duration
+	cloudEnvironment      cloudEnvironment
+	federatedCredential   *federatedCredentialConfig
+	credentialChain       []string
 }
 
 func newSuiteConfig() (*suiteConfig, error) {
@@ -29,10 +61,30 @@ func newSuiteConfig() (*suiteConfig, error) {
 	}
 
 	config := &suiteConfig{
-		subscription:   environment["SUBSCRIPTION_ID"],
-		location:       environment["LOCATION"],
-		scenariosToRun: strToBoolMap(os.Getenv("SCENARIOS_TO_RUN")),
-		keepVMSS:       os.Getenv("KEEP_VMSS") == "true",
+		subscription:          environment["SUBSCRIPTION_ID"],
+		location:              environment["LOCATION"],
+		scenariosToRun:        strToBoolMap(os.Getenv("SCENARIOS_TO_RUN")),
+		keepVMSS:              os.Getenv("KEEP_VMSS") == "true",
+		mintBootstrapTokens:   os.Getenv("MINT_BOOTSTRAP_TOKENS") == "true",
+		includeTags:           strToBoolMap(includeTags),
+		excludeTags:           strToBoolMap(excludeTags),
+		definitionsDir:        scenarioDefinitionsDir,
+		artifactsDir:          artifactsDirFlag,
+		artifactsContainerURL: os.Getenv("ARTIFACTS_CONTAINER_URL"),
+		nodeImageVersion:      os.Getenv("NODE_IMAGE_VERSION"),
+		vhdResourceID:         os.Getenv("VHD_RESOURCE_ID"),
+		buildID:               getEnvWithDefault(defaultBuildID, "BUILD_ID", "BUILD_BUILDID"),
+		owner:                 getEnvWithDefault(defaultOwner, "E2E_OWNER", "USER"),
+		seed:                  seedFlag,
+		kubeconfigCacheDir:    os.Getenv("KUBECONFIG_CACHE_DIR"),
+		networkPerfProbe:      os.Getenv("NETWORK_PERF_PROBE") == "true",
+		networkPerfImage:      getEnvWithDefault(defaultNetworkPerfImage, "NETWORK_PERF_IMAGE"),
+		httpProxyImage:        getEnvWithDefault(defaultHTTPProxyImage, "HTTP_PROXY_IMAGE"),
+		podSecurityLabels:     os.Getenv("POD_SECURITY_LABELS") != "false",
+	}
+
+	if config.seed == 0 {
+		config.seed = time.Now().UnixNano()
 	}
 
 	include := os.Getenv("SCENARIOS_TO_RUN")
@@ -45,5 +97,92 @@ func newSuiteConfig() (*suiteConfig, error) {
 		config.scenariosToExclude = strToBoolMap(exclude)
 	}
 
+	if runScenarios != "" {
+		filter, err := compileScenarioFilter(runScenarios)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -run-scenarios value %q: %w", runScenarios, err)
+		}
+		config.scenarioFilter = filter
+	}
+
+	debugDaemonset, err := newDebugDaemonsetConfig()
+	if err != nil {
+		return nil, err
+	}
+	config.debugDaemonset = debugDaemonset
+
+	cloudEnvironment, err := newCloudEnvironment()
+	if err != nil {
+		return nil, fmt.Errorf("invalid AZURE_CLOUD: %w", err)
+	}
+	config.cloudEnvironment = cloudEnvironment
+
+	federatedCredential, err := newFederatedCredentialConfig()
+	if err != nil {
+		return nil, fmt.Errorf("invalid federated credential: %w", err)
+	}
+	config.federatedCredential = federatedCredential
+
+	credentialChain, err := parseCredentialChain(os.Getenv("AZURE_CREDENTIAL_CHAIN"), federatedCredential)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AZURE_CREDENTIAL_CHAIN: %w", err)
+	}
+	config.credentialChain = credentialChain
+
+	staleObjectMaxAge, err := parseStaleObjectMaxAge(os.Getenv("STALE_OBJECT_MAX_AGE"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid STALE_OBJECT_MAX_AGE: %w", err)
+	}
+	config.staleObjectMaxAge = staleObjectMaxAge
+
+	if config.vhdResourceID != "" && !isImageResourceID(config.vhdResourceID) {
+		return nil, fmt.Errorf("VHD_RESOURCE_ID %q is neither a SIG image version nor a managed image resource ID", config.vhdResourceID)
+	}
+
 	return config, nil
 }
+
+// Returns the value of the first of the specified environment variables which is set to a non-empty value,
+// or the supplied default value if none of them are set
+func getEnvWithDefault(defaultValue string, keys ...string) string {
+	for _, key := range keys {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+	return defaultValue
+}
+
+// Matches list elements made up only of characters found within scenario names, which are taken literally.
+var plainScenarioNameRegex = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// Compiles a comma-separated list of scenario names or regular expressions into a single regular expression which
+// only matches the names of scenarios matched in full by at least one of the list's elements. Scenario names may
+// contain dots, e.g. "ubuntu2204-k8s-1.25.6", so plain names are quoted to only ever match themselves, whereas any
+// element containing other regex metacharacters is taken as a regular expression.
+func compileScenarioFilter(expr string) (*regexp.Regexp, error) {
+	var patterns []string
+	for _, pattern := range strings.Split(expr, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			if plainScenarioNameRegex.MatchString(pattern) {
+				pattern = regexp.QuoteMeta(pattern)
+			}
+			patterns = append(patterns, fmt.Sprintf("(?:%s)", pattern))
+		}
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("no scenario names or patterns were specified")
+	}
+	return regexp.Compile(fmt.Sprintf("^(?:%s)$", strings.Join(patterns, "|")))
+}
+
+// Returns the selector determining which scenarios are run during the testing session
+func (c *suiteConfig) scenarioSelector() scenario.Selector {
+	return scenario.Selector{
+		Include:     c.scenariosToRun,
+		Exclude:     c.scenariosToExclude,
+		NamePattern: c.scenarioFilter,
+		IncludeTags: c.includeTags,
+		ExcludeTags: c.excludeTags,
+	}
+}
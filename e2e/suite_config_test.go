@@ -0,0 +1,82 @@
+package e2e_test
+
+import (
+	"testing"
+)
+
+func TestCompileScenarioFilter(t *testing.T) {
+	cases := []struct {
+		name       string
+		expr       string
+		matches    []string
+		nonMatches []string
+		expectErr  bool
+	}{
+		{
+			name:       "plain name",
+			expr:       "ubuntu2204",
+			matches:    []string{"ubuntu2204"},
+			nonMatches: []string{"ubuntu2204-gpu", "azurelinuxv2-ubuntu2204"},
+		},
+		{
+			name:       "plain name containing dots",
+			expr:       "ubuntu2204-k8s-1.25.6",
+			matches:    []string{"ubuntu2204-k8s-1.25.6"},
+			nonMatches: []string{"ubuntu2204-k8s-1x25x6"},
+		},
+		{
+			name:       "regular expression",
+			expr:       "azurelinuxv2-.*",
+			matches:    []string{"azurelinuxv2-gpu", "azurelinuxv2-"},
+			nonMatches: []string{"azurelinuxv2", "ubuntu2204-azurelinuxv2-gpu"},
+		},
+		{
+			name:       "regular expression alternation is matched in full",
+			expr:       "ubuntu2204|ubuntu1804",
+			matches:    []string{"ubuntu2204", "ubuntu1804"},
+			nonMatches: []string{"ubuntu2204-gpu", "ubuntu1804-gpu"},
+		},
+		{
+			name:       "several elements and surrounding whitespace",
+			expr:       " ubuntu2204 , azurelinuxv2-.* ,",
+			matches:    []string{"ubuntu2204", "azurelinuxv2-gpu"},
+			nonMatches: []string{"ubuntu2204-gpu", "ubuntu1804"},
+		},
+		{
+			name:      "no elements",
+			expr:      " , ",
+			expectErr: true,
+		},
+		{
+			name:      "invalid regular expression",
+			expr:      "ubuntu2204-(gpu",
+			expectErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			filter, err := compileScenarioFilter(c.expr)
+			if c.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error, but got filter %q", filter)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, but got: %s", err)
+			}
+			for _, name := range c.matches {
+				if !filter.MatchString(name) {
+					t.Errorf("expected %q to match %q", filter, name)
+				}
+			}
+			for _, name := range c.nonMatches {
+				if filter.MatchString(name) {
+					t.Errorf("expected %q not to match %q", filter, name)
+				}
+			}
+		})
+	}
+}
@@ -2,37 +2,54 @@ package e2e_test
 
 import (
 	"context"
+	"fmt"
 	"log"
 	mrand "math/rand"
-	"path/filepath"
+	"strings"
 	"testing"
-	"time"
 
 	"github.com/Azure/agentbaker/pkg/agent/datamodel"
 	"github.com/Azure/agentbakere2e/scenario"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
 	"github.com/barkimedes/go-deepcopy"
 )
 
 func Test_All(t *testing.T) {
-	r := mrand.New(mrand.NewSource(time.Now().UnixNano()))
 	ctx := context.Background()
 	t.Parallel()
 
+	// golden mode generates each scenario's bootstrap payloads without touching any Azure resources
+	if e2eMode == "golden" {
+		validateGoldenBootstrapPayloads(t)
+		return
+	}
+
 	suiteConfig, err := newSuiteConfig()
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if err := createE2ELoggingDir(); err != nil {
+	// randomness shared by the suite's setup, which runs in sequence, while each scenario derives a source of its own
+	log.Printf("seeding the suite's randomness with %d, which can be passed as -seed to reproduce this run's names", suiteConfig.seed)
+	r := mrand.New(mrand.NewSource(suiteConfig.seed))
+
+	if err := createDirIfNeeded(suiteConfig.artifactsDir); err != nil {
 		t.Fatal(err)
 	}
 
-	scenarios := scenario.InitScenarioTable(suiteConfig.scenariosToRun, suiteConfig.scenariosToExclude)
+	scenarios, err := scenario.InitScenarioTable(suiteConfig.scenarioSelector(), suiteConfig.definitionsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if len(scenarios) < 1 {
 		t.Fatal("at least one scenario must be selected to run the e2e suite")
 	}
 
-	cloud, err := newAzureClient(suiteConfig.subscription)
+	credential, err := newSuiteCredential(suiteConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cloud, err := newAzureClient(suiteConfig.subscription, suiteConfig.kubeconfigCacheDir, suiteConfig.cloudEnvironment, credential)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -41,6 +58,13 @@ func Test_All(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// a pinned node image version overrides the version of every image, so there's no need to resolve the latest ones
+	if suiteConfig.nodeImageVersion == "" {
+		resolveLatestImageVersions(ctx, cloud, suiteConfig.location)
+	}
+
+	capabilities := newCapabilityProbe(cloud, suiteConfig)
+
 	clusterConfigs, err := getInitialClusterConfigs(ctx, cloud, suiteConfig.resourceGroupName)
 	if err != nil {
 		t.Fatal(err)
@@ -50,7 +74,8 @@ func Test_All(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	for _, scenario := range scenarios {
+	// scenarios depending on another are run by the scenario they depend on, once it has been validated
+	for _, scenario := range scenarios.Roots() {
 		scenario := scenario
 
 		clusterConfig, err := chooseCluster(ctx, r, cloud, suiteConfig, scenario, clusterConfigs)
@@ -66,122 +91,581 @@ func Test_All(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		copied, err := deepcopy.Anything(baseConfig)
+		nbc, err := newScenarioBootstrapConfig(baseConfig, scenario)
 		if err != nil {
 			t.Error(err)
 			continue
 		}
-		nbc := copied.(*datamodel.NodeBootstrappingConfiguration)
-
-		if scenario.Config.BootstrapConfigMutator != nil {
-			scenario.Config.BootstrapConfigMutator(nbc)
-		}
 
 		t.Run(scenario.Name, func(t *testing.T) {
 			t.Parallel()
 
-			caseLogsDir, err := createVMLogsDir(scenario.Name)
-			if err != nil {
-				t.Fatal(err)
-			}
+			// dependents run within this scenario's use of the cluster, so don't acquire it themselves, while the cluster is
+			// only released once any parallel subtests of the scenario's parameters have also finished
+			t.Cleanup(clusterConfig.acquire(scenario))
 
-			opts := &scenarioRunOpts{
+			runScenarioTest(ctx, t, &scenarioRunOpts{
 				clusterConfig: clusterConfig,
 				cloud:         cloud,
 				suiteConfig:   suiteConfig,
 				scenario:      scenario,
+				scenarios:     scenarios,
 				nbc:           nbc,
-				loggingDir:    caseLogsDir,
-			}
+				capabilities:  capabilities,
+			})
+		})
+	}
+}
 
-			runScenario(ctx, t, r, opts)
+// Runs the scenario within its subtest, writing its artifacts and result to its own artifacts directory
+func runScenarioTest(ctx context.Context, t *testing.T, opts *scenarioRunOpts) {
+	if len(opts.scenario.Parameters) > 0 {
+		runParameterizedScenario(ctx, t, opts)
+		return
+	}
+
+	artifacts, err := newScenarioArtifactsDir(opts.suiteConfig, opts.scenario.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// registered before the scenario's other cleanups such that it runs last, once every artifact has been written
+	t.Cleanup(func() {
+		if err := artifacts.upload(context.Background(), opts.cloud); err != nil {
+			t.Errorf("failed to upload artifacts of scenario %q: %s", opts.scenario.Name, err)
+		}
+		if t.Failed() {
+			t.Logf("artifacts of failed scenario %q can be found at %s", opts.scenario.Name, artifacts.location())
+			t.Logf("scenario %q: %s", opts.scenario.Name, opts.scenario.Description)
+		}
+	})
+
+	opts.artifacts = artifacts
+	opts.result = recordScenarioResult(t, opts)
+
+	timeout := opts.scenario.EffectiveTimeout()
+	log.Printf("running scenario %q with a timeout of %s", opts.scenario.Name, timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	runScenario(ctx, t, newScenarioRand(opts.suiteConfig.seed, opts.scenario.Name), opts)
+}
+
+// Runs each of the parameters of the scenario of opts within a parallel subtest of its own, each with its own VMSS, artifacts
+// directory and result. The cluster and bootstrap config of the scenario are shared by its parameters, each of which further
+// mutates a copy of the bootstrap config
+func runParameterizedScenario(ctx context.Context, t *testing.T, opts *scenarioRunOpts) {
+	for _, parameter := range opts.scenario.Parameters {
+		parameter := parameter
+
+		copied, err := deepcopy.Anything(opts.nbc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nbc := copied.(*datamodel.NodeBootstrappingConfiguration)
+		if parameter.BootstrapConfigMutator != nil {
+			parameter.BootstrapConfigMutator(nbc)
+		}
+
+		t.Run(parameter.Name, func(t *testing.T) {
+			t.Parallel()
+
+			runScenarioTest(ctx, t, &scenarioRunOpts{
+				clusterConfig: opts.clusterConfig,
+				cloud:         opts.cloud,
+				suiteConfig:   opts.suiteConfig,
+				scenario:      opts.scenario.WithParameter(parameter),
+				scenarios:     opts.scenarios,
+				nbc:           nbc,
+				capabilities:  opts.capabilities,
+			})
 		})
 	}
 }
 
 func runScenario(ctx context.Context, t *testing.T, r *mrand.Rand, opts *scenarioRunOpts) {
-	privateKeyBytes, publicKeyBytes, err := getNewRSAKeyPair(r)
+	privateKeyBytes, publicKeyBytes, err := getNewRSAKeyPair()
 	if err != nil {
 		t.Error(err)
 		return
 	}
 
-	vmssName := getVmssName(r)
-	log.Printf("vmss name: %q", vmssName)
+	unmet, err := opts.capabilities.unmetRequirements(ctx, opts.scenario.EffectiveRequirements())
+	if err != nil {
+		t.Fatalf("unable to determine whether the requirements of scenario %q are met: %s", opts.scenario.Name, err)
+	}
+	if len(unmet) > 0 {
+		t.Skipf("skipping scenario %q, as its requirements aren't met in region %q: %s", opts.scenario.Name, opts.suiteConfig.location, strings.Join(unmet, "; "))
+	}
+
+	namespace, deleteNamespace, err := createScenarioNamespace(ctx, opts.clusterConfig.kube, r, opts.suiteConfig, opts.scenario.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.namespace = namespace
+	// the pods within the namespace are retained along with the VMSS for debugging
+	if !opts.suiteConfig.keepVMSS {
+		defer deleteNamespace()
+	}
+
+	if opts.scenario.KubeletIdentity {
+		identity, err := ensureKubeletIdentity(ctx, opts.cloud, opts.suiteConfig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts.kubeletIdentity = identity
+		useKubeletIdentity(opts.nbc, identity)
+	}
+
+	if opts.scenario.PrivateACR {
+		if opts.kubeletIdentity == nil {
+			t.Fatalf("scenario %q pulls from the private ACR, so must be bootstrapped with the kubelet identity", opts.scenario.Name)
+		}
+		acr, err := ensurePrivateACR(ctx, opts.cloud, opts.suiteConfig, opts.clusterConfig, opts.kubeletIdentity)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts.privateACR = acr
+	}
+
+	if opts.scenario.HTTPProxy {
+		proxyURL, err := ensureHTTPProxy(ctx, opts.clusterConfig.kube, opts.suiteConfig.httpProxyImage)
+		if err != nil {
+			t.Fatal(err)
+		}
+		useHTTPProxy(opts.nbc, proxyURL)
+	}
+
+	if opts.suiteConfig.mintBootstrapTokens {
+		token, cleanupToken, err := mintBootstrapToken(ctx, r, opts.clusterConfig.kube, *opts.nbc.KubeletClientTLSBootstrapToken, fmt.Sprintf("agentbaker e2e bootstrap token for scenario %s", opts.scenario.Name))
+		if err != nil {
+			t.Fatalf("failed to mint bootstrap token: %s", err)
+		}
+		defer cleanupToken()
+		opts.nbc.KubeletClientTLSBootstrapToken = &token
+	}
+
+	var (
+		vmssName    string
+		vmssModel   *armcompute.VirtualMachineScaleSet
+		cleanupVMSS func()
+	)
+	// registered before the VMSS's deferred deletion, such that it runs once the VMSS has been deleted
+	if opts.scenario.Cleanup != nil && !opts.suiteConfig.keepVMSS {
+		defer func() {
+			runScenarioCleanup(t, vmssName, opts)
+		}()
+	}
+	maxAttempts := opts.scenario.EffectiveMaxAttempts()
+	for attempt := 1; ; attempt++ {
+		vmssName = getVmssName(r)
+		log.Printf("vmss name: %q (attempt %d of %d)", vmssName, attempt, maxAttempts)
+
+		vmssModel, cleanupVMSS, err = bootstrapVMSS(ctx, t, r, vmssName, opts, publicKeyBytes)
+		opts.result.recordAttempt(vmssName, err)
+		if err == nil || attempt >= maxAttempts || !isTransientError(err) {
+			break
+		}
+
+		log.Printf("attempt %d of scenario %q failed with a transient error, will retry with a new vmss: %s", attempt, opts.scenario.Name, err)
+		if cleanupVMSS != nil {
+			cleanupVMSS()
+		}
+	}
 
 	vmssSucceeded := true
-	vmssModel, cleanupVMSS, err := bootstrapVMSS(ctx, t, r, vmssName, opts, publicKeyBytes)
 	if !opts.suiteConfig.keepVMSS && cleanupVMSS != nil {
 		defer cleanupVMSS()
 	}
+
+	if opts.scenario.ExpectedFailure != nil {
+		if err := validateExpectedFailure(err, opts.scenario.ExpectedFailure); err != nil {
+			t.Fatalf("negative scenario validation failed: %s", err)
+		}
+		extractExpectedFailureLogs(ctx, vmssName, privateKeyBytes, opts)
+		return
+	}
+
 	if err != nil {
 		vmssSucceeded = false
 		if !isVMExtensionProvisioningError(err) {
+			skipIfSpotVMSSEvicted(ctx, t, vmssName, opts)
 			t.Fatalf("encountered an unknown error while creating VM: %s", err)
 		}
 		log.Println("vm was unable to be provisioned due to a CSE error, will still atempt to extract provisioning logs...")
 	}
 
 	if vmssModel != nil {
-		if err := writeToFile(filepath.Join(opts.loggingDir, "vmssId.txt"), *vmssModel.ID); err != nil {
-			t.Fatalf("failed to write vmss resource ID to disk: %s", err)
+		if _, err := opts.artifacts.writeFile("vmssId.txt", *vmssModel.ID); err != nil {
+			t.Fatalf("failed to write vmss resource ID: %s", err)
 		}
 	} else {
 		log.Printf("WARNING: bootstrapped vmss model was nil for %s", vmssName)
 	}
 
+	instances, err := listVMSSInstances(ctx, vmssName, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(instances) == 0 {
+		t.Fatalf("vmss %q has no instances to validate", vmssName)
+	}
+
+	if len(instances) == 1 {
+		runScenarioOnInstance(ctx, t, vmssName, vmssSucceeded, privateKeyBytes, opts.forInstance(instances[0], opts.artifacts))
+	} else {
+		// each instance is validated within its own subtest such that the results of every instance are reported,
+		// rather than only those of the first instance to fail
+		for _, instance := range instances {
+			instance := instance
+			t.Run(fmt.Sprintf(instanceLogsDirTemplate, instance.instanceID), func(t *testing.T) {
+				instanceArtifacts, err := opts.artifacts.instance(instance.instanceID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				runScenarioOnInstance(ctx, t, vmssName, vmssSucceeded, privateKeyBytes, opts.forInstance(instance, instanceArtifacts))
+			})
+		}
+	}
+
+	if opts.scenario.RotateSSHKey && !t.Failed() {
+		newPrivateKeyBytes, newPublicKeyBytes, err := getNewRSAKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := validateSSHKeyRotation(ctx, vmssName, instances, privateKeyBytes, newPrivateKeyBytes, newPublicKeyBytes, opts); err != nil {
+			t.Fatalf("ssh key rotation validation failed: %s", err)
+		}
+		log.Println("nodes remained healthy and reachable after ssh key rotation")
+		privateKeyBytes = newPrivateKeyBytes
+	}
+
+	// dependents run before the deferred cleanup of the VMSS, such that they're able to use it
+	if dependents := opts.scenarios.Dependents(opts.scenario.Name); len(dependents) > 0 {
+		if t.Failed() {
+			log.Printf("not running the scenarios depending on scenario %q, as it failed", opts.scenario.Name)
+		} else {
+			runDependentScenarios(t, vmssName, instances, dependents, opts)
+		}
+	}
+
+	if opts.suiteConfig.keepVMSS {
+		log.Printf("vmss %q will be retained for debugging purposes, please make sure to manually delete it later", vmssName)
+		if vmssModel != nil {
+			log.Printf("retained vmss resource ID: %q", *vmssModel.ID)
+		} else {
+			log.Printf("WARNING: model of retained vmss %q is nil", vmssName)
+		}
+		if _, err := opts.artifacts.writeFile(sshKeyArtifactName, string(privateKeyBytes)); err != nil {
+			t.Fatalf("failed to write retained vmss %q private ssh key: %s", vmssName, err)
+		}
+	}
+}
+
+// Runs the scenario's cleanup once its validation has finished, logging rather than reporting any error, such that the
+// scenario's outcome is determined by its validation alone
+func runScenarioCleanup(t *testing.T, vmssName string, opts *scenarioRunOpts) {
+	// the scenario's context may have already timed out, though its resources still need to be deleted
+	ctx, cancel := context.WithTimeout(context.Background(), scenarioCleanupTimeout)
+	defer cancel()
+
+	log.Printf("cleaning up the resources of scenario %q", opts.scenario.Name)
+	err := opts.scenario.Cleanup(ctx, &scenario.CleanupContext{
+		SubscriptionID:        opts.suiteConfig.subscription,
+		ResourceGroupName:     opts.suiteConfig.resourceGroupName,
+		NodeResourceGroupName: *opts.clusterConfig.cluster.Properties.NodeResourceGroup,
+		ClusterName:           *opts.clusterConfig.cluster.Name,
+		VMSSName:              vmssName,
+		Credential:            opts.cloud.credential,
+		Failed:                t.Failed(),
+	})
+	if err != nil {
+		t.Logf("failed to clean up the resources of scenario %q, which may need to be deleted manually: %s", opts.scenario.Name, err)
+		return
+	}
+	log.Printf("finished cleaning up the resources of scenario %q", opts.scenario.Name)
+}
+
+// Runs each of the scenarios depending on the scenario of opts as a sequential subtest of its own, once it has been validated.
+// Each dependent runs on the same cluster, and is bootstrapped according to the state published by its dependency, whose
+// VMSS is retained until every dependent has finished
+func runDependentScenarios(t *testing.T, vmssName string, instances []vmssInstance, dependents []*scenario.Scenario, opts *scenarioRunOpts) {
+	// the scenario's own context may be close to timing out, though each dependent is bounded by its own timeout
+	ctx := context.Background()
+
+	nodeNames := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		nodeNames = append(nodeNames, instance.nodeName())
+	}
+	state := &scenario.DependencyState{
+		ClusterName:     *opts.clusterConfig.cluster.Name,
+		VMSSName:        vmssName,
+		NodeNames:       nodeNames,
+		BootstrapConfig: opts.nbc,
+	}
+
+	baseConfig, err := getBaseNodeBootstrappingConfiguration(ctx, opts.cloud, opts.suiteConfig, opts.clusterConfig.parameters)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dependent := range dependents {
+		dependent := dependent
+
+		nbc, err := newScenarioBootstrapConfig(baseConfig, dependent)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if dependent.DependencyMutator != nil {
+			dependent.DependencyMutator(nbc, state)
+		}
+
+		log.Printf("running scenario %q, which depends on scenario %q", dependent.Name, opts.scenario.Name)
+		t.Run(dependent.Name, func(t *testing.T) {
+			runScenarioTest(ctx, t, &scenarioRunOpts{
+				clusterConfig: opts.clusterConfig,
+				cloud:         opts.cloud,
+				suiteConfig:   opts.suiteConfig,
+				scenario:      dependent,
+				scenarios:     opts.scenarios,
+				nbc:           nbc,
+				capabilities:  opts.capabilities,
+			})
+		})
+	}
+}
+
+// Runs the scenario's validation against a single instance of its VMSS, specified by opts.instance
+func runScenarioOnInstance(ctx context.Context, t *testing.T, vmssName string, vmssSucceeded bool, privateKeyBytes []byte, opts *scenarioRunOpts) {
 	vmPrivateIP, err := pollGetVMPrivateIP(ctx, vmssName, opts)
 	if err != nil {
 		t.Fatalf("failed to get VM private IP: %s", err)
 	}
 
+	// Set once the scenario has deliberately evicted its spot VM, after which the VM can no longer be reached
+	spotVMEvicted := false
+
 	// Perform posthoc log extraction when the VMSS creation succeeded or failed due to a CSE error
 	defer func() {
+		if spotVMEvicted {
+			return
+		}
 		err := pollExtractVMLogs(ctx, vmssName, vmPrivateIP, privateKeyBytes, opts)
 		if err != nil {
 			t.Fatal(err)
 		}
 	}()
 
+	// Archive the node's provisioning and extension logs whenever the scenario fails for any reason
+	defer func() {
+		if t.Failed() && !spotVMEvicted {
+			artifacts, err := collectFailureArtifacts(ctx, vmssName, vmPrivateIP, string(privateKeyBytes), opts)
+			if err != nil {
+				t.Errorf("failed to collect failure artifacts: %s", err)
+				return
+			}
+			for _, failure := range detectKernelFailures(artifacts[dmesgArtifactName]) {
+				opts.result.recordKernelFailure(opts.instance, failure)
+				t.Errorf("detected kernel failure on vmss %q: %s", vmssName, failure)
+			}
+
+			// a node which joined the cluster but still failed validation most likely has broken pod networking
+			registered, err := isNodeRegistered(ctx, opts.clusterConfig.kube, opts.instance.nodeName())
+			if err != nil {
+				t.Errorf("unable to determine whether node joined the cluster: %s", err)
+				return
+			}
+			if registered {
+				if err := collectNetworkArtifacts(ctx, vmssName, vmPrivateIP, string(privateKeyBytes), opts); err != nil {
+					t.Errorf("failed to collect network artifacts: %s", err)
+				}
+			}
+		}
+	}()
+
+	// Record the events involving the node and its pods whenever the scenario fails, which only requires the apiserver
+	defer func() {
+		if t.Failed() {
+			if err := collectEventsArtifact(ctx, opts.instance.nodeName(), opts); err != nil {
+				t.Errorf("failed to collect events: %s", err)
+			}
+		}
+	}()
+
 	// Only perform node readiness/pod-related checks when VMSS creation succeeded
 	if vmssSucceeded {
 		log.Println("vmss creation succeded, proceeding with node readiness and pod checks...")
-		nodeName, err := validateNodeHealth(ctx, opts.clusterConfig.kube, vmssName)
+		nodeName, err := validateNodeHealth(ctx, opts.clusterConfig.kube, opts.namespace, opts.instance.nodeName())
 		if err != nil {
+			skipIfSpotVMSSEvicted(ctx, t, vmssName, opts)
+			// still run the live VM validators against the unhealthy node to gather diagnostics from it
+			if validationErr := runLiveVMValidators(ctx, vmssName, vmPrivateIP, string(privateKeyBytes), opts); validationErr != nil {
+				log.Printf("live VM validation of unhealthy node failed: %s", validationErr)
+			}
 			t.Fatal(err)
 		}
 
+		// measured before any further validation, during which the node's Ready condition may transition again. Exceeding the
+		// objective doesn't invalidate the node, so the rest of its validation still runs
+		if opts.scenario.BootstrapLatencySLO != nil {
+			if err := validateBootstrapLatency(ctx, vmssName, nodeName, opts); err != nil {
+				t.Error(err)
+			}
+		}
+
+		if isSSHDisabled(opts) {
+			log.Println("ssh-disabled scenario: scheduling a debug pod onto the node to execute commands through...")
+			debugPodName, err := ensureNodeDebugPod(ctx, opts.clusterConfig.kube, opts.namespace, nodeName)
+			if err != nil {
+				t.Fatalf("unable to ensure node debug pod: %s", err)
+			}
+			opts.nodeDebugPodName = debugPodName
+
+			log.Println("ssh-disabled scenario: validating kubelet healthz through a port forward to the debug pod...")
+			if err := validateKubeletHealthz(ctx, opts.clusterConfig.kube, opts.namespace, debugPodName, nodeName); err != nil {
+				t.Fatalf("kubelet healthz validation failed: %s", err)
+			}
+		}
+
 		if opts.nbc.AgentPoolProfile.WorkloadRuntime == datamodel.WasmWasi {
 			log.Println("wasm scenario: running wasm validation...")
 			if err := ensureWasmRuntimeClasses(ctx, opts.clusterConfig.kube); err != nil {
 				t.Fatalf("unable to ensure wasm RuntimeClasses: %s", err)
 			}
-			if err := validateWasm(ctx, opts.clusterConfig.kube, nodeName, string(privateKeyBytes)); err != nil {
+			if err := validateWasm(ctx, opts.clusterConfig.kube, opts.namespace, nodeName, string(privateKeyBytes)); err != nil {
 				t.Fatalf("unable to validate wasm: %s", err)
 			}
 		}
 
+		for _, chart := range opts.scenario.HelmCharts {
+			if err := opts.clusterConfig.kube.installHelmChart(ctx, chart, manifestValues{Namespace: opts.namespace, NodeName: nodeName}); err != nil {
+				t.Fatalf("failed to install the helm charts of scenario %q: %s", opts.scenario.Name, err)
+			}
+		}
+
+		if opts.scenario.Manifests != "" {
+			log.Printf("applying the manifests within %s...", opts.scenario.Manifests)
+			if _, err := opts.clusterConfig.kube.applyManifests(ctx, opts.scenario.Manifests, manifestValues{Namespace: opts.namespace, NodeName: nodeName}); err != nil {
+				t.Fatalf("failed to apply the manifests of scenario %q: %s", opts.scenario.Name, err)
+			}
+		}
+
 		log.Println("node is ready, proceeding with validation commands...")
 
+		if len(opts.scenario.ExpectedAllocatableResources) > 0 {
+			log.Println("waiting for the node to report its expected allocatable resources...")
+			if err := waitUntilNodeResourcesAllocatable(ctx, opts.clusterConfig.kube, nodeName, opts.scenario.ExpectedAllocatableResources); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if opts.scenario.NvidiaGPUs > 0 {
+			log.Println("waiting for the NVIDIA device plugin to advertise the node's GPUs...")
+			if err := opts.clusterConfig.kube.ensureNvidiaDevicePlugin(ctx, opts.namespace, nodeName, opts.nbc.EnableGPUDevicePluginIfNeeded, opts.scenario.NvidiaGPUs); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := runNodeValidators(ctx, nodeName, opts); err != nil {
+			t.Fatalf("node validation failed: %s", err)
+		}
+
+		if err := runKubeletConfigValidators(ctx, nodeName, opts); err != nil {
+			t.Fatalf("kubelet config validation failed: %s", err)
+		}
+
+		if opts.scenario.ValidateKubeletServingCert {
+			if scenario.IsKubeletServingCertRotationEnabled(opts.nbc) {
+				log.Println("kubelet serving certificate rotation scenario: approving and validating the kubelet serving CSR...")
+				fingerprint, err := validateKubeletServingCertRotation(ctx, opts.clusterConfig.kube, nodeName)
+				if err != nil {
+					t.Fatalf("kubelet serving certificate rotation validation failed: %s", err)
+				}
+				opts.kubeletServingCertFingerprint = fingerprint
+			} else if err := validateNoKubeletServingCSR(ctx, opts.clusterConfig.kube, nodeName); err != nil {
+				t.Fatalf("kubelet serving certificate validation failed: %s", err)
+			}
+		}
+
+		if opts.scenario.EncryptionAtHost {
+			if err := validateEncryptionAtHost(ctx, vmssName, opts); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if opts.privateACR != nil {
+			log.Println("private ACR scenario: validating the node can pull from the private ACR...")
+			if err := validatePrivateACRPull(ctx, opts.clusterConfig.kube, opts.namespace, nodeName, opts.privateACR); err != nil {
+				t.Fatalf("private ACR validation failed: %s", err)
+			}
+		}
+
+		if opts.scenario.HTTPProxy {
+			log.Println("HTTP proxy scenario: validating the node's requests were served by the proxy...")
+			if err := validateHTTPProxyRequests(ctx, opts.clusterConfig.kube, vmPrivateIP); err != nil {
+				t.Fatalf("HTTP proxy validation failed: %s", err)
+			}
+		}
+
+		if opts.scenario.ValidateServiceConnectivity {
+			log.Println("validating service connectivity from and to the node...")
+			if err := validateServiceConnectivity(ctx, vmssName, vmPrivateIP, string(privateKeyBytes), nodeName, opts); err != nil {
+				t.Fatalf("service connectivity validation failed: %s", err)
+			}
+		}
+
+		if opts.scenario.ValidateCSIDrivers {
+			log.Println("CSI scenario: validating the Azure Disk and Azure File CSI node drivers...")
+			if err := validateCSIDrivers(ctx, opts.clusterConfig.kube, opts.namespace, nodeName); err != nil {
+				t.Fatalf("CSI driver validation failed: %s", err)
+			}
+		}
+
+		if opts.scenario.ProbeNetworkPerf || opts.suiteConfig.networkPerfProbe {
+			// only a performance signal, so a failure to measure it doesn't fail the scenario
+			if err := probeNetworkPerf(ctx, nodeName, opts); err != nil {
+				opts.result.recordWarning(opts.instance, fmt.Sprintf("network performance probe failed: %s", err))
+			}
+		}
+
+		if opts.scenario.ScrapeKubeletStats {
+			// only a performance signal, so a failure to scrape it doesn't fail the scenario
+			if err := scrapeKubeletStats(ctx, nodeName, opts); err != nil {
+				opts.result.recordWarning(opts.instance, fmt.Sprintf("kubelet stats scrape failed: %s", err))
+			}
+		}
+
+		addresses, err := getVMIPAddresses(ctx, vmssName, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if addresses.isDualStack() {
+			log.Println("vm has IPv4 and IPv6 addresses, validating dual-stack node IPs...")
+			if err := validateDualStackNodeIPs(ctx, nodeName, vmssName, vmPrivateIP, string(privateKeyBytes), addresses, opts); err != nil {
+				t.Fatalf("dual-stack node IP validation failed: %s", err)
+			}
+		}
+
 		err = runLiveVMValidators(ctx, vmssName, vmPrivateIP, string(privateKeyBytes), opts)
 		if err != nil {
+			skipIfSpotVMSSEvicted(ctx, t, vmssName, opts)
 			t.Fatalf("vm validation failed: %s", err)
 		}
 
 		log.Println("node bootstrapping succeeded!")
-	} else {
-		t.Fatal("vmss was unable to be properly created and bootstrapped")
-	}
 
-	if opts.suiteConfig.keepVMSS {
-		log.Printf("vmss %q will be retained for debugging purposes, please make sure to manually delete it later", vmssName)
-		if vmssModel != nil {
-			log.Printf("retained vmss resource ID: %q", *vmssModel.ID)
-		} else {
-			log.Printf("WARNING: model of retained vmss %q is nil", vmssName)
-		}
-		if err := writeToFile(filepath.Join(opts.loggingDir, "sshkey"), string(privateKeyBytes)); err != nil {
-			t.Fatalf("failed to write retained vmss %q private ssh key to disk: %s", vmssName, err)
+		if opts.scenario.SimulateSpotEviction {
+			// logs can't be extracted from the VM once it has been evicted
+			if err := pollExtractVMLogs(ctx, vmssName, vmPrivateIP, privateKeyBytes, opts); err != nil {
+				t.Fatal(err)
+			}
+			spotVMEvicted = true
+			if err := simulateSpotEviction(ctx, vmssName, nodeName, opts); err != nil {
+				t.Fatalf("spot eviction validation failed: %s", err)
+			}
+			log.Println("node became unavailable after spot eviction")
 		}
+	} else {
+		t.Fatal("vmss was unable to be properly created and bootstrapped")
 	}
 }
@@ -0,0 +1,46 @@
+package e2e_test
+
+import (
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+)
+
+const (
+	// Keys of the tags stamped onto every resource created by the e2e suite, used by cleanup
+	// and cost-tracking automation to attribute resources to individual runs
+	buildIDTagKey  = "abe2e-build-id"
+	scenarioTagKey = "abe2e-scenario"
+	ownerTagKey    = "abe2e-owner"
+	expiryTagKey   = "abe2e-expiry"
+
+	// Clusters are reused across runs, so they're given a much longer lifetime than scenario VMSSes
+	clusterResourceTTL = 7 * 24 * time.Hour
+	vmssResourceTTL    = 24 * time.Hour
+)
+
+// Returns the set of tags to be applied to a resource created by the suite which expires after the specified TTL.
+// The scenario tag is omitted when scenarioName is empty, as is the case for resources shared between scenarios.
+func getResourceTags(suiteConfig *suiteConfig, scenarioName string, ttl time.Duration) map[string]*string {
+	tags := map[string]*string{
+		buildIDTagKey: to.Ptr(suiteConfig.buildID),
+		ownerTagKey:   to.Ptr(suiteConfig.owner),
+		expiryTagKey:  to.Ptr(time.Now().UTC().Add(ttl).Format(time.RFC3339)),
+	}
+	if scenarioName != "" {
+		tags[scenarioTagKey] = to.Ptr(scenarioName)
+	}
+	return tags
+}
+
+// Merges the supplied tags into the existing set, overwriting any existing tags with the same keys.
+// Returns the merged set, which will be newly allocated if the existing set is nil.
+func mergeResourceTags(existing, tags map[string]*string) map[string]*string {
+	if existing == nil {
+		existing = make(map[string]*string, len(tags))
+	}
+	for k, v := range tags {
+		existing[k] = v
+	}
+	return existing
+}
@@ -0,0 +1,102 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	k8serrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Maps the types of resources which can be leaked within the node resource group after a VMSS is deleted
+// to the API version used to delete them by ID
+var orphanedResourceTypeAPIVersions = map[string]string{
+	"Microsoft.Network/networkInterfaces": "2022-07-01",
+	"Microsoft.Network/publicIPAddresses": "2022-07-01",
+	"Microsoft.Compute/disks":             "2022-03-02",
+}
+
+// Deletes the specified VMSS, retrying on failure until the VMSS is either deleted or no longer exists.
+func deleteVMSS(ctx context.Context, vmssName string, opts *scenarioRunOpts) error {
+	mcResourceGroupName := *opts.clusterConfig.cluster.Properties.NodeResourceGroup
+
+	err := wait.PollImmediateWithContext(ctx, deleteVMSSPollInterval, deleteVMSSPollingTimeout, func(ctx context.Context) (bool, error) {
+		poller, err := opts.cloud.vmssClient.BeginDelete(ctx, mcResourceGroupName, vmssName, nil)
+		if err != nil {
+			if isNotFoundError(err) || isResourceNotFoundError(err) {
+				return true, nil
+			}
+			log.Printf("error starting deletion of vmss %q, will retry: %s", vmssName, err)
+			return false, nil
+		}
+		if _, err = poller.PollUntilDone(ctx, nil); err != nil {
+			log.Printf("error polling deletion of vmss %q, will retry: %s", vmssName, err)
+			return false, nil
+		}
+		return true, nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to delete vmss %q: %w", vmssName, err)
+	}
+
+	return nil
+}
+
+// Deletes any NICs, disks, and public IPs left behind within the node resource group by the specified VMSS.
+// All such resources are named after the VMSS, which itself has a randomly generated name unique to the scenario run.
+func deleteOrphanedVMSSResources(ctx context.Context, vmssName string, opts *scenarioRunOpts) error {
+	mcResourceGroupName := *opts.clusterConfig.cluster.Properties.NodeResourceGroup
+
+	orphans, err := listOrphanedVMSSResources(ctx, opts.cloud, mcResourceGroupName, vmssName)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, orphan := range orphans {
+		id, resourceType := *orphan.ID, *orphan.Type
+		log.Printf("deleting orphaned %s %q of vmss %q", resourceType, *orphan.Name, vmssName)
+
+		poller, err := opts.cloud.resourceClient.BeginDeleteByID(ctx, id, orphanedResourceTypeAPIVersions[resourceType], nil)
+		if err != nil {
+			if !isNotFoundError(err) && !isResourceNotFoundError(err) {
+				errs = append(errs, fmt.Errorf("failed to start deletion of orphaned resource %q: %w", id, err))
+			}
+			continue
+		}
+		if _, err = poller.PollUntilDone(ctx, nil); err != nil {
+			errs = append(errs, fmt.Errorf("failed to wait for deletion of orphaned resource %q: %w", id, err))
+		}
+	}
+
+	return k8serrors.NewAggregate(errs)
+}
+
+func listOrphanedVMSSResources(ctx context.Context, cloud *azureClient, mcResourceGroupName, vmssName string) ([]*armresources.GenericResourceExpanded, error) {
+	var orphans []*armresources.GenericResourceExpanded
+	pager := cloud.resourceClient.NewListByResourceGroupPager(mcResourceGroupName, nil)
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to advance page: %w", err)
+		}
+		for _, resource := range page.Value {
+			if resource == nil || resource.ID == nil || resource.Name == nil || resource.Type == nil {
+				continue
+			}
+			if _, ok := orphanedResourceTypeAPIVersions[*resource.Type]; !ok {
+				continue
+			}
+			if strings.Contains(strings.ToLower(*resource.Name), strings.ToLower(vmssName)) {
+				orphans = append(orphans, resource)
+			}
+		}
+	}
+
+	return orphans, nil
+}
@@ -10,7 +10,7 @@ import (
 // TODO(ace): minimize the actual required defaults.
 // this is what we previously used for bash e2e from e2e/nodebootstrapping_template.json.
 // which itself was extracted from baker_test.go logic, which was inherited from aks-engine.
-func baseTemplate(location string) *datamodel.NodeBootstrappingConfiguration {
+func baseTemplate(location string, environment cloudEnvironment) *datamodel.NodeBootstrappingConfiguration {
 	var (
 		trueConst  = true
 		falseConst = false
@@ -218,41 +218,7 @@ func baseTemplate(location string) *datamodel.NodeBootstrappingConfiguration {
 				CustomConfiguration: nil,
 			},
 		},
-		CloudSpecConfig: &datamodel.AzureEnvironmentSpecConfig{
-			CloudName: "AzurePublicCloud",
-			DockerSpecConfig: datamodel.DockerSpecConfig{
-				DockerEngineRepo:         "https://aptdocker.azureedge.net/repo",
-				DockerComposeDownloadURL: "https://github.com/docker/compose/releases/download",
-			},
-			KubernetesSpecConfig: datamodel.KubernetesSpecConfig{
-				AzureTelemetryPID:                    "",
-				KubernetesImageBase:                  "k8s.gcr.io/",
-				TillerImageBase:                      "gcr.io/kubernetes-helm/",
-				ACIConnectorImageBase:                "microsoft/",
-				MCRKubernetesImageBase:               "mcr.microsoft.com/",
-				NVIDIAImageBase:                      "nvidia/",
-				AzureCNIImageBase:                    "mcr.microsoft.com/containernetworking/",
-				CalicoImageBase:                      "calico/",
-				EtcdDownloadURLBase:                  "",
-				KubeBinariesSASURLBase:               "https://acs-mirror.azureedge.net/kubernetes/",
-				WindowsTelemetryGUID:                 "fb801154-36b9-41bc-89c2-f4d4f05472b0",
-				CNIPluginsDownloadURL:                "https://acs-mirror.azureedge.net/cni/cni-plugins-amd64-v0.7.6.tgz",
-				VnetCNILinuxPluginsDownloadURL:       "https://acs-mirror.azureedge.net/azure-cni/v1.1.3/binaries/azure-vnet-cni-linux-amd64-v1.1.3.tgz",
-				VnetCNIWindowsPluginsDownloadURL:     "https://acs-mirror.azureedge.net/azure-cni/v1.1.3/binaries/azure-vnet-cni-singletenancy-windows-amd64-v1.1.3.zip",
-				ContainerdDownloadURLBase:            "https://storage.googleapis.com/cri-containerd-release/",
-				CSIProxyDownloadURL:                  "https://acs-mirror.azureedge.net/csi-proxy/v0.1.0/binaries/csi-proxy.tar.gz",
-				WindowsProvisioningScriptsPackageURL: "https://acs-mirror.azureedge.net/aks-engine/windows/provisioning/signedscripts-v0.2.2.zip",
-				WindowsPauseImageURL:                 "mcr.microsoft.com/oss/kubernetes/pause:1.4.0",
-				AlwaysPullWindowsPauseImage:          false,
-				CseScriptsPackageURL:                 "https://acs-mirror.azureedge.net/aks/windows/cse/csescripts-v0.0.1.zip",
-				CNIARM64PluginsDownloadURL:           "https://acs-mirror.azureedge.net/cni-plugins/v0.8.7/binaries/cni-plugins-linux-arm64-v0.8.7.tgz",
-				VnetCNIARM64LinuxPluginsDownloadURL:  "https://acs-mirror.azureedge.net/azure-cni/v1.4.13/binaries/azure-vnet-cni-linux-arm64-v1.4.14.tgz",
-			},
-			EndpointConfig: datamodel.AzureEndpointConfig{
-				ResourceManagerVMDNSSuffix: "cloudapp.azure.com",
-			},
-			OSImageConfig: map[datamodel.Distro]datamodel.AzureOSImageConfig(nil),
-		},
+		CloudSpecConfig: cloudSpecConfig(environment),
 		K8sComponents: &datamodel.K8sComponents{
 			PodInfraContainerImageURL: "mcr.microsoft.com/oss/kubernetes/pause:3.6",
 			HyperkubeImageURL:         "mcr.microsoft.com/oss/kubernetes/",
@@ -447,14 +413,13 @@ func baseTemplate(location string) *datamodel.NodeBootstrappingConfiguration {
 
 func getDebugDaemonset() string {
 	return `apiVersion: apps/v1
-kind: Deployment
+kind: DaemonSet
 metadata:
   name: &name debug
   namespace: default
   labels:
     app: *name
 spec:
-  replicas: 1
   selector:
     matchLabels:
       app: *name
@@ -466,6 +431,9 @@ spec:
       hostNetwork: true
       nodeSelector:
         kubernetes.azure.com/agentpool: nodepool1
+      # the suite needs debug access to the nodes it selects regardless of how they're tainted
+      tolerations:
+      - operator: Exists
       hostPID: true
       containers:
       - image: mcr.microsoft.com/oss/nginx/nginx:1.21.6
@@ -481,28 +449,261 @@ spec:
 `
 }
 
-func getNginxPodTemplate(nodeName string) string {
+// Returns the manifest of a privileged pod sharing the host's PID and network namespaces, pinned to the specified node, through
+// which commands can be executed on nodes which can't be reached over SSH from the debug daemonset
+func getNodeDebugPodTemplate(namespace, nodeName string) string {
 	return fmt.Sprintf(`apiVersion: v1
 kind: Pod
 metadata:
-  name: %[1]s-nginx
-  namespace: default
+  name: %[1]s-debug
+  namespace: %[2]s
 spec:
+  hostNetwork: true
+  hostPID: true
   containers:
-  - name: nginx
+  - image: mcr.microsoft.com/oss/nginx/nginx:1.21.6
+    name: ubuntu
+    command: ["sleep", "infinity"]
+    securityContext:
+      privileged: true
+      capabilities:
+        add: ["SYS_PTRACE", "SYS_RAWIO"]
+  nodeSelector:
+    kubernetes.io/hostname: %[1]s
+  # the pod is pinned to the node under test, so it must tolerate any taints the node was registered with
+  tolerations:
+  - operator: Exists
+`, nodeName, namespace)
+}
+
+// Returns the manifest of the upstream NVIDIA device plugin daemonset, pinned to the specified node, which registers with kubelet
+// to advertise the node's GPUs as allocatable nvidia.com/gpu resources when the device plugin managed by CSE isn't enabled
+func getNvidiaDevicePluginDaemonSetTemplate(namespace, nodeName string) string {
+	return fmt.Sprintf(`apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: &name %[1]s-nvidia-device-plugin
+  namespace: %[2]s
+  labels:
+    app: *name
+spec:
+  selector:
+    matchLabels:
+      app: *name
+  template:
+    metadata:
+      labels:
+        app: *name
+    spec:
+      nodeSelector:
+        kubernetes.io/hostname: %[1]s
+      # the pod is pinned to the node under test, so it must tolerate any taints the node was registered with
+      tolerations:
+      - operator: Exists
+      containers:
+      - image: mcr.microsoft.com/oss/nvidia/k8s-device-plugin:v0.14.1
+        name: nvidia-device-plugin
+        env:
+        - name: FAIL_ON_INIT_ERROR
+          value: "false"
+        securityContext:
+          allowPrivilegeEscalation: false
+          capabilities:
+            drop: ["ALL"]
+        volumeMounts:
+        - name: device-plugin
+          mountPath: /var/lib/kubelet/device-plugins
+      volumes:
+      - name: device-plugin
+        hostPath:
+          path: /var/lib/kubelet/device-plugins
+`, nodeName, namespace)
+}
+
+// Returns the manifest of the scheduling smoke test deployment, whose replicas are pinned to the specified node, and only
+// become ready once nginx serves requests within them
+func getSmokeTestDeploymentTemplate(namespace, nodeName string, replicas int32) string {
+	return fmt.Sprintf(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: &name %[1]s-smoke-test
+  namespace: %[2]s
+  labels:
+    app: *name
+spec:
+  replicas: %[3]d
+  selector:
+    matchLabels:
+      app: *name
+  template:
+    metadata:
+      labels:
+        app: *name
+    spec:
+      containers:
+      - name: nginx
+        image: mcr.microsoft.com/oss/nginx/nginx:1.21.6
+        imagePullPolicy: IfNotPresent
+        readinessProbe:
+          httpGet:
+            path: /
+            port: 80
+      nodeSelector:
+        kubernetes.io/hostname: %[1]s
+      # the pods are pinned to the node under test, so they must tolerate any taints the node was registered with
+      tolerations:
+      - operator: Exists
+`, nodeName, namespace, replicas)
+}
+
+// Returns the manifest of the NodePort service fronting the smoke test deployment pinned to the specified node, through which
+// its connectivity is validated
+func getSmokeTestServiceTemplate(namespace, nodeName string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Service
+metadata:
+  name: &name %[1]s-smoke-test
+  namespace: %[2]s
+spec:
+  type: NodePort
+  selector:
+    app: *name
+  ports:
+  - name: http
+    protocol: TCP
+    port: 80
+    targetPort: 80
+`, nodeName, namespace)
+}
+
+// Returns the manifest of a PVC of the CSI smoke test of the specified node, provisioned by the storage class
+func getCSISmokeTestPVCTemplate(namespace, name, storageClass, accessMode string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: %[1]s
+  namespace: %[2]s
+spec:
+  storageClassName: %[3]s
+  accessModes:
+  - %[4]s
+  resources:
+    requests:
+      storage: 1Gi
+`, name, namespace, storageClass, accessMode)
+}
+
+// Returns the manifest of the CSI smoke test pod, pinned to the specified node, which writes a file to each of its volumes, each
+// mounted at /mnt/<volume>, and reads it back before exiting successfully
+func getCSISmokeTestPodTemplate(namespace, nodeName, diskClaimName, fileClaimName string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Pod
+metadata:
+  name: %[1]s-csi-smoke-test
+  namespace: %[2]s
+spec:
+  restartPolicy: Never
+  containers:
+  - name: write
     image: mcr.microsoft.com/oss/nginx/nginx:1.21.6
-    imagePullPolicy: IfNotPresent
+    command: ["sh", "-c"]
+    args:
+    - |
+      set -e
+      for volume in disk file; do
+        echo "written by $(hostname)" > /mnt/$volume/smoke-test
+        sync
+        grep -q "written by $(hostname)" /mnt/$volume/smoke-test
+        echo "$volume volume is writable"
+      done
+    volumeMounts:
+    - name: disk
+      mountPath: /mnt/disk
+    - name: file
+      mountPath: /mnt/file
+  volumes:
+  - name: disk
+    persistentVolumeClaim:
+      claimName: %[3]s
+  - name: file
+    persistentVolumeClaim:
+      claimName: %[4]s
+  nodeSelector:
+    kubernetes.io/hostname: %[1]s
+  # the pod is pinned to the node under test, so it must tolerate any taints the node was registered with
+  tolerations:
+  - operator: Exists
+`, nodeName, namespace, diskClaimName, fileClaimName)
+}
+
+// Returns the manifest of the iperf3 server of the network performance probe of the specified node, which runs on one of the
+// cluster's existing nodes
+func getNetworkPerfServerPodTemplate(namespace, nodeName, image string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Pod
+metadata:
+  name: %[1]s-iperf3-server
+  namespace: %[2]s
+spec:
+  containers:
+  - name: iperf3
+    image: %[3]s
+    command: ["/bin/sh", "-c", "%[4]s"]
+    ports:
+    - containerPort: %[5]d
+  nodeSelector:
+    kubernetes.azure.com/agentpool: nodepool1
+`, nodeName, namespace, image, fmt.Sprintf(networkPerfCommandTemplate, fmt.Sprintf("--server --port %d", networkPerfPort)), networkPerfPort)
+}
+
+// Returns the manifest of the iperf3 client of the network performance probe, pinned to the specified node, which measures the
+// throughput to the server at the specified IP, reporting its measurements as JSON once it exits
+func getNetworkPerfClientPodTemplate(namespace, nodeName, image, serverIP string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Pod
+metadata:
+  name: %[1]s-iperf3-client
+  namespace: %[2]s
+spec:
+  restartPolicy: Never
+  containers:
+  - name: iperf3
+    image: %[3]s
+    command: ["/bin/sh", "-c", "%[4]s"]
+  nodeSelector:
+    kubernetes.io/hostname: %[1]s
+  # the pod is pinned to the node under test, so it must tolerate any taints the node was registered with
+  tolerations:
+  - operator: Exists
+`, nodeName, namespace, image, fmt.Sprintf(networkPerfCommandTemplate, fmt.Sprintf("--client %s --port %d --time %d --json", serverIP, networkPerfPort, networkPerfDurationSeconds)))
+}
+
+func getPrivateACRPodTemplate(namespace, nodeName, image string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Pod
+metadata:
+  name: %[1]s-private-acr
+  namespace: %[3]s
+spec:
+  containers:
+  - name: nginx
+    image: %[2]s
+    # always pulled, such that the pod can only run once kubelet has authenticated with the private ACR
+    imagePullPolicy: Always
   nodeSelector:
     kubernetes.io/hostname: %[1]s
-`, nodeName)
+  # the pod is pinned to the node under test, so it must tolerate any taints the node was registered with
+  tolerations:
+  - operator: Exists
+`, nodeName, image, namespace)
 }
 
-func getWasmSpinPodTemplate(nodeName string) string {
+func getWasmSpinPodTemplate(namespace, nodeName string) string {
 	return fmt.Sprintf(`apiVersion: v1
 kind: Pod
 metadata:
   name: %[1]s-wasm-spin
-  namespace: default
+  namespace: %[2]s
 spec:
   runtimeClassName: wasmtime-spin
   containers:
@@ -519,15 +720,15 @@ spec:
         memory: 128Mi
   nodeSelector:
     kubernetes.io/hostname: %[1]s
-`, nodeName)
+`, nodeName, namespace)
 }
 
-func getWasmSlightPodTemplate(nodeName string) string {
+func getWasmSlightPodTemplate(namespace, nodeName string) string {
 	return fmt.Sprintf(`apiVersion: v1
 kind: Pod
 metadata:
   name: %[1]s-wasm-slight
-  namespace: default
+  namespace: %[2]s
 spec:
   runtimeClassName: wasmtime-slight
   containers:
@@ -544,5 +745,118 @@ spec:
         memory: 128Mi
   nodeSelector:
     kubernetes.io/hostname: %[1]s
-`, nodeName)
+`, nodeName, namespace)
+}
+
+// The squid config of the suite's HTTP proxy, which accepts requests from within the cluster's VNet and logs each request
+// it serves, such that the requests of nodes bootstrapped to egress through the proxy can be found within its logs
+func getHTTPProxyConfigMapTemplate() string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %[1]s
+  namespace: default
+data:
+  squid.conf: |
+    http_port %[2]d
+    acl localnet src 10.0.0.0/8
+    acl SSL_ports port 443
+    acl CONNECT method CONNECT
+    http_access deny CONNECT !SSL_ports
+    http_access allow localnet
+    http_access deny all
+    access_log stdio:/dev/stdout
+    cache deny all
+`, httpProxyName, httpProxyPort)
+}
+
+func getHTTPProxyPodTemplate(image string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Pod
+metadata:
+  name: %[1]s
+  namespace: default
+  labels:
+    app: %[1]s
+spec:
+  # the proxy listens on the node's own IP, such that it can be reached from anywhere within the cluster's VNet
+  hostNetwork: true
+  nodeSelector:
+    kubernetes.azure.com/agentpool: nodepool1
+  containers:
+  - name: squid
+    image: %[3]s
+    imagePullPolicy: IfNotPresent
+    command: ["/bin/sh", "-c", "%[4]s"]
+    ports:
+    - containerPort: %[2]d
+    readinessProbe:
+      tcpSocket:
+        port: %[2]d
+      periodSeconds: 5
+    volumeMounts:
+    - name: config
+      mountPath: /etc/squid/squid.conf
+      subPath: squid.conf
+  volumes:
+  - name: config
+    configMap:
+      name: %[1]s
+`, httpProxyName, httpProxyPort, image, httpProxyCommand)
+}
+
+// Returns the spec of the cloud nodes are bootstrapped for, which, as within aks-engine, the cloud specs of which these are,
+// only differ in the mirrors binaries are downloaded from within Azure China, since the public ones aren't reachable from it
+func cloudSpecConfig(environment cloudEnvironment) *datamodel.AzureEnvironmentSpecConfig {
+	spec := &datamodel.AzureEnvironmentSpecConfig{
+		CloudName: environment.name,
+		DockerSpecConfig: datamodel.DockerSpecConfig{
+			DockerEngineRepo:         "https://aptdocker.azureedge.net/repo",
+			DockerComposeDownloadURL: "https://github.com/docker/compose/releases/download",
+		},
+		KubernetesSpecConfig: datamodel.KubernetesSpecConfig{
+			AzureTelemetryPID:                    "",
+			KubernetesImageBase:                  "k8s.gcr.io/",
+			TillerImageBase:                      "gcr.io/kubernetes-helm/",
+			ACIConnectorImageBase:                "microsoft/",
+			MCRKubernetesImageBase:               "mcr.microsoft.com/",
+			NVIDIAImageBase:                      "nvidia/",
+			AzureCNIImageBase:                    "mcr.microsoft.com/containernetworking/",
+			CalicoImageBase:                      "calico/",
+			EtcdDownloadURLBase:                  "",
+			KubeBinariesSASURLBase:               "https://acs-mirror.azureedge.net/kubernetes/",
+			WindowsTelemetryGUID:                 "fb801154-36b9-41bc-89c2-f4d4f05472b0",
+			CNIPluginsDownloadURL:                "https://acs-mirror.azureedge.net/cni/cni-plugins-amd64-v0.7.6.tgz",
+			VnetCNILinuxPluginsDownloadURL:       "https://acs-mirror.azureedge.net/azure-cni/v1.1.3/binaries/azure-vnet-cni-linux-amd64-v1.1.3.tgz",
+			VnetCNIWindowsPluginsDownloadURL:     "https://acs-mirror.azureedge.net/azure-cni/v1.1.3/binaries/azure-vnet-cni-singletenancy-windows-amd64-v1.1.3.zip",
+			ContainerdDownloadURLBase:            "https://storage.googleapis.com/cri-containerd-release/",
+			CSIProxyDownloadURL:                  "https://acs-mirror.azureedge.net/csi-proxy/v0.1.0/binaries/csi-proxy.tar.gz",
+			WindowsProvisioningScriptsPackageURL: "https://acs-mirror.azureedge.net/aks-engine/windows/provisioning/signedscripts-v0.2.2.zip",
+			WindowsPauseImageURL:                 "mcr.microsoft.com/oss/kubernetes/pause:1.4.0",
+			AlwaysPullWindowsPauseImage:          false,
+			CseScriptsPackageURL:                 "https://acs-mirror.azureedge.net/aks/windows/cse/csescripts-v0.0.1.zip",
+			CNIARM64PluginsDownloadURL:           "https://acs-mirror.azureedge.net/cni-plugins/v0.8.7/binaries/cni-plugins-linux-arm64-v0.8.7.tgz",
+			VnetCNIARM64LinuxPluginsDownloadURL:  "https://acs-mirror.azureedge.net/azure-cni/v1.4.13/binaries/azure-vnet-cni-linux-arm64-v1.4.14.tgz",
+		},
+		EndpointConfig: datamodel.AzureEndpointConfig{
+			ResourceManagerVMDNSSuffix: environment.vmDNSSuffix,
+		},
+		OSImageConfig: map[datamodel.Distro]datamodel.AzureOSImageConfig(nil),
+	}
+	if environment.name == datamodel.AzureChinaCloud {
+		spec.DockerSpecConfig.DockerEngineRepo = "https://mirror.azk8s.cn/docker-engine/apt/repo/"
+		spec.DockerSpecConfig.DockerComposeDownloadURL = "https://mirror.azk8s.cn/docker-toolbox/linux/compose"
+		spec.KubernetesSpecConfig.KubernetesImageBase = "gcr.azk8s.cn/google_containers/"
+		spec.KubernetesSpecConfig.TillerImageBase = "gcr.azk8s.cn/kubernetes-helm/"
+		spec.KubernetesSpecConfig.NVIDIAImageBase = "dockerhub.azk8s.cn/nvidia/"
+		spec.KubernetesSpecConfig.CalicoImageBase = "dockerhub.azk8s.cn/calico/"
+		spec.KubernetesSpecConfig.KubeBinariesSASURLBase = "https://mirror.azk8s.cn/kubernetes/"
+		spec.KubernetesSpecConfig.CNIPluginsDownloadURL = "https://mirror.azk8s.cn/kubernetes/containernetworking-plugins/cni-plugins-amd64-v0.7.6.tgz"
+		spec.KubernetesSpecConfig.VnetCNILinuxPluginsDownloadURL = "https://mirror.azk8s.cn/azure-cni/v1.1.3/binaries/azure-vnet-cni-linux-amd64-v1.1.3.tgz"
+		spec.KubernetesSpecConfig.VnetCNIWindowsPluginsDownloadURL = "https://mirror.azk8s.cn/azure-cni/v1.1.3/binaries/azure-vnet-cni-singletenancy-windows-amd64-v1.1.3.zip"
+		spec.KubernetesSpecConfig.ContainerdDownloadURLBase = "https://mirror.azk8s.cn/kubernetes/containerd/"
+		spec.KubernetesSpecConfig.CSIProxyDownloadURL = "https://mirror.azk8s.cn/csi-proxy/v0.1.0/binaries/csi-proxy.tar.gz"
+		spec.KubernetesSpecConfig.WindowsProvisioningScriptsPackageURL = "https://mirror.azk8s.cn/aks-engine/windows/provisioning/signedscripts-v0.2.2.zip"
+	}
+	return spec
 }
@@ -2,6 +2,7 @@ package e2e_test
 
 import (
 	"fmt"
+	"log"
 	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
@@ -41,3 +42,34 @@ func getVMSSNICConfig(vmss *armcompute.VirtualMachineScaleSet) (*armcompute.Virt
 	}
 	return nil, fmt.Errorf("unable to extract vmss nic info, vmss model or vmss model properties were nil/empty:\n%+v", vmss)
 }
+
+// Replaces the version segment of the SIG image version resource ID referenced by the VMSS model
+// with the supplied version, e.g. ".../images/2204Gen2/versions/<version>". Image definition resource IDs
+// are pinned by appending the version segment.
+func pinImageVersion(vmss *armcompute.VirtualMachineScaleSet, version string) error {
+	if vmss == nil || vmss.Properties == nil || vmss.Properties.VirtualMachineProfile == nil ||
+		vmss.Properties.VirtualMachineProfile.StorageProfile == nil ||
+		vmss.Properties.VirtualMachineProfile.StorageProfile.ImageReference == nil ||
+		vmss.Properties.VirtualMachineProfile.StorageProfile.ImageReference.ID == nil {
+		return fmt.Errorf("unable to pin image version, vmss model image reference was nil/empty:\n%+v", vmss)
+	}
+
+	imageRef := vmss.Properties.VirtualMachineProfile.StorageProfile.ImageReference
+	var pinnedID string
+	if idx := strings.LastIndex(*imageRef.ID, imageVersionsSegment); idx >= 0 {
+		pinnedID = (*imageRef.ID)[:idx+len(imageVersionsSegment)] + version
+	} else if sigImageDefinitionIDRegex.MatchString(*imageRef.ID) {
+		pinnedID = *imageRef.ID + imageVersionsSegment + version
+	} else {
+		return fmt.Errorf("image reference %q is not a SIG image version or image definition resource ID", *imageRef.ID)
+	}
+
+	log.Printf("pinning node image version to %q, using image %q", version, pinnedID)
+	imageRef.ID = &pinnedID
+	return nil
+}
+
+// Returns true if the supplied resource ID refers to either a SIG image version or a managed image
+func isImageResourceID(id string) bool {
+	return sigImageVersionIDRegex.MatchString(id) || managedImageIDRegex.MatchString(id)
+}
@@ -0,0 +1,118 @@
+package e2e_test
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+)
+
+const testImageDefinitionID = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/galleries/gallery/images/2204gen2containerd"
+
+func TestPinImageVersion(t *testing.T) {
+	cases := []struct {
+		name      string
+		imageID   string
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:     "image version is replaced",
+			imageID:  testImageDefinitionID + "/versions/1.1677169694.31375",
+			expected: testImageDefinitionID + "/versions/202402.01.0",
+		},
+		{
+			name:     "image definition is pinned to the version",
+			imageID:  testImageDefinitionID,
+			expected: testImageDefinitionID + "/versions/202402.01.0",
+		},
+		{
+			name:      "managed image can't be pinned",
+			imageID:   "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/images/custom",
+			expectErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			vmss := &armcompute.VirtualMachineScaleSet{
+				Properties: &armcompute.VirtualMachineScaleSetProperties{
+					VirtualMachineProfile: &armcompute.VirtualMachineScaleSetVMProfile{
+						StorageProfile: &armcompute.VirtualMachineScaleSetStorageProfile{
+							ImageReference: &armcompute.ImageReference{ID: to.Ptr(c.imageID)},
+						},
+					},
+				},
+			}
+			err := pinImageVersion(vmss, "202402.01.0")
+			if c.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error, but pinned image %q", *vmss.Properties.VirtualMachineProfile.StorageProfile.ImageReference.ID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, but got: %s", err)
+			}
+			if id := *vmss.Properties.VirtualMachineProfile.StorageProfile.ImageReference.ID; id != c.expected {
+				t.Errorf("expected image %q, but got %q", c.expected, id)
+			}
+		})
+	}
+
+	t.Run("no image reference", func(t *testing.T) {
+		if err := pinImageVersion(&armcompute.VirtualMachineScaleSet{}, "202402.01.0"); err == nil {
+			t.Errorf("expected an error, but got none")
+		}
+	})
+}
+
+func TestIsImageResourceID(t *testing.T) {
+	cases := []struct {
+		name     string
+		id       string
+		expected bool
+	}{
+		{
+			name:     "SIG image version",
+			id:       testImageDefinitionID + "/versions/1.1677169694.31375",
+			expected: true,
+		},
+		{
+			name:     "managed image",
+			id:       "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/images/custom",
+			expected: true,
+		},
+		{
+			name:     "resource ID differs in case",
+			id:       "/SUBSCRIPTIONS/sub/RESOURCEGROUPS/rg/PROVIDERS/microsoft.compute/IMAGES/custom",
+			expected: true,
+		},
+		{
+			name: "SIG image definition",
+			id:   testImageDefinitionID,
+		},
+		{
+			name: "resource of another type",
+			id:   "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/disks/custom",
+		},
+		{
+			name: "trailing segment",
+			id:   "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/images/custom/extra",
+		},
+		{
+			name: "image name",
+			id:   "2204gen2containerd",
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			if isImageID := isImageResourceID(c.id); isImageID != c.expected {
+				t.Errorf("expected isImageResourceID to be %t, but got %t", c.expected, isImageID)
+			}
+		})
+	}
+}
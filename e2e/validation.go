@@ -6,35 +6,59 @@ import (
 	"log"
 	"strings"
 
+	"github.com/Azure/agentbaker/pkg/agent/datamodel"
 	"github.com/Azure/agentbakere2e/scenario"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func validateNodeHealth(ctx context.Context, kube *kubeclient, vmssName string) (string, error) {
+const (
+	liveVMValidatorsArtifactName = "live-vm-validators.log"
+	kubeletConfigzArtifactName   = "kubelet-configz.json"
+
+	// the port of kubelet's healthz endpoint, served over plain HTTP on the node's loopback address only
+	kubeletHealthzPort = 10248
+
+	// records the description, command, exit code and output streams of a single live VM validator
+	liveVMValidatorOutputTemplate = "=== %s\n$ %s\nexit code: %s\n--- stdout\n%s\n--- stderr\n%s\n\n"
+)
+
+func validateNodeHealth(ctx context.Context, kube *kubeclient, namespace, vmssName string) (string, error) {
 	nodeName, err := waitUntilNodeReady(ctx, kube, vmssName)
 	if err != nil {
 		return "", fmt.Errorf("error waiting for node ready: %w", err)
 	}
 
-	nginxPodName, err := ensureTestNginxPod(ctx, kube, nodeName)
+	deploymentName, err := ensureSmokeTestDeployment(ctx, kube, namespace, nodeName)
 	if err != nil {
-		return "", fmt.Errorf("error waiting for pod ready: %w", err)
+		return "", fmt.Errorf("error waiting for smoke test deployment to run on node: %w", err)
 	}
 
-	err = waitUntilPodDeleted(ctx, kube, nginxPodName)
-	if err != nil {
-		return "", fmt.Errorf("error waiting pod deleted: %w", err)
+	if err := deleteSmokeTestDeployment(ctx, kube, namespace, deploymentName); err != nil {
+		return "", err
 	}
 
 	return nodeName, nil
 }
 
-func validateWasm(ctx context.Context, kube *kubeclient, nodeName, privateKey string) error {
-	spinPodName, err := ensureWasmPods(ctx, kube, nodeName)
+// Validates that kubelet reports itself healthy on its loopback healthz endpoint, reached by forwarding its port through the
+// hostNetwork debug pod scheduled onto the node, such that it can be validated even when the node can't be reached over SSH
+func validateKubeletHealthz(ctx context.Context, kube *kubeclient, namespace, debugPodName, nodeName string) error {
+	kubelet, err := kube.kubeletAPI(ctx, namespace, debugPodName, nodeName)
+	if err != nil {
+		return err
+	}
+	defer kubelet.close()
+
+	return kubelet.checkHealthz(ctx)
+}
+
+func validateWasm(ctx context.Context, kube *kubeclient, namespace, nodeName, privateKey string) error {
+	spinPodName, err := ensureWasmPods(ctx, kube, namespace, nodeName)
 	if err != nil {
 		return fmt.Errorf("failed to valiate wasm, unable to ensure wasm pods on node %q: %w", nodeName, err)
 	}
 
-	spinPodIP, err := getPodIP(ctx, kube, defaultNamespace, spinPodName)
+	spinPodIP, err := getPodIP(ctx, kube, namespace, spinPodName)
 	if err != nil {
 		return fmt.Errorf("unable to get IP of wasm spin pod %q: %w", spinPodName, err)
 	}
@@ -53,7 +77,7 @@ func validateWasm(ctx context.Context, kube *kubeclient, nodeName, privateKey st
 		// retry getting the pod IP + curling the hello endpoint if the original curl reports connection refused or a timeout
 		// since the wasm spin pod usually restarts at least once after initial creation, giving it a new IP
 		if execResult.exitCode == "7" || execResult.exitCode == "28" {
-			spinPodIP, err = getPodIP(ctx, kube, defaultNamespace, spinPodName)
+			spinPodIP, err = getPodIP(ctx, kube, namespace, spinPodName)
 			if err != nil {
 				return fmt.Errorf("unable to get IP of wasm spin pod %q: %w", spinPodName, err)
 			}
@@ -73,7 +97,7 @@ func validateWasm(ctx context.Context, kube *kubeclient, nodeName, privateKey st
 		}
 	}
 
-	if err := waitUntilPodDeleted(ctx, kube, spinPodName); err != nil {
+	if err := waitUntilPodDeleted(ctx, kube, namespace, spinPodName); err != nil {
 		return fmt.Errorf("error waiting for wasm pod deletion: %w", err)
 	}
 
@@ -86,35 +110,238 @@ func runLiveVMValidators(ctx context.Context, vmssName, privateIP, sshPrivateKey
 		return fmt.Errorf("unable to get debug pod name: %w", err)
 	}
 
-	validators := commonLiveVMValidators()
+	validators := commonLiveVMValidators(opts.nbc)
+	validators = append(validators, scenario.HealthMonitorValidators()...)
+	validators = append(validators, scenario.HyperVGenerationValidator(opts.scenario.HyperVGeneration))
+	validators = append(validators, scenario.DataDiskValidators(opts.scenario.DataDisks)...)
+	validators = append(validators, scenario.DNSResolutionValidators(opts.nbc)...)
+	if opts.kubeletIdentity != nil {
+		validators = append(validators, scenario.KubeletIdentityValidators(opts.kubeletIdentity.clientID, opts.kubeletIdentity.principalID, opts.cloud.environment.resourceManagerAudience())...)
+	}
+	if scenario.NetworkDualStackSelector(opts.clusterConfig.cluster) {
+		validators = append(validators, scenario.DualStackValidators(opts.nbc)...)
+	}
+	if opts.privateACR != nil {
+		validators = append(validators, scenario.PrivateRegistryResolutionValidator(opts.privateACR.loginServer, opts.privateACR.endpointIPs))
+	}
+	if opts.scenario.HTTPProxy {
+		validators = append(validators, scenario.HTTPProxyValidators(opts.nbc.HTTPProxyConfig)...)
+	}
+	if opts.scenario.ValidateKubeletServingCert {
+		if opts.kubeletServingCertFingerprint != "" {
+			validators = append(validators, scenario.KubeletRotatedServingCertValidators(opts.instance.nodeName(), opts.kubeletServingCertFingerprint)...)
+		} else {
+			validators = append(validators, scenario.KubeletSelfSignedServingCertValidators(opts.instance.nodeName())...)
+		}
+	}
 	if opts.scenario.LiveVMValidators != nil {
 		validators = append(validators, opts.scenario.LiveVMValidators...)
 	}
 
-	for _, validator := range validators {
-		desc := validator.Description
-		command := validator.Command
-		isShellBuiltIn := validator.IsShellBuiltIn
-		log.Printf("running live VM validator: %q", desc)
+	run := &liveVMValidatorRun{
+		exec: func(ctx context.Context, command string, isShellBuiltIn bool) (*podExecResult, error) {
+			return execOnVMWithRunCommandFallback(ctx, vmssName, privateIP, podName, sshPrivateKey, command, isShellBuiltIn, opts)
+		},
+	}
+	// the output of every validator is recorded, regardless of whether its assertion passed
+	defer func() {
+		if _, err := opts.artifacts.writeFile(liveVMValidatorsArtifactName, run.outputs.String()); err != nil {
+			log.Printf("failed to record live VM validator outputs: %s", err)
+		}
+	}()
 
-		execResult, err := pollExecOnVM(ctx, opts.clusterConfig.kube, privateIP, podName, sshPrivateKey, command, isShellBuiltIn)
+	// every validator is run, such that each one failing is reported, unless the VM can no longer be reached
+	var failures []string
+	for _, validator := range validators {
+		validatorFailures, err := run.validate(ctx, validator, validator.Description)
 		if err != nil {
-			return fmt.Errorf("unable to execute validator command %q: %w", command, err)
+			return err
 		}
+		failures = append(failures, validatorFailures...)
+	}
+	for _, warning := range run.warnings {
+		opts.result.recordWarning(opts.instance, warning)
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("failed %d of %d live VM validators, see %s for the output of each validator:\n%s", len(failures), len(validators), opts.artifacts.filePath(liveVMValidatorsArtifactName), strings.Join(failures, "\n"))
+	}
+
+	return nil
+}
+
+// Runs live VM validators against a single VM, recording the output of each command executed
+type liveVMValidatorRun struct {
+	exec func(ctx context.Context, command string, isShellBuiltIn bool) (*podExecResult, error)
+
+	outputs strings.Builder
+
+	// the failures of advisory validators, which don't fail the scenario
+	warnings []string
+}
+
+// Runs the validator, including each validator it composes, returning a description of each failure prefixed with the name
+// of the validator which failed, i.e. the path of descriptions from the top-level validator. The failures of advisory validators
+// are instead recorded as warnings. Returns an error when a command couldn't be executed at all, and validation can't continue
+func (r *liveVMValidatorRun) validate(ctx context.Context, validator *scenario.LiveVMValidator, name string) ([]string, error) {
+	failures, err := r.evaluate(ctx, validator, name)
+	if err != nil || validator.Severity != scenario.SeverityWarn {
+		return failures, err
+	}
+	r.warnings = append(r.warnings, failures...)
+	return nil, nil
+}
+
+// Runs the validator just as validate does, regardless of its severity
+func (r *liveVMValidatorRun) evaluate(ctx context.Context, validator *scenario.LiveVMValidator, name string) ([]string, error) {
+	parent := ctx
+	if validator.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, validator.Timeout)
+		defer cancel()
+	}
 
-		if validator.Asserter != nil {
-			err := validator.Asserter(execResult.exitCode, execResult.stdout.String(), execResult.stderr.String())
+	switch {
+	case len(validator.AllOf) > 0:
+		var failures []string
+		for _, composed := range validator.AllOf {
+			composedFailures, err := r.validate(ctx, composed, liveVMValidatorName(name, composed))
 			if err != nil {
-				execResult.dumpAll()
-				return fmt.Errorf("failed validator assertion: %w", err)
+				return nil, err
 			}
+			failures = append(failures, composedFailures...)
+		}
+		return failures, nil
+	case len(validator.AnyOf) > 0:
+		var failures []string
+		for _, composed := range validator.AnyOf {
+			composedFailures, err := r.evaluate(ctx, composed, liveVMValidatorName(name, composed))
+			if err != nil {
+				return nil, err
+			}
+			if len(composedFailures) == 0 {
+				return nil, nil
+			}
+			failures = append(failures, composedFailures...)
+		}
+		return []string{fmt.Sprintf("%s: none of its validators passed:\n  %s", name, strings.Join(failures, "\n  "))}, nil
+	}
+
+	log.Printf("running live VM validator: %q", name)
+	execResult, err := r.exec(ctx, validator.Command, validator.IsShellBuiltIn)
+	if err != nil {
+		// only the validator's own timeout having elapsed fails the validator alone
+		if ctx.Err() != nil && parent.Err() == nil {
+			return []string{fmt.Sprintf("%s: timed out after %s executing command %q", name, validator.Timeout, validator.Command)}, nil
+		}
+		return nil, fmt.Errorf("unable to execute command %q of validator %q: %w", validator.Command, name, err)
+	}
+	fmt.Fprintf(&r.outputs, liveVMValidatorOutputTemplate, name, validator.Command, execResult.exitCode, execResult.stdout.String(), execResult.stderr.String())
+
+	if validator.Asserter != nil {
+		if err := validator.Asserter(execResult.exitCode, execResult.stdout.String(), execResult.stderr.String()); err != nil {
+			execResult.dumpAll()
+			return []string{fmt.Sprintf("%s: %s", name, err)}, nil
+		}
+	}
+	return nil, nil
+}
+
+// Returns the name of a validator composed by the named validator
+func liveVMValidatorName(parent string, validator *scenario.LiveVMValidator) string {
+	return fmt.Sprintf("%s > %s", parent, validator.Description)
+}
+
+func runNodeValidators(ctx context.Context, nodeName string, opts *scenarioRunOpts) error {
+	node, err := opts.clusterConfig.kube.typed.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to get node %q: %w", nodeName, err)
+	}
+
+	validators := scenario.BootstrapConfigNodeValidators(opts.nbc)
+	if opts.scenario.NodeValidators != nil {
+		validators = append(validators, opts.scenario.NodeValidators...)
+	}
+
+	for _, validator := range validators {
+		log.Printf("running node validator: %q", validator.Description)
+		if err := validator.Asserter(node); err != nil {
+			if validator.Severity == scenario.SeverityWarn {
+				opts.result.recordWarning(opts.instance, fmt.Sprintf("%s: %s", validator.Description, err))
+				continue
+			}
+			return fmt.Errorf("failed node validator %q: %w", validator.Description, err)
+		}
+	}
+
+	return nil
+}
+
+func runKubeletConfigValidators(ctx context.Context, nodeName string, opts *scenarioRunOpts) error {
+	if len(opts.scenario.KubeletConfigValidators) == 0 {
+		return nil
+	}
+
+	configz, config, err := getKubeletConfigz(ctx, nodeName, opts)
+	if configz != nil {
+		if _, err := opts.artifacts.writeFile(kubeletConfigzArtifactName, string(configz)); err != nil {
+			log.Printf("failed to record kubelet configz: %s", err)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, validator := range opts.scenario.KubeletConfigValidators {
+		log.Printf("running kubelet config validator: %q", validator.Description)
+		if err := validator.Asserter(config); err != nil {
+			if validator.Severity == scenario.SeverityWarn {
+				opts.result.recordWarning(opts.instance, fmt.Sprintf("%s: %s", validator.Description, err))
+				continue
+			}
+			return fmt.Errorf("failed kubelet config validator %q, see %s for the kubelet's configuration: %w", validator.Description, opts.artifacts.filePath(kubeletConfigzArtifactName), err)
 		}
 	}
 
 	return nil
 }
 
-func commonLiveVMValidators() []*scenario.LiveVMValidator {
+// Returns the raw configz of the node's kubelet and the effective configuration decoded from it, as queried from kubelet
+// itself through the node's debug pod, which is scheduled onto the node unless it already has been
+func getKubeletConfigz(ctx context.Context, nodeName string, opts *scenarioRunOpts) ([]byte, map[string]any, error) {
+	kube := opts.clusterConfig.kube
+	debugPodName := opts.nodeDebugPodName
+	if debugPodName == "" {
+		name, err := ensureNodeDebugPod(ctx, kube, opts.namespace, nodeName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to ensure node debug pod: %w", err)
+		}
+		debugPodName = name
+	}
+	kubelet, err := kube.kubeletAPI(ctx, opts.namespace, debugPodName, nodeName)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer kubelet.close()
+	return kubelet.configz(ctx)
+}
+
+func commonLiveVMValidators(nbc *datamodel.NodeBootstrappingConfiguration) []*scenario.LiveVMValidator {
+	sysctls := map[string]string{
+		"net.ipv4.tcp_retries2":             "8",
+		"net.core.message_burst":            "80",
+		"net.core.message_cost":             "40",
+		"net.core.somaxconn":                "16384",
+		"net.ipv4.tcp_max_syn_backlog":      "16384",
+		"net.ipv4.neigh.default.gc_thresh1": "4096",
+		"net.ipv4.neigh.default.gc_thresh2": "8192",
+		"net.ipv4.neigh.default.gc_thresh3": "16384",
+	}
+	// some of the defaults can be overridden through the node's CustomLinuxOSConfig
+	for key, value := range scenario.CustomLinuxOSConfigSysctls(nbc.AgentPoolProfile.CustomLinuxOSConfig) {
+		if _, ok := sysctls[key]; ok {
+			sysctls[key] = value
+		}
+	}
 	return []*scenario.LiveVMValidator{
 		{
 			Description: "assert /etc/default/kubelet should not contain dynamic config dir flag",
@@ -129,18 +356,9 @@ func commonLiveVMValidators() []*scenario.LiveVMValidator {
 				return nil
 			},
 		},
-		scenario.SysctlConfigValidator(
-			map[string]string{
-				"net.ipv4.tcp_retries2":             "8",
-				"net.core.message_burst":            "80",
-				"net.core.message_cost":             "40",
-				"net.core.somaxconn":                "16384",
-				"net.ipv4.tcp_max_syn_backlog":      "16384",
-				"net.ipv4.neigh.default.gc_thresh1": "4096",
-				"net.ipv4.neigh.default.gc_thresh2": "8192",
-				"net.ipv4.neigh.default.gc_thresh3": "16384",
-			},
-		),
+		scenario.SysctlConfigValidator(sysctls),
+		scenario.SystemdUnitStatusValidator("kubelet.service", scenario.SystemdServiceRunning()),
+		scenario.SystemdUnitStatusValidator("containerd.service", scenario.SystemdServiceRunning()),
 		scenario.DirectoryValidator(
 			"/var/log/azure/aks",
 			[]string{
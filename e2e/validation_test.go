@@ -0,0 +1,137 @@
+package e2e_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Azure/agentbakere2e/scenario"
+)
+
+// Returns a validator whose command exits with the specified code, which it asserts is 0
+func exitCodeValidator(description, code string) *scenario.LiveVMValidator {
+	return &scenario.LiveVMValidator{
+		Description: description,
+		Command:     "exit " + code,
+		Asserter: func(code, stdout, stderr string) error {
+			if code != "0" {
+				return fmt.Errorf("exit code %s", code)
+			}
+			return nil
+		},
+	}
+}
+
+func TestLiveVMValidatorRunValidate(t *testing.T) {
+	pass := exitCodeValidator("pass", "0")
+	fail := exitCodeValidator("fail", "1")
+	hang := &scenario.LiveVMValidator{Description: "hang", Command: "sleep infinity"}
+	broken := &scenario.LiveVMValidator{Description: "broken", Command: "broken"}
+
+	cases := []struct {
+		name      string
+		validator *scenario.LiveVMValidator
+		failures  []string
+		warnings  []string
+		commands  []string
+		err       bool
+	}{
+		{
+			name:      "passing command",
+			validator: pass,
+			commands:  []string{"exit 0"},
+		},
+		{
+			name:      "failing command",
+			validator: fail,
+			failures:  []string{"fail: exit code 1"},
+			commands:  []string{"exit 1"},
+		},
+		{
+			name:      "AllOf reports every failure",
+			validator: scenario.AllOf("all", fail, pass, exitCodeValidator("fail again", "2")),
+			failures:  []string{"all > fail: exit code 1", "all > fail again: exit code 2"},
+			commands:  []string{"exit 1", "exit 0", "exit 2"},
+		},
+		{
+			name:      "AnyOf stops at the first passing validator",
+			validator: scenario.AnyOf("any", fail, pass, exitCodeValidator("unreached", "2")),
+			commands:  []string{"exit 1", "exit 0"},
+		},
+		{
+			name:      "AnyOf reports every failure when none pass",
+			validator: scenario.AnyOf("any", fail, exitCodeValidator("fail again", "2")),
+			failures:  []string{"any: none of its validators passed:\n  any > fail: exit code 1\n  any > fail again: exit code 2"},
+			commands:  []string{"exit 1", "exit 2"},
+		},
+		{
+			name:      "AnyOf ignores the severity of its validators",
+			validator: scenario.AnyOf("any", scenario.Advisory(fail)),
+			failures:  []string{"any: none of its validators passed:\n  any > fail: exit code 1"},
+			commands:  []string{"exit 1"},
+		},
+		{
+			name:      "advisory failures are warnings",
+			validator: scenario.Advisory(fail),
+			warnings:  []string{"fail: exit code 1"},
+			commands:  []string{"exit 1"},
+		},
+		{
+			name:      "advisory failures within AllOf are warnings",
+			validator: scenario.AllOf("all", scenario.Advisory(fail), exitCodeValidator("fail again", "2")),
+			failures:  []string{"all > fail again: exit code 2"},
+			warnings:  []string{"all > fail: exit code 1"},
+			commands:  []string{"exit 1", "exit 2"},
+		},
+		{
+			name:      "timed out validators fail",
+			validator: scenario.AllOf("all", scenario.WithTimeout(hang, time.Millisecond), pass),
+			failures:  []string{`all > hang: timed out after 1ms executing command "sleep infinity"`},
+			commands:  []string{"sleep infinity", "exit 0"},
+		},
+		{
+			name:      "commands which can't be executed stop validation",
+			validator: scenario.AllOf("all", broken, pass),
+			commands:  []string{"broken"},
+			err:       true,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			var commands []string
+			run := &liveVMValidatorRun{
+				exec: func(ctx context.Context, command string, _ bool) (*podExecResult, error) {
+					commands = append(commands, command)
+					switch command {
+					case hang.Command:
+						<-ctx.Done()
+						return nil, ctx.Err()
+					case broken.Command:
+						return nil, errors.New("unable to exec")
+					}
+					return &podExecResult{exitCode: command[len("exit "):], stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}}, nil
+				},
+			}
+
+			failures, err := run.validate(context.Background(), c.validator, c.validator.Description)
+			if c.err != (err != nil) {
+				t.Fatalf("expected error %t, but got: %v", c.err, err)
+			}
+			if !reflect.DeepEqual(failures, c.failures) {
+				t.Errorf("expected failures %q, but got %q", c.failures, failures)
+			}
+			if !reflect.DeepEqual(run.warnings, c.warnings) {
+				t.Errorf("expected warnings %q, but got %q", c.warnings, run.warnings)
+			}
+			if !reflect.DeepEqual(commands, c.commands) {
+				t.Errorf("expected commands %q to be executed, but got %q", c.commands, commands)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package e2e_test
 
 import (
 	"context"
+	crand "crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/json"
@@ -10,7 +11,10 @@ import (
 	"io"
 	"log"
 	mrand "math/rand"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Azure/agentbakere2e/scenario"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
@@ -21,7 +25,7 @@ import (
 
 const (
 	vmssNameTemplate                         = "abtest%s"
-	listVMSSNetworkInterfaceURLTemplate      = "https://management.azure.com/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachineScaleSets/%s/virtualMachines/%d/networkInterfaces?api-version=2018-10-01"
+	listVMSSNetworkInterfaceURLTemplate      = "%s/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachineScaleSets/%s/virtualMachines/%s/networkInterfaces?api-version=2018-10-01"
 	loadBalancerBackendAddressPoolIDTemplate = "/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network/loadBalancers/kubernetes/backendAddressPools/aksOutboundBackendPool"
 )
 
@@ -32,22 +36,49 @@ func bootstrapVMSS(ctx context.Context, t *testing.T, r *mrand.Rand, vmssName st
 	}
 
 	cleanupVMSS := func() {
-		log.Printf("deleting vmss %q", vmssName)
-		poller, err := opts.cloud.vmssClient.BeginDelete(ctx, *opts.clusterConfig.cluster.Properties.NodeResourceGroup, vmssName, nil)
+		// the scenario's context may have already timed out, though the VMSS still needs to be deleted
+		ctx := context.Background()
+
+		// drained beforehand, such that the VMSS' pods are evicted gracefully rather than lost along with their nodes
+		kube := opts.clusterConfig.kube
+		nodeNames, err := kube.listVMSSNodeNames(ctx, vmssName)
 		if err != nil {
+			log.Printf("unable to list nodes of vmss %q, they won't be drained or deleted: %s", vmssName, err)
+		}
+		for _, nodeName := range nodeNames {
+			if err := kube.cordonNode(ctx, nodeName); err != nil {
+				log.Printf("unable to cordon node %q: %s", nodeName, err)
+				continue
+			}
+			if err := kube.drainNode(ctx, nodeName); err != nil {
+				log.Printf("unable to drain node %q: %s", nodeName, err)
+			}
+		}
+
+		log.Printf("deleting vmss %q", vmssName)
+		if err := deleteVMSS(ctx, vmssName, opts); err != nil {
 			t.Error("error deleting vmss", vmssName, err)
 			return
 		}
-		_, err = poller.PollUntilDone(ctx, nil)
-		if err != nil {
-			t.Error("error polling deleting vmss", vmssName, err)
-		}
 		log.Printf("finished deleting vmss %q", vmssName)
+
+		// deleted once their VMs are gone, such that kubelet can't register them again, so as not to leave NotReady nodes behind
+		for _, nodeName := range nodeNames {
+			if err := kube.deleteNode(ctx, nodeName); err != nil {
+				t.Error("error deleting node of vmss", vmssName, err)
+			}
+		}
+
+		// orphaned resources leak subnet IP space, so make sure they're cleaned up even though the VMSS is gone
+		if err := deleteOrphanedVMSSResources(ctx, vmssName, opts); err != nil {
+			t.Error("error deleting orphaned resources of vmss", vmssName, err)
+		}
 	}
 
 	vmssModel, err := createVMSSWithPayload(ctx, nodeBootstrapping.CustomData, nodeBootstrapping.CSE, vmssName, publicKeyBytes, opts)
 	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create VMSS with payload: %w", err)
+		// the VMSS may still have been created, e.g. when its VMs failed to provision, so it still needs to be cleaned up
+		return vmssModel, cleanupVMSS, fmt.Errorf("unable to create VMSS with payload: %w", err)
 	}
 
 	return vmssModel, cleanupVMSS, nil
@@ -67,10 +98,40 @@ func createVMSSWithPayload(ctx context.Context, customData, cseCmd, vmssName str
 		}
 	}
 
-	if opts.scenario.VMConfigMutator != nil {
-		opts.scenario.VMConfigMutator(&model)
+	if opts.kubeletIdentity != nil {
+		assignUserAssignedIdentity(&model, opts.kubeletIdentity)
+	}
+
+	if opts.scenario.VMSSCapacity > 1 {
+		model.SKU.Capacity = to.Ptr(int64(opts.scenario.VMSSCapacity))
+	}
+
+	applyOSDiskConfig(&model, opts.scenario.OSDiskSizeGB, opts.scenario.OSDiskType)
+	attachDataDisks(&model, opts.scenario.DataDisks)
+
+	if opts.scenario.EncryptionAtHost {
+		enableEncryptionAtHost(&model)
+	}
+
+	if opts.scenario.VMSSMutator != nil {
+		opts.scenario.VMSSMutator(&model)
 	}
 
+	// the VMSS's NICs are child resources of the VMSS itself, and are thus attributed through the VMSS's tags
+	model.Tags = mergeResourceTags(model.Tags, getResourceTags(opts.suiteConfig, opts.scenario.Name, vmssResourceTTL))
+
+	if opts.suiteConfig.vhdResourceID != "" {
+		log.Printf("overriding image reference of vmss %q with custom VHD %q", vmssName, opts.suiteConfig.vhdResourceID)
+		model.Properties.VirtualMachineProfile.StorageProfile.ImageReference = &armcompute.ImageReference{
+			ID: to.Ptr(opts.suiteConfig.vhdResourceID),
+		}
+	} else if opts.suiteConfig.nodeImageVersion != "" {
+		if err := pinImageVersion(&model, opts.suiteConfig.nodeImageVersion); err != nil {
+			return nil, fmt.Errorf("failed to pin node image version: %w", err)
+		}
+	}
+
+	opts.vmssCreationRequested = time.Now()
 	pollerResp, err := opts.cloud.vmssClient.BeginCreateOrUpdate(
 		ctx,
 		*opts.clusterConfig.cluster.Properties.NodeResourceGroup,
@@ -90,6 +151,98 @@ func createVMSSWithPayload(ctx context.Context, customData, cseCmd, vmssName str
 	return &vmssResp.VirtualMachineScaleSet, nil
 }
 
+// Overrides the size and storage type of the VMSS model's OS disk, leaving either untouched when unset
+func applyOSDiskConfig(vmss *armcompute.VirtualMachineScaleSet, sizeGB int32, diskType scenario.OSDiskType) {
+	osDisk := vmss.Properties.VirtualMachineProfile.StorageProfile.OSDisk
+	if sizeGB > 0 {
+		osDisk.DiskSizeGB = to.Ptr(sizeGB)
+	}
+
+	switch diskType {
+	case "":
+	case scenario.OSDiskTypeEphemeral:
+		// ephemeral OS disks only support read-only caching
+		osDisk.Caching = to.Ptr(armcompute.CachingTypesReadOnly)
+		osDisk.DiffDiskSettings = &armcompute.DiffDiskSettings{
+			Option:    to.Ptr(armcompute.DiffDiskOptionsLocal),
+			Placement: to.Ptr(armcompute.DiffDiskPlacementResourceDisk),
+		}
+	default:
+		osDisk.ManagedDisk = &armcompute.VirtualMachineScaleSetManagedDiskParameters{
+			StorageAccountType: to.Ptr(armcompute.StorageAccountTypes(diskType)),
+		}
+	}
+}
+
+// Attaches an empty managed data disk to the VMSS model for each of the supplied data disks, at LUNs matching their index
+func attachDataDisks(vmss *armcompute.VirtualMachineScaleSet, dataDisks []scenario.DataDisk) {
+	if len(dataDisks) == 0 {
+		return
+	}
+
+	storageProfile := vmss.Properties.VirtualMachineProfile.StorageProfile
+	for lun, dataDisk := range dataDisks {
+		storageProfile.DataDisks = append(storageProfile.DataDisks, &armcompute.VirtualMachineScaleSetDataDisk{
+			Lun:          to.Ptr(int32(lun)),
+			CreateOption: to.Ptr(armcompute.DiskCreateOptionTypesEmpty),
+			DiskSizeGB:   to.Ptr(dataDisk.SizeGB),
+			ManagedDisk: &armcompute.VirtualMachineScaleSetManagedDiskParameters{
+				StorageAccountType: to.Ptr(armcompute.StorageAccountTypes(dataDisk.StorageType)),
+			},
+		})
+		if dataDisk.StorageType == scenario.DataDiskTypeUltraSSD {
+			vmss.Properties.AdditionalCapabilities = &armcompute.AdditionalCapabilities{
+				UltraSSDEnabled: to.Ptr(true),
+			}
+		}
+	}
+}
+
+// vmssInstance identifies a single VM within a scenario's VMSS
+type vmssInstance struct {
+	instanceID   string
+	computerName string
+}
+
+// Returns the name of the Kubernetes node the instance registers itself as
+func (i vmssInstance) nodeName() string {
+	return strings.ToLower(i.computerName)
+}
+
+// Lists the instances of the specified VMSS, ordered by instance ID
+func listVMSSInstances(ctx context.Context, vmssName string, opts *scenarioRunOpts) ([]vmssInstance, error) {
+	var instances []vmssInstance
+	pager := opts.cloud.vmssVMClient.NewListPager(*opts.clusterConfig.cluster.Properties.NodeResourceGroup, vmssName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list instances of vmss %q: %w", vmssName, err)
+		}
+		for _, vm := range page.Value {
+			if vm.InstanceID == nil {
+				continue
+			}
+			instance := vmssInstance{
+				instanceID: *vm.InstanceID,
+				// fall back to the computer name prefix, which still uniquely identifies the node of a single-instance VMSS
+				computerName: vmssName,
+			}
+			if vm.Properties != nil && vm.Properties.OSProfile != nil && vm.Properties.OSProfile.ComputerName != nil {
+				instance.computerName = *vm.Properties.OSProfile.ComputerName
+			}
+			instances = append(instances, instance)
+		}
+	}
+
+	sort.Slice(instances, func(i, j int) bool {
+		if len(instances[i].instanceID) != len(instances[j].instanceID) {
+			return len(instances[i].instanceID) < len(instances[j].instanceID)
+		}
+		return instances[i].instanceID < instances[j].instanceID
+	})
+	return instances, nil
+}
+
 // Adds additional IP configs to the passed in vmss model based on the chosen cluster's setting of "maxPodsPerNode",
 // as we need be able to allow AKS to allocate an additional IP config for each pod running on the given node.
 // Additional info: https://learn.microsoft.com/en-us/azure/aks/configure-azure-cni
@@ -120,48 +273,59 @@ func addPodIPConfigsForAzureCNI(vmss *armcompute.VirtualMachineScaleSet, vmssNam
 	return nil
 }
 
-func getVMPrivateIPAddress(ctx context.Context, cloud *azureClient, subscription, mcResourceGroupName, vmssName string) (string, error) {
+func getVMPrivateIPAddress(ctx context.Context, cloud *azureClient, subscription, mcResourceGroupName, vmssName, instanceID string) (string, error) {
+	instanceNICResult, err := getVMNetworkInterfaces(ctx, cloud, subscription, mcResourceGroupName, vmssName, instanceID)
+	if err != nil {
+		return "", err
+	}
+
+	privateIP, err := getPrivateIP(instanceNICResult)
+	if err != nil {
+		return "", err
+	}
+
+	return privateIP, nil
+}
+
+func getVMNetworkInterfaces(ctx context.Context, cloud *azureClient, subscription, mcResourceGroupName, vmssName, instanceID string) (listVMSSVMNetworkInterfaceResult, error) {
+	var instanceNICResult listVMSSVMNetworkInterfaceResult
+
 	pl := cloud.coreClient.Pipeline()
 	url := fmt.Sprintf(listVMSSNetworkInterfaceURLTemplate,
+		cloud.environment.resourceManagerEndpoint(),
 		subscription,
 		mcResourceGroupName,
 		vmssName,
-		0,
+		instanceID,
 	)
 	req, err := runtime.NewRequest(ctx, "GET", url)
 	if err != nil {
-		return "", err
+		return instanceNICResult, err
 	}
 
 	resp, err := pl.Do(req)
 	if err != nil {
-		return "", err
+		return instanceNICResult, err
 	}
 
 	defer resp.Body.Close()
 
 	respBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return instanceNICResult, err
 	}
 
-	var instanceNICResult listVMSSVMNetworkInterfaceResult
-
 	if err := json.Unmarshal(respBytes, &instanceNICResult); err != nil {
-		return "", err
+		return instanceNICResult, err
 	}
 
-	privateIP, err := getPrivateIP(instanceNICResult)
-	if err != nil {
-		return "", err
-	}
-
-	return privateIP, nil
+	return instanceNICResult, nil
 }
 
-// Returns a newly generated RSA public/private key pair with the private key in PEM format.
-func getNewRSAKeyPair(r *mrand.Rand) (privatePEMBytes []byte, publicKeyBytes []byte, e error) {
-	privateKey, err := rsa.GenerateKey(r, 4096)
+// Returns a newly generated RSA public/private key pair with the private key in PEM format. Keys are generated from
+// crypto/rand rather than the suite's seeded randomness, such that they can't be reproduced from the logged seed
+func getNewRSAKeyPair() (privatePEMBytes []byte, publicKeyBytes []byte, e error) {
+	privateKey, err := rsa.GenerateKey(crand.Reader, 4096)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create rsa private key: %w", err)
 	}
@@ -0,0 +1,334 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Watch timeouts
+	waitUntilNodeReadyTimeout                = 5 * time.Minute
+	waitUntilNodeUnavailableTimeout          = 10 * time.Minute
+	waitUntilNodeResourcesAllocatableTimeout = 5 * time.Minute
+	waitUntilPodRunningTimeout               = 3 * time.Minute
+	waitUntilPodReadyTimeout                 = 5 * time.Minute
+	waitUntilPodSucceededTimeout             = 10 * time.Minute
+	waitUntilDaemonSetRolledOutTimeout       = 5 * time.Minute
+	waitUntilDeploymentRolledOutTimeout      = 3 * time.Minute
+	waitUntilManifestObjectReadyTimeout      = 5 * time.Minute
+)
+
+// Watches the objects of the type of the list's items selected by the list options, typically a single object selected by
+// watchedObject, until the condition holds for one of their events, or the timeout passes. The objects' current state is
+// delivered as added events once the watch has synced, and the watch is re-established should it be closed by the apiserver.
+// The precondition, when set, is evaluated against the synced store before any event
+func (k *kubeclient) watchUntil(ctx context.Context, timeout time.Duration, list client.ObjectList, objType runtime.Object,
+	precondition watchtools.PreconditionFunc, condition watchtools.ConditionFunc, listOpts ...client.ListOption) (*watch.Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// the options of each list and watch made by the informer, e.g. the resource version to watch from, are applied last
+	withRaw := func(options metav1.ListOptions) []client.ListOption {
+		return append(append([]client.ListOption{}, listOpts...), &client.ListOptions{Raw: &options})
+	}
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			current := list.DeepCopyObject().(client.ObjectList)
+			if err := k.dynamic.List(ctx, current, withRaw(options)...); err != nil {
+				return nil, err
+			}
+			return current, nil
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return k.dynamic.Watch(ctx, list.DeepCopyObject().(client.ObjectList), withRaw(options)...)
+		},
+	}
+	return watchtools.UntilWithSync(ctx, lw, objType, precondition, condition)
+}
+
+// Returns the list options selecting the named object alone, within the namespace unless it's empty as for cluster-scoped objects
+func watchedObject(namespace, name string) []client.ListOption {
+	return []client.ListOption{client.InNamespace(namespace), client.MatchingFieldsSelector{Selector: fields.OneTermEqualSelector("metadata.name", name)}}
+}
+
+// Returns a condition converting the event's object into the typed object, before asserting whether the condition holds for it.
+// Fails the watch when the object is deleted, unless the condition handles deletions itself
+func typedCondition[T runtime.Object](condition func(eventType watch.EventType, obj T) (bool, error)) watchtools.ConditionFunc {
+	return func(event watch.Event) (bool, error) {
+		obj, ok := event.Object.(T)
+		if !ok {
+			if status, ok := event.Object.(*metav1.Status); ok {
+				return false, fmt.Errorf("watch failed: %s", status.Message)
+			}
+			return false, fmt.Errorf("unexpected object %T in watch event", event.Object)
+		}
+		return condition(event.Type, obj)
+	}
+}
+
+// Waits until the node has registered with the cluster and reports itself ready, returning the node's name
+func waitUntilNodeReady(ctx context.Context, kube *kubeclient, nodeName string) (string, error) {
+	var last *corev1.Node
+	_, err := kube.watchUntil(ctx, waitUntilNodeReadyTimeout, &corev1.NodeList{}, &corev1.Node{}, nil,
+		typedCondition(func(eventType watch.EventType, node *corev1.Node) (bool, error) {
+			if eventType == watch.Deleted {
+				last = nil
+				return false, nil
+			}
+			last = node
+			return isNodeReady(node), nil
+		}), watchedObject("", nodeName)...)
+	if err != nil {
+		if last == nil {
+			return "", fmt.Errorf("failed to find or wait for node %q to be ready, it never registered with the cluster: %w", nodeName, err)
+		}
+		return "", fmt.Errorf("failed to find or wait for node %q to be ready, its last conditions were %v: %w", nodeName, describeNodeConditions(last), err)
+	}
+	return nodeName, nil
+}
+
+// Waits until the node reports each of the expected resources as allocatable in the expected quantity, e.g. once a device
+// plugin has registered with kubelet and advertised its devices
+func waitUntilNodeResourcesAllocatable(ctx context.Context, kube *kubeclient, nodeName string, expected map[corev1.ResourceName]int64) error {
+	var allocatable corev1.ResourceList
+	_, err := kube.watchUntil(ctx, waitUntilNodeResourcesAllocatableTimeout, &corev1.NodeList{}, &corev1.Node{}, nil,
+		typedCondition(func(eventType watch.EventType, node *corev1.Node) (bool, error) {
+			if eventType == watch.Deleted {
+				return false, fmt.Errorf("node %q was removed from the cluster", nodeName)
+			}
+			allocatable = node.Status.Allocatable
+			for name, quantity := range expected {
+				if actual, ok := allocatable[name]; !ok || actual.Value() != quantity {
+					return false, nil
+				}
+			}
+			return true, nil
+		}), watchedObject("", nodeName)...)
+	if err != nil {
+		return fmt.Errorf("failed waiting for node %q to report allocatable resources %v, last reported allocatable resources were %v: %w", nodeName, expected, allocatable, err)
+	}
+	return nil
+}
+
+// Waits until the node is no longer ready and has been tainted to prevent pods from being scheduled onto it,
+// or has been removed from the cluster altogether
+func waitUntilNodeUnavailable(ctx context.Context, kube *kubeclient, nodeName string) error {
+	removed := func() (bool, error) {
+		log.Printf("node %q has been removed from the cluster", nodeName)
+		return true, nil
+	}
+	_, err := kube.watchUntil(ctx, waitUntilNodeUnavailableTimeout, &corev1.NodeList{}, &corev1.Node{},
+		func(store cache.Store) (bool, error) {
+			if len(store.List()) == 0 {
+				return removed()
+			}
+			return false, nil
+		},
+		typedCondition(func(eventType watch.EventType, node *corev1.Node) (bool, error) {
+			if eventType == watch.Deleted {
+				return removed()
+			}
+			if isNodeReady(node) {
+				return false, nil
+			}
+			for _, taint := range node.Spec.Taints {
+				if unavailableNodeTaintKeys[taint.Key] {
+					log.Printf("node %q is no longer ready and has been tainted with %s", nodeName, taint.ToString())
+					return true, nil
+				}
+			}
+			return false, nil
+		}), watchedObject("", nodeName)...)
+	if err != nil {
+		return fmt.Errorf("failed waiting for node %q to become unavailable: %w", nodeName, err)
+	}
+	return nil
+}
+
+func waitUntilPodRunning(ctx context.Context, kube *kubeclient, namespace, podName string) error {
+	var phase corev1.PodPhase
+	_, err := kube.watchUntil(ctx, waitUntilPodRunningTimeout, &corev1.PodList{}, &corev1.Pod{}, nil,
+		typedCondition(func(eventType watch.EventType, pod *corev1.Pod) (bool, error) {
+			if eventType == watch.Deleted {
+				return false, fmt.Errorf("pod %s/%s was deleted", namespace, podName)
+			}
+			phase = pod.Status.Phase
+			return phase == corev1.PodRunning, nil
+		}), watchedObject(namespace, podName)...)
+	if err != nil {
+		return fmt.Errorf("failed waiting for pod %s/%s to be running, its last phase was %q: %w", namespace, podName, phase, err)
+	}
+	return nil
+}
+
+// Waits until the pod is ready, i.e. each of its containers is passing its readiness probe, bounded by waitUntilPodReadyTimeout,
+// which allows for containers installing their packages as they start
+func waitUntilPodReady(ctx context.Context, kube *kubeclient, namespace, podName string) error {
+	var phase corev1.PodPhase
+	_, err := kube.watchUntil(ctx, waitUntilPodReadyTimeout, &corev1.PodList{}, &corev1.Pod{}, nil,
+		typedCondition(func(eventType watch.EventType, pod *corev1.Pod) (bool, error) {
+			if eventType == watch.Deleted {
+				return false, fmt.Errorf("pod %s/%s was deleted", namespace, podName)
+			}
+			phase = pod.Status.Phase
+			if phase == corev1.PodFailed || phase == corev1.PodSucceeded {
+				return false, fmt.Errorf("pod %s/%s terminated: %s", namespace, podName, describeContainerTerminations(pod))
+			}
+			return isPodReady(pod), nil
+		}), watchedObject(namespace, podName)...)
+	if err != nil {
+		return fmt.Errorf("failed waiting for pod %s/%s to be ready, its last phase was %q: %w", namespace, podName, phase, err)
+	}
+	return nil
+}
+
+// Waits until each of the pod's containers has run to completion successfully, bounded by waitUntilPodSucceededTimeout, which
+// allows for the volumes of the pod to be provisioned and attached before it starts
+func waitUntilPodSucceeded(ctx context.Context, kube *kubeclient, namespace, podName string) error {
+	var phase corev1.PodPhase
+	_, err := kube.watchUntil(ctx, waitUntilPodSucceededTimeout, &corev1.PodList{}, &corev1.Pod{}, nil,
+		typedCondition(func(eventType watch.EventType, pod *corev1.Pod) (bool, error) {
+			if eventType == watch.Deleted {
+				return false, fmt.Errorf("pod %s/%s was deleted", namespace, podName)
+			}
+			phase = pod.Status.Phase
+			if phase == corev1.PodFailed {
+				return false, fmt.Errorf("pod %s/%s failed: %s", namespace, podName, describeContainerTerminations(pod))
+			}
+			return phase == corev1.PodSucceeded, nil
+		}), watchedObject(namespace, podName)...)
+	if err != nil {
+		return fmt.Errorf("failed waiting for pod %s/%s to succeed, its last phase was %q: %w", namespace, podName, phase, err)
+	}
+	return nil
+}
+
+// Waits until the pod of the daemonset scheduled onto the node is running, returning its name. The daemonset's pods are
+// selected by their "app" label, which the suite's daemonsets set to their name
+func waitUntilDaemonSetPodRunning(ctx context.Context, kube *kubeclient, namespace, daemonSetName, nodeName string) (string, error) {
+	var podName string
+	var phase corev1.PodPhase
+	_, err := kube.watchUntil(ctx, waitUntilPodRunningTimeout, &corev1.PodList{}, &corev1.Pod{}, nil,
+		typedCondition(func(eventType watch.EventType, pod *corev1.Pod) (bool, error) {
+			if eventType == watch.Deleted || pod.DeletionTimestamp != nil {
+				return false, nil
+			}
+			phase = pod.Status.Phase
+			podName = pod.Name
+			return phase == corev1.PodRunning, nil
+		}),
+		client.InNamespace(namespace), client.MatchingLabels{"app": daemonSetName},
+		client.MatchingFieldsSelector{Selector: fields.OneTermEqualSelector("spec.nodeName", nodeName)})
+	if err != nil {
+		if podName == "" {
+			return "", fmt.Errorf("failed waiting for daemonset %s/%s to schedule a pod onto node %q: %w", namespace, daemonSetName, nodeName, err)
+		}
+		return "", fmt.Errorf("failed waiting for pod %s/%s of daemonset %s to be running, its last phase was %q: %w", namespace, podName, daemonSetName, phase, err)
+	}
+	return podName, nil
+}
+
+// Waits until each of the daemonset's pods have been updated to its current spec and are available, as "kubectl rollout status" does
+func waitUntilDaemonSetRolledOut(ctx context.Context, kube *kubeclient, namespace, name string) error {
+	var status appsv1.DaemonSetStatus
+	_, err := kube.watchUntil(ctx, waitUntilDaemonSetRolledOutTimeout, &appsv1.DaemonSetList{}, &appsv1.DaemonSet{}, nil,
+		typedCondition(func(eventType watch.EventType, daemonSet *appsv1.DaemonSet) (bool, error) {
+			if eventType == watch.Deleted {
+				return false, fmt.Errorf("daemonset %s/%s was deleted", namespace, name)
+			}
+			status = daemonSet.Status
+			return isDaemonSetRolledOut(daemonSet), nil
+		}), watchedObject(namespace, name)...)
+	if err != nil {
+		return fmt.Errorf("failed waiting for daemonset %s/%s to roll out, %d of %d pods were updated and %d available: %w", namespace, name,
+			status.UpdatedNumberScheduled, status.DesiredNumberScheduled, status.NumberAvailable, err)
+	}
+	return nil
+}
+
+// Waits until each of the deployment's replicas have been updated to its current spec and are available, as "kubectl rollout status" does
+func waitUntilDeploymentRolledOut(ctx context.Context, kube *kubeclient, namespace, name string) error {
+	var status appsv1.DeploymentStatus
+	_, err := kube.watchUntil(ctx, waitUntilDeploymentRolledOutTimeout, &appsv1.DeploymentList{}, &appsv1.Deployment{}, nil,
+		typedCondition(func(eventType watch.EventType, deployment *appsv1.Deployment) (bool, error) {
+			if eventType == watch.Deleted {
+				return false, fmt.Errorf("deployment %s/%s was deleted", namespace, name)
+			}
+			status = deployment.Status
+			return isDeploymentRolledOut(deployment), nil
+		}), watchedObject(namespace, name)...)
+	if err != nil {
+		return fmt.Errorf("failed waiting for deployment %s/%s to roll out, %d of its replicas were updated and %d available: %w", namespace, name,
+			status.UpdatedReplicas, status.AvailableReplicas, err)
+	}
+	return nil
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func isDeploymentRolledOut(deployment *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	return deployment.Status.ObservedGeneration >= deployment.Generation && deployment.Status.UpdatedReplicas == replicas &&
+		deployment.Status.AvailableReplicas == replicas
+}
+
+func isDaemonSetRolledOut(daemonSet *appsv1.DaemonSet) bool {
+	return daemonSet.Status.ObservedGeneration >= daemonSet.Generation && daemonSet.Status.DesiredNumberScheduled > 0 &&
+		daemonSet.Status.UpdatedNumberScheduled == daemonSet.Status.DesiredNumberScheduled &&
+		daemonSet.Status.NumberAvailable == daemonSet.Status.DesiredNumberScheduled
+}
+
+// Describes each of the node's conditions by its type, status and reason
+func describeNodeConditions(node *corev1.Node) []string {
+	conditions := make([]string, 0, len(node.Status.Conditions))
+	for _, cond := range node.Status.Conditions {
+		conditions = append(conditions, fmt.Sprintf("%s=%s (%s)", cond.Type, cond.Status, cond.Reason))
+	}
+	return conditions
+}
+
+// Describes how each of the pod's terminated containers terminated, e.g. "write: exit code 1 (Error)"
+func describeContainerTerminations(pod *corev1.Pod) string {
+	var terminations []string
+	for _, status := range pod.Status.ContainerStatuses {
+		if terminated := status.State.Terminated; terminated != nil {
+			terminations = append(terminations, fmt.Sprintf("%s: exit code %d (%s) %s", status.Name, terminated.ExitCode, terminated.Reason, terminated.Message))
+		}
+	}
+	if len(terminations) == 0 {
+		return "no containers terminated"
+	}
+	return strings.Join(terminations, ", ")
+}